@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sangkips/investify-api/internal/application/service"
@@ -12,15 +18,27 @@ import (
 	"github.com/sangkips/investify-api/internal/presentation/http/handler"
 	"github.com/sangkips/investify-api/internal/presentation/http/routes"
 	"github.com/sangkips/investify-api/pkg/email"
+	"github.com/sangkips/investify-api/pkg/logger"
 	"github.com/sangkips/investify-api/pkg/oauth"
+	"github.com/sangkips/investify-api/pkg/pagination"
 	"github.com/sangkips/investify-api/pkg/printer"
+	"github.com/sangkips/investify-api/pkg/storage"
 	"github.com/sangkips/investify-api/pkg/utils"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish on shutdown
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Structured JSON logging
+	logger.Init(cfg.App.Debug)
+
+	// Page size ceiling for list endpoints
+	pagination.SetMaxPageSize(cfg.Pagination.MaxPageSize)
+
 	// Set Gin mode based on environment
 	if cfg.App.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -42,11 +60,15 @@ func main() {
 		log.Printf("Warning: Failed to seed default data: %v", err)
 	}
 
+	// Read replica for reports and the dashboard (the primary if none is configured)
+	readDB := database.NewReplicaDB(&cfg.Database, db)
+
 	// Initialize JWT manager
 	jwtManager := utils.NewJWTManager(
 		cfg.JWT.Secret,
 		cfg.JWT.ExpiryHours,
 		cfg.JWT.RefreshExpiryHours,
+		cfg.JWT.StepUpExpiry,
 	)
 
 	// Initialize repositories
@@ -54,6 +76,7 @@ func main() {
 	roleRepo := repository.NewRoleRepository(db)
 	tenantRepo := repository.NewTenantRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	productImageRepo := repository.NewProductImageRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
 	unitRepo := repository.NewUnitRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
@@ -62,25 +85,52 @@ func main() {
 	purchaseDetailRepo := repository.NewPurchaseDetailRepository(db)
 	customerRepo := repository.NewCustomerRepository(db)
 	supplierRepo := repository.NewSupplierRepository(db)
+	supplierProductRepo := repository.NewSupplierProductRepository(db)
 	idempotencyRepo := repository.NewIdempotencyRepository(db)
 	quotationRepo := repository.NewQuotationRepository(db)
 	quotationDetailRepo := repository.NewQuotationDetailRepository(db)
+	sequenceRepo := repository.NewSequenceRepository(db)
+	loyaltyRepo := repository.NewLoyaltyRepository(db)
+	orderPaymentRepo := repository.NewOrderPaymentRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
+	shiftRepo := repository.NewShiftRepository(db)
 	settingsRepo := repository.NewSettingsRepository(db)
 	permissionRepo := repository.NewPermissionRepository(db)
-	analyticsRepo := repository.NewAnalyticsRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(readDB)
 	passwordResetRepo := repository.NewPasswordResetTokenRepository(db)
 	mpesaTxRepo := repository.NewMpesaTransactionRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	txManager := repository.NewTxManager(db)
+
+	// Read-only repositories on the replica; these may lag the primary slightly
+	readOrderRepo := repository.NewOrderRepository(readDB)
+	readPurchaseRepo := repository.NewPurchaseRepository(readDB)
+	readProductRepo := repository.NewProductRepository(readDB)
+	readCustomerRepo := repository.NewCustomerRepository(readDB)
+	readTenantRepo := repository.NewTenantRepository(readDB)
 
 	// Initialize email service
-	emailService := email.NewEmailService(email.EmailConfig{
-		SMTPHost:     cfg.Email.SMTPHost,
-		SMTPPort:     cfg.Email.SMTPPort,
-		SMTPUsername: cfg.Email.SMTPUsername,
-		SMTPPassword: cfg.Email.SMTPPassword,
-		FromName:     cfg.Email.FromName,
-		FromEmail:    cfg.Email.FromEmail,
-		FrontendURL:  cfg.Email.FrontendURL,
+	emailService, err := email.NewEmailService(email.EmailConfig{
+		Transport:          cfg.Email.Transport,
+		SMTPHost:           cfg.Email.SMTPHost,
+		SMTPPort:           cfg.Email.SMTPPort,
+		SMTPUsername:       cfg.Email.SMTPUsername,
+		SMTPPassword:       cfg.Email.SMTPPassword,
+		SMTPDisableTLS:     cfg.Email.SMTPDisableTLS,
+		SendGridAPIKey:     cfg.Email.SendGridAPIKey,
+		SESRegion:          cfg.Email.SESRegion,
+		SESAccessKeyID:     cfg.Email.SESAccessKeyID,
+		SESSecretAccessKey: cfg.Email.SESSecretAccessKey,
+		FromName:           cfg.Email.FromName,
+		FromEmail:          cfg.Email.FromEmail,
+		FrontendURL:        cfg.Email.FrontendURL,
+		Workers:            cfg.Email.Workers,
+		QueueSize:          cfg.Email.QueueSize,
+		MaxRetries:         cfg.Email.MaxRetries,
 	})
+	if err != nil {
+		log.Fatalf("Failed to initialize email service: %v", err)
+	}
 
 	// Initialize Google OAuth service
 	googleOAuthService := oauth.NewGoogleOAuthService(oauth.GoogleOAuthConfig{
@@ -91,21 +141,31 @@ func main() {
 		FrontendErrorURL:   cfg.OAuth.FrontendErrorURL,
 	})
 
+	// Uploaded files (profile photos) are kept on local disk and served by the API
+	fileStorage := storage.NewLocalStorage(cfg.Storage.Path, cfg.Storage.PublicURL)
+
 	// Initialize services
-	authService := service.NewAuthService(userRepo, roleRepo, tenantRepo, passwordResetRepo, jwtManager, emailService, googleOAuthService)
+	authService := service.NewAuthService(userRepo, roleRepo, tenantRepo, passwordResetRepo, jwtManager, emailService, googleOAuthService, fileStorage)
 	tenantService := service.NewTenantService(tenantRepo)
-	productService := service.NewProductService(productRepo, categoryRepo, unitRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
-	unitService := service.NewUnitService(unitRepo)
-	orderService := service.NewOrderService(orderRepo, orderDetailRepo, productRepo, customerRepo, emailService, tenantRepo)
-	purchaseService := service.NewPurchaseService(purchaseRepo, purchaseDetailRepo, productRepo, supplierRepo)
-	customerService := service.NewCustomerService(customerRepo)
-	supplierService := service.NewSupplierService(supplierRepo)
-	dashboardService := service.NewDashboardService(orderRepo, purchaseRepo, productRepo, customerRepo, analyticsRepo, tenantRepo)
-	quotationService := service.NewQuotationService(quotationRepo, quotationDetailRepo, productRepo, customerRepo)
-	settingsService := service.NewSettingsService(settingsRepo)
-	userService := service.NewUserService(userRepo, roleRepo, permissionRepo)
+	productService := service.NewProductService(productRepo, categoryRepo, unitRepo, tenantRepo, productImageRepo, txManager)
+	categoryService := service.NewCategoryService(categoryRepo, txManager)
+	unitService := service.NewUnitService(unitRepo, txManager)
+	orderService := service.NewOrderService(orderRepo, orderDetailRepo, productRepo, customerRepo, emailService, tenantRepo, txManager, sequenceRepo, loyaltyRepo, promotionRepo, shiftRepo, orderPaymentRepo)
+	purchaseService := service.NewPurchaseService(purchaseRepo, purchaseDetailRepo, productRepo, supplierRepo, supplierProductRepo, sequenceRepo, tenantRepo, txManager)
+	customerService := service.NewCustomerService(customerRepo, loyaltyRepo)
+	promotionService := service.NewPromotionService(promotionRepo)
+	// Z-reports reconcile the cash drawer, so they read the primary rather than the replica
+	shiftService := service.NewShiftService(shiftRepo, repository.NewAnalyticsRepository(db))
+	statementService := service.NewStatementService(customerRepo, orderRepo, orderPaymentRepo, tenantRepo)
+	supplierService := service.NewSupplierService(supplierRepo, supplierProductRepo)
+	reportService := service.NewReportService(analyticsRepo, readOrderRepo)
+	dashboardService := service.NewDashboardService(readOrderRepo, readPurchaseRepo, readProductRepo, readCustomerRepo, analyticsRepo, readTenantRepo)
+	quotationService := service.NewQuotationService(quotationRepo, quotationDetailRepo, productRepo, customerRepo, sequenceRepo, tenantRepo, txManager)
+	settingsService := service.NewSettingsService(settingsRepo, tenantRepo)
+	userService := service.NewUserService(userRepo, roleRepo, permissionRepo, tenantRepo, jwtManager)
 	mpesaService := service.NewMpesaService(mpesaTxRepo, tenantRepo, orderRepo, orderService)
+	auditService := service.NewAuditService(auditLogRepo)
+	impersonationService := service.NewImpersonationService(userRepo, tenantRepo, jwtManager, auditService)
 
 	// Initialize thermal printer
 	thermalPrinter, err := printer.NewPrinterFromConfig(
@@ -117,25 +177,32 @@ func main() {
 		log.Printf("Warning: Failed to initialize printer: %v", err)
 		thermalPrinter = printer.NewNullPrinter()
 	}
-	printerService := service.NewPrinterService(thermalPrinter, orderRepo, quotationRepo, cfg.Printer.Type)
+	printerService := service.NewPrinterService(thermalPrinter, orderRepo, quotationRepo, tenantRepo, userRepo, cfg.Printer.Type)
 
 	// Initialize handlers
 	handlers := &routes.Handlers{
-		Auth:      handler.NewAuthHandler(authService),
-		Tenant:    handler.NewTenantHandler(tenantService),
-		Product:   handler.NewProductHandler(productService),
-		Category:  handler.NewCategoryHandler(categoryService),
-		Unit:      handler.NewUnitHandler(unitService),
-		Order:     handler.NewOrderHandler(orderService, mpesaService),
-		Purchase:  handler.NewPurchaseHandler(purchaseService),
-		Customer:  handler.NewCustomerHandler(customerService),
-		Supplier:  handler.NewSupplierHandler(supplierService),
-		Dashboard: handler.NewDashboardHandler(dashboardService),
-		Quotation: handler.NewQuotationHandler(quotationService),
-		Settings:  handler.NewSettingsHandler(settingsService),
-		User:      handler.NewUserHandler(userService),
-		Printer:   handler.NewPrinterHandler(printerService),
-		Mpesa:     handler.NewMpesaHandler(mpesaService),
+		Auth:          handler.NewAuthHandler(authService),
+		Tenant:        handler.NewTenantHandler(tenantService),
+		Product:       handler.NewProductHandler(productService),
+		Category:      handler.NewCategoryHandler(categoryService),
+		Unit:          handler.NewUnitHandler(unitService),
+		Order:         handler.NewOrderHandler(orderService, mpesaService),
+		Purchase:      handler.NewPurchaseHandler(purchaseService),
+		Customer:      handler.NewCustomerHandler(customerService, statementService),
+		Supplier:      handler.NewSupplierHandler(supplierService),
+		Promotion:     handler.NewPromotionHandler(promotionService),
+		Shift:         handler.NewShiftHandler(shiftService),
+		Dashboard:     handler.NewDashboardHandler(dashboardService),
+		Quotation:     handler.NewQuotationHandler(quotationService),
+		Settings:      handler.NewSettingsHandler(settingsService),
+		User:          handler.NewUserHandler(userService),
+		Printer:       handler.NewPrinterHandler(printerService),
+		Report:        handler.NewReportHandler(reportService),
+		Mpesa:         handler.NewMpesaHandler(mpesaService),
+		Audit:         handler.NewAuditHandler(auditService),
+		Impersonation: handler.NewImpersonationHandler(impersonationService),
+		Health:        handler.NewHealthHandler(db, emailService),
+		Enum:          handler.NewEnumHandler(),
 	}
 
 	// Setup routes
@@ -143,6 +210,9 @@ func main() {
 		JWTManager:      jwtManager,
 		Cfg:             cfg,
 		IdempotencyRepo: idempotencyRepo,
+		AuditService:    auditService,
+		TenantRepo:      tenantRepo,
+		UserRepo:        userRepo,
 	})
 
 	// Get port from environment or use default
@@ -154,8 +224,30 @@ func main() {
 	log.Printf("Starting %s server on port %s...", cfg.App.Name, port)
 	log.Printf("Environment: %s", cfg.App.Env)
 
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
-		os.Exit(1)
+	srv := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for an interrupt or a termination signal from the process manager
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down server...")
+
+	// Stop accepting connections and let in-flight requests finish
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Server did not shut down cleanly: %v", err)
 	}
+
+	// Requests are done queuing work, so drain the queued audit entries and emails
+	// before exiting rather than losing them
+	auditService.Close()
+	emailService.Close()
+
+	log.Println("Server stopped")
 }
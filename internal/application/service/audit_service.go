@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// auditQueueSize is the number of pending audit entries buffered before new ones are dropped
+const auditQueueSize = 1000
+
+// AuditService records and queries audit log entries.
+// Writes are queued and persisted by a background worker so they never slow down requests.
+type AuditService struct {
+	auditRepo repository.AuditLogRepository
+	queue     chan *entity.AuditLog
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{} // Closed once the worker has written every queued entry
+}
+
+// NewAuditService creates a new audit service and starts its background writer
+func NewAuditService(auditRepo repository.AuditLogRepository) *AuditService {
+	s := &AuditService{
+		auditRepo: auditRepo,
+		queue:     make(chan *entity.AuditLog, auditQueueSize),
+		done:      make(chan struct{}),
+	}
+
+	go s.worker()
+
+	return s
+}
+
+// Record queues an audit entry for asynchronous persistence.
+// If the queue is full, or the service has been closed, the entry is dropped and logged
+// rather than blocking the caller.
+func (s *AuditService) Record(entry *entity.AuditLog) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		log.Printf("Audit log closed, dropping entry: %s %s %s", entry.Action, entry.ResourceType, entry.ResourceID)
+		return
+	}
+
+	select {
+	case s.queue <- entry:
+	default:
+		log.Printf("Audit log queue full, dropping entry: %s %s %s", entry.Action, entry.ResourceType, entry.ResourceID)
+	}
+}
+
+// Close stops accepting entries and waits until the queued ones have been written
+func (s *AuditService) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	<-s.done
+}
+
+// worker persists queued audit entries until the service is closed
+func (s *AuditService) worker() {
+	defer close(s.done)
+
+	for entry := range s.queue {
+		if err := s.auditRepo.Create(context.Background(), entry); err != nil {
+			log.Printf("Failed to write audit log (%s %s): %v", entry.Action, entry.ResourceType, err)
+		}
+	}
+}
+
+// ListAuditLogs lists audit logs with filtering
+func (s *AuditService) ListAuditLogs(ctx context.Context, params *repository.AuditLogFilterParams) (*pagination.PaginatedResult[entity.AuditLog], error) {
+	logs, total, err := s.auditRepo.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	pag := pagination.NewPagination(params.Pagination.Page, params.Pagination.PerPage, total)
+	return pagination.NewPaginatedResult(logs, pag), nil
+}
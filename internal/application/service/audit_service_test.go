@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+)
+
+type fakeAuditLogRepo struct {
+	repository.AuditLogRepository
+	mu      sync.Mutex
+	written int
+}
+
+func (r *fakeAuditLogRepo) Create(ctx context.Context, entry *entity.AuditLog) error {
+	time.Sleep(time.Millisecond)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.written++
+	return nil
+}
+
+func TestAuditServiceCloseWritesQueuedEntries(t *testing.T) {
+	repo := &fakeAuditLogRepo{}
+	s := NewAuditService(repo)
+
+	for i := 0; i < 20; i++ {
+		s.Record(&entity.AuditLog{Action: "update"})
+	}
+	s.Close()
+
+	if repo.written != 20 {
+		t.Errorf("wrote %d entries before Close returned, want 20", repo.written)
+	}
+
+	// Entries recorded after Close are dropped rather than panicking on the closed queue
+	s.Record(&entity.AuditLog{Action: "update"})
+}
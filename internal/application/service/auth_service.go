@@ -1,11 +1,15 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +19,7 @@ import (
 	"github.com/sangkips/investify-api/pkg/email"
 	"github.com/sangkips/investify-api/pkg/oauth"
 	"github.com/sangkips/investify-api/pkg/pagination"
+	"github.com/sangkips/investify-api/pkg/storage"
 	"github.com/sangkips/investify-api/pkg/utils"
 )
 
@@ -27,6 +32,7 @@ type AuthService struct {
 	jwtManager        *utils.JWTManager
 	emailService      *email.EmailService
 	googleOAuth       *oauth.GoogleOAuthService
+	fileStorage       storage.Storage
 }
 
 // NewAuthService creates a new auth service
@@ -38,6 +44,7 @@ func NewAuthService(
 	jwtManager *utils.JWTManager,
 	emailService *email.EmailService,
 	googleOAuth *oauth.GoogleOAuthService,
+	fileStorage storage.Storage,
 ) *AuthService {
 	return &AuthService{
 		userRepo:          userRepo,
@@ -47,6 +54,7 @@ func NewAuthService(
 		jwtManager:        jwtManager,
 		emailService:      emailService,
 		googleOAuth:       googleOAuth,
+		fileStorage:       fileStorage,
 	}
 }
 
@@ -76,6 +84,9 @@ func (s *AuthService) Login(ctx context.Context, input *LoginInput) (*LoginOutpu
 	if !utils.CheckPasswordHash(input.Password, user.Password) {
 		return nil, apperror.ErrInvalidCredentials
 	}
+	if !user.IsActive {
+		return nil, apperror.ErrAccountDeactivated
+	}
 
 	// Get user with roles
 	user, err = s.userRepo.GetWithRoles(ctx, user.ID)
@@ -245,6 +256,9 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*L
 	if user == nil {
 		return nil, apperror.ErrNotFound
 	}
+	if !user.IsActive {
+		return nil, apperror.ErrAccountDeactivated
+	}
 
 	roles := make([]string, 0)
 	for _, role := range user.Roles {
@@ -293,9 +307,13 @@ type ChangePasswordInput struct {
 	UserID          uuid.UUID
 	CurrentPassword string
 	NewPassword     string
+	// StepUp is set when the request was made with a step-up token
+	StepUp bool
 }
 
-// ChangePassword changes the user's password
+// ChangePassword changes the user's password. Users who signed up with Google have no
+// password yet and set their first one without a current password, but only with the
+// step-up token issued by a fresh Google sign-in; they can then sign in either way.
 func (s *AuthService) ChangePassword(ctx context.Context, input *ChangePasswordInput) error {
 	user, err := s.userRepo.GetByID(ctx, input.UserID)
 	if err != nil {
@@ -305,7 +323,10 @@ func (s *AuthService) ChangePassword(ctx context.Context, input *ChangePasswordI
 		return apperror.ErrNotFound
 	}
 
-	if !utils.CheckPasswordHash(input.CurrentPassword, user.Password) {
+	if user.Password == "" && !input.StepUp {
+		return apperror.NewAppError(http.StatusForbidden, "Setting a first password requires recent re-authentication; sign in with Google again and use the step_up_token it returns")
+	}
+	if user.Password != "" && !utils.CheckPasswordHash(input.CurrentPassword, user.Password) {
 		return errors.New("current password is incorrect")
 	}
 
@@ -318,6 +339,69 @@ func (s *AuthService) ChangePassword(ctx context.Context, input *ChangePasswordI
 	return s.userRepo.Update(ctx, user)
 }
 
+// StepUpInput represents a re-authentication request
+type StepUpInput struct {
+	UserID   uuid.UUID
+	TenantID uuid.UUID
+	Password string
+}
+
+// StepUpOutput is a short-lived token for actions that require recent re-authentication
+type StepUpOutput struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// StepUp checks the user's password again and issues a step-up token for the same tenant
+func (s *AuthService) StepUp(ctx context.Context, input *StepUpInput) (*StepUpOutput, error) {
+	user, err := s.userRepo.GetWithRoles(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, apperror.ErrNotFound
+	}
+
+	if !utils.CheckPasswordHash(input.Password, user.Password) {
+		return nil, apperror.ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, apperror.ErrAccountDeactivated
+	}
+
+	roles := make([]string, 0)
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+	}
+
+	token, expiresAt, err := s.jwtManager.GenerateStepUpToken(user.ID, input.TenantID, user.Email, roles, user.GetPermissions())
+	if err != nil {
+		return nil, err
+	}
+
+	return &StepUpOutput{AccessToken: token, ExpiresAt: expiresAt}, nil
+}
+
+// DeactivateAccount deactivates the user's own account and revokes their tokens. Their
+// orders, audit entries and other records are kept; an admin can reactivate the account.
+func (s *AuthService) DeactivateAccount(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return apperror.ErrNotFound
+	}
+
+	user.IsActive = false
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.jwtManager.RevokeUserTokens(user.ID)
+	return nil
+}
+
 // UpdateProfileInput represents the update profile input
 type UpdateProfileInput struct {
 	UserID       uuid.UUID
@@ -331,6 +415,71 @@ type UpdateProfileInput struct {
 	StoreEmail   *string
 }
 
+// maxProfilePhotoSize is the largest profile photo that may be uploaded
+const maxProfilePhotoSize = 5 << 20
+
+// profilePhotoTypes maps the accepted photo content types to file extensions
+var profilePhotoTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// UploadProfilePhoto stores a new profile photo for the user, points the profile at it
+// and removes the photo it replaces. The type is sniffed from the content rather than
+// trusted from the client.
+func (s *AuthService) UploadProfilePhoto(ctx context.Context, userID uuid.UUID, photo io.Reader, size int64) (*entity.User, error) {
+	if size > maxProfilePhotoSize {
+		return nil, apperror.NewBadRequestError(fmt.Sprintf("Photo must be at most %d MB", maxProfilePhotoSize>>20))
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(photo, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, apperror.NewBadRequestError("Photo is empty or unreadable")
+	}
+	head = head[:n]
+	ext, ok := profilePhotoTypes[http.DetectContentType(head)]
+	if !ok {
+		return nil, apperror.NewBadRequestError("Photo must be a JPEG, PNG, WebP or GIF image")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, apperror.ErrNotFound
+	}
+
+	// A random name per upload keeps cached copies of the old photo from being served
+	name := make([]byte, 8)
+	if _, err := rand.Read(name); err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("profile-photos/%s/%s%s", user.ID, hex.EncodeToString(name), ext)
+	url, err := s.fileStorage.Save(ctx, key, io.MultiReader(bytes.NewReader(head), photo))
+	if err != nil {
+		return nil, err
+	}
+
+	previous := user.Photo
+	user.Photo = &url
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		_ = s.fileStorage.Delete(ctx, url)
+		return nil, err
+	}
+
+	if previous != nil {
+		if err := s.fileStorage.Delete(ctx, *previous); err != nil {
+			log.Printf("Failed to delete previous profile photo of user %s: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
 // UpdateProfile updates the user's profile
 func (s *AuthService) UpdateProfile(ctx context.Context, input *UpdateProfileInput) (*entity.User, error) {
 	user, err := s.userRepo.GetByID(ctx, input.UserID)
@@ -422,10 +571,10 @@ func (s *AuthService) ForgotPassword(ctx context.Context, input *ForgotPasswordI
 		return err
 	}
 
-	// Send the password reset email
+	// Queue the password reset email; delivery is retried in the background. The
+	// caller always reports success, so log with the token reference for support.
 	if err := s.emailService.SendPasswordResetEmail(input.Email, token); err != nil {
-		// Log error but still return success
-		// In production, you might want to queue this for retry
+		log.Printf("Failed to queue password reset email to %s (token %s): %v", input.Email, email.TokenRef(token), err)
 		return err
 	}
 
@@ -504,8 +653,11 @@ type GoogleAuthOutput struct {
 	User         *entity.User
 	AccessToken  string
 	RefreshToken string
-	IsNewUser    bool
-	HasTenants   bool
+	// StepUpToken is issued to accounts without a password so the fresh Google sign-in
+	// can be used to set their first one
+	StepUpToken string
+	IsNewUser   bool
+	HasTenants  bool
 }
 
 // GetGoogleAuthURL returns the Google OAuth consent URL
@@ -549,12 +701,16 @@ func (s *AuthService) GoogleAuth(ctx context.Context, input *GoogleAuthInput) (*
 	var user *entity.User
 	var isNewUser bool
 
-	// First, try to find user by Google provider ID
-	user, err = s.userRepo.GetByProviderID(ctx, "google", googleUser.ID)
+	// First, try to find the user linked to this Google account
+	user, err = s.userRepo.GetByGoogleID(ctx, googleUser.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	if user != nil && !user.IsActive {
+		return nil, apperror.ErrAccountDeactivated
+	}
+
 	if user == nil {
 		// Try to find user by email (for account linking)
 		user, err = s.userRepo.GetByEmail(ctx, googleUser.Email)
@@ -563,9 +719,18 @@ func (s *AuthService) GoogleAuth(ctx context.Context, input *GoogleAuthInput) (*
 		}
 
 		if user != nil {
-			// Link existing account with Google
-			user.Provider = "google"
-			user.ProviderID = &googleUser.ID
+			if !user.IsActive {
+				return nil, apperror.ErrAccountDeactivated
+			}
+
+			// Only an address Google has verified proves the caller owns the account
+			if !googleUser.VerifiedEmail {
+				return nil, apperror.NewConflictError("An account with this email already exists; sign in with your password")
+			}
+
+			// Link the Google account to the existing one. Provider stays as it was so
+			// a password account can still sign in with its password.
+			user.GoogleID = &googleUser.ID
 			if googleUser.Picture != "" && user.Photo == nil {
 				user.Photo = &googleUser.Picture
 			}
@@ -605,6 +770,7 @@ func (s *AuthService) GoogleAuth(ctx context.Context, input *GoogleAuthInput) (*
 				Email:           googleUser.Email,
 				Provider:        "google",
 				ProviderID:      &googleUser.ID,
+				GoogleID:        &googleUser.ID,
 				Photo:           &googleUser.Picture,
 				EmailVerifiedAt: &now,
 			}
@@ -613,7 +779,7 @@ func (s *AuthService) GoogleAuth(ctx context.Context, input *GoogleAuthInput) (*
 				return nil, err
 			}
 
-			// Assign default "admin" role
+			// Assign default "admin" role, as Register does
 			defaultRole, err := s.roleRepo.GetByName(ctx, "admin")
 			if err == nil && defaultRole != nil {
 				_ = s.userRepo.AssignRole(ctx, user.ID, defaultRole.ID)
@@ -651,10 +817,19 @@ func (s *AuthService) GoogleAuth(ctx context.Context, input *GoogleAuthInput) (*
 		return nil, err
 	}
 
+	var stepUpToken string
+	if user.Password == "" {
+		stepUpToken, _, err = s.jwtManager.GenerateStepUpToken(user.ID, tenantID, user.Email, roles, permissions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &GoogleAuthOutput{
 		User:         user,
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+		StepUpToken:  stepUpToken,
 		IsNewUser:    isNewUser,
 		HasTenants:   len(tenants) > 0,
 	}, nil
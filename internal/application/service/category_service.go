@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/entity"
@@ -12,20 +14,45 @@ import (
 	"github.com/sangkips/investify-api/pkg/utils"
 )
 
+// BatchSkippedItem reports a batch entry that was not created
+type BatchSkippedItem struct {
+	Index  int    `json:"index"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// BatchCreateResult holds the outcome of a batch create, reported per item
+type BatchCreateResult[T any] struct {
+	Created []T                `json:"created"`
+	Skipped []BatchSkippedItem `json:"skipped"`
+}
+
+// skipBatchItem reports whether err should skip a single batch entry rather than
+// abort the whole batch. Only duplicates and invalid entries are skipped.
+func skipBatchItem(err error) (string, bool) {
+	appErr := apperror.GetAppError(err)
+	if appErr.Code == http.StatusConflict || appErr.Code == http.StatusBadRequest {
+		return appErr.Message, true
+	}
+	return "", false
+}
+
 // CategoryService handles category-related operations
 type CategoryService struct {
 	categoryRepo repository.CategoryRepository
+	txManager    repository.TxManager
 }
 
 // NewCategoryService creates a new category service
-func NewCategoryService(categoryRepo repository.CategoryRepository) *CategoryService {
-	return &CategoryService{categoryRepo: categoryRepo}
+func NewCategoryService(categoryRepo repository.CategoryRepository, txManager repository.TxManager) *CategoryService {
+	return &CategoryService{categoryRepo: categoryRepo, txManager: txManager}
 }
 
 // CreateCategoryInput represents the create category input
 type CreateCategoryInput struct {
-	UserID uuid.UUID
-	Name   string
+	UserID               uuid.UUID
+	Name                 string
+	DefaultQuantityAlert int
 }
 
 // CreateCategory creates a new category
@@ -52,6 +79,8 @@ func (s *CategoryService) CreateCategory(ctx context.Context, input *CreateCateg
 		UserID:   input.UserID,
 		Name:     input.Name,
 		Slug:     slug,
+
+		DefaultQuantityAlert: input.DefaultQuantityAlert,
 	}
 
 	if err := s.categoryRepo.Create(ctx, category); err != nil {
@@ -61,6 +90,42 @@ func (s *CategoryService) CreateCategory(ctx context.Context, input *CreateCateg
 	return category, nil
 }
 
+// BatchCreateCategories creates categories from a list of names in a single transaction.
+// Duplicates (existing or repeated within the batch) and blank names are skipped and
+// reported; any other error rolls back the whole batch.
+func (s *CategoryService) BatchCreateCategories(ctx context.Context, userID uuid.UUID, names []string) (*BatchCreateResult[entity.Category], error) {
+	result := &BatchCreateResult[entity.Category]{
+		Created: []entity.Category{},
+		Skipped: []BatchSkippedItem{},
+	}
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, name := range names {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				result.Skipped = append(result.Skipped, BatchSkippedItem{Index: i, Name: name, Reason: "Name is required"})
+				continue
+			}
+
+			category, err := s.CreateCategory(ctx, &CreateCategoryInput{UserID: userID, Name: name})
+			if err != nil {
+				if reason, skip := skipBatchItem(err); skip {
+					result.Skipped = append(result.Skipped, BatchSkippedItem{Index: i, Name: name, Reason: reason})
+					continue
+				}
+				return err
+			}
+			result.Created = append(result.Created, *category)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // GetCategory retrieves a category by ID
 func (s *CategoryService) GetCategory(ctx context.Context, id uuid.UUID) (*entity.Category, error) {
 	category, err := s.categoryRepo.GetByID(ctx, id)
@@ -90,6 +155,8 @@ type UpdateCategoryInput struct {
 	ID           uuid.UUID
 	IsSuperAdmin bool
 	Name         string
+
+	DefaultQuantityAlert *int // nil leaves the default unchanged
 }
 
 // UpdateCategory updates a category
@@ -120,6 +187,9 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, input *UpdateCateg
 	}
 
 	category.Name = input.Name
+	if input.DefaultQuantityAlert != nil {
+		category.DefaultQuantityAlert = *input.DefaultQuantityAlert
+	}
 
 	if err := s.categoryRepo.Update(ctx, category); err != nil {
 		return nil, err
@@ -148,12 +218,13 @@ func (s *CategoryService) DeleteCategory(ctx context.Context, userID, id uuid.UU
 
 // UnitService handles unit-related operations
 type UnitService struct {
-	unitRepo repository.UnitRepository
+	unitRepo  repository.UnitRepository
+	txManager repository.TxManager
 }
 
 // NewUnitService creates a new unit service
-func NewUnitService(unitRepo repository.UnitRepository) *UnitService {
-	return &UnitService{unitRepo: unitRepo}
+func NewUnitService(unitRepo repository.UnitRepository, txManager repository.TxManager) *UnitService {
+	return &UnitService{unitRepo: unitRepo, txManager: txManager}
 }
 
 // CreateUnitInput represents the create unit input
@@ -196,6 +267,43 @@ func (s *UnitService) CreateUnit(ctx context.Context, input *CreateUnitInput) (*
 	return unit, nil
 }
 
+// BatchCreateUnits creates units in a single transaction.
+// Duplicates (existing or repeated within the batch) and blank names are skipped and
+// reported; any other error rolls back the whole batch.
+func (s *UnitService) BatchCreateUnits(ctx context.Context, userID uuid.UUID, inputs []CreateUnitInput) (*BatchCreateResult[entity.Unit], error) {
+	result := &BatchCreateResult[entity.Unit]{
+		Created: []entity.Unit{},
+		Skipped: []BatchSkippedItem{},
+	}
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, input := range inputs {
+			input.UserID = userID
+			input.Name = strings.TrimSpace(input.Name)
+			if input.Name == "" {
+				result.Skipped = append(result.Skipped, BatchSkippedItem{Index: i, Name: input.Name, Reason: "Name is required"})
+				continue
+			}
+
+			unit, err := s.CreateUnit(ctx, &input)
+			if err != nil {
+				if reason, skip := skipBatchItem(err); skip {
+					result.Skipped = append(result.Skipped, BatchSkippedItem{Index: i, Name: input.Name, Reason: reason})
+					continue
+				}
+				return err
+			}
+			result.Created = append(result.Created, *unit)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // GetUnit retrieves a unit by ID
 func (s *UnitService) GetUnit(ctx context.Context, id uuid.UUID) (*entity.Unit, error) {
 	unit, err := s.unitRepo.GetByID(ctx, id)
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
+	"github.com/sangkips/investify-api/pkg/apperror"
+)
+
+// ImportCustomerRow represents a single row from a customer import file
+type ImportCustomerRow struct {
+	Name          string
+	Email         string
+	Phone         string
+	KRAPin        string
+	Address       string
+	AccountHolder string
+	AccountNumber string
+	BankName      string
+}
+
+// ImportSupplierRow represents a single row from a supplier import file
+type ImportSupplierRow struct {
+	Name          string
+	Email         string
+	Phone         string
+	KRAPin        string
+	Address       string
+	ShopName      string
+	Type          string
+	AccountHolder string
+	AccountNumber string
+	BankName      string
+}
+
+// contactDeduper detects rows whose email or phone matches an existing record or an
+// earlier row of the same file
+type contactDeduper struct {
+	emails map[string]int // normalized email -> row number, 0 for existing records
+	phones map[string]int // normalized phone -> row number, 0 for existing records
+}
+
+func newContactDeduper() *contactDeduper {
+	return &contactDeduper{emails: make(map[string]int), phones: make(map[string]int)}
+}
+
+// add records an email/phone pair as taken by rowNum
+func (d *contactDeduper) add(rowNum int, email, phone *string) {
+	if email != nil {
+		if key := normalizeEmail(*email); key != "" {
+			d.emails[key] = rowNum
+		}
+	}
+	if phone != nil {
+		if key := normalizePhone(*phone); key != "" {
+			d.phones[key] = rowNum
+		}
+	}
+}
+
+// check validates the email and returns an error if the email or phone is already taken
+func (d *contactDeduper) check(rowNum int, email, phone string) *ImportRowError {
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return &ImportRowError{Row: rowNum, Field: "email", Message: fmt.Sprintf("Invalid email '%s'", email)}
+		}
+		if prev, exists := d.emails[normalizeEmail(email)]; exists {
+			return &ImportRowError{Row: rowNum, Field: "email", Message: duplicateMessage("email", email, prev)}
+		}
+	}
+	if phone != "" {
+		if normalizePhone(phone) == "" {
+			return &ImportRowError{Row: rowNum, Field: "phone", Message: fmt.Sprintf("Invalid phone '%s'", phone)}
+		}
+		if prev, exists := d.phones[normalizePhone(phone)]; exists {
+			return &ImportRowError{Row: rowNum, Field: "phone", Message: duplicateMessage("phone", phone, prev)}
+		}
+	}
+	return nil
+}
+
+func duplicateMessage(field, value string, prevRow int) string {
+	if prevRow == 0 {
+		return fmt.Sprintf("A record with %s '%s' already exists", field, value)
+	}
+	return fmt.Sprintf("Duplicate %s '%s' (same as row %d)", field, value, prevRow)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizePhone keeps only the digits so "+254 712-345678" and "254712345678" match
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// optional returns nil for an empty string so blank cells are stored as NULL
+func optional(v string) *string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+// ImportCustomers validates and bulk-creates customers from parsed import rows.
+// Rows whose email or phone already belongs to a customer are reported and skipped.
+func (s *CustomerService) ImportCustomers(ctx context.Context, userID uuid.UUID, rows []ImportCustomerRow) (*ImportResult, error) {
+	tenantID, ok := infraRepo.GetTenantID(ctx)
+	if !ok {
+		return nil, apperror.NewBadRequestError("Tenant context required")
+	}
+
+	existing, err := s.customerRepo.ListContacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := newContactDeduper()
+	for _, c := range existing {
+		seen.add(0, c.Email, c.Phone)
+	}
+
+	result := &ImportResult{TotalRows: len(rows)}
+	var rowErrors []ImportRowError
+	var valid []entity.Customer
+
+	for i, row := range rows {
+		rowNum := i + 2 // +2 because row 1 is the header, data starts at row 2
+
+		if strings.TrimSpace(row.Name) == "" {
+			rowErrors = append(rowErrors, ImportRowError{Row: rowNum, Field: "name", Message: "Name is required"})
+			continue
+		}
+		if rowErr := seen.check(rowNum, row.Email, row.Phone); rowErr != nil {
+			rowErrors = append(rowErrors, *rowErr)
+			continue
+		}
+
+		customer := entity.Customer{
+			TenantID:      tenantID,
+			UserID:        userID,
+			CreatedByID:   &userID,
+			Name:          strings.TrimSpace(row.Name),
+			Email:         optional(row.Email),
+			Phone:         optional(row.Phone),
+			KRAPin:        optional(row.KRAPin),
+			Address:       optional(row.Address),
+			AccountHolder: optional(row.AccountHolder),
+			AccountNumber: optional(row.AccountNumber),
+			BankName:      optional(row.BankName),
+		}
+		seen.add(rowNum, customer.Email, customer.Phone)
+		valid = append(valid, customer)
+	}
+
+	if err := s.customerRepo.CreateBatch(ctx, valid); err != nil {
+		return nil, apperror.NewAppError(500, "Failed to import customers: "+err.Error())
+	}
+
+	result.Successful = len(valid)
+	result.Failed = len(rowErrors)
+	result.Errors = rowErrors
+
+	return result, nil
+}
+
+// ImportSuppliers validates and bulk-creates suppliers from parsed import rows.
+// Rows whose email or phone already belongs to a supplier are reported and skipped.
+func (s *SupplierService) ImportSuppliers(ctx context.Context, userID uuid.UUID, rows []ImportSupplierRow) (*ImportResult, error) {
+	tenantID, ok := infraRepo.GetTenantID(ctx)
+	if !ok {
+		return nil, apperror.NewBadRequestError("Tenant context required")
+	}
+
+	existing, err := s.supplierRepo.ListContacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := newContactDeduper()
+	for _, sup := range existing {
+		seen.add(0, sup.Email, sup.Phone)
+	}
+
+	result := &ImportResult{TotalRows: len(rows)}
+	var rowErrors []ImportRowError
+	var valid []entity.Supplier
+
+	for i, row := range rows {
+		rowNum := i + 2 // +2 because row 1 is the header, data starts at row 2
+
+		if strings.TrimSpace(row.Name) == "" {
+			rowErrors = append(rowErrors, ImportRowError{Row: rowNum, Field: "name", Message: "Name is required"})
+			continue
+		}
+
+		supplierType := enum.SupplierTypeDistributor
+		if row.Type != "" {
+			supplierType = enum.SupplierType(strings.ToLower(row.Type))
+			switch supplierType {
+			case enum.SupplierTypeDistributor, enum.SupplierTypeWholesaler, enum.SupplierTypeProducer:
+			default:
+				rowErrors = append(rowErrors, ImportRowError{
+					Row:     rowNum,
+					Field:   "type",
+					Message: fmt.Sprintf("Unknown supplier type '%s'", row.Type),
+				})
+				continue
+			}
+		}
+
+		if rowErr := seen.check(rowNum, row.Email, row.Phone); rowErr != nil {
+			rowErrors = append(rowErrors, *rowErr)
+			continue
+		}
+
+		supplier := entity.Supplier{
+			TenantID:      tenantID,
+			UserID:        userID,
+			CreatedByID:   &userID,
+			Name:          strings.TrimSpace(row.Name),
+			Email:         optional(row.Email),
+			Phone:         optional(row.Phone),
+			KRAPin:        optional(row.KRAPin),
+			Address:       optional(row.Address),
+			ShopName:      optional(row.ShopName),
+			Type:          supplierType,
+			AccountHolder: optional(row.AccountHolder),
+			AccountNumber: optional(row.AccountNumber),
+			BankName:      optional(row.BankName),
+		}
+		seen.add(rowNum, supplier.Email, supplier.Phone)
+		valid = append(valid, supplier)
+	}
+
+	if err := s.supplierRepo.CreateBatch(ctx, valid); err != nil {
+		return nil, apperror.NewAppError(500, "Failed to import suppliers: "+err.Error())
+	}
+
+	result.Successful = len(valid)
+	result.Failed = len(rowErrors)
+	result.Errors = rowErrors
+
+	return result, nil
+}
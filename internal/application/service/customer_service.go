@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,11 +16,12 @@ import (
 // CustomerService handles customer-related operations
 type CustomerService struct {
 	customerRepo repository.CustomerRepository
+	loyaltyRepo  repository.LoyaltyRepository
 }
 
 // NewCustomerService creates a new customer service
-func NewCustomerService(customerRepo repository.CustomerRepository) *CustomerService {
-	return &CustomerService{customerRepo: customerRepo}
+func NewCustomerService(customerRepo repository.CustomerRepository, loyaltyRepo repository.LoyaltyRepository) *CustomerService {
+	return &CustomerService{customerRepo: customerRepo, loyaltyRepo: loyaltyRepo}
 }
 
 // CreateCustomerInput represents the create customer input
@@ -33,6 +35,7 @@ type CreateCustomerInput struct {
 	AccountHolder *string
 	AccountNumber *string
 	BankName      *string
+	TaxExempt     bool
 }
 
 // CreateCustomer creates a new customer
@@ -46,6 +49,7 @@ func (s *CustomerService) CreateCustomer(ctx context.Context, input *CreateCusto
 	customer := &entity.Customer{
 		TenantID:      tenantID,
 		UserID:        input.UserID,
+		CreatedByID:   &input.UserID,
 		Name:          input.Name,
 		Email:         input.Email,
 		Phone:         input.Phone,
@@ -54,6 +58,7 @@ func (s *CustomerService) CreateCustomer(ctx context.Context, input *CreateCusto
 		AccountHolder: input.AccountHolder,
 		AccountNumber: input.AccountNumber,
 		BankName:      input.BankName,
+		TaxExempt:     input.TaxExempt,
 	}
 
 	if err := s.customerRepo.Create(ctx, customer); err != nil {
@@ -82,7 +87,7 @@ func (s *CustomerService) ListCustomers(ctx context.Context, userID uuid.UUID, p
 		return nil, err
 	}
 
-	pag := pagination.NewPagination(params.Page, params.PerPage, total)
+	pag := pagination.NewPaginationForParams(params, total)
 	return pagination.NewPaginatedResult(customers, pag), nil
 }
 
@@ -93,15 +98,11 @@ func (s *CustomerService) ListCustomersWithCursor(ctx context.Context, userID uu
 		return nil, err
 	}
 
-	// Determine if there was a cursor provided (meaning we're not on first page)
-	hasPrev := params.Cursor != ""
-
 	// Build cursor pagination response
-	cursorPag, items := pagination.NewCursorPagination(customers, params.Limit,
+	cursorPag, items := pagination.NewCursorPagination(customers, params,
 		func(c entity.Customer) string { return c.ID.String() },
 		func(c entity.Customer) time.Time { return c.CreatedAt },
 	)
-	cursorPag.HasPrev = hasPrev
 
 	return pagination.NewCursorPaginatedResult(items, cursorPag), nil
 }
@@ -111,6 +112,7 @@ type UpdateCustomerInput struct {
 	UserID        uuid.UUID
 	ID            uuid.UUID
 	IsSuperAdmin  bool
+	Version       *int // Version the client read; the update is rejected if the customer has moved on
 	Name          *string
 	Email         *string
 	Phone         *string
@@ -119,6 +121,7 @@ type UpdateCustomerInput struct {
 	AccountHolder *string
 	AccountNumber *string
 	BankName      *string
+	TaxExempt     *bool
 }
 
 // UpdateCustomer updates a customer
@@ -135,6 +138,9 @@ func (s *CustomerService) UpdateCustomer(ctx context.Context, input *UpdateCusto
 	if !input.IsSuperAdmin && customer.UserID != input.UserID {
 		return nil, apperror.ErrForbidden
 	}
+	if input.Version != nil && *input.Version != customer.Version {
+		return nil, apperror.ErrVersionConflict
+	}
 
 	if input.Name != nil {
 		customer.Name = *input.Name
@@ -160,6 +166,11 @@ func (s *CustomerService) UpdateCustomer(ctx context.Context, input *UpdateCusto
 	if input.BankName != nil {
 		customer.BankName = input.BankName
 	}
+	if input.TaxExempt != nil {
+		customer.TaxExempt = *input.TaxExempt
+	}
+	customer.UpdatedByID = &input.UserID
+	customer.UpdatedBy = nil
 
 	if err := s.customerRepo.Update(ctx, customer); err != nil {
 		return nil, err
@@ -168,12 +179,19 @@ func (s *CustomerService) UpdateCustomer(ctx context.Context, input *UpdateCusto
 	return customer, nil
 }
 
-// DeleteCustomer deletes a customer
-func (s *CustomerService) DeleteCustomer(ctx context.Context, userID, id uuid.UUID, isSuperAdmin bool) error {
+// DeleteCustomer moves a customer to the trash. With permanent set it removes the
+// customer for good (also from the trash), which is refused while orders or
+// quotations still reference it.
+func (s *CustomerService) DeleteCustomer(ctx context.Context, userID, id uuid.UUID, isSuperAdmin, permanent bool) error {
 	customer, err := s.customerRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if customer == nil && permanent {
+		if customer, err = s.customerRepo.GetDeletedByID(ctx, id); err != nil {
+			return err
+		}
+	}
 	if customer == nil {
 		return apperror.NewNotFoundError("Customer")
 	}
@@ -183,17 +201,93 @@ func (s *CustomerService) DeleteCustomer(ctx context.Context, userID, id uuid.UU
 		return apperror.ErrForbidden
 	}
 
-	return s.customerRepo.Delete(ctx, id)
+	if !permanent {
+		return s.customerRepo.Delete(ctx, id)
+	}
+
+	refs, err := s.customerRepo.CountReferences(ctx, id)
+	if err != nil {
+		return err
+	}
+	if refs > 0 {
+		return apperror.NewConflictError(fmt.Sprintf(
+			"Customer is referenced by %d order(s)/quotation(s) and can only be moved to the trash", refs))
+	}
+
+	return s.customerRepo.HardDelete(ctx, id)
+}
+
+// RestoreCustomer brings a customer back from the trash
+func (s *CustomerService) RestoreCustomer(ctx context.Context, userID, id uuid.UUID, isSuperAdmin bool) (*entity.Customer, error) {
+	customer, err := s.customerRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, apperror.NewNotFoundError("Deleted customer")
+	}
+
+	// Super-admin can restore any customer, regular users can only restore their own
+	if !isSuperAdmin && customer.UserID != userID {
+		return nil, apperror.ErrForbidden
+	}
+
+	if err := s.customerRepo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.customerRepo.GetByID(ctx, id)
+}
+
+// CustomerLoyalty is a customer's loyalty points balance and a page of their ledger
+type CustomerLoyalty struct {
+	CustomerID    uuid.UUID                                              `json:"customer_id"`
+	LoyaltyPoints int64                                                  `json:"loyalty_points"`
+	Entries       *pagination.PaginatedResult[entity.LoyaltyTransaction] `json:"entries"`
+}
+
+// GetCustomerLoyalty returns a customer's loyalty points balance and ledger entries, newest first
+func (s *CustomerService) GetCustomerLoyalty(ctx context.Context, id uuid.UUID, params *pagination.PaginationParams) (*CustomerLoyalty, error) {
+	customer, err := s.customerRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, apperror.NewNotFoundError("Customer")
+	}
+
+	entries, total, err := s.loyaltyRepo.ListByCustomer(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CustomerLoyalty{
+		CustomerID:    customer.ID,
+		LoyaltyPoints: customer.LoyaltyPoints,
+		Entries:       pagination.NewPaginatedResult(entries, pagination.NewPagination(params.Page, params.PerPage, total)),
+	}, nil
+}
+
+// ListDeletedCustomers lists customers in the trash. If isSuperAdmin is true, returns all of them.
+func (s *CustomerService) ListDeletedCustomers(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, isSuperAdmin bool) (*pagination.PaginatedResult[entity.Customer], error) {
+	customers, total, err := s.customerRepo.ListDeleted(ctx, userID, params, search, isSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	pag := pagination.NewPagination(params.Page, params.PerPage, total)
+	return pagination.NewPaginatedResult(customers, pag), nil
 }
 
 // SupplierService handles supplier-related operations
 type SupplierService struct {
-	supplierRepo repository.SupplierRepository
+	supplierRepo        repository.SupplierRepository
+	supplierProductRepo repository.SupplierProductRepository
 }
 
 // NewSupplierService creates a new supplier service
-func NewSupplierService(supplierRepo repository.SupplierRepository) *SupplierService {
-	return &SupplierService{supplierRepo: supplierRepo}
+func NewSupplierService(supplierRepo repository.SupplierRepository, supplierProductRepo repository.SupplierProductRepository) *SupplierService {
+	return &SupplierService{supplierRepo: supplierRepo, supplierProductRepo: supplierProductRepo}
 }
 
 // CreateSupplierInput represents the create supplier input
@@ -222,6 +316,7 @@ func (s *SupplierService) CreateSupplier(ctx context.Context, input *CreateSuppl
 	supplier := &entity.Supplier{
 		TenantID:      tenantID,
 		UserID:        input.UserID,
+		CreatedByID:   &input.UserID,
 		Name:          input.Name,
 		Email:         input.Email,
 		Phone:         input.Phone,
@@ -252,6 +347,22 @@ func (s *SupplierService) GetSupplier(ctx context.Context, id uuid.UUID) (*entit
 	return supplier, nil
 }
 
+// ListSupplierProducts lists the products bought from a supplier with the last cost
+// paid for each, most recently purchased first
+func (s *SupplierService) ListSupplierProducts(ctx context.Context, supplierID uuid.UUID, params *pagination.PaginationParams) (*pagination.PaginatedResult[entity.SupplierProduct], error) {
+	if _, err := s.GetSupplier(ctx, supplierID); err != nil {
+		return nil, err
+	}
+
+	items, total, err := s.supplierProductRepo.ListBySupplier(ctx, supplierID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	pag := pagination.NewPagination(params.Page, params.PerPage, total)
+	return pagination.NewPaginatedResult(items, pag), nil
+}
+
 // ListSuppliers lists suppliers. If isSuperAdmin is true, returns all suppliers.
 func (s *SupplierService) ListSuppliers(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, isSuperAdmin bool) (*pagination.PaginatedResult[entity.Supplier], error) {
 	suppliers, total, err := s.supplierRepo.List(ctx, userID, params, search, isSuperAdmin)
@@ -322,6 +433,8 @@ func (s *SupplierService) UpdateSupplier(ctx context.Context, input *UpdateSuppl
 	if input.BankName != nil {
 		supplier.BankName = input.BankName
 	}
+	supplier.UpdatedByID = &input.UserID
+	supplier.UpdatedBy = nil
 
 	if err := s.supplierRepo.Update(ctx, supplier); err != nil {
 		return nil, err
@@ -330,12 +443,19 @@ func (s *SupplierService) UpdateSupplier(ctx context.Context, input *UpdateSuppl
 	return supplier, nil
 }
 
-// DeleteSupplier deletes a supplier
-func (s *SupplierService) DeleteSupplier(ctx context.Context, userID, id uuid.UUID, isSuperAdmin bool) error {
+// DeleteSupplier moves a supplier to the trash. With permanent set it removes the
+// supplier for good (also from the trash), which is refused while purchases still
+// reference it.
+func (s *SupplierService) DeleteSupplier(ctx context.Context, userID, id uuid.UUID, isSuperAdmin, permanent bool) error {
 	supplier, err := s.supplierRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if supplier == nil && permanent {
+		if supplier, err = s.supplierRepo.GetDeletedByID(ctx, id); err != nil {
+			return err
+		}
+	}
 	if supplier == nil {
 		return apperror.NewNotFoundError("Supplier")
 	}
@@ -345,5 +465,51 @@ func (s *SupplierService) DeleteSupplier(ctx context.Context, userID, id uuid.UU
 		return apperror.ErrForbidden
 	}
 
-	return s.supplierRepo.Delete(ctx, id)
+	if !permanent {
+		return s.supplierRepo.Delete(ctx, id)
+	}
+
+	refs, err := s.supplierRepo.CountReferences(ctx, id)
+	if err != nil {
+		return err
+	}
+	if refs > 0 {
+		return apperror.NewConflictError(fmt.Sprintf(
+			"Supplier is referenced by %d purchase(s) and can only be moved to the trash", refs))
+	}
+
+	return s.supplierRepo.HardDelete(ctx, id)
+}
+
+// RestoreSupplier brings a supplier back from the trash
+func (s *SupplierService) RestoreSupplier(ctx context.Context, userID, id uuid.UUID, isSuperAdmin bool) (*entity.Supplier, error) {
+	supplier, err := s.supplierRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, apperror.NewNotFoundError("Deleted supplier")
+	}
+
+	// Super-admin can restore any supplier, regular users can only restore their own
+	if !isSuperAdmin && supplier.UserID != userID {
+		return nil, apperror.ErrForbidden
+	}
+
+	if err := s.supplierRepo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.supplierRepo.GetByID(ctx, id)
+}
+
+// ListDeletedSuppliers lists suppliers in the trash. If isSuperAdmin is true, returns all of them.
+func (s *SupplierService) ListDeletedSuppliers(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, isSuperAdmin bool) (*pagination.PaginatedResult[entity.Supplier], error) {
+	suppliers, total, err := s.supplierRepo.ListDeleted(ctx, userID, params, search, isSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	pag := pagination.NewPagination(params.Page, params.PerPage, total)
+	return pagination.NewPaginatedResult(suppliers, pag), nil
 }
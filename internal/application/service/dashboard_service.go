@@ -282,7 +282,7 @@ func (s *DashboardService) GetDashboardStats(ctx context.Context, userID uuid.UU
 	}
 
 	// Top products — period-filtered
-	topProducts, err := s.analyticsRepo.GetTopProducts(ctx, 10, dr)
+	topProducts, err := s.analyticsRepo.GetTopProducts(ctx, defaultTopProductsLimit, dr)
 	if err != nil {
 		return nil, err
 	}
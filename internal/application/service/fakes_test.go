@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+)
+
+// The fakes below implement only the repository methods the tests use; calling any
+// other method panics on the nil embedded interface.
+
+// fakeTxManager runs the unit of work without a transaction
+type fakeTxManager struct{}
+
+func (fakeTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeSequenceRepo hands out increasing numbers per tenant and sequence name
+type fakeSequenceRepo struct {
+	repository.SequenceRepository
+	mu   sync.Mutex
+	next map[string]int64
+}
+
+func (r *fakeSequenceRepo) Next(ctx context.Context, tenantID uuid.UUID, name string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next == nil {
+		r.next = make(map[string]int64)
+	}
+	key := tenantID.String() + "/" + name
+	r.next[key]++
+	return r.next[key], nil
+}
+
+// fakeTenantRepo has no tenants, so services fall back to the default settings. Its
+// memberships all belong to the tenant being tested, keyed by user.
+type fakeTenantRepo struct {
+	repository.TenantRepository
+	memberships map[uuid.UUID]*entity.TenantMembership
+}
+
+func (fakeTenantRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Tenant, error) {
+	return nil, nil
+}
+
+func (r fakeTenantRepo) IsMember(ctx context.Context, tenantID, userID uuid.UUID) (bool, error) {
+	_, ok := r.memberships[userID]
+	return ok, nil
+}
+
+func (r fakeTenantRepo) GetMembership(ctx context.Context, tenantID, userID uuid.UUID) (*entity.TenantMembership, error) {
+	return r.memberships[userID], nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users   map[uuid.UUID]*entity.User
+	updated int
+}
+
+func (r *fakeUserRepo) GetWithRoles(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	return r.users[id], nil
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *entity.User) error {
+	r.updated++
+	r.users[user.ID] = user
+	return nil
+}
+
+type fakeProductRepo struct {
+	repository.ProductRepository
+	mu           sync.Mutex
+	products     map[uuid.UUID]*entity.Product
+	existsByCode func(code string) bool
+	codesChecked []string
+}
+
+func newFakeProductRepo(products ...entity.Product) *fakeProductRepo {
+	r := &fakeProductRepo{products: make(map[uuid.UUID]*entity.Product)}
+	for i := range products {
+		r.products[products[i].ID] = &products[i]
+	}
+	return r
+}
+
+func (r *fakeProductRepo) ExistsByCode(ctx context.Context, code string) (bool, error) {
+	r.codesChecked = append(r.codesChecked, code)
+	return r.existsByCode(code), nil
+}
+
+func (r *fakeProductRepo) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var products []entity.Product
+	for _, id := range ids {
+		if p, ok := r.products[id]; ok {
+			products = append(products, *p)
+		}
+	}
+	return products, nil
+}
+
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	orders  map[uuid.UUID]*entity.Order
+	updated int
+}
+
+func newFakeOrderRepo(orders ...*entity.Order) *fakeOrderRepo {
+	r := &fakeOrderRepo{orders: make(map[uuid.UUID]*entity.Order)}
+	for _, o := range orders {
+		r.orders[o.ID] = o
+	}
+	return r
+}
+
+func (r *fakeOrderRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
+	return r.orders[id], nil
+}
+
+func (r *fakeOrderRepo) Update(ctx context.Context, order *entity.Order) error {
+	r.updated++
+	r.orders[order.ID] = order
+	return nil
+}
+
+type fakeQuotationRepo struct {
+	repository.QuotationRepository
+	mu         sync.Mutex
+	quotations map[uuid.UUID]*entity.Quotation
+}
+
+func (r *fakeQuotationRepo) Create(ctx context.Context, quotation *entity.Quotation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.quotations == nil {
+		r.quotations = make(map[uuid.UUID]*entity.Quotation)
+	}
+	quotation.ID = uuid.New()
+	r.quotations[quotation.ID] = quotation
+	return nil
+}
+
+func (r *fakeQuotationRepo) GetWithDetails(ctx context.Context, id uuid.UUID) (*entity.Quotation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.quotations[id], nil
+}
+
+type fakeQuotationDetailRepo struct {
+	repository.QuotationDetailRepository
+}
+
+func (fakeQuotationDetailRepo) CreateBatch(ctx context.Context, details []entity.QuotationDetail) error {
+	return nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/pagination"
+	"github.com/sangkips/investify-api/pkg/utils"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// impersonationTTL is how long an impersonation token is valid. No refresh token is
+	// issued, and the refresh endpoint only accepts tokens typed as refresh tokens.
+	impersonationTTL = 30 * time.Minute
+
+	// impersonationsPerHour limits how many sessions one super-admin may start
+	impersonationsPerHour = 10
+
+	// minImpersonationReason is the shortest reason accepted for starting a session
+	minImpersonationReason = 10
+)
+
+// ImpersonationService lets super-admins act as a tenant user to reproduce support
+// issues. Every session start and end is written to the audit log with the reason.
+type ImpersonationService struct {
+	userRepo     repository.UserRepository
+	tenantRepo   repository.TenantRepository
+	jwtManager   *utils.JWTManager
+	auditService *AuditService
+
+	limiters map[uuid.UUID]*rate.Limiter // Per super-admin
+	mu       sync.Mutex
+}
+
+// NewImpersonationService creates a new impersonation service
+func NewImpersonationService(
+	userRepo repository.UserRepository,
+	tenantRepo repository.TenantRepository,
+	jwtManager *utils.JWTManager,
+	auditService *AuditService,
+) *ImpersonationService {
+	return &ImpersonationService{
+		userRepo:     userRepo,
+		tenantRepo:   tenantRepo,
+		jwtManager:   jwtManager,
+		auditService: auditService,
+		limiters:     make(map[uuid.UUID]*rate.Limiter),
+	}
+}
+
+// StartImpersonationInput represents a request to impersonate a user
+type StartImpersonationInput struct {
+	AdminID   uuid.UUID
+	UserID    uuid.UUID
+	TenantID  *uuid.UUID // Defaults to the user's first tenant
+	Reason    string
+	ClientIP  string
+	UserAgent string
+}
+
+// ImpersonationOutput is an issued impersonation session
+type ImpersonationOutput struct {
+	AccessToken string       `json:"access_token"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	TenantID    uuid.UUID    `json:"tenant_id"`
+	User        *entity.User `json:"user"`
+}
+
+// Start issues a short-lived access token for the target user. The token carries the
+// user's own roles and permissions plus an impersonated_by claim, and has no refresh token.
+func (s *ImpersonationService) Start(ctx context.Context, input *StartImpersonationInput) (*ImpersonationOutput, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if len(reason) < minImpersonationReason {
+		return nil, apperror.NewBadRequestError("A reason of at least 10 characters is required")
+	}
+
+	if input.UserID == input.AdminID {
+		return nil, apperror.NewBadRequestError("You cannot impersonate yourself")
+	}
+
+	if !s.allow(input.AdminID) {
+		return nil, apperror.NewAppError(http.StatusTooManyRequests, "Too many impersonation requests. Please try again later.")
+	}
+
+	user, err := s.userRepo.GetWithRoles(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, apperror.NewNotFoundError("User")
+	}
+
+	roles := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		if role.Name == "super-admin" {
+			return nil, apperror.NewAppError(http.StatusForbidden, "Super-admins cannot be impersonated")
+		}
+		roles = append(roles, role.Name)
+	}
+
+	var tenantID uuid.UUID
+	if input.TenantID != nil {
+		isMember, err := s.tenantRepo.IsMember(ctx, *input.TenantID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, apperror.NewBadRequestError("User is not a member of this tenant")
+		}
+		tenantID = *input.TenantID
+	} else {
+		tenants, _, _ := s.tenantRepo.GetUserTenants(ctx, user.ID, &pagination.PaginationParams{Page: 1, PerPage: 1})
+		if len(tenants) > 0 {
+			tenantID = tenants[0].ID
+		}
+	}
+
+	token, expiresAt, err := s.jwtManager.GenerateImpersonationToken(
+		user.ID, tenantID, user.Email, roles, user.GetPermissions(), input.AdminID, impersonationTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &entity.AuditLog{
+		ActorID:      input.AdminID,
+		Action:       "impersonate",
+		ResourceType: "users",
+		ResourceID:   user.ID.String(),
+		Metadata: map[string]interface{}{
+			"reason":     reason,
+			"expires_at": expiresAt,
+			"client_ip":  input.ClientIP,
+			"user_agent": input.UserAgent,
+		},
+	}
+	if tenantID != uuid.Nil {
+		entry.TenantID = &tenantID
+	}
+	s.auditService.Record(entry)
+
+	return &ImpersonationOutput{
+		AccessToken: token,
+		ExpiresAt:   expiresAt,
+		TenantID:    tenantID,
+		User:        user,
+	}, nil
+}
+
+// End revokes the impersonation token in claims and records the end of the session
+func (s *ImpersonationService) End(claims *utils.JWTClaims, clientIP, userAgent string) error {
+	if claims == nil || claims.ImpersonatedBy == nil {
+		return apperror.NewBadRequestError("Not an impersonation session")
+	}
+
+	s.jwtManager.RevokeAccessToken(claims)
+
+	entry := &entity.AuditLog{
+		ActorID:      *claims.ImpersonatedBy,
+		Action:       "end-impersonation",
+		ResourceType: "users",
+		ResourceID:   claims.UserID.String(),
+		Metadata: map[string]interface{}{
+			"client_ip":  clientIP,
+			"user_agent": userAgent,
+		},
+	}
+	if claims.TenantID != uuid.Nil {
+		entry.TenantID = &claims.TenantID
+	}
+	s.auditService.Record(entry)
+
+	return nil
+}
+
+// allow reports whether the super-admin may start another session now
+func (s *ImpersonationService) allow(adminID uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limiter, ok := s.limiters[adminID]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(time.Hour/impersonationsPerHour), impersonationsPerHour)
+		s.limiters[adminID] = limiter
+	}
+	return limiter.Allow()
+}
@@ -185,7 +185,7 @@ func (s *MpesaService) HandleCallback(ctx context.Context, callbackBody *mpesa.S
 		orderCtx := infraRepo.WithTenant(ctx, tx.TenantID)
 		amountKES := float64(tx.Amount) / 100.0
 
-		if err := s.orderService.PayDue(orderCtx, uuid.Nil, tx.OrderID, amountKES, true); err != nil {
+		if err := s.orderService.PayDue(orderCtx, uuid.Nil, tx.OrderID, amountKES, "mpesa", true); err != nil {
 			log.Printf("M-Pesa callback: failed to update order payment for OrderID=%s: %v", tx.OrderID, err)
 			// Don't return error — the transaction itself was recorded successfully
 		}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +15,7 @@ import (
 	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
 	"github.com/sangkips/investify-api/pkg/apperror"
 	"github.com/sangkips/investify-api/pkg/email"
+	"github.com/sangkips/investify-api/pkg/money"
 	"github.com/sangkips/investify-api/pkg/pagination"
 )
 
@@ -24,6 +27,12 @@ type OrderService struct {
 	customerRepo    repository.CustomerRepository
 	emailService    *email.EmailService
 	tenantRepo      repository.TenantRepository
+	txManager       repository.TxManager
+	sequenceRepo    repository.SequenceRepository
+	loyaltyRepo     repository.LoyaltyRepository
+	promotionRepo   repository.PromotionRepository
+	shiftRepo       repository.ShiftRepository
+	paymentRepo     repository.OrderPaymentRepository
 }
 
 // NewOrderService creates a new order service
@@ -34,6 +43,12 @@ func NewOrderService(
 	customerRepo repository.CustomerRepository,
 	emailService *email.EmailService,
 	tenantRepo repository.TenantRepository,
+	txManager repository.TxManager,
+	sequenceRepo repository.SequenceRepository,
+	loyaltyRepo repository.LoyaltyRepository,
+	promotionRepo repository.PromotionRepository,
+	shiftRepo repository.ShiftRepository,
+	paymentRepo repository.OrderPaymentRepository,
 ) *OrderService {
 	return &OrderService{
 		orderRepo:       orderRepo,
@@ -42,6 +57,12 @@ func NewOrderService(
 		customerRepo:    customerRepo,
 		emailService:    emailService,
 		tenantRepo:      tenantRepo,
+		txManager:       txManager,
+		sequenceRepo:    sequenceRepo,
+		loyaltyRepo:     loyaltyRepo,
+		promotionRepo:   promotionRepo,
+		shiftRepo:       shiftRepo,
+		paymentRepo:     paymentRepo,
 	}
 }
 
@@ -59,6 +80,16 @@ type CreateOrderInput struct {
 	PaymentType string
 	Pay         float64
 	Items       []OrderItemInput
+
+	// ReserveStock holds the items in reserved stock while the order is unpaid instead of
+	// taking them off quantity. It has no effect on orders that are fully paid up front.
+	ReserveStock bool
+
+	// RedeemPoints spends the customer's loyalty points as a discount off the total
+	RedeemPoints int64
+
+	// PromoCode applies a promotion's discount to the total and counts one of its uses
+	PromoCode string
 }
 
 // CreateOrder creates a new order with its details
@@ -69,9 +100,21 @@ func (s *OrderService) CreateOrder(ctx context.Context, input *CreateOrderInput)
 		return nil, apperror.NewBadRequestError("Tenant context required")
 	}
 
+	if input.Pay < 0 {
+		return nil, apperror.NewBadRequestError("Pay cannot be negative")
+	}
+	if input.RedeemPoints < 0 {
+		return nil, apperror.NewBadRequestError("Redeemed points cannot be negative")
+	}
+	if input.RedeemPoints > 0 && input.CustomerID == nil {
+		return nil, apperror.NewBadRequestError("Redeeming loyalty points requires a customer")
+	}
+
 	// Validate customer if provided
+	var customer *entity.Customer
 	if input.CustomerID != nil {
-		customer, err := s.customerRepo.GetByID(ctx, *input.CustomerID)
+		var err error
+		customer, err = s.customerRepo.GetByID(ctx, *input.CustomerID)
 		if err != nil {
 			return nil, err
 		}
@@ -98,9 +141,7 @@ func (s *OrderService) CreateOrder(ctx context.Context, input *CreateOrderInput)
 	}
 
 	// Validate all products exist and calculate totals
-	var subTotal int64         // Total before any tax consideration
-	var taxableAmount int64    // Amount from products with exclusive tax (need to add VAT)
-	var nonTaxableAmount int64 // Amount from products with inclusive tax (VAT already included)
+	var subTotal int64 // Total before any tax consideration
 	var totalProducts int
 	orderDetails := make([]entity.OrderDetail, 0, len(input.Items))
 	stockDecrements := make(map[uuid.UUID]int)
@@ -110,103 +151,248 @@ func (s *OrderService) CreateOrder(ctx context.Context, input *CreateOrderInput)
 		if !exists {
 			return nil, apperror.NewNotFoundError(fmt.Sprintf("Product %s", item.ProductID))
 		}
+		if !product.IsActive {
+			return nil, apperror.NewBadRequestError(fmt.Sprintf("Product %s is inactive and can't be ordered", product.Name))
+		}
 
-		unitCostCents := int64(item.UnitCost * 100)
+		unitCostCents := money.ToCents(item.UnitCost)
 		itemTotal := unitCostCents * int64(item.Quantity)
 		subTotal += itemTotal
 		totalProducts += item.Quantity
 
-		// Separate taxable and non-taxable amounts based on product's tax_type
-		// TaxTypeExclusive (0) = VAT needs to be added
-		// TaxTypeInclusive (1) = VAT already included in price
-		if product.TaxType == enum.TaxTypeExclusive {
-			taxableAmount += itemTotal
-		} else {
-			nonTaxableAmount += itemTotal
-		}
-
+		// The product's name, code and tax type are snapshotted on the line so later
+		// changes to the product don't alter past orders
 		orderDetails = append(orderDetails, entity.OrderDetail{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			UnitCost:  unitCostCents,
-			Total:     itemTotal,
+			ProductID:   item.ProductID,
+			ProductName: product.Name,
+			ProductCode: product.Code,
+			Quantity:    item.Quantity,
+			UnitCost:    unitCostCents,
+			Total:       itemTotal,
+			TaxType:     product.TaxType,
 		})
 
 		// Prepare atomic stock decrement
-		stockDecrements[product.ID] = item.Quantity
+		stockDecrements[product.ID] += item.Quantity
 	}
 
-	// Atomically decrement stock - this is race-condition safe
-	// If any product has insufficient stock, the entire operation fails
-	failedIDs, err := s.productRepo.AtomicDecrementBatch(ctx, stockDecrements)
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
-
-	// If any products failed due to insufficient stock
-	if len(failedIDs) > 0 {
-		// Build list of product names that failed
-		var failedNames []string
-		for _, id := range failedIDs {
-			if product, exists := productMap[id]; exists {
-				failedNames = append(failedNames, product.Name)
-			}
-		}
-		return nil, apperror.NewAppError(400, fmt.Sprintf("Insufficient stock for: %v", failedNames))
+	var settings entity.TenantSettings
+	if tenant != nil {
+		settings = tenant.Settings
 	}
+	vatRate := settings.VATRate()
 
-	// Calculate VAT (16% for Kenya)
+	// Only a customer can be VAT-exempt; walk-in sales always carry VAT
+	taxExempt := customer != nil && customer.TaxExempt
+
+	// Calculate VAT per line at the tenant rate (16% by default)
 	// For exclusive products: VAT is added on top
 	// For inclusive products: VAT is already in price, extract it for display
-	// additionalVat = taxableAmount * 0.16 (for exclusive, this is added to total)
-	// includedVat = nonTaxableAmount * (0.16 / 1.16) (for inclusive, already in subtotal)
-	additionalVat := int64(float64(taxableAmount) * 0.16)
-	includedVat := int64(float64(nonTaxableAmount) * (0.16 / 1.16))
+	// The order's VAT is the sum of the lines so the breakdown always adds up
+	var additionalVat, includedVat int64
+	for i := range orderDetails {
+		detail := &orderDetails[i]
+		if taxExempt {
+			// Exempt customers pay no VAT: exclusive lines add none and inclusive
+			// lines are charged their price less the VAT it includes
+			if detail.TaxType == enum.TaxTypeInclusive {
+				detail.UnitCost -= money.ApplyRate(detail.UnitCost, vatRate/(1+vatRate))
+				exVAT := detail.UnitCost * int64(detail.Quantity)
+				subTotal -= detail.Total - exVAT
+				detail.Total = exVAT
+			}
+			continue
+		}
+		if detail.TaxType == enum.TaxTypeExclusive {
+			detail.TaxAmount = money.ApplyRate(detail.Total, vatRate)
+			additionalVat += detail.TaxAmount
+		} else {
+			detail.TaxAmount = money.ApplyRate(detail.Total, vatRate/(1+vatRate))
+			includedVat += detail.TaxAmount
+		}
+	}
 
 	// Total VAT shown = additional + included (for transparency to customer)
 	vat := additionalVat + includedVat
 	// Total = subTotal + only the additional VAT (included VAT is already in subTotal)
 	total := subTotal + additionalVat
-	payCents := int64(input.Pay * 100)
-	due := total - payCents
 
-	// Generate invoice number
-	invoiceNo := fmt.Sprintf("INV-%s", uuid.New().String()[:8])
+	// A promo code comes off the total before loyalty points; VAT stays as charged on
+	// the lines
+	var promotion *entity.Promotion
+	var promoDiscount int64
+	if code := strings.TrimSpace(input.PromoCode); code != "" {
+		promotion, err = s.promotionRepo.GetByCode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if promotion == nil {
+			return nil, apperror.NewNotFoundError("Promo code")
+		}
+		if err := checkPromotion(promotion, total, time.Now()); err != nil {
+			return nil, err
+		}
+		promoDiscount = promotion.Discount(total)
+		total -= promoDiscount
+	}
+
+	// Redeemed loyalty points come off the total; VAT stays as charged on the lines
+	var loyaltyValue int64
+	if input.RedeemPoints > 0 {
+		pointValue := settings.LoyaltyPointValueCents()
+		if pointValue <= 0 {
+			return nil, apperror.NewBadRequestError("Loyalty point redemption is not enabled")
+		}
+		if customer.LoyaltyPoints < input.RedeemPoints {
+			return nil, apperror.NewBadRequestError(fmt.Sprintf("Customer has only %d loyalty points", customer.LoyaltyPoints))
+		}
+		loyaltyValue = input.RedeemPoints * pointValue
+		if loyaltyValue > total {
+			return nil, apperror.NewBadRequestError(fmt.Sprintf("Redeeming %d points (%.2f) exceeds the order total of %.2f",
+				input.RedeemPoints, money.FromCents(loyaltyValue), money.FromCents(total)))
+		}
+		total -= loyaltyValue
+	}
+
+	// Cash totals round to the tenant's increment; subtotal and VAT stay exact and the
+	// difference is kept as the order's rounding
+	var rounding int64
+	if strings.EqualFold(input.PaymentType, "cash") {
+		rounding = money.RoundTo(total, settings.CashRoundingCents()) - total
+		total += rounding
+	}
+
+	payCents := money.ToCents(input.Pay)
+	due, change := settle(total, payCents)
+
+	// Orders taken while the cashier has a shift open count towards its Z-report
+	shift, err := s.shiftRepo.GetOpenByUser(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
 
 	order := &entity.Order{
 		TenantID:      tenantID,
 		UserID:        input.UserID,
 		CustomerID:    input.CustomerID,
+		CreatedByID:   &input.UserID,
 		OrderDate:     time.Now(),
 		OrderStatus:   enum.OrderStatusPending,
 		TotalProducts: totalProducts,
 		SubTotal:      subTotal,
 		VAT:           vat,
 		Total:         total,
-		InvoiceNo:     invoiceNo,
+		Rounding:      rounding,
+		PromoDiscount: promoDiscount,
+		TaxExempt:     taxExempt,
+		LoyaltyValue:  loyaltyValue,
+		LoyaltyPoints: input.RedeemPoints,
 		PaymentType:   input.PaymentType,
 		Pay:           payCents,
 		Due:           due,
+		Change:        change,
+	}
+	if shift != nil {
+		order.ShiftID = &shift.ID
 	}
 
-	if due <= 0 {
+	if due == 0 {
 		order.OrderStatus = enum.OrderStatusComplete
-	}
+	} else {
+		order.StockReserved = input.ReserveStock
+	}
+
+	// Stock decrement, order insert and detail insert run in a single transaction,
+	// so any failure rolls all of them back
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Atomically decrement (or reserve) stock - this is race-condition safe
+		// If any product has insufficient stock, the entire operation fails
+		var failedIDs []uuid.UUID
+		var err error
+		if order.StockReserved {
+			failedIDs, err = s.productRepo.AtomicReserveBatch(ctx, stockDecrements)
+		} else {
+			failedIDs, err = s.productRepo.AtomicDecrementBatch(ctx, stockDecrements)
+		}
+		if err != nil {
+			return err
+		}
 
-	if err := s.orderRepo.Create(ctx, order); err != nil {
-		// Stock was already decremented - we need to restore it
-		_ = s.productRepo.AtomicIncrementBatch(ctx, stockDecrements)
-		return nil, err
-	}
+		// If any products failed due to insufficient stock
+		if len(failedIDs) > 0 {
+			// Build list of product names that failed
+			var failedNames []string
+			for _, id := range failedIDs {
+				if product, exists := productMap[id]; exists {
+					failedNames = append(failedNames, product.Name)
+				}
+			}
+			return apperror.NewAppError(400, fmt.Sprintf("Insufficient stock for: %v", failedNames))
+		}
 
-	// Set order ID on details
-	for i := range orderDetails {
-		orderDetails[i].OrderID = order.ID
-	}
+		// Counting the use re-checks the limit atomically, so a code with one use left
+		// can't be spent by two concurrent orders
+		if promotion != nil {
+			used, err := s.promotionRepo.Use(ctx, promotion.ID, time.Now())
+			if err != nil {
+				return err
+			}
+			if !used {
+				return apperror.NewBadRequestError("Promo code has reached its usage limit")
+			}
+			order.PromotionID = &promotion.ID
+			order.PromoCode = promotion.Code
+		}
+
+		// Allocate the invoice number inside the transaction so a rolled-back
+		// order does not leave a gap in the sequence
+		nextNum, err := s.sequenceRepo.Next(ctx, tenantID, entity.SequenceOrder)
+		if err != nil {
+			return err
+		}
+		order.InvoiceNo = settings.DocumentNumber(entity.SequenceOrder, nextNum)
 
-	if err := s.orderDetailRepo.CreateBatch(ctx, orderDetails); err != nil {
-		// Restore stock on failure
-		_ = s.productRepo.AtomicIncrementBatch(ctx, stockDecrements)
+		if err := s.orderRepo.Create(ctx, order); err != nil {
+			return err
+		}
+
+		// Set order ID on details
+		for i := range orderDetails {
+			orderDetails[i].OrderID = order.ID
+		}
+
+		if err := s.orderDetailRepo.CreateBatch(ctx, orderDetails); err != nil {
+			return err
+		}
+
+		if err := s.recordPayment(ctx, order, order.ShiftID, order.PaymentType, payCents-change, input.UserID); err != nil {
+			return err
+		}
+
+		if order.LoyaltyPoints > 0 {
+			redeemed, err := s.loyaltyRepo.Redeem(ctx, &entity.LoyaltyTransaction{
+				TenantID:    tenantID,
+				CustomerID:  *order.CustomerID,
+				OrderID:     &order.ID,
+				Type:        entity.LoyaltyRedeem,
+				Points:      -order.LoyaltyPoints,
+				CreatedByID: &input.UserID,
+			})
+			if err != nil {
+				return err
+			}
+			if !redeemed {
+				return apperror.NewBadRequestError("Customer does not have enough loyalty points")
+			}
+		}
+
+		return s.settleLoyalty(ctx, order, order.OrderStatus, input.UserID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -228,6 +414,51 @@ func (s *OrderService) GetOrder(ctx context.Context, id uuid.UUID) (*entity.Orde
 	return order, nil
 }
 
+// EmailOrderReceipt renders an order's receipt and queues it for email with a PDF copy attached.
+// The receipt goes to toEmail, or to the customer's stored email when toEmail is empty.
+// It returns the address the receipt was sent to.
+func (s *OrderService) EmailOrderReceipt(ctx context.Context, orderID uuid.UUID, toEmail string) (string, error) {
+	order, err := s.orderRepo.GetWithDetails(ctx, orderID)
+	if err != nil {
+		return "", err
+	}
+	if order == nil {
+		return "", apperror.NewNotFoundError("Order")
+	}
+
+	if order.OrderStatus == enum.OrderStatusCancel {
+		return "", apperror.NewBadRequestError("Cannot send a receipt for a cancelled order")
+	}
+
+	if toEmail == "" {
+		if order.Customer == nil || order.Customer.Email == nil || *order.Customer.Email == "" {
+			return "", apperror.NewBadRequestError("The customer has no email address; provide one to send the receipt to")
+		}
+		toEmail = *order.Customer.Email
+	}
+
+	tenant, err := s.tenantRepo.GetByID(ctx, order.TenantID)
+	if err != nil {
+		return "", err
+	}
+
+	receipt := orderReceipt(order)
+	applyReceiptTenant(receipt, tenant)
+
+	attachment := &email.Attachment{
+		Filename:    fmt.Sprintf("receipt-%s.pdf", order.InvoiceNo),
+		ContentType: "application/pdf",
+		Data:        RenderReceiptPDF(receipt),
+	}
+
+	if err := s.emailService.SendOrderReceiptEmail(toEmail, emailReceipt(receipt), attachment); err != nil {
+		log.Printf("Failed to queue receipt for order %s to %s: %v", order.ID, toEmail, err)
+		return "", apperror.NewAppError(http.StatusServiceUnavailable, "Failed to queue receipt email, try again later")
+	}
+
+	return toEmail, nil
+}
+
 // ListOrders lists orders with filtering
 func (s *OrderService) ListOrders(ctx context.Context, userID uuid.UUID, params *repository.OrderFilterParams) (*pagination.PaginatedResult[entity.Order], error) {
 	orders, total, err := s.orderRepo.List(ctx, userID, params)
@@ -235,7 +466,7 @@ func (s *OrderService) ListOrders(ctx context.Context, userID uuid.UUID, params
 		return nil, err
 	}
 
-	pag := pagination.NewPagination(params.Pagination.Page, params.Pagination.PerPage, total)
+	pag := pagination.NewPaginationForParams(params.Pagination, total)
 	return pagination.NewPaginatedResult(orders, pag), nil
 }
 
@@ -246,19 +477,17 @@ func (s *OrderService) ListOrdersWithCursor(ctx context.Context, userID uuid.UUI
 		return nil, err
 	}
 
-	hasPrev := params.Cursor.Cursor != ""
-
-	cursorPag, items := pagination.NewCursorPagination(orders, params.Cursor.Limit,
+	cursorPag, items := pagination.NewCursorPagination(orders, params.Cursor,
 		func(o entity.Order) string { return o.ID.String() },
 		func(o entity.Order) time.Time { return o.CreatedAt },
 	)
-	cursorPag.HasPrev = hasPrev
 
 	return pagination.NewCursorPaginatedResult(items, cursorPag), nil
 }
 
-// UpdateOrderStatus updates the status of an order
-func (s *OrderService) UpdateOrderStatus(ctx context.Context, userID, orderID uuid.UUID, status enum.OrderStatus) error {
+// UpdateOrderStatus updates the status of an order. Cancelling goes through CancelOrder,
+// so the cancellation window applies unless canOverride is set.
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, userID, orderID uuid.UUID, status enum.OrderStatus, canOverride bool) error {
 	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return err
@@ -271,11 +500,246 @@ func (s *OrderService) UpdateOrderStatus(ctx context.Context, userID, orderID uu
 		return apperror.ErrForbidden
 	}
 
-	return s.orderRepo.UpdateStatus(ctx, orderID, status)
+	if err := checkTransition("Order", orderTransitions, order.OrderStatus, status); err != nil {
+		return err
+	}
+
+	if status == enum.OrderStatusCancel {
+		return s.CancelOrder(ctx, userID, orderID, canOverride)
+	}
+
+	if order.StockReserved {
+		return s.settleReservation(ctx, order, status, userID)
+	}
+
+	// Completing also credits loyalty points
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.updateStatus(ctx, orderID, status, userID); err != nil {
+			return err
+		}
+		return s.settleLoyalty(ctx, order, status, userID)
+	})
+}
+
+// updateStatus sets an order's status. A cancellation is refunded from the drawer of
+// the cancelling user's open shift, if they have one, which is recorded on the order.
+func (s *OrderService) updateStatus(ctx context.Context, orderID uuid.UUID, status enum.OrderStatus, userID uuid.UUID) error {
+	if err := s.orderRepo.UpdateStatus(ctx, orderID, status, userID); err != nil {
+		return err
+	}
+	if status != enum.OrderStatusCancel {
+		return nil
+	}
+
+	shift, err := s.shiftRepo.GetOpenByUser(ctx, userID)
+	if err != nil || shift == nil {
+		return err
+	}
+	return s.orderRepo.SetRefundShift(ctx, orderID, shift.ID)
+}
+
+// settleReservation moves an order holding reserved stock to Complete or Cancel. Completing
+// takes the reserved items off quantity; cancelling releases them.
+func (s *OrderService) settleReservation(ctx context.Context, order *entity.Order, status enum.OrderStatus, userID uuid.UUID) error {
+	details, err := s.orderDetailRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	reserved := detailQuantities(details)
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Claim the reservation first; a concurrent settlement that got there first
+		// makes this fail before any stock moves
+		if err := s.orderRepo.ClearStockReserved(ctx, order.ID); err != nil {
+			return err
+		}
+
+		if status == enum.OrderStatusComplete {
+			err = s.productRepo.CommitReservedBatch(ctx, reserved)
+		} else {
+			err = s.productRepo.ReleaseReservedBatch(ctx, reserved)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.settleLoyalty(ctx, order, status, userID); err != nil {
+			return err
+		}
+
+		return s.updateStatus(ctx, order.ID, status, userID)
+	})
+}
+
+// settleLoyalty applies a status change to the customer's loyalty points: completing
+// an order credits the points it earns and cancelling it takes back the points it
+// earned and refunds the points redeemed on it. It must run in the transaction that
+// changes the status; the ledger keeps retries from applying twice.
+func (s *OrderService) settleLoyalty(ctx context.Context, order *entity.Order, status enum.OrderStatus, userID uuid.UUID) error {
+	if order.CustomerID == nil {
+		return nil
+	}
+
+	switch status {
+	case enum.OrderStatusComplete:
+		settings, err := tenantSettings(ctx, s.tenantRepo, order.TenantID)
+		if err != nil {
+			return err
+		}
+		points := settings.LoyaltyPointsEarned(order.Total)
+		if points == 0 {
+			return nil
+		}
+		_, err = s.loyaltyRepo.Record(ctx, &entity.LoyaltyTransaction{
+			TenantID:    order.TenantID,
+			CustomerID:  *order.CustomerID,
+			OrderID:     &order.ID,
+			Type:        entity.LoyaltyEarn,
+			Points:      points,
+			CreatedByID: &userID,
+		})
+		return err
+
+	case enum.OrderStatusCancel:
+		entries, err := s.loyaltyRepo.ListByOrder(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			var reversal string
+			switch entry.Type {
+			case entity.LoyaltyEarn:
+				reversal = entity.LoyaltyEarnReversal
+			case entity.LoyaltyRedeem:
+				reversal = entity.LoyaltyRedeemRefund
+			default:
+				continue
+			}
+			// Earned points are taken back even if they were spent since, which can
+			// leave the customer with a negative balance
+			_, err := s.loyaltyRepo.Record(ctx, &entity.LoyaltyTransaction{
+				TenantID:    entry.TenantID,
+				CustomerID:  entry.CustomerID,
+				OrderID:     entry.OrderID,
+				Type:        reversal,
+				Points:      -entry.Points,
+				CreatedByID: &userID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// detailQuantities returns the total quantity ordered per product
+func detailQuantities(details []entity.OrderDetail) map[uuid.UUID]int {
+	quantities := make(map[uuid.UUID]int, len(details))
+	for _, detail := range details {
+		quantities[detail.ProductID] += detail.Quantity
+	}
+	return quantities
+}
+
+// maxBulkStatusOrders caps how many orders one bulk status update may touch
+const maxBulkStatusOrders = 100
+
+// BulkStatusResult is the outcome of a bulk status update for one order
+type BulkStatusResult struct {
+	OrderID uuid.UUID `json:"order_id"`
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// BulkUpdateOrderStatus sets the status of many orders at once. Each order is checked
+// on its own (it must exist in the tenant, belong to the user and not be cancelled) and
+// the orders that pass are updated in one statement. Cancelling is not allowed here
+// because it must restore stock; use CancelOrder.
+func (s *OrderService) BulkUpdateOrderStatus(ctx context.Context, userID uuid.UUID, orderIDs []uuid.UUID, status enum.OrderStatus) ([]BulkStatusResult, error) {
+	if status != enum.OrderStatusComplete {
+		return nil, apperror.NewBadRequestError("Status must be Complete; cancel orders individually")
+	}
+	if len(orderIDs) == 0 {
+		return nil, apperror.NewBadRequestError("At least one order ID is required")
+	}
+	if len(orderIDs) > maxBulkStatusOrders {
+		return nil, apperror.NewBadRequestError(fmt.Sprintf("At most %d orders can be updated at once", maxBulkStatusOrders))
+	}
+
+	orders, err := s.orderRepo.GetByIDs(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*entity.Order, len(orders))
+	for i := range orders {
+		byID[orders[i].ID] = &orders[i]
+	}
+
+	results := make([]BulkStatusResult, 0, len(orderIDs))
+	toUpdate := make([]uuid.UUID, 0, len(orderIDs))
+	seen := make(map[uuid.UUID]bool, len(orderIDs))
+	for _, id := range orderIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result := BulkStatusResult{OrderID: id}
+		order := byID[id]
+		var transitionErr error
+		if order != nil && order.OrderStatus != status {
+			transitionErr = checkTransition("Order", orderTransitions, order.OrderStatus, status)
+		}
+		switch {
+		case order == nil:
+			result.Error = "Order not found"
+		case order.UserID != userID:
+			result.Error = "You do not have permission to update this order"
+		case order.OrderStatus == status:
+			// Already there; nothing to write
+			result.Success = true
+		case transitionErr != nil:
+			result.Error = transitionErr.Error()
+		case order.StockReserved && status == enum.OrderStatusComplete:
+			// Reserved stock has to be committed, which the batch update can't do
+			if err := s.settleReservation(ctx, order, status, userID); err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+			}
+		default:
+			result.Success = true
+			toUpdate = append(toUpdate, id)
+		}
+		results = append(results, result)
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.orderRepo.UpdateStatusBatch(ctx, toUpdate, status, userID); err != nil {
+			return err
+		}
+		for _, id := range toUpdate {
+			if order := byID[id]; order.OrderStatus != status {
+				if err := s.settleLoyalty(ctx, order, status, userID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
 }
 
 // CancelOrder cancels an order and restores stock
-func (s *OrderService) CancelOrder(ctx context.Context, userID, orderID uuid.UUID) error {
+// canOverride allows cancelling orders outside the tenant's cancellation window
+// (super-admins and users with the manage-users permission).
+func (s *OrderService) CancelOrder(ctx context.Context, userID, orderID uuid.UUID, canOverride bool) error {
 	order, err := s.orderRepo.GetWithDetails(ctx, orderID)
 	if err != nil {
 		return err
@@ -292,18 +756,51 @@ func (s *OrderService) CancelOrder(ctx context.Context, userID, orderID uuid.UUI
 		return apperror.NewAppError(400, "Order is already cancelled")
 	}
 
-	// Build increment map for stock restoration
-	stockIncrements := make(map[uuid.UUID]int)
-	for _, detail := range order.Details {
-		stockIncrements[detail.ProductID] = detail.Quantity
+	if !canOverride {
+		if err := s.checkCancellationWindow(ctx, order); err != nil {
+			return err
+		}
+	}
+
+	if order.StockReserved {
+		return s.settleReservation(ctx, order, enum.OrderStatusCancel, userID)
 	}
 
-	// Atomically restore stock
-	if err := s.productRepo.AtomicIncrementBatch(ctx, stockIncrements); err != nil {
+	// Build increment map for stock restoration
+	stockIncrements := detailQuantities(order.Details)
+
+	// Restore stock and loyalty points and cancel in one transaction so they cannot diverge
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.productRepo.AtomicIncrementBatch(ctx, stockIncrements); err != nil {
+			return err
+		}
+
+		if err := s.settleLoyalty(ctx, order, enum.OrderStatusCancel, userID); err != nil {
+			return err
+		}
+
+		return s.updateStatus(ctx, orderID, enum.OrderStatusCancel, userID)
+	})
+}
+
+// checkCancellationWindow rejects cancelling an order older than the tenant's
+// cancellation window. CreatedAt is used rather than OrderDate because order_date
+// is stored as a date and would lose the time of day.
+func (s *OrderService) checkCancellationWindow(ctx context.Context, order *entity.Order) error {
+	tenant, err := s.tenantRepo.GetByID(ctx, order.TenantID)
+	if err != nil {
 		return err
 	}
+	if tenant == nil || tenant.Settings.CancellationWindowHours <= 0 {
+		return nil
+	}
 
-	return s.orderRepo.UpdateStatus(ctx, orderID, enum.OrderStatusCancel)
+	window := time.Duration(tenant.Settings.CancellationWindowHours) * time.Hour
+	if time.Since(order.CreatedAt) > window {
+		return apperror.NewAppError(http.StatusForbidden, fmt.Sprintf(
+			"Orders older than %d hours can only be cancelled by a manager", tenant.Settings.CancellationWindowHours))
+	}
+	return nil
 }
 
 // GetDueOrders returns orders with outstanding dues
@@ -317,8 +814,10 @@ func (s *OrderService) GetDueOrders(ctx context.Context, userID uuid.UUID, param
 	return pagination.NewPaginatedResult(orders, pag), nil
 }
 
-// PayDue records a payment towards an order's due amount
-func (s *OrderService) PayDue(ctx context.Context, userID, orderID uuid.UUID, amount float64, skipUserCheck bool) error {
+// PayDue records a payment towards an order's due amount. paymentType is how this
+// payment was made; empty means the order's own payment type. Only cash (change is
+// handed back) and M-Pesa (the money has already been received) may pay more than is due.
+func (s *OrderService) PayDue(ctx context.Context, userID, orderID uuid.UUID, amount float64, paymentType string, skipUserCheck bool) error {
 	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return err
@@ -332,16 +831,135 @@ func (s *OrderService) PayDue(ctx context.Context, userID, orderID uuid.UUID, am
 		return apperror.ErrForbidden
 	}
 
-	amountCents := int64(amount * 100)
+	if order.OrderStatus == enum.OrderStatusCancel {
+		return apperror.NewBadRequestError("Cannot record a payment against a cancelled order")
+	}
+	if order.Due <= 0 {
+		return apperror.NewBadRequestError("Order has no outstanding due")
+	}
+
+	if amount <= 0 {
+		return apperror.NewBadRequestError("Amount must be greater than zero")
+	}
+
+	amountCents := money.ToCents(amount)
+	if paymentType == "" {
+		paymentType = order.PaymentType
+	}
+	if amountCents > order.Due && !strings.EqualFold(paymentType, "cash") && !strings.EqualFold(paymentType, "mpesa") {
+		return apperror.NewBadRequestError(fmt.Sprintf("Amount exceeds the outstanding due of %.2f", money.FromCents(order.Due)))
+	}
+
+	due, change := settle(order.Due, amountCents)
 	order.Pay += amountCents
-	order.Due -= amountCents
+	order.Due = due
+	order.Change += change
 
-	if order.Due <= 0 {
-		order.Due = 0
+	if order.Due == 0 {
 		order.OrderStatus = enum.OrderStatusComplete
 	}
+	order.UpdatedByID = &userID
+
+	// The payment goes into the drawer of the shift the cashier has open now, not the
+	// shift the order was taken in
+	shift, err := s.shiftRepo.GetOpenByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var shiftID *uuid.UUID
+	if shift != nil {
+		shiftID = &shift.ID
+	}
+
+	if order.OrderStatus != enum.OrderStatusComplete {
+		return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := s.orderRepo.Update(ctx, order); err != nil {
+				return err
+			}
+			return s.recordPayment(ctx, order, shiftID, paymentType, amountCents-change, userID)
+		})
+	}
+
+	// Paying off a reserved order completes it, so its reservation becomes a real decrement
+	var reserved map[uuid.UUID]int
+	if order.StockReserved {
+		details, err := s.orderDetailRepo.GetByOrderID(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		reserved = detailQuantities(details)
+		order.StockReserved = false
+	}
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if reserved != nil {
+			if err := s.productRepo.CommitReservedBatch(ctx, reserved); err != nil {
+				return err
+			}
+		}
+		if err := s.orderRepo.Update(ctx, order); err != nil {
+			return err
+		}
+		if err := s.recordPayment(ctx, order, shiftID, paymentType, amountCents-change, userID); err != nil {
+			return err
+		}
+		return s.settleLoyalty(ctx, order, enum.OrderStatusComplete, userID)
+	})
+}
+
+// recordPayment adds the money kept from a payment on an order to the payments ledger,
+// against the given shift. Payments that kept nothing are not recorded.
+func (s *OrderService) recordPayment(ctx context.Context, order *entity.Order, shiftID *uuid.UUID, paymentType string, amount int64, userID uuid.UUID) error {
+	if amount <= 0 {
+		return nil
+	}
+	return s.paymentRepo.Create(ctx, &entity.OrderPayment{
+		TenantID:    order.TenantID,
+		OrderID:     order.ID,
+		ShiftID:     shiftID,
+		PaymentType: paymentType,
+		Amount:      amount,
+		PaidAt:      time.Now(),
+		CreatedByID: &userID,
+	})
+}
+
+// settle applies a payment (in cents) to an amount owed and returns what is still
+// due and the change owed back to the customer; neither is ever negative.
+func settle(owed, paid int64) (due, change int64) {
+	if paid >= owed {
+		return 0, paid - owed
+	}
+	return owed - paid, 0
+}
+
+// tenantSettings returns a tenant's settings, or empty settings (which fall back to
+// the defaults) if the tenant can't be found
+func tenantSettings(ctx context.Context, tenantRepo repository.TenantRepository, tenantID uuid.UUID) (entity.TenantSettings, error) {
+	tenant, err := tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return entity.TenantSettings{}, err
+	}
+	if tenant == nil {
+		return entity.TenantSettings{}, nil
+	}
+	return tenant.Settings, nil
+}
+
+// tenantVATRate returns the VAT rate configured for a tenant, or the default rate if
+// the tenant can't be found
+func tenantVATRate(ctx context.Context, tenantRepo repository.TenantRepository, tenantID uuid.UUID) (float64, error) {
+	settings, err := tenantSettings(ctx, tenantRepo, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return settings.VATRate(), nil
+}
 
-	return s.orderRepo.Update(ctx, order)
+// splitVAT returns the VAT to add on top of exclusive-priced amounts and the VAT
+// already contained in inclusive-priced amounts, in cents
+func splitVAT(exclusive, inclusive int64, rate float64) (additional, included int64) {
+	return money.ApplyRate(exclusive, rate), money.ApplyRate(inclusive, rate/(1+rate))
 }
 
 // checkAndNotifyLowStock checks if any ordered products have hit low stock and emails admins
@@ -356,14 +974,15 @@ func (s *OrderService) checkAndNotifyLowStock(reqCtx context.Context, tenantID u
 		return
 	}
 
-	// Filter products that are at or below their alert threshold
+	// Filter products whose available stock is at or below their alert threshold
 	var lowStockProducts []email.LowStockProduct
 	for _, p := range products {
-		if p.QuantityAlert > 0 && p.Quantity <= p.QuantityAlert {
+		if p.QuantityAlert > 0 && p.Available() <= p.QuantityAlert {
 			lowStockProducts = append(lowStockProducts, email.LowStockProduct{
 				Name:          p.Name,
 				Code:          p.Code,
-				Quantity:      p.Quantity,
+				Quantity:      p.Available(),
+				Reserved:      p.ReservedQuantity,
 				QuantityAlert: p.QuantityAlert,
 			})
 		}
@@ -390,7 +1009,7 @@ func (s *OrderService) checkAndNotifyLowStock(reqCtx context.Context, tenantID u
 	// Send email to each admin
 	for _, adminEmail := range adminEmails {
 		if err := s.emailService.SendLowStockAlertEmail(adminEmail, tenant.Name, lowStockProducts); err != nil {
-			log.Printf("Low stock check: failed to send email to %s: %v", adminEmail, err)
+			log.Printf("Low stock check: failed to queue email to %s: %v", adminEmail, err)
 		}
 	}
 }
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/pkg/apperror"
+)
+
+func TestPayDueRejectsSettledOrders(t *testing.T) {
+	userID := uuid.New()
+	tests := []struct {
+		name  string
+		order *entity.Order
+	}{
+		{
+			name:  "cancelled order",
+			order: &entity.Order{ID: uuid.New(), UserID: userID, OrderStatus: enum.OrderStatusCancel, Total: 1000, Due: 1000},
+		},
+		{
+			name:  "nothing due",
+			order: &entity.Order{ID: uuid.New(), UserID: userID, OrderStatus: enum.OrderStatusComplete, Total: 1000, Pay: 1000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderRepo := newFakeOrderRepo(tt.order)
+			s := &OrderService{orderRepo: orderRepo}
+
+			err := s.PayDue(context.Background(), userID, tt.order.ID, 5, "cash", false)
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) || appErr.Code != http.StatusBadRequest {
+				t.Fatalf("err = %v, want a bad request error", err)
+			}
+			if orderRepo.updated != 0 {
+				t.Error("rejected payment updated the order")
+			}
+		})
+	}
+}
@@ -3,12 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
-	"log"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/entity"
 	"github.com/sangkips/investify-api/internal/domain/repository"
 	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/logger"
+	"github.com/sangkips/investify-api/pkg/money"
 	"github.com/sangkips/investify-api/pkg/printer"
 )
 
@@ -17,6 +19,8 @@ type PrinterService struct {
 	printer       printer.Printer
 	orderRepo     repository.OrderRepository
 	quotationRepo repository.QuotationRepository
+	tenantRepo    repository.TenantRepository
+	userRepo      repository.UserRepository
 	printerType   string
 }
 
@@ -25,12 +29,16 @@ func NewPrinterService(
 	p printer.Printer,
 	orderRepo repository.OrderRepository,
 	quotationRepo repository.QuotationRepository,
+	tenantRepo repository.TenantRepository,
+	userRepo repository.UserRepository,
 	printerType string,
 ) *PrinterService {
 	return &PrinterService{
 		printer:       p,
 		orderRepo:     orderRepo,
 		quotationRepo: quotationRepo,
+		tenantRepo:    tenantRepo,
+		userRepo:      userRepo,
 		printerType:   printerType,
 	}
 }
@@ -82,54 +90,62 @@ func (s *PrinterService) TestPrint() (*entity.Receipt, error) {
 	return receipt, nil
 }
 
-// PrintOrderReceipt fetches an order (with details) and prints its receipt.
-func (s *PrinterService) PrintOrderReceipt(ctx context.Context, orderID uuid.UUID) (*entity.Receipt, error) {
+// GetOrderReceipt builds an order's receipt as PrintOrderReceipt prints it, without
+// printing. cashierID is the user viewing the receipt; their name is shown as the cashier.
+func (s *PrinterService) GetOrderReceipt(ctx context.Context, orderID, cashierID uuid.UUID) (*entity.Receipt, error) {
 	order, err := s.orderRepo.GetWithDetails(ctx, orderID)
-	if err != nil {
+	if err != nil || order == nil {
 		return nil, apperror.NewNotFoundError("Order")
 	}
 
-	receipt := &entity.Receipt{
-		Header: entity.ReceiptHeader{
-			StoreName: "Investify Store",
-		},
-		InvoiceNo:   order.InvoiceNo,
-		Date:        order.OrderDate.Format("2006-01-02 15:04"),
-		PaymentType: order.PaymentType,
-		SubTotal:    float64(order.SubTotal) / 100,
-		VAT:         float64(order.VAT) / 100,
-		Total:       float64(order.Total) / 100,
-		Paid:        float64(order.Pay) / 100,
-		Due:         float64(order.Due) / 100,
-	}
-
-	if order.Customer != nil {
-		receipt.Customer = order.Customer.Name
-	}
+	receipt := orderReceipt(order)
+	receipt.Cashier = s.cashierName(ctx, cashierID)
+	s.applyTenant(ctx, receipt, order.TenantID)
+	return receipt, nil
+}
 
-	for _, d := range order.Details {
-		item := entity.ReceiptItem{
-			Quantity:  d.Quantity,
-			UnitPrice: float64(d.UnitCost) / 100,
-			Total:     float64(d.Total) / 100,
-		}
-		if d.Product.Name != "" {
-			item.Name = d.Product.Name
-		} else {
-			item.Name = "Product"
-		}
-		receipt.Items = append(receipt.Items, item)
+// PrintOrderReceipt fetches an order (with details) and prints its receipt.
+// cashierID is the user printing the receipt; their name is shown as the cashier.
+func (s *PrinterService) PrintOrderReceipt(ctx context.Context, orderID, cashierID uuid.UUID) (*entity.Receipt, error) {
+	receipt, err := s.GetOrderReceipt(ctx, orderID, cashierID)
+	if err != nil {
+		return nil, err
 	}
 
 	data := FormatReceipt(receipt)
 	if err := s.printer.Print(data); err != nil {
-		log.Printf("Printer error (order %s): %v", orderID, err)
+		logger.FromContext(ctx).Error("printer error", "order_id", orderID.String(), "error", err)
 		return receipt, fmt.Errorf("failed to print receipt: %w", err)
 	}
 
 	return receipt, nil
 }
 
+// applyTenant fills the receipt header (name, KRA PIN) and the currency, locale and
+// tax label from the tenant's settings. A generic store name is used if the tenant
+// cannot be loaded.
+func (s *PrinterService) applyTenant(ctx context.Context, r *entity.Receipt, tenantID uuid.UUID) {
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		tenant = nil
+	}
+	applyReceiptTenant(r, tenant)
+}
+
+// cashierName returns the full name of the user printing the receipt.
+func (s *PrinterService) cashierName(ctx context.Context, userID uuid.UUID) string {
+	if userID == uuid.Nil {
+		return ""
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
 // PrintQuotationReceipt fetches a quotation (with details) and prints its receipt.
 func (s *PrinterService) PrintQuotationReceipt(ctx context.Context, quotationID uuid.UUID) (*entity.Receipt, error) {
 	quotation, err := s.quotationRepo.GetWithDetails(ctx, quotationID)
@@ -138,16 +154,15 @@ func (s *PrinterService) PrintQuotationReceipt(ctx context.Context, quotationID
 	}
 
 	receipt := &entity.Receipt{
-		Header: entity.ReceiptHeader{
-			StoreName: "Investify Store",
-		},
 		InvoiceNo: quotation.Reference,
 		Date:      quotation.Date.Format("2006-01-02 15:04"),
-		SubTotal:  quotation.TotalAmount - quotation.TaxAmount,
-		VAT:       quotation.TaxAmount,
-		Total:     quotation.TotalAmount,
+		SubTotal:  float64(quotation.TotalAmount-quotation.TaxAmount) / 100,
+		VAT:       quotation.GetTaxAmountDecimal(),
+		Total:     quotation.GetTotalAmountDecimal(),
 	}
 
+	s.applyTenant(ctx, receipt, quotation.TenantID)
+
 	if quotation.Customer != nil {
 		receipt.Customer = quotation.Customer.Name
 	} else if quotation.CustomerName != "" {
@@ -158,8 +173,8 @@ func (s *PrinterService) PrintQuotationReceipt(ctx context.Context, quotationID
 		item := entity.ReceiptItem{
 			Name:      d.ProductName,
 			Quantity:  d.Quantity,
-			UnitPrice: d.UnitPrice,
-			Total:     d.SubTotal,
+			UnitPrice: d.GetUnitPriceDecimal(),
+			Total:     float64(d.SubTotal) / 100,
 		}
 		if item.Name == "" {
 			if d.Product.Name != "" {
@@ -173,7 +188,7 @@ func (s *PrinterService) PrintQuotationReceipt(ctx context.Context, quotationID
 
 	data := FormatReceipt(receipt)
 	if err := s.printer.Print(data); err != nil {
-		log.Printf("Printer error (quotation %s): %v", quotationID, err)
+		logger.FromContext(ctx).Error("printer error", "quotation_id", quotationID.String(), "error", err)
 		return receipt, fmt.Errorf("failed to print receipt: %w", err)
 	}
 
@@ -181,8 +196,21 @@ func (s *PrinterService) PrintQuotationReceipt(ctx context.Context, quotationID
 }
 
 // FormatReceipt converts a Receipt into ESC/POS bytes.
+// Amounts use the receipt's locale separators; the total carries the ISO currency
+// code rather than a symbol because thermal printers only print ASCII reliably.
 func FormatReceipt(r *entity.Receipt) []byte {
-	doc := printer.NewDocument(32) // 58mm paper = 32 chars
+	width := 32 // 58mm paper = 32 chars
+	if r.PaperWidth == 80 {
+		width = 48
+	}
+	doc := printer.NewDocument(width)
+	f := money.NewFormatter(r.Currency, r.Locale)
+	amount := func(v float64) string { return f.Number(money.ToCents(v)) }
+
+	taxLabel := r.TaxLabel
+	if taxLabel == "" {
+		taxLabel = "VAT"
+	}
 
 	// Header
 	doc.SetAlign(printer.AlignCenter).
@@ -223,36 +251,57 @@ func FormatReceipt(r *entity.Receipt) []byte {
 
 	// Items
 	for _, item := range r.Items {
-		doc.ItemLine(item.Quantity, item.Name, fmt.Sprintf("%.2f", item.Total))
+		doc.ItemLine(item.Quantity, item.Name, amount(item.Total))
 		if item.Quantity > 1 {
-			doc.TextF("  @ %.2f each", item.UnitPrice)
+			doc.TextF("  @ %s each", amount(item.UnitPrice))
+		}
+		if item.TaxAmount != 0 {
+			doc.TextF("  %s %s", taxLabel, itemTax(item, amount))
 		}
 	}
 
 	doc.Separator('-')
 
 	// Totals
-	doc.KeyValue("Subtotal:", fmt.Sprintf("%.2f", r.SubTotal))
+	doc.KeyValue("Subtotal:", amount(r.SubTotal))
 	if r.VAT > 0 {
-		doc.KeyValue("VAT:", fmt.Sprintf("%.2f", r.VAT))
+		doc.KeyValue(taxLabel+":", amount(r.VAT))
+	} else if r.TaxExempt {
+		doc.KeyValue(taxLabel+":", "Exempt")
+	}
+	if r.Promo > 0 {
+		doc.KeyValue(receiptPromoLabel(r)+":", "-"+amount(r.Promo))
+	}
+	if r.Discount > 0 {
+		doc.KeyValue("Loyalty discount:", "-"+amount(r.Discount))
+	}
+	if r.Rounding != 0 {
+		doc.KeyValue("Rounding:", amount(r.Rounding))
 	}
 	doc.SetBold(true).
-		KeyValue("TOTAL:", fmt.Sprintf("%.2f", r.Total)).
+		KeyValue("TOTAL:", f.FormatCode(money.ToCents(r.Total))).
 		SetBold(false)
 
 	if r.Paid > 0 {
-		doc.KeyValue("Paid:", fmt.Sprintf("%.2f", r.Paid))
+		doc.KeyValue("Paid:", amount(r.Paid))
 	}
 	if r.Due > 0 {
-		doc.KeyValue("Due:", fmt.Sprintf("%.2f", r.Due))
+		doc.KeyValue("Due:", amount(r.Due))
+	}
+	if r.Change > 0 {
+		doc.KeyValue("Change:", amount(r.Change))
 	}
 
 	doc.Separator('-')
 
 	// Footer
+	footer := r.Footer
+	if footer == "" {
+		footer = "Thank you for your business!"
+	}
 	doc.SetAlign(printer.AlignCenter).
 		LineFeed().
-		Text("Thank you for your business!").
+		Text(footer).
 		LineFeed().
 		SetAlign(printer.AlignLeft)
 
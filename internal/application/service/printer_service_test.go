@@ -0,0 +1,34 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sangkips/investify-api/internal/domain/entity"
+)
+
+func TestFormatReceiptPrintsCashierAndTaxID(t *testing.T) {
+	r := &entity.Receipt{
+		Header: entity.ReceiptHeader{
+			StoreName: "Corner Shop",
+			TaxID:     "P051234567X",
+		},
+		InvoiceNo: "INV-0001",
+		Date:      "2024-01-02 10:00",
+		Cashier:   "Jane Doe",
+		Items: []entity.ReceiptItem{
+			{Name: "Milk", Quantity: 1, UnitPrice: 1.5, Total: 1.5},
+		},
+		SubTotal: 1.5,
+		Total:    1.5,
+		Paid:     1.5,
+	}
+
+	out := FormatReceipt(r)
+
+	for _, want := range []string{"Cashier:", "Jane Doe", "Tax ID: P051234567X"} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("receipt does not contain %q", want)
+		}
+	}
+}
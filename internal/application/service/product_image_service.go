@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/pkg/apperror"
+)
+
+// AddProductImageInput represents the input for adding an image to a product's gallery
+type AddProductImageInput struct {
+	UserID        uuid.UUID
+	ProductSlug   string
+	SkipUserCheck bool // If true (super-admin), skip ownership check
+	URL           string
+	IsPrimary     bool // The first image of a product is always primary
+}
+
+// AddProductImage appends an image to the product's gallery and returns the updated product
+func (s *ProductService) AddProductImage(ctx context.Context, input *AddProductImageInput) (*entity.Product, error) {
+	if u, err := url.Parse(input.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, apperror.NewBadRequestError("Image URL must be an absolute http or https URL")
+	}
+
+	product, err := s.ownedProduct(ctx, input.ProductSlug, input.UserID, input.SkipUserCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		images, err := s.imageRepo.ListByProduct(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		if len(images) >= entity.MaxProductImages {
+			return apperror.NewBadRequestError(fmt.Sprintf("A product can have at most %d images", entity.MaxProductImages))
+		}
+
+		image := entity.ProductImage{
+			TenantID:  product.TenantID,
+			ProductID: product.ID,
+			URL:       input.URL,
+			SortOrder: len(images),
+		}
+		if err := s.imageRepo.Create(ctx, &image); err != nil {
+			return err
+		}
+
+		primaryID := image.ID
+		if !input.IsPrimary {
+			if current := primaryImage(images); current != nil {
+				primaryID = current.ID
+			}
+		}
+		return s.saveGallery(ctx, product, append(images, image), primaryID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reload(ctx, product.ID)
+}
+
+// DeleteProductImage removes an image from the product's gallery. If it was the primary
+// image, the next image in order becomes primary.
+func (s *ProductService) DeleteProductImage(ctx context.Context, userID uuid.UUID, slug string, imageID uuid.UUID, skipOwnerCheck bool) (*entity.Product, error) {
+	product, err := s.ownedProduct(ctx, slug, userID, skipOwnerCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		images, err := s.imageRepo.ListByProduct(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+
+		remaining := make([]entity.ProductImage, 0, len(images))
+		found := false
+		for _, image := range images {
+			if image.ID == imageID {
+				found = true
+				continue
+			}
+			remaining = append(remaining, image)
+		}
+		if !found {
+			return apperror.NewNotFoundError("Product image")
+		}
+
+		if err := s.imageRepo.Delete(ctx, imageID); err != nil {
+			return err
+		}
+
+		var primaryID uuid.UUID
+		if current := primaryImage(remaining); current != nil {
+			primaryID = current.ID
+		} else if len(remaining) > 0 {
+			primaryID = remaining[0].ID
+		}
+		return s.saveGallery(ctx, product, remaining, primaryID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reload(ctx, product.ID)
+}
+
+// ReorderProductImagesInput represents the input for reordering a product's gallery
+type ReorderProductImagesInput struct {
+	UserID         uuid.UUID
+	ProductSlug    string
+	SkipUserCheck  bool        // If true (super-admin), skip ownership check
+	ImageIDs       []uuid.UUID // Every image of the product, in the new order
+	PrimaryImageID *uuid.UUID  // Optional; keeps the current primary when nil
+}
+
+// ReorderProductImages sets the gallery order and optionally changes the primary image
+func (s *ProductService) ReorderProductImages(ctx context.Context, input *ReorderProductImagesInput) (*entity.Product, error) {
+	product, err := s.ownedProduct(ctx, input.ProductSlug, input.UserID, input.SkipUserCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		images, err := s.imageRepo.ListByProduct(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		if len(input.ImageIDs) != len(images) {
+			return apperror.NewBadRequestError("image_ids must list every image of the product exactly once")
+		}
+
+		byID := make(map[uuid.UUID]entity.ProductImage, len(images))
+		for _, image := range images {
+			byID[image.ID] = image
+		}
+		ordered := make([]entity.ProductImage, 0, len(images))
+		for _, id := range input.ImageIDs {
+			image, ok := byID[id]
+			if !ok {
+				return apperror.NewBadRequestError("image_ids must list every image of the product exactly once")
+			}
+			delete(byID, id)
+			ordered = append(ordered, image)
+		}
+
+		var primaryID uuid.UUID
+		if input.PrimaryImageID != nil {
+			primaryID = *input.PrimaryImageID
+			found := false
+			for _, image := range ordered {
+				found = found || image.ID == primaryID
+			}
+			if !found {
+				return apperror.NewBadRequestError("primary_image_id must be one of the product's images")
+			}
+		} else if current := primaryImage(ordered); current != nil {
+			primaryID = current.ID
+		} else if len(ordered) > 0 {
+			primaryID = ordered[0].ID
+		}
+		return s.saveGallery(ctx, product, ordered, primaryID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reload(ctx, product.ID)
+}
+
+// ownedProduct loads a product by slug and checks the user may change it
+func (s *ProductService) ownedProduct(ctx context.Context, slug string, userID uuid.UUID, skipOwnerCheck bool) (*entity.Product, error) {
+	product, err := s.productRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, apperror.NewNotFoundError("Product")
+	}
+	if !skipOwnerCheck && product.UserID != userID {
+		return nil, apperror.ErrForbidden
+	}
+	return product, nil
+}
+
+// saveGallery renumbers the images in slice order and flags primaryID as the only
+// primary image; the repository points the legacy ProductImage field at it
+func (s *ProductService) saveGallery(ctx context.Context, product *entity.Product, images []entity.ProductImage, primaryID uuid.UUID) error {
+	for i := range images {
+		images[i].SortOrder = i
+		images[i].IsPrimary = images[i].ID == primaryID
+	}
+	return s.imageRepo.SaveGallery(ctx, product.ID, images)
+}
+
+// primaryImage returns the image flagged as primary, or nil if there is none
+func primaryImage(images []entity.ProductImage) *entity.ProductImage {
+	for i := range images {
+		if images[i].IsPrimary {
+			return &images[i]
+		}
+	}
+	return nil
+}
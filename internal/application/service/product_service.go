@@ -12,6 +12,8 @@ import (
 	"github.com/sangkips/investify-api/internal/domain/repository"
 	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
 	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/money"
+	"github.com/sangkips/investify-api/pkg/nullable"
 	"github.com/sangkips/investify-api/pkg/pagination"
 	"github.com/sangkips/investify-api/pkg/utils"
 )
@@ -21,6 +23,9 @@ type ProductService struct {
 	productRepo  repository.ProductRepository
 	categoryRepo repository.CategoryRepository
 	unitRepo     repository.UnitRepository
+	tenantRepo   repository.TenantRepository
+	imageRepo    repository.ProductImageRepository
+	txManager    repository.TxManager
 }
 
 // NewProductService creates a new product service
@@ -28,28 +33,95 @@ func NewProductService(
 	productRepo repository.ProductRepository,
 	categoryRepo repository.CategoryRepository,
 	unitRepo repository.UnitRepository,
+	tenantRepo repository.TenantRepository,
+	imageRepo repository.ProductImageRepository,
+	txManager repository.TxManager,
 ) *ProductService {
 	return &ProductService{
 		productRepo:  productRepo,
 		categoryRepo: categoryRepo,
 		unitRepo:     unitRepo,
+		tenantRepo:   tenantRepo,
+		imageRepo:    imageRepo,
+		txManager:    txManager,
 	}
 }
 
+// withTaxPrices sets each product's tenant VAT rate so its JSON carries price_with_tax
+// and price_without_tax
+func (s *ProductService) withTaxPrices(ctx context.Context, products ...*entity.Product) error {
+	rates := make(map[uuid.UUID]float64)
+	for _, p := range products {
+		if p == nil {
+			continue
+		}
+		rate, ok := rates[p.TenantID]
+		if !ok {
+			var err error
+			if rate, err = tenantVATRate(ctx, s.tenantRepo, p.TenantID); err != nil {
+				return err
+			}
+			rates[p.TenantID] = rate
+		}
+		p.VATRate = rate
+	}
+	return nil
+}
+
+// withTaxPricesAll is withTaxPrices for a slice of products
+func (s *ProductService) withTaxPricesAll(ctx context.Context, products []entity.Product) error {
+	ptrs := make([]*entity.Product, len(products))
+	for i := range products {
+		ptrs[i] = &products[i]
+	}
+	return s.withTaxPrices(ctx, ptrs...)
+}
+
 // CreateProductInput represents the create product input
 type CreateProductInput struct {
-	UserID        uuid.UUID
-	CategoryID    *uuid.UUID
-	UnitID        *uuid.UUID
-	Name          string
-	Code          string
-	Quantity      int
-	QuantityAlert int
-	BuyingPrice   float64
-	SellingPrice  float64
-	Tax           int
-	TaxType       int
-	Notes         *string
+	UserID              uuid.UUID
+	CategoryID          *uuid.UUID
+	UnitID              *uuid.UUID
+	PurchaseUnitID      *uuid.UUID
+	PiecesPerUnit       int // Sale units per purchase unit; 0 means 1
+	PreferredSupplierID *uuid.UUID
+	Name                string
+	Code                string
+	Quantity            int
+	QuantityAlert       int
+	MinStock            int
+	MaxStock            int
+	ReorderQty          int
+	AllowNegativeStock  bool
+	BuyingPrice         float64
+	SellingPrice        float64
+	Tax                 int
+	TaxType             int
+	Notes               *string
+}
+
+// maxProductCodeAttempts bounds how many generated product codes are tried before giving up
+const maxProductCodeAttempts = 5
+
+// uniqueProductCode generates a product code that no product in the tenant uses and
+// that is not among reserved (codes claimed earlier in the same import). A collision
+// just draws another code.
+func (s *ProductService) uniqueProductCode(ctx context.Context, reserved map[string]int) (string, error) {
+	for attempt := 0; attempt < maxProductCodeAttempts; attempt++ {
+		code := utils.GenerateProductCode()
+		if _, taken := reserved[code]; taken {
+			continue
+		}
+
+		exists, err := s.productRepo.ExistsByCode(ctx, code)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return code, nil
+		}
+	}
+	return "", apperror.NewConflictError("Could not generate a unique product code; please provide one")
 }
 
 // CreateProduct creates a new product
@@ -60,37 +132,65 @@ func (s *ProductService) CreateProduct(ctx context.Context, input *CreateProduct
 		return nil, apperror.NewBadRequestError("Tenant context required")
 	}
 
-	// Auto-generate code if not provided
+	// Auto-generate code if not provided, otherwise check it is free
 	code := input.Code
 	if code == "" {
-		code = utils.GenerateProductCode()
+		generated, err := s.uniqueProductCode(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		code = generated
+	} else {
+		exists, err := s.productRepo.ExistsByCode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, apperror.NewConflictError("Product code already exists")
+		}
 	}
 
-	// Check if code already exists
-	existingProduct, err := s.productRepo.GetByCode(ctx, code)
-	if err != nil {
+	if err := validateStockLevels(input.MinStock, input.MaxStock); err != nil {
 		return nil, err
 	}
-	if existingProduct != nil {
-		return nil, apperror.NewConflictError("Product code already exists")
+
+	// Products without their own low-stock threshold inherit the category default
+	quantityAlert := input.QuantityAlert
+	if quantityAlert == 0 && input.CategoryID != nil {
+		category, err := s.categoryRepo.GetByID(ctx, *input.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if category != nil {
+			quantityAlert = category.DefaultQuantityAlert
+		}
 	}
 
 	// Generate slug
 	slug := utils.Slugify(input.Name)
 
 	product := &entity.Product{
-		TenantID:      tenantID,
-		UserID:        input.UserID,
-		CategoryID:    input.CategoryID,
-		UnitID:        input.UnitID,
-		Name:          input.Name,
-		Slug:          slug,
-		Code:          code,
-		Quantity:      input.Quantity,
-		QuantityAlert: input.QuantityAlert,
-		Tax:           input.Tax,
-		TaxType:       enum.TaxType(input.TaxType),
-		Notes:         input.Notes,
+		TenantID:            tenantID,
+		UserID:              input.UserID,
+		CreatedByID:         &input.UserID,
+		CategoryID:          input.CategoryID,
+		UnitID:              input.UnitID,
+		PurchaseUnitID:      input.PurchaseUnitID,
+		PiecesPerUnit:       max(input.PiecesPerUnit, 1),
+		PreferredSupplierID: input.PreferredSupplierID,
+		Name:                input.Name,
+		Slug:                slug,
+		Code:                code,
+		Quantity:            input.Quantity,
+		QuantityAlert:       quantityAlert,
+		MinStock:            input.MinStock,
+		MaxStock:            input.MaxStock,
+		ReorderQty:          input.ReorderQty,
+		AllowNegativeStock:  input.AllowNegativeStock,
+		IsActive:            true,
+		Tax:                 input.Tax,
+		TaxType:             enum.TaxType(input.TaxType),
+		Notes:               input.Notes,
 	}
 	product.SetBuyingPriceFromDecimal(input.BuyingPrice)
 	product.SetSellingPriceFromDecimal(input.SellingPrice)
@@ -99,7 +199,7 @@ func (s *ProductService) CreateProduct(ctx context.Context, input *CreateProduct
 		return nil, err
 	}
 
-	return s.productRepo.GetByID(ctx, product.ID)
+	return s.reload(ctx, product.ID)
 }
 
 // GetProduct retrieves a product by slug
@@ -111,6 +211,24 @@ func (s *ProductService) GetProduct(ctx context.Context, slug string) (*entity.P
 	if product == nil {
 		return nil, apperror.NewNotFoundError("Product")
 	}
+	if err := s.withTaxPrices(ctx, product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// GetProductByCode retrieves a product by its code
+func (s *ProductService) GetProductByCode(ctx context.Context, code string) (*entity.Product, error) {
+	product, err := s.productRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, apperror.NewNotFoundError("Product")
+	}
+	if err := s.withTaxPrices(ctx, product); err != nil {
+		return nil, err
+	}
 	return product, nil
 }
 
@@ -123,6 +241,9 @@ func (s *ProductService) GetProductByID(ctx context.Context, id uuid.UUID) (*ent
 	if product == nil {
 		return nil, apperror.NewNotFoundError("Product")
 	}
+	if err := s.withTaxPrices(ctx, product); err != nil {
+		return nil, err
+	}
 	return product, nil
 }
 
@@ -132,8 +253,11 @@ func (s *ProductService) ListProducts(ctx context.Context, userID uuid.UUID, par
 	if err != nil {
 		return nil, err
 	}
+	if err := s.withTaxPricesAll(ctx, products); err != nil {
+		return nil, err
+	}
 
-	pag := pagination.NewPagination(params.Pagination.Page, params.Pagination.PerPage, total)
+	pag := pagination.NewPaginationForParams(params.Pagination, total)
 	return pagination.NewPaginatedResult(products, pag), nil
 }
 
@@ -143,37 +267,46 @@ func (s *ProductService) ListProductsWithCursor(ctx context.Context, userID uuid
 	if err != nil {
 		return nil, err
 	}
+	if err := s.withTaxPricesAll(ctx, products); err != nil {
+		return nil, err
+	}
 
-	hasPrev := params.Cursor.Cursor != ""
-
-	cursorPag, items := pagination.NewCursorPagination(products, params.Cursor.Limit,
+	cursorPag, items := pagination.NewCursorPagination(products, params.Cursor,
 		func(p entity.Product) string { return p.ID.String() },
 		func(p entity.Product) time.Time { return p.CreatedAt },
 	)
-	cursorPag.HasPrev = hasPrev
 
 	return pagination.NewCursorPaginatedResult(items, cursorPag), nil
 }
 
 // UpdateProductInput represents the update product input
 type UpdateProductInput struct {
-	UserID        uuid.UUID
-	ProductSlug   string
-	SkipUserCheck bool // If true (super-admin), skip ownership check
-	CategoryID    *uuid.UUID
-	UnitID        *uuid.UUID
-	Name          *string
-	Code          *string
-	Quantity      *int
-	QuantityAlert *int
-	BuyingPrice   *float64
-	SellingPrice  *float64
-	Tax           *int
-	TaxType       *int
-	Notes         *string
+	UserID              uuid.UUID
+	ProductSlug         string
+	SkipUserCheck       bool                      // If true (super-admin), skip ownership check
+	Version             *int                      // Version the client read; the update is rejected if the product has moved on
+	CategoryID          nullable.Field[uuid.UUID] // Set with Null clears the category
+	UnitID              nullable.Field[uuid.UUID] // Set with Null clears the unit
+	PurchaseUnitID      nullable.Field[uuid.UUID] // Set with Null clears the purchase unit
+	PiecesPerUnit       *int
+	PreferredSupplierID nullable.Field[uuid.UUID] // Set with Null clears the preferred supplier
+	Name                *string
+	Code                *string
+	Quantity            *int
+	QuantityAlert       *int
+	MinStock            *int
+	MaxStock            *int
+	ReorderQty          *int
+	AllowNegativeStock  *bool
+	IsActive            *bool // False takes the product off sale without deleting it
+	BuyingPrice         *float64
+	SellingPrice        *float64
+	Tax                 *int
+	TaxType             *int
+	Notes               nullable.Field[string] // Set with Null clears the notes
 }
 
-// UpdateProduct updates a product
+// UpdateProduct applies a partial update to a product; nil/unset fields are left unchanged
 func (s *ProductService) UpdateProduct(ctx context.Context, input *UpdateProductInput) (*entity.Product, error) {
 	product, err := s.productRepo.GetBySlug(ctx, input.ProductSlug)
 	if err != nil {
@@ -187,24 +320,39 @@ func (s *ProductService) UpdateProduct(ctx context.Context, input *UpdateProduct
 	if !input.SkipUserCheck && product.UserID != input.UserID {
 		return nil, apperror.ErrForbidden
 	}
+	if input.Version != nil && *input.Version != product.Version {
+		return nil, apperror.ErrVersionConflict
+	}
 
 	// Check if new code is unique
 	if input.Code != nil && *input.Code != product.Code {
-		existingProduct, err := s.productRepo.GetByCode(ctx, *input.Code)
+		exists, err := s.productRepo.ExistsByCode(ctx, *input.Code)
 		if err != nil {
 			return nil, err
 		}
-		if existingProduct != nil && existingProduct.ID != product.ID {
+		if exists {
 			return nil, apperror.NewConflictError("Product code already exists")
 		}
 		product.Code = *input.Code
 	}
 
-	if input.CategoryID != nil {
-		product.CategoryID = input.CategoryID
+	if input.CategoryID.Set {
+		product.CategoryID = input.CategoryID.Ptr()
+		product.Category = nil
 	}
-	if input.UnitID != nil {
-		product.UnitID = input.UnitID
+	if input.UnitID.Set {
+		product.UnitID = input.UnitID.Ptr()
+		product.Unit = nil
+	}
+	if input.PurchaseUnitID.Set {
+		product.PurchaseUnitID = input.PurchaseUnitID.Ptr()
+		product.PurchaseUnit = nil
+	}
+	if input.PiecesPerUnit != nil {
+		product.PiecesPerUnit = *input.PiecesPerUnit
+	}
+	if input.PreferredSupplierID.Set {
+		product.PreferredSupplierID = input.PreferredSupplierID.Ptr()
 	}
 	if input.Name != nil {
 		product.Name = *input.Name
@@ -215,6 +363,24 @@ func (s *ProductService) UpdateProduct(ctx context.Context, input *UpdateProduct
 	if input.QuantityAlert != nil {
 		product.QuantityAlert = *input.QuantityAlert
 	}
+	if input.MinStock != nil {
+		product.MinStock = *input.MinStock
+	}
+	if input.MaxStock != nil {
+		product.MaxStock = *input.MaxStock
+	}
+	if input.ReorderQty != nil {
+		product.ReorderQty = *input.ReorderQty
+	}
+	if input.AllowNegativeStock != nil {
+		product.AllowNegativeStock = *input.AllowNegativeStock
+	}
+	if input.IsActive != nil {
+		product.IsActive = *input.IsActive
+	}
+	if err := validateStockLevels(product.MinStock, product.MaxStock); err != nil {
+		return nil, err
+	}
 	if input.BuyingPrice != nil {
 		product.SetBuyingPriceFromDecimal(*input.BuyingPrice)
 	}
@@ -227,15 +393,29 @@ func (s *ProductService) UpdateProduct(ctx context.Context, input *UpdateProduct
 	if input.TaxType != nil {
 		product.TaxType = enum.TaxType(*input.TaxType)
 	}
-	if input.Notes != nil {
-		product.Notes = input.Notes
+	if input.Notes.Set {
+		product.Notes = input.Notes.Ptr()
 	}
+	product.UpdatedByID = &input.UserID
+	product.UpdatedBy = nil
 
 	if err := s.productRepo.Update(ctx, product); err != nil {
 		return nil, err
 	}
 
-	return s.productRepo.GetByID(ctx, product.ID)
+	return s.reload(ctx, product.ID)
+}
+
+// reload fetches a product after a write, with its tax prices set
+func (s *ProductService) reload(ctx context.Context, id uuid.UUID) (*entity.Product, error) {
+	product, err := s.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.withTaxPrices(ctx, product); err != nil {
+		return nil, err
+	}
+	return product, nil
 }
 
 // DeleteProduct deletes a product
@@ -245,6 +425,15 @@ func (s *ProductService) DeleteProduct(ctx context.Context, userID uuid.UUID, sl
 	if err != nil {
 		return err
 	}
+	if err := checkProductDelete(product, userID, skipOwnerCheck); err != nil {
+		return err
+	}
+
+	return s.productRepo.Delete(ctx, product.ID)
+}
+
+// checkProductDelete checks that a looked-up product exists and may be deleted by the user
+func checkProductDelete(product *entity.Product, userID uuid.UUID, skipOwnerCheck bool) error {
 	if product == nil {
 		return apperror.NewNotFoundError("Product")
 	}
@@ -253,13 +442,152 @@ func (s *ProductService) DeleteProduct(ctx context.Context, userID uuid.UUID, sl
 	if !skipOwnerCheck && product.UserID != userID {
 		return apperror.ErrForbidden
 	}
+	return nil
+}
 
-	return s.productRepo.Delete(ctx, product.ID)
+// maxBulkDeleteProducts caps how many products one bulk delete may touch
+const maxBulkDeleteProducts = 100
+
+// BulkDeleteResult is the outcome of a bulk delete for one product
+type BulkDeleteResult struct {
+	Product   string `json:"product"` // The slug or ID as given
+	Success   bool   `json:"success"`
+	Permanent bool   `json:"permanent,omitempty"` // Removed for good rather than soft-deleted
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkDeleteProducts deletes many products, each given by slug or ID. Every product
+// gets the same checks as DeleteProduct. Products referenced by orders, purchases or
+// quotations are soft-deleted so those records keep their product; the rest are
+// removed for good.
+func (s *ProductService) BulkDeleteProducts(ctx context.Context, userID uuid.UUID, refs []string, skipOwnerCheck bool) ([]BulkDeleteResult, error) {
+	if len(refs) == 0 {
+		return nil, apperror.NewBadRequestError("At least one product is required")
+	}
+	if len(refs) > maxBulkDeleteProducts {
+		return nil, apperror.NewBadRequestError(fmt.Sprintf("At most %d products can be deleted at once", maxBulkDeleteProducts))
+	}
+
+	results := make([]BulkDeleteResult, 0, len(refs))
+	seen := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		result := BulkDeleteResult{Product: ref}
+		permanent, err := s.deleteProductRef(ctx, userID, ref, skipOwnerCheck)
+		if err != nil {
+			result.Error = apperror.GetAppError(err).Message
+		} else {
+			result.Success, result.Permanent = true, permanent
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// deleteProductRef deletes the product with the given ID or slug and reports whether
+// it was removed for good
+func (s *ProductService) deleteProductRef(ctx context.Context, userID uuid.UUID, ref string, skipOwnerCheck bool) (bool, error) {
+	var product *entity.Product
+	var err error
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		product, err = s.productRepo.GetByID(ctx, id)
+	} else {
+		product, err = s.productRepo.GetBySlug(ctx, ref)
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := checkProductDelete(product, userID, skipOwnerCheck); err != nil {
+		return false, err
+	}
+
+	var permanent bool
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		refs, err := s.productRepo.CountReferences(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		if refs > 0 {
+			return s.productRepo.Delete(ctx, product.ID)
+		}
+		permanent = true
+		return s.productRepo.HardDelete(ctx, product.ID)
+	})
+	return permanent, err
+}
+
+// validateStockLevels checks that a set maximum stock level is not below the minimum
+func validateStockLevels(minStock, maxStock int) error {
+	if maxStock > 0 && maxStock < minStock {
+		return apperror.NewBadRequestError("max_stock must not be less than min_stock")
+	}
+	return nil
+}
+
+// ReorderSuggestion is a product at or below its minimum stock with the quantity to buy
+type ReorderSuggestion struct {
+	ProductID     uuid.UUID  `json:"product_id"`
+	ProductName   string     `json:"product_name"`
+	ProductCode   string     `json:"product_code"`
+	Quantity      int        `json:"quantity"`
+	MinStock      int        `json:"min_stock"`
+	MaxStock      int        `json:"max_stock"`
+	ReorderQty    int        `json:"reorder_qty"`
+	SuggestedQty  int        `json:"suggested_qty"`
+	UnitCost      float64    `json:"unit_cost"`      // Current buying price
+	EstimatedCost float64    `json:"estimated_cost"` // suggested_qty * unit_cost
+	CategoryID    *uuid.UUID `json:"category_id,omitempty"`
+	SupplierID    *uuid.UUID `json:"preferred_supplier_id,omitempty"`
+}
+
+// GetReorderSuggestions lists products at or below their minimum stock with the
+// quantity to buy (see Product.ReorderQuantity), largest shortfall first
+func (s *ProductService) GetReorderSuggestions(ctx context.Context, userID uuid.UUID) ([]ReorderSuggestion, error) {
+	products, err := s.productRepo.GetBelowMinStock(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]ReorderSuggestion, 0, len(products))
+	for _, p := range products {
+		qty := p.ReorderQuantity()
+		if qty <= 0 {
+			continue
+		}
+		suggestions = append(suggestions, ReorderSuggestion{
+			ProductID:     p.ID,
+			ProductName:   p.Name,
+			ProductCode:   p.Code,
+			Quantity:      p.Quantity,
+			MinStock:      p.MinStock,
+			MaxStock:      p.MaxStock,
+			ReorderQty:    p.ReorderQty,
+			SuggestedQty:  qty,
+			UnitCost:      p.GetBuyingPriceDecimal(),
+			EstimatedCost: money.FromCents(p.BuyingPrice * int64(qty)),
+			CategoryID:    p.CategoryID,
+			SupplierID:    p.PreferredSupplierID,
+		})
+	}
+
+	return suggestions, nil
 }
 
 // GetLowStockProducts returns products with low stock
 func (s *ProductService) GetLowStockProducts(ctx context.Context, userID uuid.UUID) ([]entity.Product, error) {
-	return s.productRepo.GetLowStock(ctx, userID)
+	products, err := s.productRepo.GetLowStock(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.withTaxPricesAll(ctx, products); err != nil {
+		return nil, err
+	}
+	return products, nil
 }
 
 // ImportProductRow represents a single row from the import file
@@ -303,12 +631,12 @@ func (s *ProductService) ImportProducts(ctx context.Context, userID uuid.UUID, r
 	var rowErrors []ImportRowError
 
 	// Load categories and units for the tenant for name-based matching
-	categoryMap := make(map[string]*uuid.UUID)
+	categoryMap := make(map[string]*entity.Category)
 	unitMap := make(map[string]*uuid.UUID)
 
 	categories, _, _ := s.categoryRepo.List(ctx, uuid.Nil, &pagination.PaginationParams{Page: 1, PerPage: 1000}, "", true)
 	for i := range categories {
-		categoryMap[strings.ToLower(categories[i].Name)] = &categories[i].ID
+		categoryMap[strings.ToLower(categories[i].Name)] = &categories[i]
 	}
 
 	units, _, _ := s.unitRepo.List(ctx, uuid.Nil, &pagination.PaginationParams{Page: 1, PerPage: 1000}, "", true)
@@ -330,35 +658,40 @@ func (s *ProductService) ImportProducts(ctx context.Context, userID uuid.UUID, r
 			continue
 		}
 
-		// Auto-generate code if empty
+		// Auto-generate code if empty; generated codes are already known to be free
 		code := strings.TrimSpace(row.Code)
 		if code == "" {
-			code = utils.GenerateProductCode()
-		}
-
-		// Check for duplicate code within the file
-		if prevRow, exists := seenCodes[code]; exists {
-			rowErrors = append(rowErrors, ImportRowError{
-				Row:     rowNum,
-				Field:   "code",
-				Message: fmt.Sprintf("Duplicate code '%s' (same as row %d)", code, prevRow),
-			})
-			continue
-		}
+			generated, err := s.uniqueProductCode(ctx, seenCodes)
+			if err != nil {
+				rowErrors = append(rowErrors, ImportRowError{Row: rowNum, Field: "code", Message: "Error generating code: " + err.Error()})
+				continue
+			}
+			code = generated
+		} else {
+			// Check for duplicate code within the file
+			if prevRow, exists := seenCodes[code]; exists {
+				rowErrors = append(rowErrors, ImportRowError{
+					Row:     rowNum,
+					Field:   "code",
+					Message: fmt.Sprintf("Duplicate code '%s' (same as row %d)", code, prevRow),
+				})
+				continue
+			}
 
-		// Check if code already exists in DB
-		existingProduct, err := s.productRepo.GetByCode(ctx, code)
-		if err != nil {
-			rowErrors = append(rowErrors, ImportRowError{Row: rowNum, Field: "code", Message: "Error checking code: " + err.Error()})
-			continue
-		}
-		if existingProduct != nil {
-			rowErrors = append(rowErrors, ImportRowError{
-				Row:     rowNum,
-				Field:   "code",
-				Message: fmt.Sprintf("Product code '%s' already exists", code),
-			})
-			continue
+			// Check if code already exists in DB
+			exists, err := s.productRepo.ExistsByCode(ctx, code)
+			if err != nil {
+				rowErrors = append(rowErrors, ImportRowError{Row: rowNum, Field: "code", Message: "Error checking code: " + err.Error()})
+				continue
+			}
+			if exists {
+				rowErrors = append(rowErrors, ImportRowError{
+					Row:     rowNum,
+					Field:   "code",
+					Message: fmt.Sprintf("Product code '%s' already exists", code),
+				})
+				continue
+			}
 		}
 
 		seenCodes[code] = rowNum
@@ -366,11 +699,15 @@ func (s *ProductService) ImportProducts(ctx context.Context, userID uuid.UUID, r
 		// Generate slug with uniqueness suffix
 		slug := utils.Slugify(row.Name) + "-" + strings.ToLower(uuid.New().String()[:8])
 
-		// Match category by name
+		// Match category by name; rows without a low-stock threshold get its default
 		var categoryID *uuid.UUID
+		quantityAlert := row.QuantityAlert
 		if row.CategoryName != "" {
-			if id, ok := categoryMap[strings.ToLower(strings.TrimSpace(row.CategoryName))]; ok {
-				categoryID = id
+			if category, ok := categoryMap[strings.ToLower(strings.TrimSpace(row.CategoryName))]; ok {
+				categoryID = &category.ID
+				if quantityAlert == 0 {
+					quantityAlert = category.DefaultQuantityAlert
+				}
 			}
 		}
 
@@ -385,13 +722,15 @@ func (s *ProductService) ImportProducts(ctx context.Context, userID uuid.UUID, r
 		product := entity.Product{
 			TenantID:      tenantID,
 			UserID:        userID,
+			CreatedByID:   &userID,
 			CategoryID:    categoryID,
 			UnitID:        unitID,
 			Name:          strings.TrimSpace(row.Name),
 			Slug:          slug,
 			Code:          code,
 			Quantity:      row.Quantity,
-			QuantityAlert: row.QuantityAlert,
+			QuantityAlert: quantityAlert,
+			IsActive:      true,
 			Tax:           row.Tax,
 			TaxType:       enum.TaxType(row.TaxType),
 		}
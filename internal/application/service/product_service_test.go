@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sangkips/investify-api/pkg/apperror"
+)
+
+func TestUniqueProductCodeRetriesOnCollision(t *testing.T) {
+	repo := newFakeProductRepo()
+	repo.existsByCode = func(code string) bool { return len(repo.codesChecked) == 1 }
+	s := &ProductService{productRepo: repo}
+
+	code, err := s.uniqueProductCode(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("uniqueProductCode: %v", err)
+	}
+	if len(repo.codesChecked) != 2 {
+		t.Fatalf("checked %d codes, want 2", len(repo.codesChecked))
+	}
+	if code != repo.codesChecked[1] {
+		t.Errorf("returned %q, want the second code drawn %q", code, repo.codesChecked[1])
+	}
+}
+
+func TestUniqueProductCodeGivesUp(t *testing.T) {
+	repo := newFakeProductRepo()
+	repo.existsByCode = func(string) bool { return true }
+	s := &ProductService{productRepo: repo}
+
+	_, err := s.uniqueProductCode(context.Background(), nil)
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || appErr.Code != apperror.ErrConflict.Code {
+		t.Fatalf("err = %v, want a conflict error", err)
+	}
+	if len(repo.codesChecked) != maxProductCodeAttempts {
+		t.Errorf("checked %d codes, want %d", len(repo.codesChecked), maxProductCodeAttempts)
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
+	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/money"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// PromotionService handles promotion (discount code) operations
+type PromotionService struct {
+	promotionRepo repository.PromotionRepository
+}
+
+// NewPromotionService creates a new promotion service
+func NewPromotionService(promotionRepo repository.PromotionRepository) *PromotionService {
+	return &PromotionService{promotionRepo: promotionRepo}
+}
+
+// PromotionInput holds the editable fields of a promotion
+type PromotionInput struct {
+	Code           string
+	Description    *string
+	Type           enum.PromotionType
+	Value          float64 // Amount off for fixed, percentage off for percent
+	MinOrderAmount float64
+	StartsAt       *time.Time
+	EndsAt         *time.Time
+	UsageLimit     *int // Nil means unlimited
+	IsActive       bool
+}
+
+// CreatePromotionInput represents the create promotion input
+type CreatePromotionInput struct {
+	UserID uuid.UUID
+	PromotionInput
+}
+
+// UpdatePromotionInput represents the update promotion input; every field is replaced
+type UpdatePromotionInput struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+	PromotionInput
+}
+
+// validate normalises the code and checks the fields against each other
+func (in *PromotionInput) validate() error {
+	in.Code = strings.ToUpper(strings.TrimSpace(in.Code))
+	if in.Code == "" {
+		return apperror.NewBadRequestError("Promo code is required")
+	}
+	if !in.Type.IsValid() {
+		return apperror.NewBadRequestError("Promotion type must be percent or fixed")
+	}
+	if in.Value <= 0 {
+		return apperror.NewBadRequestError("Promotion value must be greater than zero")
+	}
+	if in.Type == enum.PromotionPercent && in.Value > 100 {
+		return apperror.NewBadRequestError("A percent promotion cannot exceed 100")
+	}
+	if in.MinOrderAmount < 0 {
+		return apperror.NewBadRequestError("Minimum order amount cannot be negative")
+	}
+	if in.UsageLimit != nil && *in.UsageLimit < 1 {
+		return apperror.NewBadRequestError("Usage limit must be at least 1")
+	}
+	if in.StartsAt != nil && in.EndsAt != nil && !in.EndsAt.After(*in.StartsAt) {
+		return apperror.NewBadRequestError("Promotion must end after it starts")
+	}
+	return nil
+}
+
+// apply copies the input onto promotion
+func (in *PromotionInput) apply(promotion *entity.Promotion) {
+	promotion.Code = in.Code
+	promotion.Description = in.Description
+	promotion.Type = in.Type
+	promotion.Value = money.ToCents(in.Value)
+	promotion.MinOrderAmount = money.ToCents(in.MinOrderAmount)
+	promotion.StartsAt = in.StartsAt
+	promotion.EndsAt = in.EndsAt
+	promotion.UsageLimit = in.UsageLimit
+	promotion.IsActive = in.IsActive
+}
+
+// ensureCodeFree returns a conflict if another promotion in the tenant uses code
+func (s *PromotionService) ensureCodeFree(ctx context.Context, code string, id uuid.UUID) error {
+	existing, err := s.promotionRepo.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != id {
+		return apperror.NewConflictError("Promo code already exists")
+	}
+	return nil
+}
+
+// CreatePromotion creates a new promotion
+func (s *PromotionService) CreatePromotion(ctx context.Context, input *CreatePromotionInput) (*entity.Promotion, error) {
+	// Extract tenant ID from context
+	tenantID, ok := infraRepo.GetTenantID(ctx)
+	if !ok {
+		return nil, apperror.NewBadRequestError("Tenant context required")
+	}
+
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	if err := s.ensureCodeFree(ctx, input.Code, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	promotion := &entity.Promotion{
+		TenantID:    tenantID,
+		UserID:      input.UserID,
+		CreatedByID: &input.UserID,
+	}
+	input.apply(promotion)
+
+	if err := s.promotionRepo.Create(ctx, promotion); err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+// GetPromotion retrieves a promotion by ID
+func (s *PromotionService) GetPromotion(ctx context.Context, id uuid.UUID) (*entity.Promotion, error) {
+	promotion, err := s.promotionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if promotion == nil {
+		return nil, apperror.NewNotFoundError("Promotion")
+	}
+	return promotion, nil
+}
+
+// ListPromotions lists the tenant's promotions, newest first
+func (s *PromotionService) ListPromotions(ctx context.Context, params *pagination.PaginationParams, search string) (*pagination.PaginatedResult[entity.Promotion], error) {
+	promotions, total, err := s.promotionRepo.List(ctx, params, search)
+	if err != nil {
+		return nil, err
+	}
+
+	pag := pagination.NewPagination(params.Page, params.PerPage, total)
+	return pagination.NewPaginatedResult(promotions, pag), nil
+}
+
+// UpdatePromotion replaces a promotion's fields. Its use count is kept, so lowering
+// the usage limit below it leaves the promotion exhausted.
+func (s *PromotionService) UpdatePromotion(ctx context.Context, input *UpdatePromotionInput) (*entity.Promotion, error) {
+	promotion, err := s.GetPromotion(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	if input.Code != promotion.Code {
+		if err := s.ensureCodeFree(ctx, input.Code, promotion.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	input.apply(promotion)
+	promotion.UpdatedByID = &input.UserID
+
+	if err := s.promotionRepo.Update(ctx, promotion); err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+// DeletePromotion deletes a promotion. Orders keep the code and discount they were given.
+func (s *PromotionService) DeletePromotion(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.GetPromotion(ctx, id); err != nil {
+		return err
+	}
+	return s.promotionRepo.Delete(ctx, id)
+}
+
+// checkPromotion reports why promotion cannot be applied at now to an order of total
+// (in cents), or nil if it can
+func checkPromotion(promotion *entity.Promotion, total int64, now time.Time) error {
+	switch {
+	case !promotion.IsActive:
+		return apperror.NewBadRequestError("Promo code is not active")
+	case promotion.StartsAt != nil && now.Before(*promotion.StartsAt):
+		return apperror.NewBadRequestError(fmt.Sprintf("Promo code is not valid until %s", promotion.StartsAt.Format("2006-01-02 15:04")))
+	case promotion.EndsAt != nil && !now.Before(*promotion.EndsAt):
+		return apperror.NewBadRequestError("Promo code has expired")
+	case promotion.UsageLimit != nil && promotion.UsedCount >= *promotion.UsageLimit:
+		return apperror.NewBadRequestError("Promo code has reached its usage limit")
+	case total < promotion.MinOrderAmount:
+		return apperror.NewBadRequestError(fmt.Sprintf("Promo code requires an order of at least %.2f", money.FromCents(promotion.MinOrderAmount)))
+	}
+	return nil
+}
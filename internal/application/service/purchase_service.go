@@ -11,15 +11,20 @@ import (
 	"github.com/sangkips/investify-api/internal/domain/repository"
 	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
 	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/money"
 	"github.com/sangkips/investify-api/pkg/pagination"
 )
 
 // PurchaseService handles purchase-related operations
 type PurchaseService struct {
-	purchaseRepo       repository.PurchaseRepository
-	purchaseDetailRepo repository.PurchaseDetailRepository
-	productRepo        repository.ProductRepository
-	supplierRepo       repository.SupplierRepository
+	purchaseRepo        repository.PurchaseRepository
+	purchaseDetailRepo  repository.PurchaseDetailRepository
+	productRepo         repository.ProductRepository
+	supplierRepo        repository.SupplierRepository
+	supplierProductRepo repository.SupplierProductRepository
+	sequenceRepo        repository.SequenceRepository
+	tenantRepo          repository.TenantRepository
+	txManager           repository.TxManager
 }
 
 // NewPurchaseService creates a new purchase service
@@ -28,12 +33,20 @@ func NewPurchaseService(
 	purchaseDetailRepo repository.PurchaseDetailRepository,
 	productRepo repository.ProductRepository,
 	supplierRepo repository.SupplierRepository,
+	supplierProductRepo repository.SupplierProductRepository,
+	sequenceRepo repository.SequenceRepository,
+	tenantRepo repository.TenantRepository,
+	txManager repository.TxManager,
 ) *PurchaseService {
 	return &PurchaseService{
-		purchaseRepo:       purchaseRepo,
-		purchaseDetailRepo: purchaseDetailRepo,
-		productRepo:        productRepo,
-		supplierRepo:       supplierRepo,
+		purchaseRepo:        purchaseRepo,
+		purchaseDetailRepo:  purchaseDetailRepo,
+		productRepo:         productRepo,
+		supplierRepo:        supplierRepo,
+		supplierProductRepo: supplierProductRepo,
+		sequenceRepo:        sequenceRepo,
+		tenantRepo:          tenantRepo,
+		txManager:           txManager,
 	}
 }
 
@@ -93,28 +106,26 @@ func (s *PurchaseService) CreatePurchase(ctx context.Context, input *CreatePurch
 	purchaseDetails := make([]entity.PurchaseDetail, 0, len(input.Items))
 
 	for _, item := range input.Items {
-		_, exists := productMap[item.ProductID]
+		product, exists := productMap[item.ProductID]
 		if !exists {
 			return nil, apperror.NewNotFoundError(fmt.Sprintf("Product %s", item.ProductID))
 		}
 
-		unitCostCents := int64(item.UnitCost * 100)
+		unitCostCents := money.ToCents(item.UnitCost)
 		itemTotal := unitCostCents * int64(item.Quantity)
 		totalAmount += itemTotal
 
 		purchaseDetails = append(purchaseDetails, entity.PurchaseDetail{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			UnitCost:  unitCostCents,
-			Total:     itemTotal,
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			PiecesPerUnit: product.UnitFactor(),
+			UnitCost:      unitCostCents,
+			Total:         itemTotal,
 		})
 	}
 
 	// Calculate tax
-	taxAmount := int64(float64(totalAmount) * input.TaxPercentage / 100)
-
-	// Generate purchase number
-	purchaseNo := fmt.Sprintf("PUR-%s", uuid.New().String()[:8])
+	taxAmount := money.Percent(totalAmount, input.TaxPercentage)
 
 	purchase := &entity.Purchase{
 		TenantID:      tenantID,
@@ -122,29 +133,190 @@ func (s *PurchaseService) CreatePurchase(ctx context.Context, input *CreatePurch
 		SupplierID:    input.SupplierID,
 		CreatedByID:   &input.UserID,
 		Date:          time.Now(),
-		PurchaseNo:    purchaseNo,
 		Status:        enum.PurchaseStatusPending,
-		TotalAmount:   float64(totalAmount+taxAmount) / 100, // Convert cents to float
+		TotalAmount:   totalAmount + taxAmount,
 		TaxPercentage: input.TaxPercentage,
-		TaxAmount:     float64(taxAmount) / 100, // Convert cents to float
+		TaxAmount:     taxAmount,
 	}
 
-	if err := s.purchaseRepo.Create(ctx, purchase); err != nil {
+	settings, err := tenantSettings(ctx, s.tenantRepo, tenantID)
+	if err != nil {
 		return nil, err
 	}
 
-	// Create purchase details
-	for i := range purchaseDetails {
-		purchaseDetails[i].PurchaseID = purchase.ID
-	}
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Generate purchase number
+		nextNum, err := s.sequenceRepo.Next(ctx, tenantID, entity.SequencePurchase)
+		if err != nil {
+			return err
+		}
+		purchase.PurchaseNo = settings.DocumentNumber(entity.SequencePurchase, nextNum)
+
+		if err := s.purchaseRepo.Create(ctx, purchase); err != nil {
+			return err
+		}
+
+		// Create purchase details
+		for i := range purchaseDetails {
+			purchaseDetails[i].PurchaseID = purchase.ID
+		}
 
-	if err := s.purchaseDetailRepo.CreateBatch(ctx, purchaseDetails); err != nil {
+		if err := s.purchaseDetailRepo.CreateBatch(ctx, purchaseDetails); err != nil {
+			return err
+		}
+
+		return s.recordSupplierCosts(ctx, purchase, purchaseDetails, purchase.Date)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return s.purchaseRepo.GetWithDetails(ctx, purchase.ID)
 }
 
+// recordSupplierCosts refreshes the supplier-product catalog with the unit costs on a
+// purchase. Purchases without a supplier are skipped.
+func (s *PurchaseService) recordSupplierCosts(ctx context.Context, purchase *entity.Purchase, details []entity.PurchaseDetail, at time.Time) error {
+	if purchase.SupplierID == nil {
+		return nil
+	}
+
+	// One row per product; a later line for the same product wins
+	costs := make(map[uuid.UUID]int64, len(details))
+	productIDs := make([]uuid.UUID, 0, len(details))
+	for _, detail := range details {
+		if _, seen := costs[detail.ProductID]; !seen {
+			productIDs = append(productIDs, detail.ProductID)
+		}
+		costs[detail.ProductID] = detail.UnitCost
+	}
+
+	items := make([]entity.SupplierProduct, 0, len(productIDs))
+	for _, productID := range productIDs {
+		items = append(items, entity.SupplierProduct{
+			TenantID:        purchase.TenantID,
+			SupplierID:      *purchase.SupplierID,
+			ProductID:       productID,
+			LastCost:        costs[productID],
+			LastPurchasedAt: at,
+		})
+	}
+	return s.supplierProductRepo.Upsert(ctx, items)
+}
+
+// supplierProductKey identifies a supplier-product catalog entry
+type supplierProductKey struct {
+	supplierID uuid.UUID
+	productID  uuid.UUID
+}
+
+// AutoGeneratePurchases creates one pending purchase per preferred supplier for the
+// products at or below their minimum stock, using the suggested reorder quantity. Unit
+// costs are what the supplier last charged, falling back to the product's buying
+// price. Products without a preferred supplier (or whose supplier no
+// longer exists) share a single purchase with no supplier. Products already on a
+// pending purchase are skipped. Returns the drafts created.
+func (s *PurchaseService) AutoGeneratePurchases(ctx context.Context, userID uuid.UUID) ([]*entity.Purchase, error) {
+	products, err := s.productRepo.GetBelowMinStock(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]uuid.UUID, len(products))
+	for i, p := range products {
+		productIDs[i] = p.ID
+	}
+
+	// Products already on a pending purchase are on their way; ordering them again
+	// would double the delivery
+	onOrder, err := s.purchaseDetailRepo.PendingProductIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[uuid.UUID]bool, len(onOrder))
+	for _, id := range onOrder {
+		pending[id] = true
+	}
+
+	catalog, err := s.supplierProductRepo.ListByProducts(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	lastCosts := make(map[supplierProductKey]int64, len(catalog))
+	for _, item := range catalog {
+		lastCosts[supplierProductKey{item.SupplierID, item.ProductID}] = item.LastCost
+	}
+
+	// Group items by supplier in shortfall order; uuid.Nil collects unassigned products
+	var supplierOrder []uuid.UUID
+	groups := make(map[uuid.UUID][]PurchaseItemInput)
+	supplierExists := make(map[uuid.UUID]bool)
+	for _, p := range products {
+		qty := p.ReorderQuantity()
+		if qty <= 0 || pending[p.ID] {
+			continue
+		}
+
+		key := uuid.Nil
+		if p.PreferredSupplierID != nil {
+			id := *p.PreferredSupplierID
+			exists, checked := supplierExists[id]
+			if !checked {
+				supplier, err := s.supplierRepo.GetByID(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				exists = supplier != nil
+				supplierExists[id] = exists
+			}
+			if exists {
+				key = id
+			}
+		}
+
+		// Reorder quantities are in sale units; purchases are in purchase units
+		factor := p.UnitFactor()
+		qty = (qty + factor - 1) / factor
+
+		unitCost := p.BuyingPrice * int64(factor)
+		if cost, ok := lastCosts[supplierProductKey{key, p.ID}]; ok && key != uuid.Nil {
+			unitCost = cost
+		}
+
+		if _, ok := groups[key]; !ok {
+			supplierOrder = append(supplierOrder, key)
+		}
+		groups[key] = append(groups[key], PurchaseItemInput{
+			ProductID: p.ID,
+			Quantity:  qty,
+			UnitCost:  money.FromCents(unitCost),
+		})
+	}
+
+	purchases := make([]*entity.Purchase, 0, len(supplierOrder))
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, key := range supplierOrder {
+			input := &CreatePurchaseInput{UserID: userID, Items: groups[key]}
+			if key != uuid.Nil {
+				supplierID := key
+				input.SupplierID = &supplierID
+			}
+
+			purchase, err := s.CreatePurchase(ctx, input)
+			if err != nil {
+				return err
+			}
+			purchases = append(purchases, purchase)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return purchases, nil
+}
+
 // GetPurchase retrieves a purchase by ID
 func (s *PurchaseService) GetPurchase(ctx context.Context, id uuid.UUID) (*entity.Purchase, error) {
 	purchase, err := s.purchaseRepo.GetWithDetails(ctx, id)
@@ -183,22 +355,33 @@ func (s *PurchaseService) ApprovePurchase(ctx context.Context, userID, purchaseI
 		return apperror.ErrForbidden
 	}
 
-	if purchase.Status == enum.PurchaseStatusApproved {
-		return apperror.NewAppError(400, "Purchase is already approved")
+	if err := checkTransition("Purchase", purchaseTransitions, purchase.Status, enum.PurchaseStatusApproved); err != nil {
+		return err
 	}
 
 	// Build increment map for stock update
 	stockIncrements := make(map[uuid.UUID]int)
 	for _, detail := range purchase.Details {
-		stockIncrements[detail.ProductID] = detail.Quantity
+		// Stock is kept in sale units; convert from the purchase unit
+		factor := detail.PiecesPerUnit
+		if factor < 1 {
+			factor = 1
+		}
+		stockIncrements[detail.ProductID] += detail.Quantity * factor
 	}
 
-	// Atomically add purchased quantities to stock
-	if err := s.productRepo.AtomicIncrementBatch(ctx, stockIncrements); err != nil {
-		return err
-	}
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Atomically add purchased quantities to stock
+		if err := s.productRepo.AtomicIncrementBatch(ctx, stockIncrements); err != nil {
+			return err
+		}
+
+		if err := s.purchaseRepo.UpdateStatus(ctx, purchaseID, enum.PurchaseStatusApproved, userID); err != nil {
+			return err
+		}
 
-	return s.purchaseRepo.UpdateStatus(ctx, purchaseID, enum.PurchaseStatusApproved, userID)
+		return s.recordSupplierCosts(ctx, purchase, purchase.Details, time.Now())
+	})
 }
 
 // DeletePurchase deletes a pending purchase
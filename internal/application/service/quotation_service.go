@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,6 +10,7 @@ import (
 	"github.com/sangkips/investify-api/internal/domain/repository"
 	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
 	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/money"
 	"github.com/sangkips/investify-api/pkg/pagination"
 )
 
@@ -20,6 +20,9 @@ type QuotationService struct {
 	quotationDetailRepo repository.QuotationDetailRepository
 	productRepo         repository.ProductRepository
 	customerRepo        repository.CustomerRepository
+	sequenceRepo        repository.SequenceRepository
+	tenantRepo          repository.TenantRepository
+	txManager           repository.TxManager
 }
 
 // NewQuotationService creates a new quotation service
@@ -28,12 +31,18 @@ func NewQuotationService(
 	quotationDetailRepo repository.QuotationDetailRepository,
 	productRepo repository.ProductRepository,
 	customerRepo repository.CustomerRepository,
+	sequenceRepo repository.SequenceRepository,
+	tenantRepo repository.TenantRepository,
+	txManager repository.TxManager,
 ) *QuotationService {
 	return &QuotationService{
 		quotationRepo:       quotationRepo,
 		quotationDetailRepo: quotationDetailRepo,
 		productRepo:         productRepo,
 		customerRepo:        customerRepo,
+		sequenceRepo:        sequenceRepo,
+		tenantRepo:          tenantRepo,
+		txManager:           txManager,
 	}
 }
 
@@ -42,12 +51,14 @@ type CreateQuotationInput struct {
 	UserID             uuid.UUID
 	CustomerID         *uuid.UUID
 	Date               time.Time
+	TaxMode            enum.QuotationTaxMode // Empty means flat
 	TaxPercentage      float64
 	DiscountPercentage float64
 	ShippingAmount     float64
 	Note               *string
 	Status             enum.QuotationStatus
 	Items              []QuotationItemInput
+	CheckStock         bool // Report lines exceeding current stock without blocking
 }
 
 // QuotationItemInput represents a line item input
@@ -57,6 +68,87 @@ type QuotationItemInput struct {
 	UnitPrice float64
 }
 
+// quotationAmounts holds the computed quotation amounts in cents
+type quotationAmounts struct {
+	mode          enum.QuotationTaxMode
+	taxPercentage float64
+	tax           int64
+	discount      int64
+	total         int64
+}
+
+// quotationTotals computes tax, discount and total in cents to avoid floating-point drift.
+// In flat mode the tax percentage applies to the whole subtotal. In per-product mode VAT
+// is computed per line at vatRate from the product's TaxType exactly like CreateOrder, so a
+// converted quotation matches the order; the tax percentage is then the VAT rate.
+func quotationTotals(mode enum.QuotationTaxMode, items []QuotationItemInput, products map[uuid.UUID]*entity.Product, vatRate, taxPercentage, discountPercentage, shipping float64) (*quotationAmounts, error) {
+	if mode == "" {
+		mode = enum.QuotationTaxFlat
+	}
+	if !mode.IsValid() {
+		return nil, apperror.NewBadRequestError("Invalid tax_mode: use 'flat' or 'per_product'")
+	}
+
+	var subtotal, exclusive, inclusive int64
+	for _, item := range items {
+		lineTotal := money.ToCents(item.UnitPrice) * int64(item.Quantity)
+		subtotal += lineTotal
+		if product := products[item.ProductID]; product != nil && product.TaxType == enum.TaxTypeInclusive {
+			inclusive += lineTotal
+		} else {
+			exclusive += lineTotal
+		}
+	}
+
+	amounts := &quotationAmounts{
+		mode:          mode,
+		taxPercentage: taxPercentage,
+		discount:      money.Percent(subtotal, discountPercentage),
+	}
+
+	// In per-product mode only the VAT on exclusive-priced lines is added to the total;
+	// VAT contained in inclusive prices is reported but is already in the subtotal
+	added := money.Percent(subtotal, taxPercentage)
+	amounts.tax = added
+	if mode == enum.QuotationTaxPerProduct {
+		var included int64
+		added, included = splitVAT(exclusive, inclusive, vatRate)
+		amounts.taxPercentage = vatRate * 100
+		amounts.tax = added + included
+	}
+
+	amounts.total = subtotal + added - amounts.discount + money.ToCents(shipping)
+	return amounts, nil
+}
+
+// stockWarnings lists the products whose total quoted quantity exceeds their stock.
+// Quantities of repeated products are summed; warnings follow the order of first use.
+func stockWarnings(items []QuotationItemInput, products map[uuid.UUID]*entity.Product) []entity.StockWarning {
+	requested := make(map[uuid.UUID]int, len(items))
+	var order []uuid.UUID
+	for _, item := range items {
+		if _, seen := requested[item.ProductID]; !seen {
+			order = append(order, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	var warnings []entity.StockWarning
+	for _, id := range order {
+		product := products[id]
+		if product == nil || requested[id] <= product.Quantity {
+			continue
+		}
+		warnings = append(warnings, entity.StockWarning{
+			ProductID:   id,
+			ProductName: product.Name,
+			Requested:   requested[id],
+			Available:   product.Quantity,
+		})
+	}
+	return warnings
+}
+
 // CreateQuotation creates a new quotation
 func (s *QuotationService) CreateQuotation(ctx context.Context, input *CreateQuotationInput) (*entity.Quotation, error) {
 	// Extract tenant ID from context
@@ -65,12 +157,9 @@ func (s *QuotationService) CreateQuotation(ctx context.Context, input *CreateQuo
 		return nil, apperror.NewBadRequestError("Tenant context required")
 	}
 
-	// Generate reference number
-	nextNum, err := s.quotationRepo.GetNextReferenceNumber(ctx)
-	if err != nil {
-		return nil, err
+	if !input.Status.IsValid() {
+		return nil, apperror.NewBadRequestError("Invalid quotation status")
 	}
-	reference := fmt.Sprintf("QT-%06d", nextNum)
 
 	// Get customer name if customer ID is provided
 	var customerName string
@@ -84,65 +173,101 @@ func (s *QuotationService) CreateQuotation(ctx context.Context, input *CreateQuo
 		}
 	}
 
-	// Calculate subtotal
-	var subtotal float64
+	// Batch fetch and validate all products before opening the transaction
+	productIDs := make([]uuid.UUID, len(input.Items))
+	for i, item := range input.Items {
+		productIDs[i] = item.ProductID
+	}
+
+	products, err := s.productRepo.GetByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	productMap := make(map[uuid.UUID]*entity.Product, len(products))
+	for i := range products {
+		productMap[products[i].ID] = &products[i]
+	}
+
+	details := make([]entity.QuotationDetail, 0, len(input.Items))
 	for _, item := range input.Items {
-		subtotal += float64(item.Quantity) * item.UnitPrice
+		product, exists := productMap[item.ProductID]
+		if !exists {
+			return nil, apperror.NewNotFoundError("Product")
+		}
+
+		detail := entity.QuotationDetail{
+			ProductID:   item.ProductID,
+			ProductName: product.Name,
+			ProductCode: product.Code,
+			Quantity:    item.Quantity,
+		}
+		detail.SetUnitPriceFromDecimal(item.UnitPrice)
+		detail.SubTotal = detail.UnitPrice * int64(item.Quantity)
+		details = append(details, detail)
 	}
 
-	// Calculate tax and discount amounts
-	taxAmount := (subtotal * input.TaxPercentage) / 100
-	discountAmount := (subtotal * input.DiscountPercentage) / 100
-	totalAmount := subtotal + taxAmount - discountAmount + input.ShippingAmount
+	settings, err := tenantSettings(ctx, s.tenantRepo, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := quotationTotals(input.TaxMode, input.Items, productMap, settings.VATRate(), input.TaxPercentage, input.DiscountPercentage, input.ShippingAmount)
+	if err != nil {
+		return nil, err
+	}
 
 	quotation := &entity.Quotation{
 		TenantID:           tenantID,
 		UserID:             input.UserID,
 		CustomerID:         input.CustomerID,
 		Date:               input.Date,
-		Reference:          reference,
 		CustomerName:       customerName,
-		TaxPercentage:      input.TaxPercentage,
-		TaxAmount:          taxAmount,
+		TaxMode:            totals.mode,
+		TaxPercentage:      totals.taxPercentage,
+		TaxAmount:          totals.tax,
 		DiscountPercentage: input.DiscountPercentage,
-		DiscountAmount:     discountAmount,
-		ShippingAmount:     input.ShippingAmount,
-		TotalAmount:        totalAmount,
+		DiscountAmount:     totals.discount,
+		TotalAmount:        totals.total,
 		Status:             input.Status,
 		Note:               input.Note,
 	}
+	quotation.SetShippingAmountFromDecimal(input.ShippingAmount)
 
-	if err := s.quotationRepo.Create(ctx, quotation); err != nil {
-		return nil, err
-	}
-
-	// Create quotation details
-	for _, item := range input.Items {
-		product, err := s.productRepo.GetByID(ctx, item.ProductID)
+	// Reference allocation, quotation insert and detail insert run in one transaction:
+	// the sequence row lock serializes concurrent creates, and a failure releases the number
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		nextNum, err := s.sequenceRepo.Next(ctx, tenantID, entity.SequenceQuotation)
 		if err != nil {
-			return nil, err
-		}
-		if product == nil {
-			return nil, apperror.NewNotFoundError("Product")
+			return err
 		}
+		quotation.Reference = settings.DocumentNumber(entity.SequenceQuotation, nextNum)
 
-		detail := &entity.QuotationDetail{
-			QuotationID: quotation.ID,
-			ProductID:   item.ProductID,
-			ProductName: product.Name,
-			ProductCode: product.Code,
-			Quantity:    item.Quantity,
-			UnitPrice:   item.UnitPrice,
-			SubTotal:    float64(item.Quantity) * item.UnitPrice,
+		if err := s.quotationRepo.Create(ctx, quotation); err != nil {
+			return err
 		}
 
-		if err := s.quotationDetailRepo.Create(ctx, detail); err != nil {
-			return nil, err
+		if len(details) == 0 {
+			return nil
 		}
+		for i := range details {
+			details[i].QuotationID = quotation.ID
+		}
+		return s.quotationDetailRepo.CreateBatch(ctx, details)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch the complete quotation with details
-	return s.quotationRepo.GetWithDetails(ctx, quotation.ID)
+	created, err := s.quotationRepo.GetWithDetails(ctx, quotation.ID)
+	if err != nil || created == nil {
+		return created, err
+	}
+	if input.CheckStock {
+		created.StockWarnings = stockWarnings(input.Items, productMap)
+	}
+	return created, nil
 }
 
 // GetQuotation retrieves a quotation by ID
@@ -165,6 +290,8 @@ type ListQuotationsInput struct {
 	Search       string
 	Status       *enum.QuotationStatus
 	CustomerID   *uuid.UUID
+	StartDate    *time.Time
+	EndDate      *time.Time
 }
 
 // ListQuotations lists quotations with filtering
@@ -174,6 +301,8 @@ func (s *QuotationService) ListQuotations(ctx context.Context, input *ListQuotat
 		Search:     input.Search,
 		Status:     input.Status,
 		CustomerID: input.CustomerID,
+		StartDate:  input.StartDate,
+		EndDate:    input.EndDate,
 	}
 
 	var userID uuid.UUID
@@ -197,12 +326,14 @@ type UpdateQuotationInput struct {
 	IsSuperAdmin       bool
 	CustomerID         *uuid.UUID
 	Date               time.Time
+	TaxMode            enum.QuotationTaxMode // Empty means flat
 	TaxPercentage      float64
 	DiscountPercentage float64
 	ShippingAmount     float64
 	Note               *string
 	Status             enum.QuotationStatus
 	Items              []QuotationItemInput
+	CheckStock         bool // Report lines exceeding current stock without blocking
 }
 
 // UpdateQuotation updates an existing quotation
@@ -220,6 +351,12 @@ func (s *QuotationService) UpdateQuotation(ctx context.Context, input *UpdateQuo
 		return nil, apperror.ErrForbidden
 	}
 
+	if input.Status != quotation.Status {
+		if err := checkTransition("Quotation", quotationTransitions, quotation.Status, input.Status); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get customer name if customer ID is provided
 	var customerName string
 	if input.CustomerID != nil {
@@ -232,64 +369,99 @@ func (s *QuotationService) UpdateQuotation(ctx context.Context, input *UpdateQuo
 		}
 	}
 
-	// Calculate subtotal
-	var subtotal float64
-	for _, item := range input.Items {
-		subtotal += float64(item.Quantity) * item.UnitPrice
+	// Batch fetch and validate all products before opening the transaction
+	productIDs := make([]uuid.UUID, len(input.Items))
+	for i, item := range input.Items {
+		productIDs[i] = item.ProductID
 	}
 
-	// Calculate tax and discount amounts
-	taxAmount := (subtotal * input.TaxPercentage) / 100
-	discountAmount := (subtotal * input.DiscountPercentage) / 100
-	totalAmount := subtotal + taxAmount - discountAmount + input.ShippingAmount
-
-	// Update quotation fields
-	quotation.CustomerID = input.CustomerID
-	quotation.Date = input.Date
-	quotation.CustomerName = customerName
-	quotation.TaxPercentage = input.TaxPercentage
-	quotation.TaxAmount = taxAmount
-	quotation.DiscountPercentage = input.DiscountPercentage
-	quotation.DiscountAmount = discountAmount
-	quotation.ShippingAmount = input.ShippingAmount
-	quotation.TotalAmount = totalAmount
-	quotation.Status = input.Status
-	quotation.Note = input.Note
-
-	if err := s.quotationRepo.Update(ctx, quotation); err != nil {
+	products, err := s.productRepo.GetByIDs(ctx, productIDs)
+	if err != nil {
 		return nil, err
 	}
 
-	// Delete existing details and create new ones
-	if err := s.quotationDetailRepo.DeleteByQuotationID(ctx, quotation.ID); err != nil {
-		return nil, err
+	productMap := make(map[uuid.UUID]*entity.Product, len(products))
+	for i := range products {
+		productMap[products[i].ID] = &products[i]
 	}
 
+	details := make([]entity.QuotationDetail, 0, len(input.Items))
 	for _, item := range input.Items {
-		product, err := s.productRepo.GetByID(ctx, item.ProductID)
-		if err != nil {
-			return nil, err
-		}
-		if product == nil {
+		product, exists := productMap[item.ProductID]
+		if !exists {
 			return nil, apperror.NewNotFoundError("Product")
 		}
 
-		detail := &entity.QuotationDetail{
-			QuotationID: quotation.ID,
+		detail := entity.QuotationDetail{
 			ProductID:   item.ProductID,
 			ProductName: product.Name,
 			ProductCode: product.Code,
 			Quantity:    item.Quantity,
-			UnitPrice:   item.UnitPrice,
-			SubTotal:    float64(item.Quantity) * item.UnitPrice,
 		}
+		detail.SetUnitPriceFromDecimal(item.UnitPrice)
+		detail.SubTotal = detail.UnitPrice * int64(item.Quantity)
+		details = append(details, detail)
+	}
 
-		if err := s.quotationDetailRepo.Create(ctx, detail); err != nil {
-			return nil, err
+	vatRate, err := tenantVATRate(ctx, s.tenantRepo, quotation.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := quotationTotals(input.TaxMode, input.Items, productMap, vatRate, input.TaxPercentage, input.DiscountPercentage, input.ShippingAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update quotation fields
+	quotation.CustomerID = input.CustomerID
+	quotation.Date = input.Date
+	quotation.CustomerName = customerName
+	quotation.TaxMode = totals.mode
+	quotation.TaxPercentage = totals.taxPercentage
+	quotation.TaxAmount = totals.tax
+	quotation.DiscountPercentage = input.DiscountPercentage
+	quotation.DiscountAmount = totals.discount
+	quotation.SetShippingAmountFromDecimal(input.ShippingAmount)
+	quotation.TotalAmount = totals.total
+	quotation.Status = input.Status
+	quotation.Note = input.Note
+
+	// Header update and detail replacement run in one transaction so a failure rolls
+	// back to the original lines. The row lock makes concurrent updates of the same
+	// quotation wait for each other instead of both appending their lines.
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.quotationRepo.LockForUpdate(ctx, quotation.ID); err != nil {
+			return err
+		}
+		if err := s.quotationRepo.Update(ctx, quotation); err != nil {
+			return err
 		}
+
+		// Delete existing details and create the new ones from the batch-fetched products
+		if err := s.quotationDetailRepo.DeleteByQuotationID(ctx, quotation.ID); err != nil {
+			return err
+		}
+		if len(details) == 0 {
+			return nil
+		}
+		for i := range details {
+			details[i].QuotationID = quotation.ID
+		}
+		return s.quotationDetailRepo.CreateBatch(ctx, details)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.quotationRepo.GetWithDetails(ctx, quotation.ID)
+	updated, err := s.quotationRepo.GetWithDetails(ctx, quotation.ID)
+	if err != nil || updated == nil {
+		return updated, err
+	}
+	if input.CheckStock {
+		updated.StockWarnings = stockWarnings(input.Items, productMap)
+	}
+	return updated, nil
 }
 
 // DeleteQuotation deletes a quotation
@@ -307,12 +479,14 @@ func (s *QuotationService) DeleteQuotation(ctx context.Context, userID, id uuid.
 		return apperror.ErrForbidden
 	}
 
-	// Delete details first
-	if err := s.quotationDetailRepo.DeleteByQuotationID(ctx, id); err != nil {
-		return err
-	}
-
-	return s.quotationRepo.Delete(ctx, id)
+	// Delete details first; both deletes share a transaction so a failure cannot
+	// leave a quotation header without its lines
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.quotationDetailRepo.DeleteByQuotationID(ctx, id); err != nil {
+			return err
+		}
+		return s.quotationRepo.Delete(ctx, id)
+	})
 }
 
 // UpdateQuotationStatus updates the status of a quotation
@@ -330,5 +504,9 @@ func (s *QuotationService) UpdateQuotationStatus(ctx context.Context, userID, id
 		return apperror.ErrForbidden
 	}
 
+	if err := checkTransition("Quotation", quotationTransitions, quotation.Status, status); err != nil {
+		return err
+	}
+
 	return s.quotationRepo.UpdateStatus(ctx, id, status)
 }
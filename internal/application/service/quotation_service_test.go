@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
+)
+
+func TestCreateQuotationConcurrentReferencesAreUnique(t *testing.T) {
+	product := entity.Product{ID: uuid.New(), Name: "Widget", Code: "W-1", Quantity: 10}
+	s := NewQuotationService(&fakeQuotationRepo{}, fakeQuotationDetailRepo{}, newFakeProductRepo(product),
+		nil, &fakeSequenceRepo{}, fakeTenantRepo{}, fakeTxManager{})
+	ctx := infraRepo.WithTenant(context.Background(), uuid.New())
+
+	const creates = 20
+	references := make([]string, creates)
+	errs := make([]error, creates)
+	var wg sync.WaitGroup
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			quotation, err := s.CreateQuotation(ctx, &CreateQuotationInput{
+				UserID: uuid.New(),
+				Date:   time.Now(),
+				Status: enum.QuotationStatusPending,
+				Items:  []QuotationItemInput{{ProductID: product.ID, Quantity: 1, UnitPrice: 10}},
+			})
+			if err == nil {
+				references[i] = quotation.Reference
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, creates)
+	for i, ref := range references {
+		if errs[i] != nil {
+			t.Fatalf("CreateQuotation: %v", errs[i])
+		}
+		if ref == "" {
+			t.Fatal("quotation created without a reference")
+		}
+		if seen[ref] {
+			t.Errorf("reference %s was allocated twice", ref)
+		}
+		seen[ref] = true
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"strconv"
+
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/pkg/email"
+	"github.com/sangkips/investify-api/pkg/money"
+	"github.com/sangkips/investify-api/pkg/pdf"
+)
+
+// defaultStoreName heads receipts of tenants without a name
+const defaultStoreName = "Investify Store"
+
+// orderReceipt composes the receipt of an order loaded with its customer and
+// details. The header and presentation settings are filled by applyReceiptTenant.
+func orderReceipt(order *entity.Order) *entity.Receipt {
+	receipt := &entity.Receipt{
+		InvoiceNo:   order.InvoiceNo,
+		Date:        order.OrderDate.Format("2006-01-02 15:04"),
+		PaymentType: order.PaymentType,
+		SubTotal:    float64(order.SubTotal) / 100,
+		VAT:         float64(order.VAT) / 100,
+		TaxExempt:   order.TaxExempt,
+		PromoCode:   order.PromoCode,
+		Promo:       float64(order.PromoDiscount) / 100,
+		Discount:    float64(order.LoyaltyValue) / 100,
+		Rounding:    float64(order.Rounding) / 100,
+		Total:       float64(order.Total) / 100,
+		Paid:        float64(order.Pay) / 100,
+		Due:         float64(order.Due) / 100,
+		Change:      float64(order.Change) / 100,
+	}
+
+	if order.Customer != nil {
+		receipt.Customer = order.Customer.Name
+	}
+
+	for _, d := range order.Details {
+		item := entity.ReceiptItem{
+			Name:         d.ProductName,
+			Quantity:     d.Quantity,
+			UnitPrice:    float64(d.UnitCost) / 100,
+			Total:        float64(d.Total) / 100,
+			TaxAmount:    float64(d.TaxAmount) / 100,
+			TaxInclusive: d.TaxType == enum.TaxTypeInclusive,
+		}
+		if item.Name == "" {
+			if d.Product.Name != "" {
+				item.Name = d.Product.Name
+			} else {
+				item.Name = "Product"
+			}
+		}
+		receipt.Items = append(receipt.Items, item)
+	}
+
+	return receipt
+}
+
+// applyReceiptTenant fills the receipt header (name, KRA PIN) and the currency, locale,
+// tax label, footer and paper width from the tenant's settings. A nil tenant leaves a
+// generic store name.
+func applyReceiptTenant(r *entity.Receipt, tenant *entity.Tenant) {
+	r.Header = entity.ReceiptHeader{StoreName: defaultStoreName}
+	if tenant == nil {
+		return
+	}
+
+	if tenant.Name != "" {
+		r.Header.StoreName = tenant.Name
+	}
+	r.Header.TaxID = tenant.Settings.KRAPin
+	r.Currency = tenant.Settings.Currency
+	r.Locale = tenant.Settings.Locale
+	r.TaxLabel = tenant.Settings.TaxLabel
+	r.Footer = tenant.Settings.ReceiptFooter
+	r.PaperWidth = tenant.Settings.ReceiptPaperWidth
+}
+
+// receiptFooter returns the tenant's receipt footer or the default thank-you line
+func receiptFooter(r *entity.Receipt) string {
+	if r.Footer != "" {
+		return r.Footer
+	}
+	return "Thank you for your business!"
+}
+
+// receiptTaxLabel returns the tenant's tax label or "VAT"
+func receiptTaxLabel(r *entity.Receipt) string {
+	if r.TaxLabel != "" {
+		return r.TaxLabel
+	}
+	return "VAT"
+}
+
+// receiptPromoLabel names the promo code applied to the order, if it is known
+func receiptPromoLabel(r *entity.Receipt) string {
+	if r.PromoCode != "" {
+		return "Discount (" + r.PromoCode + ")"
+	}
+	return "Discount"
+}
+
+// itemTax formats a line's tax for the receipt, marking tax already included in
+// the line total; lines without tax show a dash
+func itemTax(item entity.ReceiptItem, amount func(float64) string) string {
+	switch {
+	case item.TaxAmount == 0:
+		return "-"
+	case item.TaxInclusive:
+		return amount(item.TaxAmount) + " incl."
+	}
+	return amount(item.TaxAmount)
+}
+
+// emailReceipt formats a receipt's amounts for the receipt email
+func emailReceipt(r *entity.Receipt) email.OrderReceipt {
+	f := money.NewFormatter(r.Currency, r.Locale)
+	amount := func(v float64) string { return f.Number(money.ToCents(v)) }
+
+	out := email.OrderReceipt{
+		StoreName:   r.Header.StoreName,
+		TaxID:       r.Header.TaxID,
+		InvoiceNo:   r.InvoiceNo,
+		Date:        r.Date,
+		Customer:    r.Customer,
+		PaymentType: r.PaymentType,
+		Currency:    f.Currency,
+		SubTotal:    amount(r.SubTotal),
+		TaxLabel:    receiptTaxLabel(r),
+		Total:       f.Format(money.ToCents(r.Total)),
+		Footer:      receiptFooter(r),
+	}
+	if r.VAT > 0 {
+		out.VAT = amount(r.VAT)
+	} else if r.TaxExempt {
+		out.VAT = "Exempt"
+	}
+	if r.Promo > 0 {
+		out.PromoLabel = receiptPromoLabel(r)
+		out.Promo = "-" + amount(r.Promo)
+	}
+	if r.Discount > 0 {
+		out.Discount = "-" + amount(r.Discount)
+	}
+	if r.Rounding != 0 {
+		out.Rounding = amount(r.Rounding)
+	}
+	if r.Paid > 0 {
+		out.Paid = amount(r.Paid)
+	}
+	if r.Due > 0 {
+		out.Due = amount(r.Due)
+	}
+	if r.Change > 0 {
+		out.Change = amount(r.Change)
+	}
+	for _, item := range r.Items {
+		out.Items = append(out.Items, email.OrderReceiptItem{
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: amount(item.UnitPrice),
+			Total:     amount(item.Total),
+		})
+	}
+	return out
+}
+
+// RenderReceiptPDF lays out a receipt as an A4 PDF invoice.
+func RenderReceiptPDF(r *entity.Receipt) []byte {
+	doc := pdf.NewDocument()
+	f := money.NewFormatter(r.Currency, r.Locale)
+	amount := func(v float64) string { return f.Number(money.ToCents(v)) }
+	right := pdf.PageWidth - pdf.Margin
+
+	// Store header and receipt details
+	y := pdf.Margin + 16
+	doc.SetFont(true, 16).Text(pdf.Margin, y, pdf.AlignLeft, r.Header.StoreName)
+	doc.Text(right, y, pdf.AlignRight, "RECEIPT")
+	y += 16
+	doc.SetFont(false, 10)
+	if r.Header.TaxID != "" {
+		doc.Text(pdf.Margin, y, pdf.AlignLeft, "Tax ID: "+r.Header.TaxID)
+	}
+	doc.Text(right, y, pdf.AlignRight, "Invoice "+r.InvoiceNo)
+	y += 14
+	doc.Text(right, y, pdf.AlignRight, r.Date)
+	for _, kv := range [][2]string{{"Customer", r.Customer}, {"Payment", r.PaymentType}} {
+		if kv[1] != "" {
+			y += 14
+			doc.Text(right, y, pdf.AlignRight, kv[0]+": "+kv[1])
+		}
+	}
+
+	// Items; column positions are right edges except for the description
+	colQty, colPrice, colTax := right-270, right-180, right-90
+	tableHeader := func() {
+		doc.SetFont(true, 10)
+		doc.Text(pdf.Margin, y, pdf.AlignLeft, "Item")
+		doc.Text(colQty, y, pdf.AlignRight, "Qty")
+		doc.Text(colPrice, y, pdf.AlignRight, "Unit price")
+		doc.Text(colTax, y, pdf.AlignRight, receiptTaxLabel(r))
+		doc.Text(right, y, pdf.AlignRight, "Total")
+		doc.Line(pdf.Margin, y+4, right, y+4)
+		doc.SetFont(false, 10)
+		y += 18
+	}
+
+	y += 30
+	tableHeader()
+	for _, item := range r.Items {
+		if y > pdf.PageHeight-pdf.Margin-20 {
+			doc.AddPage()
+			y = pdf.Margin + 12
+			tableHeader()
+		}
+		doc.Text(pdf.Margin, y, pdf.AlignLeft, item.Name)
+		doc.Text(colQty, y, pdf.AlignRight, strconv.Itoa(item.Quantity))
+		doc.Text(colPrice, y, pdf.AlignRight, amount(item.UnitPrice))
+		doc.Text(colTax, y, pdf.AlignRight, itemTax(item, amount))
+		doc.Text(right, y, pdf.AlignRight, amount(item.Total))
+		y += 16
+	}
+
+	// Totals and footer need about 160pt
+	if y > pdf.PageHeight-pdf.Margin-160 {
+		doc.AddPage()
+		y = pdf.Margin
+	}
+	doc.Line(pdf.Margin, y-10, right, y-10)
+	y += 4
+	totals := [][2]string{{"Subtotal", amount(r.SubTotal)}}
+	if r.VAT > 0 {
+		totals = append(totals, [2]string{receiptTaxLabel(r), amount(r.VAT)})
+	} else if r.TaxExempt {
+		totals = append(totals, [2]string{receiptTaxLabel(r), "Exempt"})
+	}
+	if r.Promo > 0 {
+		totals = append(totals, [2]string{receiptPromoLabel(r), "-" + amount(r.Promo)})
+	}
+	if r.Discount > 0 {
+		totals = append(totals, [2]string{"Loyalty discount", "-" + amount(r.Discount)})
+	}
+	if r.Rounding != 0 {
+		totals = append(totals, [2]string{"Rounding", amount(r.Rounding)})
+	}
+	for _, kv := range totals {
+		doc.Text(colPrice, y, pdf.AlignRight, kv[0])
+		doc.Text(right, y, pdf.AlignRight, kv[1])
+		y += 16
+	}
+	doc.SetFont(true, 11)
+	doc.Text(colPrice, y, pdf.AlignRight, "Total")
+	doc.Text(right, y, pdf.AlignRight, f.FormatCode(money.ToCents(r.Total)))
+	doc.SetFont(false, 10)
+	y += 18
+	payments := []struct {
+		label string
+		value float64
+	}{{"Paid", r.Paid}, {"Due", r.Due}, {"Change", r.Change}}
+	for _, p := range payments {
+		if p.value > 0 {
+			doc.Text(colPrice, y, pdf.AlignRight, p.label)
+			doc.Text(right, y, pdf.AlignRight, amount(p.value))
+			y += 16
+		}
+	}
+
+	y += 24
+	doc.Text(pdf.PageWidth/2, y, pdf.AlignCenter, receiptFooter(r))
+
+	return doc.Bytes()
+}
@@ -0,0 +1,497 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/money"
+)
+
+// maxSeriesBuckets caps the number of buckets a sales series may return
+const maxSeriesBuckets = 1000
+
+// ReportService handles report generation
+type ReportService struct {
+	analyticsRepo repository.AnalyticsRepository
+	orderRepo     repository.OrderRepository
+}
+
+// NewReportService creates a new report service
+func NewReportService(analyticsRepo repository.AnalyticsRepository, orderRepo repository.OrderRepository) *ReportService {
+	return &ReportService{analyticsRepo: analyticsRepo, orderRepo: orderRepo}
+}
+
+// SalesSeriesPoint is one bucket of a sales series
+type SalesSeriesPoint struct {
+	PeriodStart string  `json:"period_start"` // YYYY-MM-DD
+	Revenue     float64 `json:"revenue"`
+	Profit      float64 `json:"profit"`
+	OrdersCount int     `json:"orders_count"`
+}
+
+// SalesSeries is revenue and profit over time
+type SalesSeries struct {
+	Granularity repository.SalesGranularity `json:"granularity"`
+	Start       string                      `json:"start"`
+	End         string                      `json:"end"`
+	Points      []SalesSeriesPoint          `json:"points"`
+}
+
+// GetSalesSeries returns revenue and profit per day, week or month for orders dated
+// from start to end inclusive. Buckets without sales are included with zero values so
+// charts get a continuous axis.
+func (s *ReportService) GetSalesSeries(ctx context.Context, start, end time.Time, granularity repository.SalesGranularity) (*SalesSeries, error) {
+	if end.Before(start) {
+		return nil, apperror.NewBadRequestError("'end' must not be before 'start'")
+	}
+
+	first := truncateToBucket(start, granularity)
+	buckets := 0
+	for b := first; !b.After(end); b = nextBucket(b, granularity) {
+		if buckets++; buckets > maxSeriesBuckets {
+			return nil, apperror.NewBadRequestError("Date range too large for the selected granularity")
+		}
+	}
+
+	rows, err := s.analyticsRepo.GetSalesSeries(ctx, start, end.AddDate(0, 0, 1), granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	byBucket := make(map[string]repository.SalesSeriesPoint, len(rows))
+	for _, row := range rows {
+		byBucket[row.Bucket.Format("2006-01-02")] = row
+	}
+
+	series := &SalesSeries{
+		Granularity: granularity,
+		Start:       start.Format("2006-01-02"),
+		End:         end.Format("2006-01-02"),
+		Points:      make([]SalesSeriesPoint, 0, buckets),
+	}
+	for b := first; !b.After(end); b = nextBucket(b, granularity) {
+		key := b.Format("2006-01-02")
+		row := byBucket[key]
+		series.Points = append(series.Points, SalesSeriesPoint{
+			PeriodStart: key,
+			Revenue:     row.Revenue,
+			Profit:      row.Profit,
+			OrdersCount: row.OrdersCount,
+		})
+	}
+
+	return series, nil
+}
+
+// truncateToBucket returns the start of the bucket containing t, matching Postgres date_trunc
+func truncateToBucket(t time.Time, granularity repository.SalesGranularity) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case repository.GranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+		return day.AddDate(0, 0, -offset)
+	case repository.GranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextBucket returns the start of the bucket after b
+func nextBucket(b time.Time, granularity repository.SalesGranularity) time.Time {
+	switch granularity {
+	case repository.GranularityWeek:
+		return b.AddDate(0, 0, 7)
+	case repository.GranularityMonth:
+		return b.AddDate(0, 1, 0)
+	default:
+		return b.AddDate(0, 0, 1)
+	}
+}
+
+// HourlySales is the completed orders placed in one hour of the day
+type HourlySales struct {
+	Hour        int     `json:"hour"` // 0-23
+	OrdersCount int     `json:"orders_count"`
+	Revenue     float64 `json:"revenue"`
+}
+
+// SalesByHour is completed-order count and revenue per hour of the day over a date range
+type SalesByHour struct {
+	Start string        `json:"start"`
+	End   string        `json:"end"`
+	Hours []HourlySales `json:"hours"`
+}
+
+// WeekdaySales is the completed orders dated on one day of the week
+type WeekdaySales struct {
+	Weekday     int     `json:"weekday"` // 0 = Sunday
+	Day         string  `json:"day"`
+	OrdersCount int     `json:"orders_count"`
+	Revenue     float64 `json:"revenue"`
+}
+
+// SalesByWeekday is completed-order count and revenue per day of the week over a date range
+type SalesByWeekday struct {
+	Start string         `json:"start"`
+	End   string         `json:"end"`
+	Days  []WeekdaySales `json:"days"`
+}
+
+// GetSalesByHour returns order count and revenue for each hour of the day (0-23, all
+// present) for completed orders dated from start to end inclusive
+func (s *ReportService) GetSalesByHour(ctx context.Context, start, end time.Time) (*SalesByHour, error) {
+	if end.Before(start) {
+		return nil, apperror.NewBadRequestError("'end' must not be before 'start'")
+	}
+
+	rows, err := s.analyticsRepo.GetSalesByHour(ctx, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SalesByHour{
+		Start: start.Format("2006-01-02"),
+		End:   end.Format("2006-01-02"),
+		Hours: make([]HourlySales, 24),
+	}
+	for hour := range result.Hours {
+		result.Hours[hour].Hour = hour
+	}
+	for _, row := range rows {
+		if row.Bucket >= 0 && row.Bucket < 24 {
+			result.Hours[row.Bucket].OrdersCount = row.OrdersCount
+			result.Hours[row.Bucket].Revenue = row.Revenue
+		}
+	}
+
+	return result, nil
+}
+
+// GetSalesByWeekday returns order count and revenue for each day of the week (Sunday
+// first, all present) for completed orders dated from start to end inclusive
+func (s *ReportService) GetSalesByWeekday(ctx context.Context, start, end time.Time) (*SalesByWeekday, error) {
+	if end.Before(start) {
+		return nil, apperror.NewBadRequestError("'end' must not be before 'start'")
+	}
+
+	rows, err := s.analyticsRepo.GetSalesByWeekday(ctx, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SalesByWeekday{
+		Start: start.Format("2006-01-02"),
+		End:   end.Format("2006-01-02"),
+		Days:  make([]WeekdaySales, 7),
+	}
+	for day := range result.Days {
+		result.Days[day].Weekday = day
+		result.Days[day].Day = time.Weekday(day).String()
+	}
+	for _, row := range rows {
+		if row.Bucket >= 0 && row.Bucket < 7 {
+			result.Days[row.Bucket].OrdersCount = row.OrdersCount
+			result.Days[row.Bucket].Revenue = row.Revenue
+		}
+	}
+
+	return result, nil
+}
+
+// InventoryValuation is the money tied up in stock on hand
+type InventoryValuation struct {
+	TotalQuantity    int64               `json:"total_quantity"`
+	TotalCostValue   float64             `json:"total_cost_value"`
+	TotalRetailValue float64             `json:"total_retail_value"`
+	Categories       []CategoryValuation `json:"categories"`
+}
+
+// CategoryValuation is the stock value of one category
+type CategoryValuation struct {
+	CategoryID   uuid.UUID `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	ProductCount int       `json:"product_count"`
+	Quantity     int64     `json:"quantity"`
+	CostValue    float64   `json:"cost_value"`
+	RetailValue  float64   `json:"retail_value"`
+}
+
+// GetInventoryValuation returns the cost value (quantity * buying price) and retail value
+// (quantity * selling price) of stock on hand, in total and per category
+func (s *ReportService) GetInventoryValuation(ctx context.Context) (*InventoryValuation, error) {
+	rows, err := s.analyticsRepo.GetInventoryValuation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Totals are summed in cents so they don't pick up float drift
+	var costCents, retailCents int64
+	valuation := &InventoryValuation{Categories: make([]CategoryValuation, 0, len(rows))}
+	for _, row := range rows {
+		valuation.TotalQuantity += row.Quantity
+		costCents += money.ToCents(row.CostValue)
+		retailCents += money.ToCents(row.RetailValue)
+		valuation.Categories = append(valuation.Categories, CategoryValuation{
+			CategoryID:   row.CategoryID,
+			CategoryName: row.CategoryName,
+			ProductCount: row.ProductCount,
+			Quantity:     row.Quantity,
+			CostValue:    row.CostValue,
+			RetailValue:  row.RetailValue,
+		})
+	}
+	valuation.TotalCostValue = money.FromCents(costCents)
+	valuation.TotalRetailValue = money.FromCents(retailCents)
+
+	return valuation, nil
+}
+
+// maxSlowMoverDays caps the look-back window of the slow movers report
+const maxSlowMoverDays = 3650
+
+// SlowMover is a product in stock that has sold little or nothing recently
+type SlowMover struct {
+	ProductID    uuid.UUID  `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	ProductCode  string     `json:"product_code"`
+	Quantity     int        `json:"quantity"`
+	QuantitySold int        `json:"quantity_sold"`
+	LastSoldAt   *time.Time `json:"last_sold_at"`
+	StockValue   float64    `json:"stock_value"`
+}
+
+// SlowMovers lists products worth discounting
+type SlowMovers struct {
+	Days       int         `json:"days"`
+	MaxSold    int         `json:"max_sold"`
+	StockValue float64     `json:"stock_value"`
+	Products   []SlowMover `json:"products"`
+}
+
+// GetSlowMovers returns products with stock on hand that sold at most maxSold units in the
+// last `days` days. With maxSold 0 these are the products that did not sell at all.
+func (s *ReportService) GetSlowMovers(ctx context.Context, days, maxSold int) (*SlowMovers, error) {
+	if days < 1 || days > maxSlowMoverDays {
+		return nil, apperror.NewBadRequestError("'days' must be between 1 and 3650")
+	}
+	if maxSold < 0 {
+		return nil, apperror.NewBadRequestError("'max_sold' must not be negative")
+	}
+
+	rows, err := s.analyticsRepo.GetSlowMovers(ctx, days, maxSold)
+	if err != nil {
+		return nil, err
+	}
+
+	var valueCents int64
+	result := &SlowMovers{Days: days, MaxSold: maxSold, Products: make([]SlowMover, 0, len(rows))}
+	for _, row := range rows {
+		valueCents += money.ToCents(row.StockValue)
+		result.Products = append(result.Products, SlowMover{
+			ProductID:    row.ProductID,
+			ProductName:  row.ProductName,
+			ProductCode:  row.ProductCode,
+			Quantity:     row.Quantity,
+			QuantitySold: row.QuantitySold,
+			LastSoldAt:   row.LastSoldAt,
+			StockValue:   row.StockValue,
+		})
+	}
+	result.StockValue = money.FromCents(valueCents)
+
+	return result, nil
+}
+
+// SupplierPerformance is how one supplier (or purchases without one) delivered in the
+// report window
+type SupplierPerformance struct {
+	SupplierID      *uuid.UUID `json:"supplier_id"`
+	SupplierName    string     `json:"supplier_name"`
+	PurchasesCount  int        `json:"purchases_count"`
+	ReceivedCount   int        `json:"received_count"`
+	AvgLeadTimeDays *float64   `json:"avg_lead_time_days"` // null if nothing was received
+	OrderedAmount   float64    `json:"ordered_amount"`
+	TotalSpend      float64    `json:"total_spend"` // Received purchases only
+	FillRate        float64    `json:"fill_rate"`   // Percentage of the ordered amount received
+}
+
+// SupplierPerformanceReport is procurement performance per supplier over a date range
+type SupplierPerformanceReport struct {
+	Start     string                `json:"start"`
+	End       string                `json:"end"`
+	Suppliers []SupplierPerformance `json:"suppliers"`
+}
+
+// GetSupplierPerformance returns average lead time (purchase date to receipt), fill rate
+// and spend per supplier for purchases dated from start to end inclusive
+func (s *ReportService) GetSupplierPerformance(ctx context.Context, start, end time.Time) (*SupplierPerformanceReport, error) {
+	if end.Before(start) {
+		return nil, apperror.NewBadRequestError("'end' must not be before 'start'")
+	}
+
+	rows, err := s.analyticsRepo.GetSupplierPerformance(ctx, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SupplierPerformanceReport{
+		Start:     start.Format("2006-01-02"),
+		End:       end.Format("2006-01-02"),
+		Suppliers: make([]SupplierPerformance, 0, len(rows)),
+	}
+	for _, row := range rows {
+		supplier := SupplierPerformance{
+			SupplierID:     row.SupplierID,
+			SupplierName:   row.SupplierName,
+			PurchasesCount: row.PurchasesCount,
+			ReceivedCount:  row.ReceivedCount,
+			OrderedAmount:  row.OrderedAmount,
+			TotalSpend:     row.ReceivedAmount,
+		}
+		if row.AvgLeadTimeDays != nil {
+			days := math.Round(*row.AvgLeadTimeDays*10) / 10
+			supplier.AvgLeadTimeDays = &days
+		}
+		if ordered := money.ToCents(row.OrderedAmount); ordered > 0 {
+			supplier.FillRate = math.Round(float64(money.ToCents(row.ReceivedAmount))/float64(ordered)*10000) / 100
+		}
+		result.Suppliers = append(result.Suppliers, supplier)
+	}
+
+	return result, nil
+}
+
+// Top products report limits
+const (
+	defaultTopProductsLimit = 10
+	maxTopProductsLimit     = 100
+)
+
+// TopProduct is a product's sales in the report window
+type TopProduct struct {
+	ProductID    uuid.UUID `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	ProductCode  string    `json:"product_code"`
+	QuantitySold int       `json:"quantity_sold"`
+	Revenue      float64   `json:"revenue"`
+}
+
+// TopProducts lists the best selling products by revenue
+type TopProducts struct {
+	Limit     int          `json:"limit"`
+	StartDate *string      `json:"start_date"` // YYYY-MM-DD; nil when unbounded
+	EndDate   *string      `json:"end_date"`   // YYYY-MM-DD, inclusive; nil when unbounded
+	Products  []TopProduct `json:"products"`
+}
+
+// GetTopProducts returns up to limit products ordered by revenue, highest first, from
+// completed orders dated from start to end inclusive. A limit of 0 means the default of
+// 10. Either date may be nil to leave that side of the range open; with both nil all
+// orders count.
+func (s *ReportService) GetTopProducts(ctx context.Context, limit int, start, end *time.Time) (*TopProducts, error) {
+	if limit == 0 {
+		limit = defaultTopProductsLimit
+	}
+	if limit < 1 || limit > maxTopProductsLimit {
+		return nil, apperror.NewBadRequestError("'limit' must be between 1 and 100")
+	}
+	if start != nil && end != nil && end.Before(*start) {
+		return nil, apperror.NewBadRequestError("'end_date' must not be before 'start_date'")
+	}
+
+	result := &TopProducts{Limit: limit}
+	var dr *repository.DateRange
+	if start != nil || end != nil {
+		// The repository range is end-exclusive; an open end runs through today
+		dr = &repository.DateRange{End: time.Now().AddDate(0, 0, 1)}
+		if start != nil {
+			dr.Start = *start
+			startDate := start.Format("2006-01-02")
+			result.StartDate = &startDate
+		}
+		if end != nil {
+			dr.End = end.AddDate(0, 0, 1)
+			endDate := end.Format("2006-01-02")
+			result.EndDate = &endDate
+		}
+	}
+
+	rows, err := s.analyticsRepo.GetTopProducts(ctx, limit, dr)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Products = make([]TopProduct, 0, len(rows))
+	for _, row := range rows {
+		result.Products = append(result.Products, TopProduct{
+			ProductID:    row.ProductID,
+			ProductName:  row.ProductName,
+			ProductCode:  row.ProductCode,
+			QuantitySold: row.QuantitySold,
+			Revenue:      row.Revenue,
+		})
+	}
+
+	return result, nil
+}
+
+// orderExportBatchSize is how many orders an export reads from the database at a time
+const orderExportBatchSize = 500
+
+// OrderExportRow is one order in a streamed orders export
+type OrderExportRow struct {
+	ID            uuid.UUID        `json:"id"`
+	InvoiceNo     string           `json:"invoice_no"`
+	OrderDate     string           `json:"order_date"` // YYYY-MM-DD
+	OrderStatus   enum.OrderStatus `json:"order_status"`
+	CustomerID    *uuid.UUID       `json:"customer_id"`
+	CustomerName  string           `json:"customer_name,omitempty"`
+	PaymentType   string           `json:"payment_type"`
+	TotalProducts int              `json:"total_products"`
+	SubTotal      float64          `json:"sub_total"`
+	VAT           float64          `json:"vat"`
+	Rounding      float64          `json:"rounding"`
+	Total         float64          `json:"total"`
+	Pay           float64          `json:"pay"`
+	Due           float64          `json:"due"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// StreamOrders passes every order matching params to fn in batches, oldest first,
+// without holding more than one batch in memory. An error from fn stops the export.
+func (s *ReportService) StreamOrders(ctx context.Context, params *repository.OrderFilterParams, fn func([]OrderExportRow) error) error {
+	rows := make([]OrderExportRow, 0, orderExportBatchSize)
+	return s.orderRepo.ScanForExport(ctx, uuid.Nil, params, orderExportBatchSize, func(orders []entity.Order) error {
+		rows = rows[:0]
+		for _, o := range orders {
+			row := OrderExportRow{
+				ID:            o.ID,
+				InvoiceNo:     o.InvoiceNo,
+				OrderDate:     o.OrderDate.Format("2006-01-02"),
+				OrderStatus:   o.OrderStatus,
+				CustomerID:    o.CustomerID,
+				PaymentType:   o.PaymentType,
+				TotalProducts: o.TotalProducts,
+				SubTotal:      money.FromCents(o.SubTotal),
+				VAT:           money.FromCents(o.VAT),
+				Rounding:      money.FromCents(o.Rounding),
+				Total:         money.FromCents(o.Total),
+				Pay:           money.FromCents(o.Pay),
+				Due:           money.FromCents(o.Due),
+				CreatedAt:     o.CreatedAt,
+			}
+			if o.Customer != nil {
+				row.CustomerName = o.Customer.Name
+			}
+			rows = append(rows, row)
+		}
+		return fn(rows)
+	})
+}
@@ -2,26 +2,82 @@ package service
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/entity"
 	"github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/pkg/apperror"
+)
+
+// Session timeout bounds in minutes
+const (
+	minSessionTimeout = 5
+	maxSessionTimeout = 1440
 )
 
 // SettingsService handles settings-related business logic
 type SettingsService struct {
 	settingsRepo repository.SettingsRepository
+	tenantRepo   repository.TenantRepository
 }
 
 // NewSettingsService creates a new settings service
-func NewSettingsService(settingsRepo repository.SettingsRepository) *SettingsService {
+func NewSettingsService(settingsRepo repository.SettingsRepository, tenantRepo repository.TenantRepository) *SettingsService {
 	return &SettingsService{
 		settingsRepo: settingsRepo,
+		tenantRepo:   tenantRepo,
+	}
+}
+
+// BusinessSettings is the read-only subset of the tenant's settings that applies to
+// every member. Integration credentials are deliberately left out.
+type BusinessSettings struct {
+	Currency                string  `json:"currency"`
+	Locale                  string  `json:"locale"`
+	Timezone                string  `json:"timezone"`
+	DateFormat              string  `json:"date_format"`
+	TaxRate                 float64 `json:"tax_rate"`
+	TaxLabel                string  `json:"tax_label"`
+	CancellationWindowHours int     `json:"cancellation_window_hours"`
+	CashRounding            float64 `json:"cash_rounding"` // Increment cash totals round to; 0 for none
+	ReceiptFooter           string  `json:"receipt_footer,omitempty"`
+	ReceiptPaperWidth       int     `json:"receipt_paper_width"`
+}
+
+// EffectiveSettings is the user's own settings merged with the business settings of
+// the active tenant. User fields are serialized at the top level.
+type EffectiveSettings struct {
+	*entity.UserSettings
+	Business BusinessSettings `json:"business"`
+}
+
+// defaultUserSettings returns the settings a user starts with
+func defaultUserSettings(userID uuid.UUID) *entity.UserSettings {
+	return &entity.UserSettings{
+		UserID:             userID,
+		Language:           "en",
+		Timezone:           "Africa/Nairobi",
+		Currency:           "KES",
+		DateFormat:         "DD/MM/YYYY",
+		EmailNotifications: true,
+		PushNotifications:  true,
+		OrderAlerts:        true,
+		LowStockAlerts:     true,
+		MarketingEmails:    false,
+		Theme:              "light",
+		CompactMode:        false,
+		ShowAnimations:     true,
+		TwoFactorAuth:      false,
+		SessionTimeout:     "30",
+		LoginAlerts:        true,
 	}
 }
 
-// GetSettings retrieves user settings, creating defaults if not exists
-func (s *SettingsService) GetSettings(ctx context.Context, userID uuid.UUID) (*entity.UserSettings, error) {
+// GetSettings retrieves the effective settings for a user, creating default user
+// settings if none exist yet
+func (s *SettingsService) GetSettings(ctx context.Context, userID, tenantID uuid.UUID) (*EffectiveSettings, error) {
 	settings, err := s.settingsRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -29,82 +85,76 @@ func (s *SettingsService) GetSettings(ctx context.Context, userID uuid.UUID) (*e
 
 	// If no settings exist, create default settings
 	if settings == nil {
-		settings = &entity.UserSettings{
-			UserID:             userID,
-			Language:           "en",
-			Timezone:           "Africa/Nairobi",
-			Currency:           "KES",
-			DateFormat:         "DD/MM/YYYY",
-			EmailNotifications: true,
-			PushNotifications:  true,
-			OrderAlerts:        true,
-			LowStockAlerts:     true,
-			MarketingEmails:    false,
-			Theme:              "light",
-			CompactMode:        false,
-			ShowAnimations:     true,
-			TwoFactorAuth:      false,
-			SessionTimeout:     "30",
-			LoginAlerts:        true,
-		}
+		settings = defaultUserSettings(userID)
 		if err := s.settingsRepo.Create(ctx, settings); err != nil {
 			return nil, err
 		}
 	}
 
-	return settings, nil
+	return s.merge(ctx, settings, tenantID)
 }
 
-// UpdateSettingsInput represents the input for updating settings
+// UpdateSettingsInput represents a partial settings update; nil fields are left unchanged
 type UpdateSettingsInput struct {
 	UserID             uuid.UUID
-	Language           string
-	Timezone           string
-	Currency           string
-	DateFormat         string
-	EmailNotifications bool
-	PushNotifications  bool
-	OrderAlerts        bool
-	LowStockAlerts     bool
-	MarketingEmails    bool
-	Theme              string
-	CompactMode        bool
-	ShowAnimations     bool
-	TwoFactorAuth      bool
-	SessionTimeout     string
-	LoginAlerts        bool
+	TenantID           uuid.UUID
+	Language           *string
+	Timezone           *string
+	Currency           *string
+	DateFormat         *string
+	EmailNotifications *bool
+	PushNotifications  *bool
+	OrderAlerts        *bool
+	LowStockAlerts     *bool
+	MarketingEmails    *bool
+	Theme              *string
+	CompactMode        *bool
+	ShowAnimations     *bool
+	TwoFactorAuth      *bool
+	SessionTimeout     *string
+	LoginAlerts        *bool
 }
 
-// UpdateSettings updates user settings
-func (s *SettingsService) UpdateSettings(ctx context.Context, input *UpdateSettingsInput) (*entity.UserSettings, error) {
+// UpdateSettings applies the provided fields to the user's settings and returns the
+// effective settings
+func (s *SettingsService) UpdateSettings(ctx context.Context, input *UpdateSettingsInput) (*EffectiveSettings, error) {
+	if input.Timezone != nil {
+		if _, err := time.LoadLocation(*input.Timezone); err != nil {
+			return nil, apperror.NewBadRequestError("Unknown timezone: " + *input.Timezone)
+		}
+	}
+	if input.SessionTimeout != nil {
+		minutes, err := strconv.Atoi(*input.SessionTimeout)
+		if err != nil || minutes < minSessionTimeout || minutes > maxSessionTimeout {
+			return nil, apperror.NewBadRequestError("Session timeout must be between 5 and 1440 minutes")
+		}
+	}
+
 	settings, err := s.settingsRepo.GetByUserID(ctx, input.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	// If no settings exist, create new
+	// Start from the defaults so a first partial update doesn't zero the other fields
 	if settings == nil {
-		settings = &entity.UserSettings{
-			UserID: input.UserID,
-		}
+		settings = defaultUserSettings(input.UserID)
 	}
 
-	// Update fields
-	settings.Language = input.Language
-	settings.Timezone = input.Timezone
-	settings.Currency = input.Currency
-	settings.DateFormat = input.DateFormat
-	settings.EmailNotifications = input.EmailNotifications
-	settings.PushNotifications = input.PushNotifications
-	settings.OrderAlerts = input.OrderAlerts
-	settings.LowStockAlerts = input.LowStockAlerts
-	settings.MarketingEmails = input.MarketingEmails
-	settings.Theme = input.Theme
-	settings.CompactMode = input.CompactMode
-	settings.ShowAnimations = input.ShowAnimations
-	settings.TwoFactorAuth = input.TwoFactorAuth
-	settings.SessionTimeout = input.SessionTimeout
-	settings.LoginAlerts = input.LoginAlerts
+	setIfPresent(&settings.Language, input.Language)
+	setIfPresent(&settings.Timezone, input.Timezone)
+	setIfPresent(&settings.Currency, input.Currency)
+	setIfPresent(&settings.DateFormat, input.DateFormat)
+	setIfPresent(&settings.EmailNotifications, input.EmailNotifications)
+	setIfPresent(&settings.PushNotifications, input.PushNotifications)
+	setIfPresent(&settings.OrderAlerts, input.OrderAlerts)
+	setIfPresent(&settings.LowStockAlerts, input.LowStockAlerts)
+	setIfPresent(&settings.MarketingEmails, input.MarketingEmails)
+	setIfPresent(&settings.Theme, input.Theme)
+	setIfPresent(&settings.CompactMode, input.CompactMode)
+	setIfPresent(&settings.ShowAnimations, input.ShowAnimations)
+	setIfPresent(&settings.TwoFactorAuth, input.TwoFactorAuth)
+	setIfPresent(&settings.SessionTimeout, input.SessionTimeout)
+	setIfPresent(&settings.LoginAlerts, input.LoginAlerts)
 
 	if settings.ID == uuid.Nil {
 		if err := s.settingsRepo.Create(ctx, settings); err != nil {
@@ -116,5 +166,68 @@ func (s *SettingsService) UpdateSettings(ctx context.Context, input *UpdateSetti
 		}
 	}
 
-	return settings, nil
+	return s.merge(ctx, settings, input.TenantID)
+}
+
+// merge combines user settings with the active tenant's business settings. Tenant
+// values missing from older tenants fall back to the defaults.
+func (s *SettingsService) merge(ctx context.Context, settings *entity.UserSettings, tenantID uuid.UUID) (*EffectiveSettings, error) {
+	ts := entity.DefaultTenantSettings()
+	if tenantID != uuid.Nil {
+		tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if tenant != nil {
+			ts = withTenantDefaults(tenant.Settings)
+		}
+	}
+
+	return &EffectiveSettings{
+		UserSettings: settings,
+		Business: BusinessSettings{
+			Currency:                ts.Currency,
+			Locale:                  ts.Locale,
+			Timezone:                ts.Timezone,
+			DateFormat:              ts.DateFormat,
+			TaxRate:                 ts.VATPercent(),
+			TaxLabel:                ts.TaxLabel,
+			CancellationWindowHours: ts.CancellationWindowHours,
+			CashRounding:            ts.CashRounding,
+			ReceiptFooter:           ts.ReceiptFooter,
+			ReceiptPaperWidth:       ts.ReceiptPaperWidth,
+		},
+	}, nil
+}
+
+// withTenantDefaults fills empty display settings with the defaults. Numeric settings
+// are kept as stored because zero is a meaningful value for them.
+func withTenantDefaults(ts entity.TenantSettings) entity.TenantSettings {
+	defaults := entity.DefaultTenantSettings()
+	if ts.Currency == "" {
+		ts.Currency = defaults.Currency
+	}
+	if ts.Locale == "" {
+		ts.Locale = defaults.Locale
+	}
+	if ts.Timezone == "" {
+		ts.Timezone = defaults.Timezone
+	}
+	if ts.DateFormat == "" {
+		ts.DateFormat = defaults.DateFormat
+	}
+	if ts.TaxLabel == "" {
+		ts.TaxLabel = defaults.TaxLabel
+	}
+	if ts.ReceiptPaperWidth == 0 {
+		ts.ReceiptPaperWidth = defaults.ReceiptPaperWidth
+	}
+	return ts
+}
+
+// setIfPresent assigns *src to *dst when src is non-nil
+func setIfPresent[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
 }
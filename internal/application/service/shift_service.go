@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
+	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/money"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// ShiftService handles cashier shifts and their Z-reports (end-of-shift reconciliation)
+type ShiftService struct {
+	shiftRepo     repository.ShiftRepository
+	analyticsRepo repository.AnalyticsRepository
+}
+
+// NewShiftService creates a new shift service
+func NewShiftService(shiftRepo repository.ShiftRepository, analyticsRepo repository.AnalyticsRepository) *ShiftService {
+	return &ShiftService{shiftRepo: shiftRepo, analyticsRepo: analyticsRepo}
+}
+
+// ShiftReport is the Z-report of a shift: the orders taken in it, the refunds paid from
+// its drawer and the cash the drawer should hold
+type ShiftReport struct {
+	Shift             *entity.Shift         `json:"shift"`
+	From              time.Time             `json:"from"`
+	To                time.Time             `json:"to"`
+	TransactionsCount int                   `json:"transactions_count"` // Orders taken and not cancelled
+	TotalSales        float64               `json:"total_sales"`
+	VATCollected      float64               `json:"vat_collected"`
+	Outstanding       float64               `json:"outstanding"` // Still due on the shift's orders
+	Payments          []ShiftPaymentSummary `json:"payments"`
+	Refunds           ShiftRefunds          `json:"refunds"`
+	Cash              ShiftCash             `json:"cash"`
+}
+
+// ShiftPaymentSummary is the shift's sales (not cancelled) with one payment type and
+// the money received with it while the shift was open
+type ShiftPaymentSummary struct {
+	PaymentType string  `json:"payment_type"`
+	OrdersCount int     `json:"orders_count"`
+	Sales       float64 `json:"sales"`
+	Received    float64 `json:"received"` // Paid minus change given, including dues paid on earlier orders
+}
+
+// ShiftRefunds is the orders cancelled while the shift was open, refunded from its drawer
+type ShiftRefunds struct {
+	Count    int     `json:"count"`
+	Amount   float64 `json:"amount"`   // Order totals
+	Returned float64 `json:"returned"` // Money that had been paid on them and was handed back
+}
+
+// ShiftCash reconciles the cash drawer
+type ShiftCash struct {
+	OpeningFloat float64  `json:"opening_float"`
+	CashSales    float64  `json:"cash_sales"`    // Cash kept from payments taken in the shift
+	CashRefunds  float64  `json:"cash_refunds"`  // Cash handed back on refunded cash orders
+	ExpectedCash float64  `json:"expected_cash"` // Opening float + cash sales - cash refunds
+	CountedCash  *float64 `json:"counted_cash,omitempty"`
+	Variance     *float64 `json:"variance,omitempty"` // Counted minus expected; negative is a shortage
+	Status       string   `json:"status,omitempty"`   // balanced, short or over, once counted
+}
+
+// setCount fills in the drawer count of a closed shift
+func (c *ShiftCash) setCount(shift *entity.Shift) {
+	variance := shift.Variance()
+	if variance == nil {
+		return
+	}
+	c.CountedCash = centsToDecimalPtr(shift.CountedCash)
+	c.Variance = centsToDecimalPtr(variance)
+	switch {
+	case *variance < 0:
+		c.Status = "short"
+	case *variance > 0:
+		c.Status = "over"
+	default:
+		c.Status = "balanced"
+	}
+}
+
+// OpenShift opens a shift for the user with the cash float put in the drawer
+func (s *ShiftService) OpenShift(ctx context.Context, userID uuid.UUID, openingFloat float64, notes *string) (*entity.Shift, error) {
+	// Extract tenant ID from context
+	tenantID, ok := infraRepo.GetTenantID(ctx)
+	if !ok {
+		return nil, apperror.NewBadRequestError("Tenant context required")
+	}
+
+	if openingFloat < 0 {
+		return nil, apperror.NewBadRequestError("Opening float cannot be negative")
+	}
+
+	open, err := s.shiftRepo.GetOpenByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, apperror.NewConflictError("You already have an open shift; close it first")
+	}
+
+	shift := &entity.Shift{
+		TenantID:     tenantID,
+		UserID:       userID,
+		OpenedAt:     time.Now(),
+		OpeningFloat: money.ToCents(openingFloat),
+		OpeningNotes: notes,
+	}
+	if err := s.shiftRepo.Create(ctx, shift); err != nil {
+		return nil, err
+	}
+
+	return s.shiftRepo.GetByID(ctx, shift.ID)
+}
+
+// CurrentShift returns the user's open shift
+func (s *ShiftService) CurrentShift(ctx context.Context, userID uuid.UUID) (*entity.Shift, error) {
+	shift, err := s.shiftRepo.GetOpenByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if shift == nil {
+		return nil, apperror.NewNotFoundError("Open shift")
+	}
+	return shift, nil
+}
+
+// GetShift retrieves a shift; cashiers without viewAll may only see their own
+func (s *ShiftService) GetShift(ctx context.Context, id, userID uuid.UUID, viewAll bool) (*entity.Shift, error) {
+	shift, err := s.shiftRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if shift == nil {
+		return nil, apperror.NewNotFoundError("Shift")
+	}
+	if !viewAll && shift.UserID != userID {
+		return nil, apperror.ErrForbidden
+	}
+	return shift, nil
+}
+
+// ListShifts lists shifts newest first, only userID's if it is set
+func (s *ShiftService) ListShifts(ctx context.Context, params *pagination.PaginationParams, userID *uuid.UUID) (*pagination.PaginatedResult[entity.Shift], error) {
+	shifts, total, err := s.shiftRepo.List(ctx, params, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pag := pagination.NewPagination(params.Page, params.PerPage, total)
+	return pagination.NewPaginatedResult(shifts, pag), nil
+}
+
+// CloseShiftInput represents closing a shift after counting the drawer
+type CloseShiftInput struct {
+	UserID      uuid.UUID
+	ID          uuid.UUID // uuid.Nil closes the user's open shift
+	CloseAll    bool      // May close other cashiers' shifts
+	CountedCash float64
+	Notes       *string
+}
+
+// CloseShift closes a shift with the cash counted in the drawer and returns its final
+// Z-report, whose expected cash is stored on the shift
+func (s *ShiftService) CloseShift(ctx context.Context, input *CloseShiftInput) (*ShiftReport, error) {
+	var shift *entity.Shift
+	var err error
+	if input.ID == uuid.Nil {
+		shift, err = s.CurrentShift(ctx, input.UserID)
+	} else {
+		shift, err = s.GetShift(ctx, input.ID, input.UserID, input.CloseAll)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !shift.IsOpen() {
+		return nil, apperror.NewBadRequestError("Shift is already closed")
+	}
+	if input.CountedCash < 0 {
+		return nil, apperror.NewBadRequestError("Counted cash cannot be negative")
+	}
+
+	now := time.Now()
+	report, err := s.report(ctx, shift, now)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := money.ToCents(report.Cash.ExpectedCash)
+	counted := money.ToCents(input.CountedCash)
+	shift.ClosedAt = &now
+	shift.ExpectedCash = &expected
+	shift.CountedCash = &counted
+	shift.ClosingNotes = input.Notes
+
+	closed, err := s.shiftRepo.Close(ctx, shift)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, apperror.NewConflictError("Shift was closed in the meantime")
+	}
+
+	report.Cash.setCount(shift)
+	return report, nil
+}
+
+// GetShiftReport returns the Z-report of a shift, up to now if it is still open
+func (s *ShiftService) GetShiftReport(ctx context.Context, id, userID uuid.UUID, viewAll bool) (*ShiftReport, error) {
+	shift, err := s.GetShift(ctx, id, userID, viewAll)
+	if err != nil {
+		return nil, err
+	}
+
+	to := time.Now()
+	if shift.ClosedAt != nil {
+		to = *shift.ClosedAt
+	}
+	report, err := s.report(ctx, shift, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Cash.setCount(shift)
+	return report, nil
+}
+
+// report totals the orders taken in the shift, the payments received and the refunds
+// paid from its drawer. Payments count towards the shift open when they were taken,
+// including dues paid on orders of earlier shifts, and cash taken on orders cancelled
+// later still counts as cash sales; the refund is taken off the expected cash of the
+// shift that paid it. A closed shift keeps the expected cash worked out at close.
+func (s *ShiftService) report(ctx context.Context, shift *entity.Shift, to time.Time) (*ShiftReport, error) {
+	sales, err := s.analyticsRepo.GetShiftSales(ctx, shift.ID)
+	if err != nil {
+		return nil, err
+	}
+	received, err := s.analyticsRepo.GetShiftPayments(ctx, shift.ID)
+	if err != nil {
+		return nil, err
+	}
+	refunds, err := s.analyticsRepo.GetShiftRefunds(ctx, shift.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var total, vat, due, cashSales, cashRefunds int64
+	payments := make(map[string]*ShiftPaymentSummary)
+	summary := func(paymentType string) *ShiftPaymentSummary {
+		if paymentType == "" {
+			paymentType = "unspecified"
+		}
+		payment, ok := payments[paymentType]
+		if !ok {
+			payment = &ShiftPaymentSummary{PaymentType: paymentType}
+			payments[paymentType] = payment
+		}
+		return payment
+	}
+
+	report := &ShiftReport{Shift: shift, From: shift.OpenedAt, To: to}
+	for _, row := range sales {
+		if row.Cancelled {
+			continue
+		}
+
+		report.TransactionsCount += row.OrdersCount
+		total += row.Total
+		vat += row.VAT
+		due += row.Due
+
+		payment := summary(row.PaymentType)
+		payment.OrdersCount += row.OrdersCount
+		payment.Sales += money.FromCents(row.Total)
+	}
+
+	for _, row := range received {
+		if row.PaymentType == "cash" {
+			cashSales += row.Amount
+		}
+		summary(row.PaymentType).Received += money.FromCents(row.Amount)
+	}
+
+	var refunded, returned int64
+	for _, row := range refunds {
+		report.Refunds.Count += row.OrdersCount
+		refunded += row.Total
+		returned += row.Received
+		if row.PaymentType == "cash" {
+			cashRefunds += row.Received
+		}
+	}
+
+	report.TotalSales = money.FromCents(total)
+	report.VATCollected = money.FromCents(vat)
+	report.Outstanding = money.FromCents(due)
+	report.Refunds.Amount = money.FromCents(refunded)
+	report.Refunds.Returned = money.FromCents(returned)
+	expected := shift.OpeningFloat + cashSales - cashRefunds
+	if shift.ExpectedCash != nil {
+		expected = *shift.ExpectedCash
+	}
+	report.Cash = ShiftCash{
+		OpeningFloat: money.FromCents(shift.OpeningFloat),
+		CashSales:    money.FromCents(cashSales),
+		CashRefunds:  money.FromCents(cashRefunds),
+		ExpectedCash: money.FromCents(expected),
+	}
+
+	report.Payments = make([]ShiftPaymentSummary, 0, len(payments))
+	for _, payment := range payments {
+		report.Payments = append(report.Payments, *payment)
+	}
+	sort.Slice(report.Payments, func(i, j int) bool {
+		return report.Payments[i].PaymentType < report.Payments[j].PaymentType
+	})
+
+	return report, nil
+}
+
+// centsToDecimalPtr converts an optional amount in cents to currency units
+func centsToDecimalPtr(cents *int64) *float64 {
+	if cents == nil {
+		return nil
+	}
+	value := money.FromCents(*cents)
+	return &value
+}
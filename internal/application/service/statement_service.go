@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/money"
+	"github.com/sangkips/investify-api/pkg/pdf"
+)
+
+// StatementService builds customer account statements.
+type StatementService struct {
+	customerRepo repository.CustomerRepository
+	orderRepo    repository.OrderRepository
+	paymentRepo  repository.OrderPaymentRepository
+	tenantRepo   repository.TenantRepository
+}
+
+// NewStatementService creates a new statement service.
+func NewStatementService(
+	customerRepo repository.CustomerRepository,
+	orderRepo repository.OrderRepository,
+	paymentRepo repository.OrderPaymentRepository,
+	tenantRepo repository.TenantRepository,
+) *StatementService {
+	return &StatementService{
+		customerRepo: customerRepo,
+		orderRepo:    orderRepo,
+		paymentRepo:  paymentRepo,
+		tenantRepo:   tenantRepo,
+	}
+}
+
+// Statement line types
+const (
+	StatementLineOrder   = "order"   // An order of the period, with what was paid on it by the end
+	StatementLinePayment = "payment" // A payment in the period on an order from before it
+)
+
+// StatementLine is one entry on a statement. Amounts are in cents; Balance is the
+// running outstanding balance after this entry.
+type StatementLine struct {
+	Type      string    `json:"type"`
+	Date      time.Time `json:"date"`
+	InvoiceNo string    `json:"invoice_no"`
+	Total     int64     `json:"total"`
+	Paid      int64     `json:"paid"`
+	Due       int64     `json:"due"`
+	Balance   int64     `json:"balance"`
+}
+
+// AgingBuckets splits the outstanding balance by how old the orders are, in cents.
+type AgingBuckets struct {
+	Days0To30  int64 `json:"days_0_30"`
+	Days31To60 int64 `json:"days_31_60"`
+	Over60     int64 `json:"over_60"`
+}
+
+// CustomerStatement is the account statement of a customer for a period.
+type CustomerStatement struct {
+	Header         entity.ReceiptHeader `json:"header"`
+	Customer       *entity.Customer     `json:"customer"`
+	Currency       string               `json:"currency"`
+	Locale         string               `json:"locale"`
+	From           *time.Time           `json:"from,omitempty"` // nil means since the first order
+	To             time.Time            `json:"to"`
+	OpeningBalance int64                `json:"opening_balance"`
+	Lines          []StatementLine      `json:"lines"`
+	ClosingBalance int64                `json:"closing_balance"`
+	Aging          AgingBuckets         `json:"aging"`
+}
+
+// CustomerStatement gathers the customer's orders up to `to` and the payments made on
+// them by the end of that day, and computes the opening balance (what was owed on
+// orders before `from` when the period started), the period lines, the closing balance
+// and the aging of everything still outstanding at `to`. Balances are as of those
+// dates, not as of today. Cancelled orders are ignored.
+func (s *StatementService) CustomerStatement(ctx context.Context, customerID uuid.UUID, from *time.Time, to time.Time) (*CustomerStatement, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, apperror.NewNotFoundError("Customer")
+	}
+	if from != nil && from.After(to) {
+		return nil, apperror.NewBadRequestError("'from' must not be after 'to'")
+	}
+
+	st := &CustomerStatement{
+		Header:   entity.ReceiptHeader{StoreName: "Investify Store"},
+		Customer: customer,
+		Currency: money.DefaultCurrency,
+		From:     from,
+		To:       to,
+		Lines:    []StatementLine{},
+	}
+
+	tenant, err := s.tenantRepo.GetByID(ctx, customer.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant != nil {
+		if tenant.Name != "" {
+			st.Header.StoreName = tenant.Name
+		}
+		st.Header.TaxID = tenant.Settings.KRAPin
+		if tenant.Settings.Currency != "" {
+			st.Currency = tenant.Settings.Currency
+		}
+		st.Locale = tenant.Settings.Locale
+	}
+
+	orders, err := s.orderRepo.ListByCustomer(ctx, customerID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	orderIDs := make([]uuid.UUID, len(orders))
+	for i, o := range orders {
+		orderIDs[i] = o.ID
+	}
+	payments, err := s.paymentRepo.ListByOrders(ctx, orderIDs, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	// What was paid on each order by the end of the period, and before it started
+	paid := make(map[uuid.UUID]int64)
+	paidBefore := make(map[uuid.UUID]int64)
+	for _, p := range payments {
+		paid[p.OrderID] += p.Amount
+		if from != nil && p.PaidAt.Before(*from) {
+			paidBefore[p.OrderID] += p.Amount
+		}
+	}
+
+	earlier := make(map[uuid.UUID]string) // Orders from before the period, by invoice number
+	for _, o := range orders {
+		if from != nil && o.OrderDate.Before(*from) {
+			earlier[o.ID] = o.InvoiceNo
+			st.OpeningBalance += o.Total - paidBefore[o.ID]
+			continue
+		}
+		st.Lines = append(st.Lines, StatementLine{
+			Type:      StatementLineOrder,
+			Date:      o.OrderDate,
+			InvoiceNo: o.InvoiceNo,
+			Total:     o.Total,
+			Paid:      paid[o.ID],
+			Due:       o.Total - paid[o.ID],
+		})
+	}
+
+	// Payments in the period on earlier orders reduce the balance carried in
+	for _, p := range payments {
+		invoiceNo, ok := earlier[p.OrderID]
+		if !ok || p.PaidAt.Before(*from) {
+			continue
+		}
+		st.Lines = append(st.Lines, StatementLine{
+			Type:      StatementLinePayment,
+			Date:      p.PaidAt,
+			InvoiceNo: invoiceNo,
+			Paid:      p.Amount,
+		})
+	}
+	sort.SliceStable(st.Lines, func(i, j int) bool {
+		return st.Lines[i].Date.Before(st.Lines[j].Date)
+	})
+
+	balance := st.OpeningBalance
+	for i := range st.Lines {
+		line := &st.Lines[i]
+		if line.Type == StatementLineOrder {
+			balance += line.Due
+		} else {
+			balance -= line.Paid
+		}
+		line.Balance = balance
+	}
+	st.ClosingBalance = balance
+
+	for _, o := range orders {
+		due := o.Total - paid[o.ID]
+		if due <= 0 {
+			continue
+		}
+		switch age := int(to.Sub(o.OrderDate).Hours() / 24); {
+		case age <= 30:
+			st.Aging.Days0To30 += due
+		case age <= 60:
+			st.Aging.Days31To60 += due
+		default:
+			st.Aging.Over60 += due
+		}
+	}
+
+	return st, nil
+}
+
+// statementDate is the date layout used on printed statements
+const statementDate = "02 Jan 2006"
+
+// RenderStatementPDF lays out a customer statement as an A4 PDF.
+func RenderStatementPDF(st *CustomerStatement) []byte {
+	doc := pdf.NewDocument()
+	f := money.NewFormatter(st.Currency, st.Locale)
+	right := pdf.PageWidth - pdf.Margin
+
+	// Store header and statement title
+	y := pdf.Margin + 16
+	doc.SetFont(true, 16).Text(pdf.Margin, y, pdf.AlignLeft, st.Header.StoreName)
+	doc.Text(right, y, pdf.AlignRight, "STATEMENT")
+	y += 16
+	doc.SetFont(false, 10)
+	if st.Header.TaxID != "" {
+		doc.Text(pdf.Margin, y, pdf.AlignLeft, "Tax ID: "+st.Header.TaxID)
+	}
+	period := "Up to " + st.To.Format(statementDate)
+	if st.From != nil {
+		period = st.From.Format(statementDate) + " - " + st.To.Format(statementDate)
+	}
+	doc.Text(right, y, pdf.AlignRight, period)
+	y += 14
+	doc.Text(right, y, pdf.AlignRight, "Amounts in "+f.Currency)
+
+	// Customer block
+	y += 24
+	doc.SetFont(true, 10).Text(pdf.Margin, y, pdf.AlignLeft, "Statement for")
+	doc.SetFont(false, 10)
+	y += 14
+	doc.Text(pdf.Margin, y, pdf.AlignLeft, st.Customer.Name)
+	for _, line := range []*string{st.Customer.Email, st.Customer.Phone, st.Customer.Address} {
+		if line != nil && *line != "" {
+			y += 14
+			doc.Text(pdf.Margin, y, pdf.AlignLeft, *line)
+		}
+	}
+
+	// Order lines; column positions are right edges except for the first two
+	colInvoice := pdf.Margin + 80
+	amountCols := []float64{right - 300, right - 200, right - 100, right}
+	tableHeader := func() {
+		doc.SetFont(true, 10)
+		doc.Text(pdf.Margin, y, pdf.AlignLeft, "Date")
+		doc.Text(colInvoice, y, pdf.AlignLeft, "Invoice")
+		for i, title := range []string{"Total", "Paid", "Due", "Balance"} {
+			doc.Text(amountCols[i], y, pdf.AlignRight, title)
+		}
+		doc.Line(pdf.Margin, y+4, right, y+4)
+		doc.SetFont(false, 10)
+		y += 18
+	}
+
+	y += 30
+	tableHeader()
+	doc.Text(colInvoice, y, pdf.AlignLeft, "Opening balance")
+	doc.Text(amountCols[3], y, pdf.AlignRight, f.Number(st.OpeningBalance))
+	y += 16
+
+	for _, line := range st.Lines {
+		if y > pdf.PageHeight-pdf.Margin-20 {
+			doc.AddPage()
+			y = pdf.Margin + 12
+			tableHeader()
+		}
+		doc.Text(pdf.Margin, y, pdf.AlignLeft, line.Date.Format(statementDate))
+		if line.Type == StatementLinePayment {
+			doc.Text(colInvoice, y, pdf.AlignLeft, "Payment on "+line.InvoiceNo)
+			doc.Text(amountCols[1], y, pdf.AlignRight, f.Number(line.Paid))
+			doc.Text(amountCols[3], y, pdf.AlignRight, f.Number(line.Balance))
+		} else {
+			doc.Text(colInvoice, y, pdf.AlignLeft, line.InvoiceNo)
+			for i, v := range []int64{line.Total, line.Paid, line.Due, line.Balance} {
+				doc.Text(amountCols[i], y, pdf.AlignRight, f.Number(v))
+			}
+		}
+		y += 16
+	}
+
+	// Closing balance and aging summary need about 110pt
+	if y > pdf.PageHeight-pdf.Margin-110 {
+		doc.AddPage()
+		y = pdf.Margin
+	}
+	doc.Line(pdf.Margin, y-10, right, y-10)
+	y += 4
+	doc.SetFont(true, 11)
+	doc.Text(colInvoice, y, pdf.AlignLeft, "Closing balance")
+	doc.Text(amountCols[3], y, pdf.AlignRight, f.FormatCode(st.ClosingBalance))
+
+	y += 36
+	doc.Text(pdf.Margin, y, pdf.AlignLeft, "Aging of outstanding balance")
+	y += 18
+	doc.SetFont(true, 10)
+	for i, title := range []string{"0-30 days", "31-60 days", "Over 60 days", "Total due"} {
+		doc.Text(amountCols[i], y, pdf.AlignRight, title)
+	}
+	doc.Line(pdf.Margin, y+4, right, y+4)
+	y += 18
+	doc.SetFont(false, 10)
+	aging := st.Aging
+	totalDue := aging.Days0To30 + aging.Days31To60 + aging.Over60
+	for i, v := range []int64{aging.Days0To30, aging.Days31To60, aging.Over60, totalDue} {
+		doc.Text(amountCols[i], y, pdf.AlignRight, f.Number(v))
+	}
+
+	return doc.Bytes()
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/pkg/apperror"
+)
+
+// statusTransitions maps each status to the statuses it may move to. A status
+// missing from the table is final.
+type statusTransitions[S comparable] map[S][]S
+
+// orderTransitions lists the legal order status moves. Completed orders can still be
+// cancelled (refunded), but nothing goes back to pending.
+var orderTransitions = statusTransitions[enum.OrderStatus]{
+	enum.OrderStatusPending:  {enum.OrderStatusComplete, enum.OrderStatusCancel},
+	enum.OrderStatusComplete: {enum.OrderStatusCancel},
+}
+
+// purchaseTransitions lists the legal purchase status moves
+var purchaseTransitions = statusTransitions[enum.PurchaseStatus]{
+	enum.PurchaseStatusPending: {enum.PurchaseStatusApproved},
+}
+
+// quotationTransitions lists the legal quotation status moves
+var quotationTransitions = statusTransitions[enum.QuotationStatus]{
+	enum.QuotationStatusPending: {enum.QuotationStatusSent, enum.QuotationStatusCanceled},
+	enum.QuotationStatusSent:    {enum.QuotationStatusCanceled},
+}
+
+// statusValue is a status enum that can check its own value
+type statusValue interface {
+	comparable
+	fmt.Stringer
+	IsValid() bool
+}
+
+// checkTransition returns a bad request error unless moving a record of the given
+// kind (e.g. "Order") from one status to another is allowed by the table.
+func checkTransition[S statusValue](kind string, table statusTransitions[S], from, to S) error {
+	if !to.IsValid() {
+		return apperror.NewBadRequestError(fmt.Sprintf("Invalid %s status", kind))
+	}
+	if from == to {
+		return apperror.NewBadRequestError(fmt.Sprintf("%s is already %s", kind, to))
+	}
+	if !slices.Contains(table[from], to) {
+		return apperror.NewBadRequestError(fmt.Sprintf("%s cannot move from %s to %s", kind, from, to))
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 
 	"crypto/rand"
 	"encoding/hex"
+	"regexp"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/entity"
@@ -164,6 +166,9 @@ func (s *TenantService) UpdateTenant(ctx context.Context, input *UpdateTenantInp
 		tenant.Name = input.Name
 	}
 	if input.Settings != nil {
+		if err := validateTenantSettings(input.Settings); err != nil {
+			return nil, err
+		}
 		tenant.Settings = *input.Settings
 	}
 
@@ -174,6 +179,55 @@ func (s *TenantService) UpdateTenant(ctx context.Context, input *UpdateTenantInp
 	return tenant, nil
 }
 
+// validateTenantSettings checks that the business settings are within their allowed ranges
+func validateTenantSettings(ts *entity.TenantSettings) error {
+	if ts.TaxRate != nil && (*ts.TaxRate < 0 || *ts.TaxRate > 100) {
+		return apperror.NewBadRequestError("Tax rate must be between 0 and 100")
+	}
+	if ts.Currency != "" && len(ts.Currency) != 3 {
+		return apperror.NewBadRequestError("Currency must be a 3-letter ISO 4217 code")
+	}
+	if ts.Timezone != "" {
+		if _, err := time.LoadLocation(ts.Timezone); err != nil {
+			return apperror.NewBadRequestError("Unknown timezone: " + ts.Timezone)
+		}
+	}
+	if ts.CancellationWindowHours < 0 || ts.CancellationWindowHours > 24*365 {
+		return apperror.NewBadRequestError("Cancellation window must be between 0 and 8760 hours")
+	}
+	if ts.CashRounding < 0 || ts.CashRounding > 100 {
+		return apperror.NewBadRequestError("Cash rounding must be between 0 and 100")
+	}
+	if ts.LoyaltyEarnAmount < 0 || ts.LoyaltyPointValue < 0 {
+		return apperror.NewBadRequestError("Loyalty earn amount and point value cannot be negative")
+	}
+	if len(ts.ReceiptFooter) > 200 {
+		return apperror.NewBadRequestError("Receipt footer must be at most 200 characters")
+	}
+	if ts.ReceiptPaperWidth != 0 && ts.ReceiptPaperWidth != 58 && ts.ReceiptPaperWidth != 80 {
+		return apperror.NewBadRequestError("Receipt paper width must be 58 or 80")
+	}
+	prefixes := []struct{ name, value string }{
+		{"Invoice", ts.InvoicePrefix},
+		{"Purchase", ts.PurchasePrefix},
+		{"Quotation", ts.QuotationPrefix},
+	}
+	for _, p := range prefixes {
+		if p.value != "" && !documentPrefixPattern.MatchString(p.value) {
+			return apperror.NewBadRequestError(p.name + " prefix must be at most 10 letters, digits or hyphens")
+		}
+	}
+	for name := range ts.FeatureFlags {
+		if !entity.IsKnownFeature(name) {
+			return apperror.NewBadRequestError("Unknown feature flag: " + name)
+		}
+	}
+	return nil
+}
+
+// documentPrefixPattern matches the allowed invoice, purchase and quotation prefixes
+var documentPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,10}$`)
+
 // InviteMemberInput represents input for inviting a user to a tenant
 type InviteMemberInput struct {
 	TenantID uuid.UUID
@@ -203,9 +257,9 @@ func (s *TenantService) RemoveMember(ctx context.Context, tenantID, userID uuid.
 	return s.tenantRepo.RemoveMember(ctx, tenantID, userID)
 }
 
-// GetTenantMembers retrieves all members of a tenant
-func (s *TenantService) GetTenantMembers(ctx context.Context, tenantID uuid.UUID) ([]entity.TenantMembership, error) {
-	members, err := s.tenantRepo.GetMembers(ctx, tenantID)
+// GetTenantMembers retrieves a page of a tenant's members, optionally filtered by name or email
+func (s *TenantService) GetTenantMembers(ctx context.Context, tenantID uuid.UUID, params *pagination.PaginationParams, search string) (*pagination.PaginatedResult[entity.TenantMembership], error) {
+	members, total, err := s.tenantRepo.GetMembers(ctx, tenantID, params, search)
 	if err != nil {
 		return nil, err
 	}
@@ -215,7 +269,8 @@ func (s *TenantService) GetTenantMembers(ctx context.Context, tenantID uuid.UUID
 		members[i].PopulateUserDetails()
 	}
 
-	return members, nil
+	pag := pagination.NewPagination(params.Page, params.PerPage, total)
+	return pagination.NewPaginatedResult(members, pag), nil
 }
 
 // UpdateMemberRole updates a member's role in a tenant
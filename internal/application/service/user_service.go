@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/entity"
 	"github.com/sangkips/investify-api/internal/domain/repository"
 	"github.com/sangkips/investify-api/pkg/apperror"
 	"github.com/sangkips/investify-api/pkg/pagination"
+	"github.com/sangkips/investify-api/pkg/utils"
 )
 
 // UserService handles user management operations
@@ -15,6 +18,8 @@ type UserService struct {
 	userRepo       repository.UserRepository
 	roleRepo       repository.RoleRepository
 	permissionRepo repository.PermissionRepository
+	tenantRepo     repository.TenantRepository
+	jwtManager     *utils.JWTManager
 }
 
 // NewUserService creates a new user service
@@ -22,11 +27,15 @@ func NewUserService(
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
 	permissionRepo repository.PermissionRepository,
+	tenantRepo repository.TenantRepository,
+	jwtManager *utils.JWTManager,
 ) *UserService {
 	return &UserService{
 		userRepo:       userRepo,
 		roleRepo:       roleRepo,
 		permissionRepo: permissionRepo,
+		tenantRepo:     tenantRepo,
+		jwtManager:     jwtManager,
 	}
 }
 
@@ -85,28 +94,50 @@ func (s *UserService) GetUser(ctx context.Context, userID uuid.UUID) (*entity.Us
 	return user, nil
 }
 
+// privilegedRoles can only be granted or revoked by a super-admin
+var privilegedRoles = map[string]bool{
+	"super-admin": true,
+	"admin":       true,
+}
+
 // UpdateUserRolesInput represents the input for updating user roles
 type UpdateUserRolesInput struct {
-	UserID  uuid.UUID
-	RoleIDs []uint
+	ActorID  uuid.UUID
+	TenantID uuid.UUID // The actor's tenant; the user must be a member unless the actor is a super-admin
+	UserID   uuid.UUID
+	RoleIDs  []uint
 }
 
-// UpdateUserRoles updates the roles assigned to a user
+// UpdateUserRoles updates the roles assigned to a user. Apart from super-admins, the
+// actor can only manage members of their own tenant, cannot grant or revoke the
+// admin and super-admin roles, and cannot grant a role carrying a permission they
+// don't hold themselves.
 func (s *UserService) UpdateUserRoles(ctx context.Context, input *UpdateUserRolesInput) (*entity.User, error) {
-	// Check if user exists
-	user, err := s.userRepo.GetByID(ctx, input.UserID)
+	// The actor's roles are loaded rather than taken from the token, which may predate
+	// a change to them
+	actor, err := s.userRepo.GetWithRoles(ctx, input.ActorID)
 	if err != nil {
 		return nil, err
 	}
-	if user == nil {
-		return nil, apperror.ErrNotFound
+	if actor == nil {
+		return nil, apperror.ErrForbidden
 	}
+	actorIsSuperAdmin := actor.HasRole("super-admin")
 
-	// Get current roles
+	// Get the user with their current roles
 	userWithRoles, err := s.userRepo.GetWithRoles(ctx, input.UserID)
 	if err != nil {
 		return nil, err
 	}
+	if userWithRoles == nil {
+		return nil, apperror.ErrNotFound
+	}
+
+	if !actorIsSuperAdmin {
+		if err := s.requireMember(ctx, input.TenantID, input.UserID, "You can only change the roles of members of your own organization"); err != nil {
+			return nil, err
+		}
+	}
 
 	// Create a map of desired role IDs
 	desiredRoles := make(map[uint]bool)
@@ -120,6 +151,40 @@ func (s *UserService) UpdateUserRoles(ctx context.Context, input *UpdateUserRole
 		currentRoles[role.ID] = true
 	}
 
+	// Check every change before making any, so a rejected request changes nothing
+	newRoles := make([]*entity.Role, 0, len(desiredRoles))
+	if !actorIsSuperAdmin {
+		for _, role := range userWithRoles.Roles {
+			if !desiredRoles[role.ID] && privilegedRoles[role.Name] {
+				return nil, apperror.NewAppError(http.StatusForbidden, fmt.Sprintf("Only a super-admin can remove the %s role", role.Name))
+			}
+		}
+	}
+	for roleID := range desiredRoles {
+		if currentRoles[roleID] {
+			continue
+		}
+		role, err := s.roleRepo.GetWithPermissions(ctx, roleID)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			continue // Skip non-existent roles
+		}
+		if !actorIsSuperAdmin {
+			if privilegedRoles[role.Name] {
+				return nil, apperror.NewAppError(http.StatusForbidden, fmt.Sprintf("Only a super-admin can grant the %s role", role.Name))
+			}
+			for _, permission := range role.Permissions {
+				if !actor.HasPermission(permission.Name) {
+					return nil, apperror.NewAppError(http.StatusForbidden,
+						fmt.Sprintf("You cannot grant the %s role because it includes the %s permission, which you don't have", role.Name, permission.Name))
+				}
+			}
+		}
+		newRoles = append(newRoles, role)
+	}
+
 	// Remove roles that are no longer desired
 	for _, role := range userWithRoles.Roles {
 		if !desiredRoles[role.ID] {
@@ -130,19 +195,9 @@ func (s *UserService) UpdateUserRoles(ctx context.Context, input *UpdateUserRole
 	}
 
 	// Add new roles
-	for roleID := range desiredRoles {
-		if !currentRoles[roleID] {
-			// Verify the role exists
-			role, err := s.roleRepo.GetByID(ctx, roleID)
-			if err != nil {
-				return nil, err
-			}
-			if role == nil {
-				continue // Skip non-existent roles
-			}
-			if err := s.userRepo.AssignRole(ctx, input.UserID, roleID); err != nil {
-				return nil, err
-			}
+	for _, role := range newRoles {
+		if err := s.userRepo.AssignRole(ctx, input.UserID, role.ID); err != nil {
+			return nil, err
 		}
 	}
 
@@ -163,6 +218,87 @@ func (s *UserService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	return s.userRepo.Delete(ctx, userID)
 }
 
+// requireMember returns a forbidden error with the given message unless the user is a
+// member of the tenant
+func (s *UserService) requireMember(ctx context.Context, tenantID, userID uuid.UUID, message string) error {
+	if tenantID != uuid.Nil {
+		isMember, err := s.tenantRepo.IsMember(ctx, tenantID, userID)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			return nil
+		}
+	}
+	return apperror.NewAppError(http.StatusForbidden, message)
+}
+
+// SetUserStatusInput represents the input for deactivating or reactivating a user
+type SetUserStatusInput struct {
+	ActorID           uuid.UUID
+	ActorIsSuperAdmin bool
+	TenantID          uuid.UUID
+	UserID            uuid.UUID
+	IsActive          bool
+}
+
+// privilegedMemberships are the tenant membership roles whose holders only a super-admin
+// can deactivate
+var privilegedMemberships = map[string]bool{
+	"owner": true,
+	"admin": true,
+}
+
+// SetUserStatus deactivates or reactivates a user. Deactivated users keep their
+// records but cannot sign in, and their existing tokens are revoked. The status is
+// global: a deactivated user is locked out of every tenant they belong to, not just
+// the actor's. For that reason only a super-admin can change the status of a user
+// holding the admin or super-admin role, or of a tenant's owner or admin.
+func (s *UserService) SetUserStatus(ctx context.Context, input *SetUserStatusInput) (*entity.User, error) {
+	if input.UserID == input.ActorID {
+		return nil, apperror.NewBadRequestError("Cannot change the status of your own account")
+	}
+
+	user, err := s.userRepo.GetWithRoles(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, apperror.ErrNotFound
+	}
+	if !input.ActorIsSuperAdmin {
+		if err := s.requireMember(ctx, input.TenantID, input.UserID, "You can only change the status of members of your own organization"); err != nil {
+			return nil, err
+		}
+		for _, role := range user.Roles {
+			if privilegedRoles[role.Name] {
+				return nil, apperror.NewAppError(http.StatusForbidden, fmt.Sprintf("Only a super-admin can change the status of a user with the %s role", role.Name))
+			}
+		}
+		membership, err := s.tenantRepo.GetMembership(ctx, input.TenantID, input.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if membership != nil && privilegedMemberships[membership.Role] {
+			return nil, apperror.NewAppError(http.StatusForbidden, fmt.Sprintf("Only a super-admin can change the status of an organization %s", membership.Role))
+		}
+	}
+
+	if user.IsActive == input.IsActive {
+		return user, nil
+	}
+
+	user.IsActive = input.IsActive
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		s.jwtManager.RevokeUserTokens(user.ID)
+	}
+	return user, nil
+}
+
 // ListRoles returns all available roles
 func (s *UserService) ListRoles(ctx context.Context) ([]entity.Role, error) {
 	return s.roleRepo.List(ctx)
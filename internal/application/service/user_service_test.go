@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/utils"
+)
+
+func TestSetUserStatusProtectsPrivilegedUsers(t *testing.T) {
+	tenantID := uuid.New()
+	tests := []struct {
+		name           string
+		roles          []entity.Role
+		membershipRole string
+		superAdmin     bool
+		wantErr        bool
+	}{
+		{name: "member", membershipRole: "member"},
+		{name: "admin role", roles: []entity.Role{{Name: "admin"}}, membershipRole: "member", wantErr: true},
+		{name: "super-admin role", roles: []entity.Role{{Name: "super-admin"}}, membershipRole: "member", wantErr: true},
+		{name: "tenant owner", membershipRole: "owner", wantErr: true},
+		{name: "tenant admin", membershipRole: "admin", wantErr: true},
+		{name: "tenant owner by a super-admin", membershipRole: "owner", superAdmin: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entity.User{ID: uuid.New(), IsActive: true, Roles: tt.roles}
+			userRepo := &fakeUserRepo{users: map[uuid.UUID]*entity.User{user.ID: user}}
+			tenantRepo := fakeTenantRepo{memberships: map[uuid.UUID]*entity.TenantMembership{
+				user.ID: {TenantID: tenantID, UserID: user.ID, Role: tt.membershipRole},
+			}}
+			s := NewUserService(userRepo, nil, nil, tenantRepo, utils.NewJWTManager("test-secret", time.Hour, time.Hour, time.Minute))
+
+			_, err := s.SetUserStatus(context.Background(), &SetUserStatusInput{
+				ActorID:           uuid.New(),
+				ActorIsSuperAdmin: tt.superAdmin,
+				TenantID:          tenantID,
+				UserID:            user.ID,
+				IsActive:          false,
+			})
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("SetUserStatus: %v", err)
+				}
+				if userRepo.updated != 1 || user.IsActive {
+					t.Error("user was not deactivated")
+				}
+				return
+			}
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) || appErr.Code != http.StatusForbidden {
+				t.Fatalf("err = %v, want forbidden", err)
+			}
+			if userRepo.updated != 0 {
+				t.Error("forbidden status change updated the user")
+			}
+		})
+	}
+}
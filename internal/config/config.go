@@ -2,28 +2,32 @@ package config
 
 import (
 	"log"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	App       AppConfig
-	Database  DatabaseConfig
-	JWT       JWTConfig
-	Storage   StorageConfig
-	CORS      CORSConfig
-	RateLimit RateLimitConfig
-	Email     EmailConfig
-	OAuth     OAuthConfig
-	Printer   PrinterConfig
+	App        AppConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Storage    StorageConfig
+	CORS       CORSConfig
+	RateLimit  RateLimitConfig
+	Email      EmailConfig
+	OAuth      OAuthConfig
+	Printer    PrinterConfig
+	Pagination PaginationConfig
+	API        APIConfig
 }
 
 type AppConfig struct {
-	Name  string
-	Env   string
-	Port  string
-	Debug bool
+	Name        string
+	Env         string
+	Port        string
+	Debug       bool
+	MaxBodySize int64 // Largest request body accepted outside the upload routes, in bytes
 }
 
 type DatabaseConfig struct {
@@ -34,38 +38,68 @@ type DatabaseConfig struct {
 	Password string
 	SSLMode  string
 	Timezone string
+	// ReplicaDSN points at a read replica that serves report and dashboard queries;
+	// empty sends them to the primary
+	ReplicaDSN string
 }
 
 type JWTConfig struct {
 	Secret             string
 	ExpiryHours        time.Duration
 	RefreshExpiryHours time.Duration
+	StepUpExpiry       time.Duration // Lifetime of tokens issued by re-authentication
 }
 
 type StorageConfig struct {
 	Path          string
-	UploadMaxSize int64
+	PublicURL     string // URL the files under Path are served at
+	UploadMaxSize int64  // Largest request body accepted by file upload and import routes, in bytes
 }
 
 type CORSConfig struct {
-	AllowedOrigins []string
-	AllowedMethods []string
-	AllowedHeaders []string
+	AllowedOrigins   []string // Exact origins or one-wildcard patterns like "https://*.example.com"
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string // Added to the headers the API always exposes
+	AllowCredentials bool
+	MaxAge           time.Duration // How long browsers may cache preflight responses
 }
 
 type RateLimitConfig struct {
 	Requests int
 	Duration int
+
+	// Stricter limit for reports, exports, imports and PDF statements
+	HeavyRequests int
+	HeavyDuration int
+
+	// Roles that are never rate limited
+	BypassRoles []string
 }
 
 type EmailConfig struct {
-	SMTPHost     string
-	SMTPPort     int
-	SMTPUsername string
-	SMTPPassword string
-	FromName     string
-	FromEmail    string
-	FrontendURL  string
+	Transport string // smtp, sendgrid or ses
+
+	SMTPHost       string
+	SMTPPort       int
+	SMTPUsername   string
+	SMTPPassword   string
+	SMTPDisableTLS bool
+
+	SendGridAPIKey string
+
+	SESRegion          string
+	SESAccessKeyID     string
+	SESSecretAccessKey string
+
+	FromName    string
+	FromEmail   string
+	FrontendURL string
+
+	// Background delivery queue
+	Workers    int
+	QueueSize  int
+	MaxRetries int
 }
 
 type OAuthConfig struct {
@@ -83,6 +117,19 @@ type PrinterConfig struct {
 	Address string // TCP address, e.g. "192.168.1.100:9100"
 }
 
+// PaginationConfig holds list endpoint paging limits.
+type PaginationConfig struct {
+	MaxPageSize int // Upper bound for per_page/limit; still capped by pagination.HardMaxPageSize
+}
+
+// APIConfig controls which API versions are served and the deprecation of v1 routes.
+type APIConfig struct {
+	V2Enabled          bool      // Serve /api/v2 alongside /api/v1
+	V1DeprecatedRoutes []string  // v1 route prefixes slated for removal, e.g. "/quotations"; "/" for all
+	V1DeprecatedAt     time.Time // Sent in the Deprecation header; zero sends "true"
+	V1Sunset           time.Time // Sent in the Sunset header; zero omits it
+}
+
 func Load() *Config {
 	viper.SetConfigFile(".env")
 	viper.AutomaticEnv()
@@ -96,6 +143,7 @@ func Load() *Config {
 	viper.SetDefault("APP_ENV", "development")
 	viper.SetDefault("APP_PORT", "8080")
 	viper.SetDefault("APP_DEBUG", true)
+	viper.SetDefault("APP_MAX_BODY_SIZE", 1048576)
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", "5432")
 	viper.SetDefault("DB_NAME", "investify")
@@ -103,21 +151,38 @@ func Load() *Config {
 	viper.SetDefault("DB_PASSWORD", "zx0011")
 	viper.SetDefault("DB_SSL_MODE", "disable")
 	viper.SetDefault("DB_TIMEZONE", "Africa/Nairobi")
+	viper.SetDefault("DB_REPLICA_DSN", "")
 	viper.SetDefault("JWT_SECRET", "change-this-secret-in-production")
 	viper.SetDefault("JWT_EXPIRY_HOURS", 24)
 	viper.SetDefault("JWT_REFRESH_EXPIRY_HOURS", 168)
+	viper.SetDefault("JWT_STEP_UP_EXPIRY_MINUTES", 5)
 	viper.SetDefault("STORAGE_PATH", "./storage")
+	viper.SetDefault("STORAGE_PUBLIC_URL", "http://localhost:8080/uploads")
 	viper.SetDefault("UPLOAD_MAX_SIZE", 10485760)
 	viper.SetDefault("CORS_ALLOWED_ORIGINS", "http://localhost:3000")
 	viper.SetDefault("CORS_ALLOWED_HEADERS", []string{})
+	viper.SetDefault("CORS_ALLOW_CREDENTIALS", true)
+	viper.SetDefault("CORS_MAX_AGE", 43200)
 	viper.SetDefault("RATE_LIMIT_REQUESTS", 100)
 	viper.SetDefault("RATE_LIMIT_DURATION", 60)
+	viper.SetDefault("RATE_LIMIT_HEAVY_REQUESTS", 20)
+	viper.SetDefault("RATE_LIMIT_HEAVY_DURATION", 60)
+	viper.SetDefault("RATE_LIMIT_BYPASS_ROLES", []string{"super-admin"})
+	viper.SetDefault("EMAIL_TRANSPORT", "smtp")
 	viper.SetDefault("SMTP_HOST", "smtp.gmail.com")
 	viper.SetDefault("SMTP_PORT", 587)
 	viper.SetDefault("SMTP_USERNAME", "")
 	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("SMTP_DISABLE_TLS", false)
+	viper.SetDefault("SENDGRID_API_KEY", "")
+	viper.SetDefault("AWS_SES_REGION", "")
+	viper.SetDefault("AWS_ACCESS_KEY_ID", "")
+	viper.SetDefault("AWS_SECRET_ACCESS_KEY", "")
 	viper.SetDefault("EMAIL_FROM_NAME", "Investify")
 	viper.SetDefault("EMAIL_FROM_ADDRESS", "")
+	viper.SetDefault("EMAIL_WORKERS", 2)
+	viper.SetDefault("EMAIL_QUEUE_SIZE", 100)
+	viper.SetDefault("EMAIL_MAX_RETRIES", 3)
 	viper.SetDefault("FRONTEND_URL", "https://investify.autoscaleops.com")
 	viper.SetDefault("GOOGLE_CLIENT_ID", "")
 	viper.SetDefault("GOOGLE_CLIENT_SECRET", "")
@@ -127,6 +192,11 @@ func Load() *Config {
 	viper.SetDefault("PRINTER_TYPE", "none")
 	viper.SetDefault("PRINTER_USB_PATH", "/dev/usb/lp0")
 	viper.SetDefault("PRINTER_ADDRESS", "")
+	viper.SetDefault("PAGINATION_MAX_PAGE_SIZE", 100)
+	viper.SetDefault("API_V2_ENABLED", false)
+	viper.SetDefault("API_V1_DEPRECATED_ROUTES", []string{})
+	viper.SetDefault("API_V1_DEPRECATION_DATE", "")
+	viper.SetDefault("API_V1_SUNSET_DATE", "")
 
 	return &Config{
 		App: AppConfig{
@@ -134,6 +204,8 @@ func Load() *Config {
 			Env:   viper.GetString("APP_ENV"),
 			Port:  viper.GetString("APP_PORT"),
 			Debug: viper.GetBool("APP_DEBUG"),
+
+			MaxBodySize: viper.GetInt64("APP_MAX_BODY_SIZE"),
 		},
 		Database: DatabaseConfig{
 			Host:     viper.GetString("DB_HOST"),
@@ -143,33 +215,58 @@ func Load() *Config {
 			Password: viper.GetString("DB_PASSWORD"),
 			SSLMode:  viper.GetString("DB_SSL_MODE"),
 			Timezone: viper.GetString("DB_TIMEZONE"),
+
+			ReplicaDSN: viper.GetString("DB_REPLICA_DSN"),
 		},
 		JWT: JWTConfig{
 			Secret:             viper.GetString("JWT_SECRET"),
 			ExpiryHours:        time.Duration(viper.GetInt("JWT_EXPIRY_HOURS")) * time.Hour,
 			RefreshExpiryHours: time.Duration(viper.GetInt("JWT_REFRESH_EXPIRY_HOURS")) * time.Hour,
+			StepUpExpiry:       time.Duration(viper.GetInt("JWT_STEP_UP_EXPIRY_MINUTES")) * time.Minute,
 		},
 		Storage: StorageConfig{
 			Path:          viper.GetString("STORAGE_PATH"),
+			PublicURL:     viper.GetString("STORAGE_PUBLIC_URL"),
 			UploadMaxSize: viper.GetInt64("UPLOAD_MAX_SIZE"),
 		},
 		CORS: CORSConfig{
-			AllowedOrigins: viper.GetStringSlice("CORS_ALLOWED_ORIGINS"),
-			AllowedMethods: viper.GetStringSlice("CORS_ALLOWED_METHODS"),
-			AllowedHeaders: viper.GetStringSlice("CORS_ALLOWED_HEADERS"),
+			AllowedOrigins:   getList("CORS_ALLOWED_ORIGINS"),
+			AllowedMethods:   getList("CORS_ALLOWED_METHODS"),
+			AllowedHeaders:   getList("CORS_ALLOWED_HEADERS"),
+			ExposedHeaders:   getList("CORS_EXPOSED_HEADERS"),
+			AllowCredentials: viper.GetBool("CORS_ALLOW_CREDENTIALS"),
+			MaxAge:           time.Duration(viper.GetInt("CORS_MAX_AGE")) * time.Second,
 		},
 		RateLimit: RateLimitConfig{
 			Requests: viper.GetInt("RATE_LIMIT_REQUESTS"),
 			Duration: viper.GetInt("RATE_LIMIT_DURATION"),
+
+			HeavyRequests: viper.GetInt("RATE_LIMIT_HEAVY_REQUESTS"),
+			HeavyDuration: viper.GetInt("RATE_LIMIT_HEAVY_DURATION"),
+			BypassRoles:   getList("RATE_LIMIT_BYPASS_ROLES"),
 		},
 		Email: EmailConfig{
-			SMTPHost:     viper.GetString("SMTP_HOST"),
-			SMTPPort:     viper.GetInt("SMTP_PORT"),
-			SMTPUsername: viper.GetString("SMTP_USERNAME"),
-			SMTPPassword: viper.GetString("SMTP_PASSWORD"),
-			FromName:     viper.GetString("EMAIL_FROM_NAME"),
-			FromEmail:    viper.GetString("EMAIL_FROM_ADDRESS"),
-			FrontendURL:  viper.GetString("FRONTEND_URL"),
+			Transport: viper.GetString("EMAIL_TRANSPORT"),
+
+			SMTPHost:       viper.GetString("SMTP_HOST"),
+			SMTPPort:       viper.GetInt("SMTP_PORT"),
+			SMTPUsername:   viper.GetString("SMTP_USERNAME"),
+			SMTPPassword:   viper.GetString("SMTP_PASSWORD"),
+			SMTPDisableTLS: viper.GetBool("SMTP_DISABLE_TLS"),
+
+			SendGridAPIKey: viper.GetString("SENDGRID_API_KEY"),
+
+			SESRegion:          viper.GetString("AWS_SES_REGION"),
+			SESAccessKeyID:     viper.GetString("AWS_ACCESS_KEY_ID"),
+			SESSecretAccessKey: viper.GetString("AWS_SECRET_ACCESS_KEY"),
+
+			FromName:    viper.GetString("EMAIL_FROM_NAME"),
+			FromEmail:   viper.GetString("EMAIL_FROM_ADDRESS"),
+			FrontendURL: viper.GetString("FRONTEND_URL"),
+
+			Workers:    viper.GetInt("EMAIL_WORKERS"),
+			QueueSize:  viper.GetInt("EMAIL_QUEUE_SIZE"),
+			MaxRetries: viper.GetInt("EMAIL_MAX_RETRIES"),
 		},
 		OAuth: OAuthConfig{
 			GoogleClientID:     viper.GetString("GOOGLE_CLIENT_ID"),
@@ -183,7 +280,44 @@ func Load() *Config {
 			USBPath: viper.GetString("PRINTER_USB_PATH"),
 			Address: viper.GetString("PRINTER_ADDRESS"),
 		},
+		Pagination: PaginationConfig{
+			MaxPageSize: viper.GetInt("PAGINATION_MAX_PAGE_SIZE"),
+		},
+		API: APIConfig{
+			V2Enabled:          viper.GetBool("API_V2_ENABLED"),
+			V1DeprecatedRoutes: getList("API_V1_DEPRECATED_ROUTES"),
+			V1DeprecatedAt:     getDate("API_V1_DEPRECATION_DATE"),
+			V1Sunset:           getDate("API_V1_SUNSET_DATE"),
+		},
+	}
+}
+
+// getDate reads a YYYY-MM-DD date setting (midnight UTC). Empty or invalid values give
+// the zero time; invalid ones are logged.
+func getDate(key string) time.Time {
+	value := strings.TrimSpace(viper.GetString(key))
+	if value == "" {
+		return time.Time{}
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		log.Printf("Warning: ignoring %s=%q, expected YYYY-MM-DD: %v", key, value, err)
+	}
+	return date
+}
+
+// getList reads a list setting. Environment values are comma-separated
+// ("a,b, c"); viper alone would only split them on whitespace.
+func getList(key string) []string {
+	var list []string
+	for _, item := range viper.GetStringSlice(key) {
+		for _, part := range strings.Split(item, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				list = append(list, part)
+			}
+		}
 	}
+	return list
 }
 
 func (c *DatabaseConfig) DSN() string {
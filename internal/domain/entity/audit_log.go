@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditLog records a mutating action performed by a user
+type AuditLog struct {
+	ID           uuid.UUID              `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID     *uuid.UUID             `gorm:"type:uuid;index" json:"tenant_id,omitempty"`
+	ActorID      uuid.UUID              `gorm:"type:uuid;not null;index" json:"actor_id"`
+	Action       string                 `gorm:"size:50;not null;index" json:"action"` // create, update, delete
+	ResourceType string                 `gorm:"size:100;not null;index:idx_audit_resource" json:"resource_type"`
+	ResourceID   string                 `gorm:"size:255;index:idx_audit_resource" json:"resource_id,omitempty"`
+	Metadata     map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"metadata,omitempty"`
+	// ImpersonatedBy is the super-admin who performed the action while impersonating the actor
+	ImpersonatedBy *uuid.UUID `gorm:"type:uuid;index" json:"impersonated_by,omitempty"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+
+	// Relationships
+	Actor User `gorm:"foreignKey:ActorID" json:"-"`
+}
+
+// BeforeCreate generates a UUID before creating a new audit log entry
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName returns the table name for the AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
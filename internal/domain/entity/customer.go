@@ -12,6 +12,8 @@ type Customer struct {
 	ID            uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
 	TenantID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
 	UserID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	CreatedByID   *uuid.UUID     `gorm:"type:uuid;column:created_by" json:"created_by,omitempty"`
+	UpdatedByID   *uuid.UUID     `gorm:"type:uuid;column:updated_by" json:"updated_by,omitempty"`
 	Name          string         `gorm:"size:255;not null" json:"name"`
 	Email         *string        `gorm:"size:255" json:"email,omitempty"`
 	Phone         *string        `gorm:"size:50" json:"phone,omitempty"`
@@ -21,15 +23,20 @@ type Customer struct {
 	AccountHolder *string        `gorm:"size:255" json:"account_holder,omitempty"`
 	AccountNumber *string        `gorm:"size:100" json:"account_number,omitempty"`
 	BankName      *string        `gorm:"size:255" json:"bank_name,omitempty"`
+	LoyaltyPoints int64          `gorm:"not null;default:0" json:"loyalty_points"` // Balance of the loyalty ledger
+	TaxExempt     bool           `gorm:"not null;default:false" json:"tax_exempt"` // Orders are charged no VAT (e.g. diplomatic, wholesale)
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	Version       int            `gorm:"not null;default:1" json:"version"` // Bumped on every write; updates must match it
 
 	// Relationships
-	Tenant     Tenant      `gorm:"foreignKey:TenantID" json:"-"`
-	User       User        `gorm:"foreignKey:UserID" json:"-"`
-	Orders     []Order     `gorm:"foreignKey:CustomerID" json:"-"`
-	Quotations []Quotation `gorm:"foreignKey:CustomerID" json:"-"`
+	Tenant     Tenant       `gorm:"foreignKey:TenantID" json:"-"`
+	User       User         `gorm:"foreignKey:UserID" json:"-"`
+	CreatedBy  *UserSummary `gorm:"foreignKey:CreatedByID" json:"created_by_user,omitempty"`
+	UpdatedBy  *UserSummary `gorm:"foreignKey:UpdatedByID" json:"updated_by_user,omitempty"`
+	Orders     []Order      `gorm:"foreignKey:CustomerID" json:"-"`
+	Quotations []Quotation  `gorm:"foreignKey:CustomerID" json:"-"`
 }
 
 // BeforeCreate generates a UUID before creating a new customer
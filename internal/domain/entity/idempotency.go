@@ -6,17 +6,20 @@ import (
 	"github.com/google/uuid"
 )
 
-// IdempotencyKey stores processed requests to prevent duplicates
+// IdempotencyKey stores processed requests to prevent duplicates. Keys are scoped to a
+// tenant and user, so the same client-chosen key never collides across either.
 type IdempotencyKey struct {
-	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Key          string    `gorm:"uniqueIndex;size:255;not null"` // The idempotency key from client
-	UserID       uuid.UUID `gorm:"type:uuid;not null;index"`      // User who made the request
-	Endpoint     string    `gorm:"size:255;not null"`             // API endpoint (e.g., "POST /orders")
-	RequestHash  string    `gorm:"size:64"`                       // SHA256 hash of request body (optional)
-	ResponseCode int       `gorm:"not null"`                      // HTTP status code of original response
-	ResponseBody string    `gorm:"type:text"`                     // JSON response body (cached)
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
-	ExpiresAt    time.Time `gorm:"not null;index"` // Keys expire after 24 hours
+	ID              uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	TenantID        uuid.UUID `gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000000';uniqueIndex:idx_idempotency_scope,priority:1"`
+	UserID          uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_idempotency_scope,priority:2"` // User who made the request
+	Key             string    `gorm:"size:255;not null;uniqueIndex:idx_idempotency_scope,priority:3"`        // The idempotency key from client
+	Endpoint        string    `gorm:"size:255;not null"`                                                     // Method and request path (e.g., "POST /api/v1/orders/<id>/pay")
+	RequestHash     string    `gorm:"size:64"`                                                               // Hex SHA-256 of the request body; a reused key must match it
+	ResponseCode    int       `gorm:"not null"`                                                              // HTTP status code of original response; 0 while in flight
+	ResponseBody    string    `gorm:"type:text"`                                                             // JSON response body (cached)
+	ResponseHeaders string    `gorm:"type:text"`                                                             // JSON object of replayed headers (Content-Type, Location)
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	ExpiresAt       time.Time `gorm:"not null;index"` // Keys expire after 24 hours
 }
 
 // TableName returns the table name for IdempotencyKey
@@ -28,3 +31,8 @@ func (IdempotencyKey) TableName() string {
 func (i *IdempotencyKey) IsExpired() bool {
 	return time.Now().After(i.ExpiresAt)
 }
+
+// IsPending reports whether the original request is still being processed
+func (i *IdempotencyKey) IsPending() bool {
+	return i.ResponseCode == 0
+}
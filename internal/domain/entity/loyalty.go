@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Loyalty ledger entry types
+const (
+	LoyaltyEarn         = "earn"          // Points accrued when an order completes
+	LoyaltyRedeem       = "redeem"        // Points spent as a discount on an order
+	LoyaltyEarnReversal = "earn_reversal" // Accrued points taken back when the order is cancelled
+	LoyaltyRedeemRefund = "redeem_refund" // Spent points given back when the order is cancelled
+)
+
+// LoyaltyTransaction is an entry in a customer's loyalty points ledger. The customer's
+// loyalty_points balance is the sum of their entries. An order has at most one entry
+// of each type, which keeps retried completions and cancellations from applying twice.
+type LoyaltyTransaction struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	CustomerID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"customer_id"`
+	OrderID     *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_loyalty_order_type" json:"order_id,omitempty"`
+	Type        string     `gorm:"size:20;not null;uniqueIndex:idx_loyalty_order_type" json:"type"`
+	Points      int64      `gorm:"not null" json:"points"` // Positive for credits, negative for debits
+	CreatedByID *uuid.UUID `gorm:"type:uuid;column:created_by" json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Relationships
+	Order *Order `gorm:"foreignKey:OrderID" json:"-"`
+}
+
+// BeforeCreate generates a UUID before creating a new ledger entry
+func (lt *LoyaltyTransaction) BeforeCreate(tx *gorm.DB) error {
+	if lt.ID == uuid.Nil {
+		lt.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName returns the table name for the LoyaltyTransaction model
+func (LoyaltyTransaction) TableName() string {
+	return "loyalty_transactions"
+}
@@ -15,25 +15,44 @@ type Order struct {
 	TenantID      uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_order_invoice;index" json:"tenant_id"`
 	UserID        uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
 	CustomerID    *uuid.UUID       `gorm:"type:uuid;index" json:"customer_id,omitempty"`
+	CreatedByID   *uuid.UUID       `gorm:"type:uuid;column:created_by" json:"created_by,omitempty"`
+	UpdatedByID   *uuid.UUID       `gorm:"type:uuid;column:updated_by" json:"updated_by,omitempty"`
 	OrderDate     time.Time        `gorm:"type:date;not null" json:"order_date"`
 	OrderStatus   enum.OrderStatus `gorm:"default:0" json:"order_status"`
 	TotalProducts int              `gorm:"default:0" json:"total_products"`
 	SubTotal      int64            `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
 	VAT           int64            `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
 	Total         int64            `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
+	Rounding      int64            `gorm:"default:0" json:"-"` // Cash rounding included in Total, stored in cents, excluded from JSON
+	PromoDiscount int64            `gorm:"default:0" json:"-"` // Taken off Total by the promotion, stored in cents, excluded from JSON
+	LoyaltyValue  int64            `gorm:"default:0" json:"-"` // Value of the loyalty points redeemed, already taken off Total, stored in cents, excluded from JSON
+	LoyaltyPoints int64            `gorm:"default:0" json:"loyalty_points_redeemed"`
+	PromotionID   *uuid.UUID       `gorm:"type:uuid;index" json:"promotion_id,omitempty"`
+	PromoCode     string           `gorm:"size:50" json:"promo_code,omitempty"`              // Snapshot of the promotion's code
+	TaxExempt     bool             `gorm:"default:false" json:"tax_exempt"`                  // The customer was VAT-exempt, so no VAT was charged
+	ShiftID       *uuid.UUID       `gorm:"type:uuid;index" json:"shift_id,omitempty"`        // Cashier shift the order was taken in
+	RefundShiftID *uuid.UUID       `gorm:"type:uuid;index" json:"refund_shift_id,omitempty"` // Shift whose drawer refunded the order when it was cancelled
 	InvoiceNo     string           `gorm:"size:100;uniqueIndex:idx_tenant_order_invoice;not null" json:"invoice_no"`
 	PaymentType   string           `gorm:"size:50" json:"payment_type"`
 	Pay           int64            `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
 	Due           int64            `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
-	CreatedAt     time.Time        `json:"created_at"`
-	UpdatedAt     time.Time        `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt   `gorm:"index" json:"-"`
+	Change        int64            `gorm:"default:0" json:"-"` // Change owed on overpayment, stored in cents, excluded from JSON
+	// StockReserved orders hold their items in products.reserved_quantity rather than
+	// taking them off quantity; completing the order turns the reservation into a real
+	// decrement and cancelling it releases the reservation
+	StockReserved bool           `gorm:"default:false" json:"stock_reserved"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	Version       int            `gorm:"not null;default:1" json:"version"` // Bumped on every write; updates must match it
 
 	// Relationships
-	Tenant   Tenant        `gorm:"foreignKey:TenantID" json:"-"`
-	User     User          `gorm:"foreignKey:UserID" json:"-"`
-	Customer *Customer     `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
-	Details  []OrderDetail `gorm:"foreignKey:OrderID" json:"details,omitempty"`
+	Tenant    Tenant        `gorm:"foreignKey:TenantID" json:"-"`
+	User      User          `gorm:"foreignKey:UserID" json:"-"`
+	Customer  *Customer     `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	CreatedBy *UserSummary  `gorm:"foreignKey:CreatedByID" json:"created_by_user,omitempty"`
+	UpdatedBy *UserSummary  `gorm:"foreignKey:UpdatedByID" json:"updated_by_user,omitempty"`
+	Details   []OrderDetail `gorm:"foreignKey:OrderID" json:"details,omitempty"`
 }
 
 // MarshalJSON custom marshaler to convert cents to decimal for API responses
@@ -44,15 +63,23 @@ func (o Order) MarshalJSON() ([]byte, error) {
 		SubTotal float64 `json:"sub_total"`
 		VAT      float64 `json:"vat"`
 		Total    float64 `json:"total"`
+		Rounding float64 `json:"rounding"`
+		Promo    float64 `json:"promo_discount"`
+		Loyalty  float64 `json:"loyalty_discount"`
 		Pay      float64 `json:"pay"`
 		Due      float64 `json:"due"`
+		Change   float64 `json:"change"`
 	}{
 		Alias:    Alias(o),
 		SubTotal: float64(o.SubTotal) / 100,
 		VAT:      float64(o.VAT) / 100,
 		Total:    float64(o.Total) / 100,
+		Rounding: float64(o.Rounding) / 100,
+		Promo:    float64(o.PromoDiscount) / 100,
+		Loyalty:  float64(o.LoyaltyValue) / 100,
 		Pay:      float64(o.Pay) / 100,
 		Due:      float64(o.Due) / 100,
+		Change:   float64(o.Change) / 100,
 	})
 }
 
@@ -81,15 +108,19 @@ func (o *Order) GetSubTotalDecimal() float64 {
 
 // OrderDetail represents a line item in an order
 type OrderDetail struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	OrderID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"order_id"`
-	ProductID uuid.UUID      `gorm:"type:uuid;not null;index" json:"product_id"`
-	Quantity  int            `gorm:"not null" json:"quantity"`
-	UnitCost  int64          `gorm:"not null" json:"-"` // Stored in cents, excluded from JSON
-	Total     int64          `gorm:"not null" json:"-"` // Stored in cents, excluded from JSON
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	OrderID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"order_id"`
+	ProductID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"product_id"`
+	ProductName string         `gorm:"size:255" json:"product_name"` // Snapshot at order time; survives renames and deletion
+	ProductCode string         `gorm:"size:100" json:"product_code"`
+	Quantity    int            `gorm:"not null" json:"quantity"`
+	UnitCost    int64          `gorm:"not null" json:"-"`  // Stored in cents, excluded from JSON
+	Total       int64          `gorm:"not null" json:"-"`  // Stored in cents, excluded from JSON
+	TaxAmount   int64          `gorm:"default:0" json:"-"` // VAT added to or included in Total, stored in cents, excluded from JSON
+	TaxType     enum.TaxType   `gorm:"default:0" json:"tax_type"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
 	Order   Order   `gorm:"foreignKey:OrderID" json:"-"`
@@ -101,12 +132,14 @@ func (od OrderDetail) MarshalJSON() ([]byte, error) {
 	type Alias OrderDetail
 	return json.Marshal(&struct {
 		Alias
-		UnitCost float64 `json:"unit_cost"`
-		Total    float64 `json:"total"`
+		UnitCost  float64 `json:"unit_cost"`
+		Total     float64 `json:"total"`
+		TaxAmount float64 `json:"tax_amount"`
 	}{
-		Alias:    Alias(od),
-		UnitCost: float64(od.UnitCost) / 100,
-		Total:    float64(od.Total) / 100,
+		Alias:     Alias(od),
+		UnitCost:  float64(od.UnitCost) / 100,
+		Total:     float64(od.Total) / 100,
+		TaxAmount: float64(od.TaxAmount) / 100,
 	})
 }
 
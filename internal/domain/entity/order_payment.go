@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderPayment is money received on an order: the payment taken at checkout or a later
+// payment towards its due. It belongs to the shift open for the cashier who took it,
+// which need not be the shift the order was created in.
+type OrderPayment struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	OrderID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"`
+	ShiftID     *uuid.UUID `gorm:"type:uuid;index" json:"shift_id,omitempty"`
+	PaymentType string     `gorm:"size:50" json:"payment_type"`
+	Amount      int64      `gorm:"not null" json:"-"` // Received less change given, stored in cents, excluded from JSON
+	PaidAt      time.Time  `gorm:"not null;index" json:"paid_at"`
+	CreatedByID *uuid.UUID `gorm:"type:uuid;column:created_by" json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Relationships
+	Order *Order `gorm:"foreignKey:OrderID" json:"-"`
+}
+
+// MarshalJSON custom marshaler to convert cents to decimal for API responses
+func (p OrderPayment) MarshalJSON() ([]byte, error) {
+	type Alias OrderPayment
+	return json.Marshal(&struct {
+		Alias
+		Amount float64 `json:"amount"`
+	}{
+		Alias:  Alias(p),
+		Amount: float64(p.Amount) / 100,
+	})
+}
+
+// BeforeCreate generates a UUID before creating a new payment
+func (p *OrderPayment) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName returns the table name for the OrderPayment model
+func (OrderPayment) TableName() string {
+	return "order_payments"
+}
@@ -6,36 +6,57 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/pkg/money"
 	"gorm.io/gorm"
 )
 
 // Product represents a product in the inventory
 type Product struct {
-	ID            uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	TenantID      uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_product_slug;uniqueIndex:idx_tenant_product_code;index" json:"tenant_id"`
-	UserID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
-	CategoryID    *uuid.UUID     `gorm:"type:uuid;index" json:"category_id,omitempty"`
-	UnitID        *uuid.UUID     `gorm:"type:uuid;index" json:"unit_id,omitempty"`
-	Name          string         `gorm:"size:255;not null" json:"name"`
-	Slug          string         `gorm:"size:255;uniqueIndex:idx_tenant_product_slug;not null" json:"slug"`
-	Code          string         `gorm:"size:100;uniqueIndex:idx_tenant_product_code;not null" json:"code"`
-	Quantity      int            `gorm:"default:0" json:"quantity"`
-	QuantityAlert int            `gorm:"default:0" json:"quantity_alert"`
-	BuyingPrice   int64          `gorm:"default:0" json:"buying_price"`  // Stored in cents
-	SellingPrice  int64          `gorm:"default:0" json:"selling_price"` // Stored in cents
-	Tax           int            `gorm:"default:0" json:"tax"`
-	TaxType       enum.TaxType   `gorm:"default:0" json:"tax_type"`
-	Notes         *string        `gorm:"type:text" json:"notes,omitempty"`
-	ProductImage  *string        `gorm:"size:255" json:"product_image,omitempty"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                  uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID            uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_product_live_slug;uniqueIndex:idx_tenant_product_live_code;index" json:"tenant_id"`
+	UserID              uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	CreatedByID         *uuid.UUID     `gorm:"type:uuid;column:created_by" json:"created_by,omitempty"`
+	UpdatedByID         *uuid.UUID     `gorm:"type:uuid;column:updated_by" json:"updated_by,omitempty"`
+	CategoryID          *uuid.UUID     `gorm:"type:uuid;index" json:"category_id,omitempty"`
+	UnitID              *uuid.UUID     `gorm:"type:uuid;index" json:"unit_id,omitempty"`
+	PurchaseUnitID      *uuid.UUID     `gorm:"type:uuid;index" json:"purchase_unit_id,omitempty"`      // Unit it is bought in, if not the sale unit (UnitID)
+	PiecesPerUnit       int            `gorm:"not null;default:1" json:"pieces_per_unit"`              // Sale units in one purchase unit
+	PreferredSupplierID *uuid.UUID     `gorm:"type:uuid;index" json:"preferred_supplier_id,omitempty"` // Supplier auto-generated purchases go to
+	Name                string         `gorm:"size:255;not null" json:"name"`
+	Slug                string         `gorm:"size:255;uniqueIndex:idx_tenant_product_live_slug,where:deleted_at IS NULL;not null" json:"slug"` // Unique among undeleted products
+	Code                string         `gorm:"size:100;uniqueIndex:idx_tenant_product_live_code,where:deleted_at IS NULL;not null" json:"code"` // Unique among undeleted products
+	Quantity            int            `gorm:"default:0" json:"quantity"`
+	ReservedQuantity    int            `gorm:"default:0" json:"reserved_quantity"` // Held for pending orders; still counted in Quantity
+	AllowNegativeStock  bool           `gorm:"default:false" json:"allow_negative_stock"`
+	IsActive            bool           `gorm:"not null;default:true" json:"is_active"` // Inactive products stay in reports but are hidden from lists and can't be ordered
+	QuantityAlert       int            `gorm:"default:0" json:"quantity_alert"`
+	MinStock            int            `gorm:"default:0" json:"min_stock"`     // Reorder when quantity falls to this level; 0 disables
+	MaxStock            int            `gorm:"default:0" json:"max_stock"`     // Level a reorder should bring stock back up to; 0 if unset
+	ReorderQty          int            `gorm:"default:0" json:"reorder_qty"`   // Fixed quantity to reorder when MaxStock is unset
+	BuyingPrice         int64          `gorm:"default:0" json:"buying_price"`  // Stored in cents
+	SellingPrice        int64          `gorm:"default:0" json:"selling_price"` // Stored in cents
+	Tax                 int            `gorm:"default:0" json:"tax"`
+	TaxType             enum.TaxType   `gorm:"default:0" json:"tax_type"`
+	Notes               *string        `gorm:"type:text" json:"notes,omitempty"`
+	ProductImage        *string        `gorm:"size:255" json:"product_image,omitempty"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
+	Version             int            `gorm:"not null;default:1" json:"version"` // Bumped on every write; updates must match it
+
+	// VATRate is the tenant VAT rate used to render price_with_tax/price_without_tax;
+	// zero leaves them out of the JSON
+	VATRate float64 `gorm:"-" json:"-"`
 
 	// Relationships
-	Tenant   Tenant    `gorm:"foreignKey:TenantID" json:"-"`
-	User     User      `gorm:"foreignKey:UserID" json:"-"`
-	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
-	Unit     *Unit     `gorm:"foreignKey:UnitID" json:"unit,omitempty"`
+	Tenant       Tenant         `gorm:"foreignKey:TenantID" json:"-"`
+	User         User           `gorm:"foreignKey:UserID" json:"-"`
+	Category     *Category      `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Unit         *Unit          `gorm:"foreignKey:UnitID" json:"unit,omitempty"`
+	PurchaseUnit *Unit          `gorm:"foreignKey:PurchaseUnitID" json:"purchase_unit,omitempty"`
+	CreatedBy    *UserSummary   `gorm:"foreignKey:CreatedByID" json:"created_by_user,omitempty"`
+	UpdatedBy    *UserSummary   `gorm:"foreignKey:UpdatedByID" json:"updated_by_user,omitempty"`
+	Images       []ProductImage `gorm:"foreignKey:ProductID" json:"images,omitempty"` // Ordered by SortOrder
 }
 
 // BeforeCreate generates a UUID before creating a new product
@@ -63,73 +84,194 @@ func (p *Product) GetSellingPriceDecimal() float64 {
 
 // SetBuyingPriceFromDecimal sets the buying price from a decimal value
 func (p *Product) SetBuyingPriceFromDecimal(price float64) {
-	p.BuyingPrice = int64(price * 100)
+	p.BuyingPrice = money.ToCents(price)
 }
 
 // SetSellingPriceFromDecimal sets the selling price from a decimal value
 func (p *Product) SetSellingPriceFromDecimal(price float64) {
-	p.SellingPrice = int64(price * 100)
+	p.SellingPrice = money.ToCents(price)
+}
+
+// UnitFactor returns how many sale units one purchase unit holds, at least 1
+func (p *Product) UnitFactor() int {
+	if p.PiecesPerUnit < 1 {
+		return 1
+	}
+	return p.PiecesPerUnit
+}
+
+// Available returns the stock that can still be sold: on hand less what is reserved
+func (p *Product) Available() int {
+	return p.Quantity - p.ReservedQuantity
+}
+
+// ReorderQuantity returns how many units to buy for a product at or below MinStock:
+// enough to reach MaxStock if set, otherwise ReorderQty, otherwise enough to get back
+// above MinStock. It is 0 if the product doesn't need reordering.
+func (p *Product) ReorderQuantity() int {
+	if p.MinStock <= 0 || p.Quantity > p.MinStock {
+		return 0
+	}
+	switch {
+	case p.MaxStock > p.Quantity:
+		return p.MaxStock - p.Quantity
+	case p.ReorderQty > 0:
+		return p.ReorderQty
+	default:
+		return p.MinStock - p.Quantity + 1
+	}
+}
+
+// TaxPrices returns the selling price with and without VAT at the given rate, in cents.
+// Inclusive prices already contain VAT; exclusive prices have it added on top, matching
+// how orders are charged.
+func (p *Product) TaxPrices(rate float64) (withTax, withoutTax int64) {
+	if p.TaxType == enum.TaxTypeInclusive {
+		return p.SellingPrice, p.SellingPrice - money.ApplyRate(p.SellingPrice, rate/(1+rate))
+	}
+	return p.SellingPrice + money.ApplyRate(p.SellingPrice, rate), p.SellingPrice
 }
 
 // ProductJSON is a helper struct for JSON marshaling with decimal prices
 type ProductJSON struct {
-	ID            uuid.UUID    `json:"id"`
-	UserID        uuid.UUID    `json:"user_id"`
-	CategoryID    *uuid.UUID   `json:"category_id,omitempty"`
-	UnitID        *uuid.UUID   `json:"unit_id,omitempty"`
-	Name          string       `json:"name"`
-	Slug          string       `json:"slug"`
-	Code          string       `json:"code"`
-	Quantity      int          `json:"quantity"`
-	QuantityAlert int          `json:"quantity_alert"`
-	BuyingPrice   float64      `json:"buying_price"`  // Decimal value for JSON
-	SellingPrice  float64      `json:"selling_price"` // Decimal value for JSON
-	Tax           int          `json:"tax"`
-	TaxType       enum.TaxType `json:"tax_type"`
-	Notes         *string      `json:"notes,omitempty"`
-	ProductImage  *string      `json:"product_image,omitempty"`
-	CreatedAt     time.Time    `json:"created_at"`
-	UpdatedAt     time.Time    `json:"updated_at"`
-	Category      *Category    `json:"category,omitempty"`
-	Unit          *Unit        `json:"unit,omitempty"`
+	ID                  uuid.UUID      `json:"id"`
+	UserID              uuid.UUID      `json:"user_id"`
+	CreatedByID         *uuid.UUID     `json:"created_by,omitempty"`
+	UpdatedByID         *uuid.UUID     `json:"updated_by,omitempty"`
+	CategoryID          *uuid.UUID     `json:"category_id,omitempty"`
+	UnitID              *uuid.UUID     `json:"unit_id,omitempty"`
+	PurchaseUnitID      *uuid.UUID     `json:"purchase_unit_id,omitempty"`
+	PiecesPerUnit       int            `json:"pieces_per_unit"`
+	PreferredSupplierID *uuid.UUID     `json:"preferred_supplier_id,omitempty"`
+	Name                string         `json:"name"`
+	Slug                string         `json:"slug"`
+	Code                string         `json:"code"`
+	Quantity            int            `json:"quantity"`
+	ReservedQuantity    int            `json:"reserved_quantity"`
+	Available           int            `json:"available"` // Quantity - ReservedQuantity
+	AllowNegativeStock  bool           `json:"allow_negative_stock"`
+	IsActive            bool           `json:"is_active"`
+	QuantityAlert       int            `json:"quantity_alert"`
+	MinStock            int            `json:"min_stock"`
+	MaxStock            int            `json:"max_stock"`
+	ReorderQty          int            `json:"reorder_qty"`
+	BuyingPrice         float64        `json:"buying_price"`  // Decimal value for JSON
+	SellingPrice        float64        `json:"selling_price"` // Decimal value for JSON
+	PriceWithTax        *float64       `json:"price_with_tax,omitempty"`
+	PriceWithoutTax     *float64       `json:"price_without_tax,omitempty"`
+	Tax                 int            `json:"tax"`
+	TaxType             enum.TaxType   `json:"tax_type"`
+	Notes               *string        `json:"notes,omitempty"`
+	ProductImage        *string        `json:"product_image,omitempty"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	Version             int            `json:"version"`
+	Category            *Category      `json:"category,omitempty"`
+	Unit                *Unit          `json:"unit,omitempty"`
+	PurchaseUnit        *Unit          `json:"purchase_unit,omitempty"`
+	CreatedBy           *UserSummary   `json:"created_by_user,omitempty"`
+	UpdatedBy           *UserSummary   `json:"updated_by_user,omitempty"`
+	Images              []ProductImage `json:"images,omitempty"`
 }
 
 // MarshalJSON converts Product to JSON with decimal prices
 func (p Product) MarshalJSON() ([]byte, error) {
-	return json.Marshal(ProductJSON{
-		ID:            p.ID,
-		UserID:        p.UserID,
-		CategoryID:    p.CategoryID,
-		UnitID:        p.UnitID,
-		Name:          p.Name,
-		Slug:          p.Slug,
-		Code:          p.Code,
-		Quantity:      p.Quantity,
-		QuantityAlert: p.QuantityAlert,
-		BuyingPrice:   p.GetBuyingPriceDecimal(),
-		SellingPrice:  p.GetSellingPriceDecimal(),
-		Tax:           p.Tax,
-		TaxType:       p.TaxType,
-		Notes:         p.Notes,
-		ProductImage:  p.ProductImage,
-		CreatedAt:     p.CreatedAt,
-		UpdatedAt:     p.UpdatedAt,
-		Category:      p.Category,
-		Unit:          p.Unit,
-	})
+	out := ProductJSON{
+		ID:                  p.ID,
+		UserID:              p.UserID,
+		CreatedByID:         p.CreatedByID,
+		UpdatedByID:         p.UpdatedByID,
+		CategoryID:          p.CategoryID,
+		UnitID:              p.UnitID,
+		PurchaseUnitID:      p.PurchaseUnitID,
+		PiecesPerUnit:       p.UnitFactor(),
+		PreferredSupplierID: p.PreferredSupplierID,
+		Name:                p.Name,
+		Slug:                p.Slug,
+		Code:                p.Code,
+		Quantity:            p.Quantity,
+		ReservedQuantity:    p.ReservedQuantity,
+		Available:           p.Available(),
+		AllowNegativeStock:  p.AllowNegativeStock,
+		IsActive:            p.IsActive,
+		QuantityAlert:       p.QuantityAlert,
+		MinStock:            p.MinStock,
+		MaxStock:            p.MaxStock,
+		ReorderQty:          p.ReorderQty,
+		BuyingPrice:         p.GetBuyingPriceDecimal(),
+		SellingPrice:        p.GetSellingPriceDecimal(),
+		Tax:                 p.Tax,
+		TaxType:             p.TaxType,
+		Notes:               p.Notes,
+		ProductImage:        p.ProductImage,
+		CreatedAt:           p.CreatedAt,
+		UpdatedAt:           p.UpdatedAt,
+		Version:             p.Version,
+		Category:            p.Category,
+		Unit:                p.Unit,
+		PurchaseUnit:        p.PurchaseUnit,
+		CreatedBy:           p.CreatedBy,
+		UpdatedBy:           p.UpdatedBy,
+		Images:              p.Images,
+	}
+	if p.VATRate > 0 {
+		withTax, withoutTax := p.TaxPrices(p.VATRate)
+		withTaxDecimal, withoutTaxDecimal := money.FromCents(withTax), money.FromCents(withoutTax)
+		out.PriceWithTax = &withTaxDecimal
+		out.PriceWithoutTax = &withoutTaxDecimal
+	}
+	return json.Marshal(out)
+}
+
+// MaxProductImages is the most images a product's gallery may hold
+const MaxProductImages = 10
+
+// ProductImage is one image in a product's gallery. Exactly one image of a product with
+// images is primary; Product.ProductImage mirrors its URL for older clients.
+type ProductImage struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID  uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	URL       string    `gorm:"size:500;not null" json:"url"`
+	SortOrder int       `gorm:"not null;default:0" json:"sort_order"`
+	IsPrimary bool      `gorm:"not null;default:false" json:"is_primary"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate generates a UUID before creating a new product image
+func (i *ProductImage) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName returns the table name for the ProductImage model
+func (ProductImage) TableName() string {
+	return "product_images"
 }
 
 // Category represents a product category
 type Category struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	TenantID  uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_category_slug;index" json:"tenant_id"`
-	UserID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
-	Name      string         `gorm:"size:255;not null" json:"name"`
-	Slug      string         `gorm:"size:255;uniqueIndex:idx_tenant_category_slug;not null" json:"slug"`
+	ID       uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_category_slug;index" json:"tenant_id"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name     string    `gorm:"size:255;not null" json:"name"`
+	Slug     string    `gorm:"size:255;uniqueIndex:idx_tenant_category_slug;not null" json:"slug"`
+
+	// DefaultQuantityAlert is the low-stock threshold given to new products in this
+	// category that don't set their own; a product-level alert always takes precedence
+	DefaultQuantityAlert int `gorm:"default:0" json:"default_quantity_alert"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// ProductCount is the number of non-deleted products using this record.
+	// It is read-only and only populated by list queries.
+	ProductCount *int64 `gorm:"->;-:migration" json:"product_count,omitempty"`
+
 	// Relationships
 	Tenant   Tenant    `gorm:"foreignKey:TenantID" json:"-"`
 	User     User      `gorm:"foreignKey:UserID" json:"-"`
@@ -161,6 +303,10 @@ type Unit struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// ProductCount is the number of non-deleted products using this record.
+	// It is read-only and only populated by list queries.
+	ProductCount *int64 `gorm:"->;-:migration" json:"product_count,omitempty"`
+
 	// Relationships
 	Tenant   Tenant    `gorm:"foreignKey:TenantID" json:"-"`
 	User     User      `gorm:"foreignKey:UserID" json:"-"`
@@ -0,0 +1,14 @@
+package entity
+
+import "testing"
+
+func TestAvailableCountsReservedStock(t *testing.T) {
+	// 10 on hand is above the alert level of 5, but only 4 are free once 6 are reserved
+	p := Product{Quantity: 10, ReservedQuantity: 6, QuantityAlert: 5}
+	if got := p.Available(); got != 4 {
+		t.Fatalf("Available() = %d, want 4", got)
+	}
+	if p.Available() > p.QuantityAlert {
+		t.Error("reserved stock should take the product to its alert level")
+	}
+}
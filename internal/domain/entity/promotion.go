@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/pkg/money"
+	"gorm.io/gorm"
+)
+
+// Promotion is a discount code that can be applied to an order at checkout
+type Promotion struct {
+	ID             uuid.UUID          `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID       uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_promotion_code;index" json:"tenant_id"`
+	UserID         uuid.UUID          `gorm:"type:uuid;not null;index" json:"user_id"`
+	CreatedByID    *uuid.UUID         `gorm:"type:uuid;column:created_by" json:"created_by,omitempty"`
+	UpdatedByID    *uuid.UUID         `gorm:"type:uuid;column:updated_by" json:"updated_by,omitempty"`
+	Code           string             `gorm:"size:50;uniqueIndex:idx_tenant_promotion_code;not null" json:"code"` // Stored upper-case
+	Description    *string            `gorm:"type:text" json:"description,omitempty"`
+	Type           enum.PromotionType `gorm:"size:20;not null" json:"type"`
+	Value          int64              `gorm:"not null" json:"-"`  // Cents for fixed, hundredths of a percent for percent, excluded from JSON
+	MinOrderAmount int64              `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
+	StartsAt       *time.Time         `json:"starts_at,omitempty"`
+	EndsAt         *time.Time         `json:"ends_at,omitempty"`
+	UsageLimit     *int               `json:"usage_limit,omitempty"` // Nil means unlimited
+	UsedCount      int                `gorm:"not null;default:0" json:"used_count"`
+	IsActive       bool               `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt     `gorm:"index" json:"-"`
+
+	// Relationships
+	Tenant    Tenant       `gorm:"foreignKey:TenantID" json:"-"`
+	User      User         `gorm:"foreignKey:UserID" json:"-"`
+	CreatedBy *UserSummary `gorm:"foreignKey:CreatedByID" json:"created_by_user,omitempty"`
+	UpdatedBy *UserSummary `gorm:"foreignKey:UpdatedByID" json:"updated_by_user,omitempty"`
+}
+
+// MarshalJSON custom marshaler to convert cents to decimal for API responses
+func (p Promotion) MarshalJSON() ([]byte, error) {
+	type Alias Promotion
+	return json.Marshal(&struct {
+		Alias
+		Value          float64 `json:"value"`
+		MinOrderAmount float64 `json:"min_order_amount"`
+		RemainingUses  *int    `json:"remaining_uses,omitempty"`
+	}{
+		Alias:          Alias(p),
+		Value:          float64(p.Value) / 100,
+		MinOrderAmount: float64(p.MinOrderAmount) / 100,
+		RemainingUses:  p.RemainingUses(),
+	})
+}
+
+// BeforeCreate generates a UUID before creating a new promotion
+func (p *Promotion) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName returns the table name for the Promotion model
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// RemainingUses returns how many more orders can use the promotion, or nil if it is unlimited
+func (p *Promotion) RemainingUses() *int {
+	if p.UsageLimit == nil {
+		return nil
+	}
+	remaining := max(*p.UsageLimit-p.UsedCount, 0)
+	return &remaining
+}
+
+// Discount returns the amount the promotion takes off total (in cents), never more than total
+func (p *Promotion) Discount(total int64) int64 {
+	var discount int64
+	if p.Type == enum.PromotionPercent {
+		discount = money.Percent(total, float64(p.Value)/100)
+	} else {
+		discount = p.Value
+	}
+	return min(discount, total)
+}
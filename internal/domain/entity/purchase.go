@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,9 +20,10 @@ type Purchase struct {
 	Date          time.Time           `gorm:"type:date;not null" json:"date"`
 	PurchaseNo    string              `gorm:"size:100;uniqueIndex:idx_tenant_purchase_no;not null" json:"purchase_no"`
 	Status        enum.PurchaseStatus `gorm:"default:0" json:"status"`
-	TotalAmount   float64             `gorm:"type:decimal(15,2);default:0" json:"total_amount"`
+	TotalAmount   int64               `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
 	TaxPercentage float64             `gorm:"type:decimal(5,2);default:0" json:"tax_percentage"`
-	TaxAmount     float64             `gorm:"type:decimal(15,2);default:0" json:"tax_amount"`
+	TaxAmount     int64               `gorm:"default:0" json:"-"`    // Stored in cents, excluded from JSON
+	ReceivedAt    *time.Time          `json:"received_at,omitempty"` // When the stock came in, i.e. the purchase was approved
 	CreatedAt     time.Time           `json:"created_at"`
 	UpdatedAt     time.Time           `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt      `gorm:"index" json:"-"`
@@ -30,11 +32,35 @@ type Purchase struct {
 	Tenant    Tenant           `gorm:"foreignKey:TenantID" json:"-"`
 	User      User             `gorm:"foreignKey:UserID" json:"-"`
 	Supplier  *Supplier        `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
-	CreatedBy *User            `gorm:"foreignKey:CreatedByID" json:"created_by_user,omitempty"`
-	UpdatedBy *User            `gorm:"foreignKey:UpdatedByID" json:"updated_by_user,omitempty"`
+	CreatedBy *UserSummary     `gorm:"foreignKey:CreatedByID" json:"created_by_user,omitempty"`
+	UpdatedBy *UserSummary     `gorm:"foreignKey:UpdatedByID" json:"updated_by_user,omitempty"`
 	Details   []PurchaseDetail `gorm:"foreignKey:PurchaseID" json:"details,omitempty"`
 }
 
+// MarshalJSON custom marshaler to convert cents to decimal for API responses
+func (p Purchase) MarshalJSON() ([]byte, error) {
+	type Alias Purchase
+	return json.Marshal(&struct {
+		Alias
+		TotalAmount float64 `json:"total_amount"`
+		TaxAmount   float64 `json:"tax_amount"`
+	}{
+		Alias:       Alias(p),
+		TotalAmount: p.GetTotalAmountDecimal(),
+		TaxAmount:   p.GetTaxAmountDecimal(),
+	})
+}
+
+// GetTotalAmountDecimal returns the total amount as a decimal
+func (p *Purchase) GetTotalAmountDecimal() float64 {
+	return float64(p.TotalAmount) / 100
+}
+
+// GetTaxAmountDecimal returns the tax amount as a decimal
+func (p *Purchase) GetTaxAmountDecimal() float64 {
+	return float64(p.TaxAmount) / 100
+}
+
 // BeforeCreate generates a UUID before creating a new purchase
 func (p *Purchase) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == uuid.Nil {
@@ -50,21 +76,36 @@ func (Purchase) TableName() string {
 
 // PurchaseDetail represents a line item in a purchase
 type PurchaseDetail struct {
-	ID         uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	PurchaseID uuid.UUID      `gorm:"type:uuid;not null;index" json:"purchase_id"`
-	ProductID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"product_id"`
-	Quantity   int            `gorm:"not null" json:"quantity"`
-	UnitCost   int64          `gorm:"not null" json:"unit_cost"` // Stored in cents
-	Total      int64          `gorm:"not null" json:"total"`     // Stored in cents
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	PurchaseID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"purchase_id"`
+	ProductID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"product_id"`
+	Quantity      int            `gorm:"not null" json:"quantity"`                  // In the product's purchase unit
+	PiecesPerUnit int            `gorm:"not null;default:1" json:"pieces_per_unit"` // Snapshot of Product.PiecesPerUnit
+	UnitCost      int64          `gorm:"not null" json:"-"`                         // Stored in cents, per purchase unit, excluded from JSON
+	Total         int64          `gorm:"not null" json:"-"`                         // Stored in cents, excluded from JSON
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
 	Purchase Purchase `gorm:"foreignKey:PurchaseID" json:"-"`
 	Product  Product  `gorm:"foreignKey:ProductID" json:"product,omitempty"`
 }
 
+// MarshalJSON custom marshaler to convert cents to decimal for API responses
+func (pd PurchaseDetail) MarshalJSON() ([]byte, error) {
+	type Alias PurchaseDetail
+	return json.Marshal(&struct {
+		Alias
+		UnitCost float64 `json:"unit_cost"`
+		Total    float64 `json:"total"`
+	}{
+		Alias:    Alias(pd),
+		UnitCost: float64(pd.UnitCost) / 100,
+		Total:    float64(pd.Total) / 100,
+	})
+}
+
 // BeforeCreate generates a UUID before creating a new purchase detail
 func (pd *PurchaseDetail) BeforeCreate(tx *gorm.DB) error {
 	if pd.ID == uuid.Nil {
@@ -1,39 +1,86 @@
 package entity
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/pkg/money"
 	"gorm.io/gorm"
 )
 
 // Quotation represents a price quotation for a customer
 type Quotation struct {
-	ID                 uuid.UUID            `gorm:"type:uuid;primary_key" json:"id"`
-	TenantID           uuid.UUID            `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_quotation_reference;index" json:"tenant_id"`
-	UserID             uuid.UUID            `gorm:"type:uuid;not null;index" json:"user_id"`
-	CustomerID         *uuid.UUID           `gorm:"type:uuid;index" json:"customer_id,omitempty"`
-	Date               time.Time            `gorm:"type:date;not null" json:"date"`
-	Reference          string               `gorm:"size:100;uniqueIndex:idx_tenant_quotation_reference;not null" json:"reference"`
-	CustomerName       string               `gorm:"size:255" json:"customer_name"`
-	TaxPercentage      float64              `gorm:"type:decimal(5,2);default:0" json:"tax_percentage"`
-	TaxAmount          float64              `gorm:"type:decimal(15,2);default:0" json:"tax_amount"`
-	DiscountPercentage float64              `gorm:"type:decimal(5,2);default:0" json:"discount_percentage"`
-	DiscountAmount     float64              `gorm:"type:decimal(15,2);default:0" json:"discount_amount"`
-	ShippingAmount     float64              `gorm:"type:decimal(15,2);default:0" json:"shipping_amount"`
-	TotalAmount        float64              `gorm:"type:decimal(15,2);default:0" json:"total_amount"`
-	Status             enum.QuotationStatus `gorm:"default:0" json:"status"`
-	Note               *string              `gorm:"type:text" json:"note,omitempty"`
-	CreatedAt          time.Time            `json:"created_at"`
-	UpdatedAt          time.Time            `json:"updated_at"`
-	DeletedAt          gorm.DeletedAt       `gorm:"index" json:"-"`
+	ID                 uuid.UUID             `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID           uuid.UUID             `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_quotation_reference;index" json:"tenant_id"`
+	UserID             uuid.UUID             `gorm:"type:uuid;not null;index" json:"user_id"`
+	CustomerID         *uuid.UUID            `gorm:"type:uuid;index" json:"customer_id,omitempty"`
+	Date               time.Time             `gorm:"type:date;not null" json:"date"`
+	Reference          string                `gorm:"size:100;uniqueIndex:idx_tenant_quotation_reference;not null" json:"reference"`
+	CustomerName       string                `gorm:"size:255" json:"customer_name"`
+	TaxMode            enum.QuotationTaxMode `gorm:"size:20;default:'flat'" json:"tax_mode"`
+	TaxPercentage      float64               `gorm:"type:decimal(5,2);default:0" json:"tax_percentage"`
+	TaxAmount          int64                 `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
+	DiscountPercentage float64               `gorm:"type:decimal(5,2);default:0" json:"discount_percentage"`
+	DiscountAmount     int64                 `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
+	ShippingAmount     int64                 `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
+	TotalAmount        int64                 `gorm:"default:0" json:"-"` // Stored in cents, excluded from JSON
+	Status             enum.QuotationStatus  `gorm:"default:0" json:"status"`
+	Note               *string               `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt          time.Time             `json:"created_at"`
+	UpdatedAt          time.Time             `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt        `gorm:"index" json:"-"`
 
 	// Relationships
 	Tenant   Tenant            `gorm:"foreignKey:TenantID" json:"-"`
 	User     User              `gorm:"foreignKey:UserID" json:"-"`
 	Customer *Customer         `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
 	Details  []QuotationDetail `gorm:"foreignKey:QuotationID" json:"details,omitempty"`
+
+	// Computed on create/update when a stock check is requested; not persisted
+	StockWarnings []StockWarning `gorm:"-" json:"stock_warnings,omitempty"`
+}
+
+// StockWarning flags a quoted product whose quantity exceeds the current stock
+type StockWarning struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Requested   int       `json:"requested"`
+	Available   int       `json:"available"`
+}
+
+// MarshalJSON custom marshaler to convert cents to decimal for API responses
+func (q Quotation) MarshalJSON() ([]byte, error) {
+	type Alias Quotation
+	return json.Marshal(&struct {
+		Alias
+		TaxAmount      float64 `json:"tax_amount"`
+		DiscountAmount float64 `json:"discount_amount"`
+		ShippingAmount float64 `json:"shipping_amount"`
+		TotalAmount    float64 `json:"total_amount"`
+	}{
+		Alias:          Alias(q),
+		TaxAmount:      q.GetTaxAmountDecimal(),
+		DiscountAmount: float64(q.DiscountAmount) / 100,
+		ShippingAmount: float64(q.ShippingAmount) / 100,
+		TotalAmount:    q.GetTotalAmountDecimal(),
+	})
+}
+
+// GetTaxAmountDecimal returns the tax amount as a decimal
+func (q *Quotation) GetTaxAmountDecimal() float64 {
+	return float64(q.TaxAmount) / 100
+}
+
+// GetTotalAmountDecimal returns the total amount as a decimal
+func (q *Quotation) GetTotalAmountDecimal() float64 {
+	return float64(q.TotalAmount) / 100
+}
+
+// SetShippingAmountFromDecimal sets the shipping amount from a decimal value
+func (q *Quotation) SetShippingAmountFromDecimal(amount float64) {
+	q.ShippingAmount = money.ToCents(amount)
 }
 
 // BeforeCreate generates a UUID and reference before creating a new quotation
@@ -57,8 +104,8 @@ type QuotationDetail struct {
 	ProductName string         `gorm:"size:255" json:"product_name"`
 	ProductCode string         `gorm:"size:100" json:"product_code"`
 	Quantity    int            `gorm:"not null" json:"quantity"`
-	UnitPrice   float64        `gorm:"type:decimal(15,2);not null" json:"unit_price"`
-	SubTotal    float64        `gorm:"type:decimal(15,2);not null" json:"sub_total"`
+	UnitPrice   int64          `gorm:"not null" json:"-"` // Stored in cents, excluded from JSON
+	SubTotal    int64          `gorm:"not null" json:"-"` // Stored in cents, excluded from JSON
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -68,6 +115,30 @@ type QuotationDetail struct {
 	Product   Product   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
 }
 
+// MarshalJSON custom marshaler to convert cents to decimal for API responses
+func (qd QuotationDetail) MarshalJSON() ([]byte, error) {
+	type Alias QuotationDetail
+	return json.Marshal(&struct {
+		Alias
+		UnitPrice float64 `json:"unit_price"`
+		SubTotal  float64 `json:"sub_total"`
+	}{
+		Alias:     Alias(qd),
+		UnitPrice: qd.GetUnitPriceDecimal(),
+		SubTotal:  float64(qd.SubTotal) / 100,
+	})
+}
+
+// GetUnitPriceDecimal returns the unit price as a decimal
+func (qd *QuotationDetail) GetUnitPriceDecimal() float64 {
+	return float64(qd.UnitPrice) / 100
+}
+
+// SetUnitPriceFromDecimal sets the unit price from a decimal value
+func (qd *QuotationDetail) SetUnitPriceFromDecimal(price float64) {
+	qd.UnitPrice = money.ToCents(price)
+}
+
 // BeforeCreate generates a UUID before creating a new quotation detail
 func (qd *QuotationDetail) BeforeCreate(tx *gorm.DB) error {
 	if qd.ID == uuid.Nil {
@@ -14,6 +14,9 @@ type ReceiptItem struct {
 	Quantity  int     `json:"quantity"`
 	UnitPrice float64 `json:"unit_price"`
 	Total     float64 `json:"total"`
+	// Tax on the line; TaxInclusive means it is already part of Total
+	TaxAmount    float64 `json:"tax_amount,omitempty"`
+	TaxInclusive bool    `json:"tax_inclusive,omitempty"`
 }
 
 // Receipt is a value object representing a printable receipt.
@@ -28,7 +31,21 @@ type Receipt struct {
 	Items       []ReceiptItem `json:"items"`
 	SubTotal    float64       `json:"sub_total"`
 	VAT         float64       `json:"vat"`
+	TaxExempt   bool          `json:"tax_exempt,omitempty"` // The customer was VAT-exempt; VAT is zero
+	PromoCode   string        `json:"promo_code,omitempty"`
+	Promo       float64       `json:"promo_discount,omitempty"` // Promotion discount taken off Total
+	Discount    float64       `json:"discount,omitempty"`       // Redeemed loyalty points taken off Total
+	Rounding    float64       `json:"rounding,omitempty"`       // Cash rounding included in Total
 	Total       float64       `json:"total"`
 	Paid        float64       `json:"paid"`
 	Due         float64       `json:"due"`
+	Change      float64       `json:"change,omitempty"`
+
+	// Presentation settings taken from the tenant; empty values use the defaults
+	Currency string `json:"currency,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	TaxLabel string `json:"tax_label,omitempty"`
+	Footer   string `json:"footer,omitempty"`
+	// PaperWidth is the paper width in mm (58 or 80); 0 means 58mm
+	PaperWidth int `json:"paper_width,omitempty"`
 }
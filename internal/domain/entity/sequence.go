@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sequence names used for per-tenant document numbering
+const (
+	SequenceOrder     = "order"
+	SequencePurchase  = "purchase"
+	SequenceQuotation = "quotation"
+)
+
+// Sequence is a per-tenant monotonic counter used to number documents
+// (invoices, purchases, quotations). One row exists per tenant and sequence name.
+type Sequence struct {
+	TenantID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name      string    `gorm:"size:50;primaryKey"`
+	Value     int64     `gorm:"not null;default:0"` // Last value handed out
+	UpdatedAt time.Time
+}
+
+// TableName returns the table name for Sequence
+func (Sequence) TableName() string {
+	return "sequences"
+}
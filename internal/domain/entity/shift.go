@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Shift is a cashier's session at the till, from opening the cash drawer with a float
+// to counting it at close. Orders the cashier creates in between belong to the shift.
+type Shift struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_user_open_shift;index" json:"tenant_id"`
+	// A cashier has at most one open shift
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_user_open_shift,where:closed_at IS NULL;index" json:"user_id"`
+	OpenedAt     time.Time  `gorm:"not null" json:"opened_at"`
+	ClosedAt     *time.Time `gorm:"index" json:"closed_at,omitempty"`
+	OpeningFloat int64      `gorm:"default:0" json:"-"` // Cash in the drawer at open, stored in cents, excluded from JSON
+	ExpectedCash *int64     `json:"-"`                  // Float plus cash taken, worked out at close, stored in cents, excluded from JSON
+	CountedCash  *int64     `json:"-"`                  // Cash counted in the drawer at close, stored in cents, excluded from JSON
+	OpeningNotes *string    `gorm:"type:text" json:"opening_notes,omitempty"`
+	ClosingNotes *string    `gorm:"type:text" json:"closing_notes,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Relationships
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+	User   *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// MarshalJSON custom marshaler to convert cents to decimal for API responses
+func (s Shift) MarshalJSON() ([]byte, error) {
+	type Alias Shift
+	return json.Marshal(&struct {
+		Alias
+		OpeningFloat float64  `json:"opening_float"`
+		ExpectedCash *float64 `json:"expected_cash,omitempty"`
+		CountedCash  *float64 `json:"counted_cash,omitempty"`
+		Variance     *float64 `json:"variance,omitempty"` // Counted minus expected; negative is a shortage
+		IsOpen       bool     `json:"is_open"`
+	}{
+		Alias:        Alias(s),
+		OpeningFloat: float64(s.OpeningFloat) / 100,
+		ExpectedCash: centsPtrToDecimal(s.ExpectedCash),
+		CountedCash:  centsPtrToDecimal(s.CountedCash),
+		Variance:     centsPtrToDecimal(s.Variance()),
+		IsOpen:       s.IsOpen(),
+	})
+}
+
+// IsOpen reports whether the shift has not been closed yet
+func (s *Shift) IsOpen() bool {
+	return s.ClosedAt == nil
+}
+
+// Variance is the counted cash minus the expected cash in cents, or nil while open
+func (s *Shift) Variance() *int64 {
+	if s.ExpectedCash == nil || s.CountedCash == nil {
+		return nil
+	}
+	variance := *s.CountedCash - *s.ExpectedCash
+	return &variance
+}
+
+// centsPtrToDecimal converts an optional amount in cents to currency units
+func centsPtrToDecimal(cents *int64) *float64 {
+	if cents == nil {
+		return nil
+	}
+	value := float64(*cents) / 100
+	return &value
+}
+
+// BeforeCreate generates a UUID before creating a new shift
+func (s *Shift) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName returns the table name for the Shift model
+func (Shift) TableName() string {
+	return "shifts"
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,8 @@ type Supplier struct {
 	ID            uuid.UUID         `gorm:"type:uuid;primary_key" json:"id"`
 	TenantID      uuid.UUID         `gorm:"type:uuid;not null;index" json:"tenant_id"`
 	UserID        uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	CreatedByID   *uuid.UUID        `gorm:"type:uuid;column:created_by" json:"created_by,omitempty"`
+	UpdatedByID   *uuid.UUID        `gorm:"type:uuid;column:updated_by" json:"updated_by,omitempty"`
 	Name          string            `gorm:"size:255;not null" json:"name"`
 	Email         *string           `gorm:"size:255" json:"email,omitempty"`
 	Phone         *string           `gorm:"size:50" json:"phone,omitempty"`
@@ -29,9 +32,11 @@ type Supplier struct {
 	DeletedAt     gorm.DeletedAt    `gorm:"index" json:"-"`
 
 	// Relationships
-	Tenant    Tenant     `gorm:"foreignKey:TenantID" json:"-"`
-	User      User       `gorm:"foreignKey:UserID" json:"-"`
-	Purchases []Purchase `gorm:"foreignKey:SupplierID" json:"-"`
+	Tenant    Tenant       `gorm:"foreignKey:TenantID" json:"-"`
+	User      User         `gorm:"foreignKey:UserID" json:"-"`
+	CreatedBy *UserSummary `gorm:"foreignKey:CreatedByID" json:"created_by_user,omitempty"`
+	UpdatedBy *UserSummary `gorm:"foreignKey:UpdatedByID" json:"updated_by_user,omitempty"`
+	Purchases []Purchase   `gorm:"foreignKey:SupplierID" json:"-"`
 }
 
 // BeforeCreate generates a UUID before creating a new supplier
@@ -46,3 +51,46 @@ func (s *Supplier) BeforeCreate(tx *gorm.DB) error {
 func (Supplier) TableName() string {
 	return "suppliers"
 }
+
+// SupplierProduct records what a supplier last charged for a product. It is
+// refreshed whenever a purchase from the supplier is created or approved.
+type SupplierProduct struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	TenantID        uuid.UUID `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	SupplierID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_supplier_product" json:"supplier_id"`
+	ProductID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_supplier_product;index" json:"product_id"`
+	LastCost        int64     `gorm:"not null" json:"last_cost"` // Stored in cents
+	LastPurchasedAt time.Time `gorm:"not null" json:"last_purchased_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	// Relationships
+	Tenant   Tenant   `gorm:"foreignKey:TenantID" json:"-"`
+	Supplier Supplier `gorm:"foreignKey:SupplierID" json:"-"`
+	Product  *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+// MarshalJSON custom marshaler to convert cents to decimal for API responses
+func (sp SupplierProduct) MarshalJSON() ([]byte, error) {
+	type Alias SupplierProduct
+	return json.Marshal(&struct {
+		Alias
+		LastCost float64 `json:"last_cost"`
+	}{
+		Alias:    Alias(sp),
+		LastCost: float64(sp.LastCost) / 100,
+	})
+}
+
+// BeforeCreate generates a UUID before creating a new supplier product
+func (sp *SupplierProduct) BeforeCreate(tx *gorm.DB) error {
+	if sp.ID == uuid.Nil {
+		sp.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName returns the table name for the SupplierProduct model
+func (SupplierProduct) TableName() string {
+	return "supplier_products"
+}
@@ -4,9 +4,11 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/pkg/money"
 	"gorm.io/gorm"
 )
 
@@ -45,6 +47,7 @@ type MemberUser struct {
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 	Email     string    `json:"email"`
+	Photo     *string   `json:"photo,omitempty"`
 }
 
 // TenantMembership represents a user's membership in a tenant
@@ -70,6 +73,7 @@ func (tm *TenantMembership) PopulateUserDetails() {
 			FirstName: tm.User.FirstName,
 			LastName:  tm.User.LastName,
 			Email:     tm.User.Email,
+			Photo:     tm.User.Photo,
 		}
 	}
 }
@@ -94,10 +98,30 @@ type TenantSettings struct {
 	DateFormat string `json:"date_format,omitempty"`
 
 	// Business Configuration
-	TaxRate         float64 `json:"tax_rate,omitempty"`
-	TaxLabel        string  `json:"tax_label,omitempty"`
-	InvoicePrefix   string  `json:"invoice_prefix,omitempty"`
-	QuotationPrefix string  `json:"quotation_prefix,omitempty"`
+	TaxRate         *float64 `json:"tax_rate,omitempty"` // Percent; nil uses DefaultVATRate, 0 charges no VAT
+	TaxLabel        string   `json:"tax_label,omitempty"`
+	InvoicePrefix   string   `json:"invoice_prefix,omitempty"`
+	PurchasePrefix  string   `json:"purchase_prefix,omitempty"`
+	QuotationPrefix string   `json:"quotation_prefix,omitempty"`
+	KRAPin          string   `json:"kra_pin,omitempty"` // Printed as the Tax ID on receipts
+
+	// CancellationWindowHours is how long after creation an order can be cancelled
+	// by its owner; older orders need the override permission. 0 means no limit.
+	CancellationWindowHours int `json:"cancellation_window_hours,omitempty"`
+
+	// CashRounding is the increment cash order totals are rounded to, in currency units
+	// (e.g. 0.5 or 1). 0 charges cash orders to the cent.
+	CashRounding float64 `json:"cash_rounding,omitempty"`
+
+	// Loyalty points, in currency units: customers earn a point for every
+	// LoyaltyEarnAmount of a completed order, and each redeemed point takes
+	// LoyaltyPointValue off an order. 0 turns earning or redeeming off.
+	LoyaltyEarnAmount float64 `json:"loyalty_earn_amount,omitempty"`
+	LoyaltyPointValue float64 `json:"loyalty_point_value,omitempty"`
+
+	// Receipt printing
+	ReceiptFooter     string `json:"receipt_footer,omitempty"`      // Closing line, defaults to a thank-you note
+	ReceiptPaperWidth int    `json:"receipt_paper_width,omitempty"` // Paper width in mm: 58 or 80
 
 	// Payment Integrations
 	Mpesa    *MpesaIntegration    `json:"mpesa,omitempty"`
@@ -111,6 +135,9 @@ type TenantSettings struct {
 
 	// Feature Flags
 	Features TenantFeatures `json:"features,omitempty"`
+	// FeatureFlags turns integrations on or off for the tenant, by flag name (see
+	// FeatureETIMS etc.). Flags left out keep their default.
+	FeatureFlags map[string]bool `json:"feature_flags,omitempty"`
 }
 
 // Scan implements the sql.Scanner interface for TenantSettings
@@ -171,18 +198,130 @@ type PaystackIntegration struct {
 	SecretKey string `json:"secret_key"`
 }
 
+// DefaultVATRate is the VAT rate used when a tenant has none configured (16% for Kenya)
+const DefaultVATRate = 0.16
+
+// VATRate returns the tenant's VAT rate as a fraction (0.16 for 16%). An unset rate
+// falls back to DefaultVATRate; a rate set to 0 means no VAT.
+func (ts TenantSettings) VATRate() float64 {
+	return ts.VATPercent() / 100
+}
+
+// VATPercent returns the tenant's VAT rate as a percentage (16 for 16%)
+func (ts TenantSettings) VATPercent() float64 {
+	if ts.TaxRate == nil {
+		return DefaultVATRate * 100
+	}
+	return *ts.TaxRate
+}
+
+// CashRoundingCents returns the cash rounding increment in cents
+func (ts TenantSettings) CashRoundingCents() int64 {
+	return money.ToCents(ts.CashRounding)
+}
+
+// LoyaltyPointsEarned returns the loyalty points earned by an order total in cents
+func (ts TenantSettings) LoyaltyPointsEarned(total int64) int64 {
+	per := money.ToCents(ts.LoyaltyEarnAmount)
+	if per <= 0 || total <= 0 {
+		return 0
+	}
+	return total / per
+}
+
+// LoyaltyPointValueCents returns what one redeemed loyalty point is worth, in cents
+func (ts TenantSettings) LoyaltyPointValueCents() int64 {
+	return money.ToCents(ts.LoyaltyPointValue)
+}
+
+// Document number prefixes used when a tenant has none configured
+const (
+	DefaultInvoicePrefix   = "INV-"
+	DefaultPurchasePrefix  = "PUR-"
+	DefaultQuotationPrefix = "QT-"
+)
+
+// DocumentNumber formats the nth document of a sequence (SequenceOrder,
+// SequencePurchase or SequenceQuotation) with the tenant's prefix for it, e.g.
+// "INV-000042". Unset prefixes fall back to the defaults.
+func (ts TenantSettings) DocumentNumber(sequence string, n int64) string {
+	var prefix string
+	switch sequence {
+	case SequenceOrder:
+		prefix = ts.InvoicePrefix
+		if prefix == "" {
+			prefix = DefaultInvoicePrefix
+		}
+	case SequencePurchase:
+		prefix = ts.PurchasePrefix
+		if prefix == "" {
+			prefix = DefaultPurchasePrefix
+		}
+	case SequenceQuotation:
+		prefix = ts.QuotationPrefix
+		if prefix == "" {
+			prefix = DefaultQuotationPrefix
+		}
+	}
+	return fmt.Sprintf("%s%06d", prefix, n)
+}
+
+// Feature flag names for TenantSettings.FeatureFlags
+const (
+	FeatureETIMS    = "etims"    // KRA eTIMS invoice submission
+	FeatureMpesa    = "mpesa"    // M-Pesa STK Push payments
+	FeaturePrinting = "printing" // Thermal receipt printing
+)
+
+// defaultFeatureFlags holds every known feature flag and whether it is on for tenants
+// that have not set it
+var defaultFeatureFlags = map[string]bool{
+	FeatureETIMS:    false,
+	FeatureMpesa:    true,
+	FeaturePrinting: true,
+}
+
+// IsKnownFeature reports whether name is a feature flag
+func IsKnownFeature(name string) bool {
+	_, ok := defaultFeatureFlags[name]
+	return ok
+}
+
+// FeatureEnabled reports whether a feature is on for the tenant. Unknown features are off.
+func (ts TenantSettings) FeatureEnabled(name string) bool {
+	if on, ok := ts.FeatureFlags[name]; ok && IsKnownFeature(name) {
+		return on
+	}
+	return defaultFeatureFlags[name]
+}
+
+// EffectiveFeatureFlags returns every known feature flag with the tenant's value or
+// the default
+func (ts TenantSettings) EffectiveFeatureFlags() map[string]bool {
+	flags := make(map[string]bool, len(defaultFeatureFlags))
+	for name := range defaultFeatureFlags {
+		flags[name] = ts.FeatureEnabled(name)
+	}
+	return flags
+}
+
 // DefaultTenantSettings returns default settings for new tenants
 func DefaultTenantSettings() TenantSettings {
+	defaultTaxRate := DefaultVATRate * 100
 	return TenantSettings{
 		Currency:           "KES",
 		Timezone:           "Africa/Nairobi",
 		Locale:             "en-KE",
 		DateFormat:         "DD/MM/YYYY",
-		TaxRate:            16.0,
+		TaxRate:            &defaultTaxRate,
 		TaxLabel:           "VAT",
-		InvoicePrefix:      "INV-",
-		QuotationPrefix:    "QUO-",
+		InvoicePrefix:      DefaultInvoicePrefix,
+		PurchasePrefix:     DefaultPurchasePrefix,
+		QuotationPrefix:    DefaultQuotationPrefix,
 		EmailNotifications: true,
+
+		CancellationWindowHours: 24,
+		ReceiptPaperWidth:       58,
 		Features: TenantFeatures{
 			EnableInvoicing:  true,
 			EnableQuotations: true,
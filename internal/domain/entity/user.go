@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,12 +19,14 @@ type User struct {
 	Password        string         `gorm:"size:255" json:"-"`
 	Provider        string         `gorm:"size:50;default:'local'" json:"provider"`
 	ProviderID      *string        `gorm:"size:255" json:"-"`
+	GoogleID        *string        `gorm:"size:255;uniqueIndex" json:"-"` // Linked Google account, also set on password accounts
 	Photo           *string        `gorm:"size:255" json:"photo,omitempty"`
 	StoreName       *string        `gorm:"size:255" json:"store_name,omitempty"`
 	StoreAddress    *string        `gorm:"type:text" json:"store_address,omitempty"`
 	StorePhone      *string        `gorm:"size:50" json:"store_phone,omitempty"`
 	StoreEmail      *string        `gorm:"size:255" json:"store_email,omitempty"`
 	EmailVerifiedAt *time.Time     `json:"email_verified_at,omitempty"`
+	IsActive        bool           `gorm:"not null;default:true" json:"is_active"` // Deactivated users cannot sign in; their records are kept
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -50,6 +54,29 @@ func (User) TableName() string {
 	return "users"
 }
 
+// UserSummary is the part of a user shown on the records they created or last changed
+type UserSummary struct {
+	ID        uuid.UUID
+	FirstName string
+	LastName  string
+}
+
+// TableName returns the table name for the UserSummary model
+func (UserSummary) TableName() string {
+	return "users"
+}
+
+// MarshalJSON renders the summary as the user's ID and full name
+func (u UserSummary) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID   uuid.UUID `json:"id"`
+		Name string    `json:"name"`
+	}{
+		ID:   u.ID,
+		Name: strings.TrimSpace(u.FirstName + " " + u.LastName),
+	})
+}
+
 // Role represents a role in the RBAC system
 type Role struct {
 	ID          uint         `gorm:"primary_key" json:"id"`
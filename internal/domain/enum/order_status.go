@@ -14,8 +14,23 @@ const (
 	OrderStatusCancel   OrderStatus = 2
 )
 
+// OrderStatuses lists every order status in value order
+func OrderStatuses() []OrderStatus {
+	return []OrderStatus{OrderStatusPending, OrderStatusComplete, OrderStatusCancel}
+}
+
+var orderStatusLabels = [...]string{"Pending", "Complete", "Cancel"}
+
+// IsValid reports whether s is a known order status
+func (s OrderStatus) IsValid() bool {
+	return s >= 0 && int(s) < len(orderStatusLabels)
+}
+
 func (s OrderStatus) String() string {
-	return [...]string{"Pending", "Complete", "Cancel"}[s]
+	if !s.IsValid() {
+		return "Unknown"
+	}
+	return orderStatusLabels[s]
 }
 
 func (s OrderStatus) MarshalJSON() ([]byte, error) {
@@ -40,6 +55,9 @@ func (s *OrderStatus) UnmarshalJSON(data []byte) error {
 		*s = OrderStatusComplete
 	case "Cancel":
 		*s = OrderStatusCancel
+	default:
+		// Unknown labels become an invalid status so IsValid rejects them
+		*s = OrderStatus(-1)
 	}
 	return nil
 }
@@ -0,0 +1,42 @@
+package enum
+
+import (
+	"database/sql/driver"
+)
+
+// PromotionType represents how a promotion's value is applied to an order
+type PromotionType string
+
+const (
+	// PromotionPercent takes a percentage off the order total
+	PromotionPercent PromotionType = "percent"
+	// PromotionFixed takes a fixed amount off the order total
+	PromotionFixed PromotionType = "fixed"
+)
+
+func (t PromotionType) String() string {
+	return string(t)
+}
+
+// IsValid reports whether t is a known promotion type
+func (t PromotionType) IsValid() bool {
+	return t == PromotionPercent || t == PromotionFixed
+}
+
+func (t PromotionType) Value() (driver.Value, error) {
+	return string(t), nil
+}
+
+func (t *PromotionType) Scan(value interface{}) error {
+	if value == nil {
+		*t = PromotionPercent
+		return nil
+	}
+	switch v := value.(type) {
+	case string:
+		*t = PromotionType(v)
+	case []byte:
+		*t = PromotionType(string(v))
+	}
+	return nil
+}
@@ -13,8 +13,23 @@ const (
 	PurchaseStatusApproved PurchaseStatus = 1
 )
 
+// PurchaseStatuses lists every purchase status in value order
+func PurchaseStatuses() []PurchaseStatus {
+	return []PurchaseStatus{PurchaseStatusPending, PurchaseStatusApproved}
+}
+
+var purchaseStatusLabels = [...]string{"Pending", "Approved"}
+
+// IsValid reports whether s is a known purchase status
+func (s PurchaseStatus) IsValid() bool {
+	return s >= 0 && int(s) < len(purchaseStatusLabels)
+}
+
 func (s PurchaseStatus) String() string {
-	return [...]string{"Pending", "Approved"}[s]
+	if !s.IsValid() {
+		return "Unknown"
+	}
+	return purchaseStatusLabels[s]
 }
 
 func (s PurchaseStatus) MarshalJSON() ([]byte, error) {
@@ -36,6 +51,9 @@ func (s *PurchaseStatus) UnmarshalJSON(data []byte) error {
 		*s = PurchaseStatusPending
 	case "Approved":
 		*s = PurchaseStatusApproved
+	default:
+		// Unknown labels become an invalid status so IsValid rejects them
+		*s = PurchaseStatus(-1)
 	}
 	return nil
 }
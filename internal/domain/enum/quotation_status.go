@@ -14,8 +14,23 @@ const (
 	QuotationStatusCanceled QuotationStatus = 2
 )
 
+// QuotationStatuses lists every quotation status in value order
+func QuotationStatuses() []QuotationStatus {
+	return []QuotationStatus{QuotationStatusPending, QuotationStatusSent, QuotationStatusCanceled}
+}
+
+var quotationStatusLabels = [...]string{"Pending", "Sent", "Canceled"}
+
+// IsValid reports whether s is a known quotation status
+func (s QuotationStatus) IsValid() bool {
+	return s >= 0 && int(s) < len(quotationStatusLabels)
+}
+
 func (s QuotationStatus) String() string {
-	return [...]string{"Pending", "Sent", "Canceled"}[s]
+	if !s.IsValid() {
+		return "Unknown"
+	}
+	return quotationStatusLabels[s]
 }
 
 func (s QuotationStatus) MarshalJSON() ([]byte, error) {
@@ -39,6 +54,9 @@ func (s *QuotationStatus) UnmarshalJSON(data []byte) error {
 		*s = QuotationStatusSent
 	case "Canceled":
 		*s = QuotationStatusCanceled
+	default:
+		// Unknown labels become an invalid status so IsValid rejects them
+		*s = QuotationStatus(-1)
 	}
 	return nil
 }
@@ -0,0 +1,42 @@
+package enum
+
+import (
+	"database/sql/driver"
+)
+
+// QuotationTaxMode represents how tax is computed on a quotation
+type QuotationTaxMode string
+
+const (
+	// QuotationTaxFlat applies the quotation's tax percentage to the whole subtotal
+	QuotationTaxFlat QuotationTaxMode = "flat"
+	// QuotationTaxPerProduct applies VAT per line using each product's TaxType, as orders do
+	QuotationTaxPerProduct QuotationTaxMode = "per_product"
+)
+
+func (m QuotationTaxMode) String() string {
+	return string(m)
+}
+
+// IsValid reports whether m is a known tax mode
+func (m QuotationTaxMode) IsValid() bool {
+	return m == QuotationTaxFlat || m == QuotationTaxPerProduct
+}
+
+func (m QuotationTaxMode) Value() (driver.Value, error) {
+	return string(m), nil
+}
+
+func (m *QuotationTaxMode) Scan(value interface{}) error {
+	if value == nil {
+		*m = QuotationTaxFlat
+		return nil
+	}
+	switch v := value.(type) {
+	case string:
+		*m = QuotationTaxMode(v)
+	case []byte:
+		*m = QuotationTaxMode(string(v))
+	}
+	return nil
+}
@@ -13,6 +13,11 @@ const (
 	TaxTypeInclusive TaxType = 1
 )
 
+// TaxTypes lists every tax type in value order
+func TaxTypes() []TaxType {
+	return []TaxType{TaxTypeExclusive, TaxTypeInclusive}
+}
+
 func (t TaxType) String() string {
 	names := [...]string{"Exclusive", "Inclusive"}
 	if int(t) < 0 || int(t) >= len(names) {
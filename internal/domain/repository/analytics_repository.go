@@ -54,6 +54,84 @@ type DailySalesReportResult struct {
 	OrdersCount  int
 }
 
+// SalesGranularity is the bucket size of a sales series; the values are Postgres date_trunc units
+type SalesGranularity string
+
+const (
+	GranularityDay   SalesGranularity = "day"
+	GranularityWeek  SalesGranularity = "week" // Weeks start on Monday
+	GranularityMonth SalesGranularity = "month"
+)
+
+// SalesSeriesPoint holds revenue, gross profit and order count for one time bucket
+type SalesSeriesPoint struct {
+	Bucket      time.Time
+	Revenue     float64
+	Profit      float64
+	OrdersCount int
+}
+
+// SalesBucketResult holds completed-order count and revenue for one hour of the day
+// (0-23) or day of the week (0 = Sunday)
+type SalesBucketResult struct {
+	Bucket      int
+	OrdersCount int
+	Revenue     float64
+}
+
+// ShiftSalesResult holds a shift's orders with one payment type, either cancelled or
+// not. Amounts are in cents.
+type ShiftSalesResult struct {
+	PaymentType string // Lower-cased; empty if the order had none
+	Cancelled   bool
+	OrdersCount int
+	Total       int64
+	VAT         int64
+	Received    int64 // Paid minus change given, i.e. money kept
+	Due         int64
+}
+
+// ShiftPaymentResult holds the money received with one payment type while a shift was
+// open, on its own orders or towards the dues of earlier ones. Amounts are in cents.
+type ShiftPaymentResult struct {
+	PaymentType   string // Lower-cased; empty if the payment had none
+	PaymentsCount int
+	Amount        int64 // Received less change given
+}
+
+// InventoryValuationResult holds the stock value of one category (or all products)
+type InventoryValuationResult struct {
+	CategoryID   uuid.UUID
+	CategoryName string
+	ProductCount int
+	Quantity     int64
+	CostValue    float64 // SUM(quantity * buying_price)
+	RetailValue  float64 // SUM(quantity * selling_price)
+}
+
+// SlowMoverResult holds a product in stock that has sold little or nothing recently
+type SlowMoverResult struct {
+	ProductID    uuid.UUID
+	ProductName  string
+	ProductCode  string
+	Quantity     int
+	QuantitySold int        // Units sold within the window
+	LastSoldAt   *time.Time // nil if the product has never sold
+	StockValue   float64    // quantity * buying_price
+}
+
+// SupplierPerformanceResult holds the purchases from one supplier (or from none) dated
+// in a report window
+type SupplierPerformanceResult struct {
+	SupplierID      *uuid.UUID // nil for purchases without a supplier
+	SupplierName    string
+	PurchasesCount  int
+	ReceivedCount   int
+	AvgLeadTimeDays *float64 // Days from purchase date to receipt; nil if nothing was received
+	OrderedAmount   float64  // Total of every purchase
+	ReceivedAmount  float64  // Total of the received purchases, i.e. the spend
+}
+
 // AnalyticsRepository defines interface for analytics/aggregation queries
 type AnalyticsRepository interface {
 	// GetDailySalesReport returns total revenue and order count for completed orders on a given day
@@ -78,4 +156,41 @@ type AnalyticsRepository interface {
 
 	// GetMonthlyRevenue returns revenue for the current month
 	GetMonthlyRevenue(ctx context.Context) (float64, error)
+
+	// GetSalesSeries returns completed-order revenue and profit per bucket for orders dated
+	// in [start, end). Buckets without orders are omitted.
+	GetSalesSeries(ctx context.Context, start, end time.Time, granularity SalesGranularity) ([]SalesSeriesPoint, error)
+
+	// GetSalesByHour returns completed-order count and revenue per hour of the day the
+	// orders were placed (in the database time zone) for orders dated in [start, end).
+	// Hours without orders are omitted.
+	GetSalesByHour(ctx context.Context, start, end time.Time) ([]SalesBucketResult, error)
+
+	// GetSalesByWeekday returns completed-order count and revenue per day of the week
+	// (0 = Sunday) for orders dated in [start, end). Days without orders are omitted.
+	GetSalesByWeekday(ctx context.Context, start, end time.Time) ([]SalesBucketResult, error)
+
+	// GetShiftSales returns the orders taken in a shift grouped by payment type and
+	// whether they have been cancelled since
+	GetShiftSales(ctx context.Context, shiftID uuid.UUID) ([]ShiftSalesResult, error)
+
+	// GetShiftRefunds returns the orders refunded from a shift's drawer (cancelled while
+	// it was open) grouped by payment type
+	GetShiftRefunds(ctx context.Context, shiftID uuid.UUID) ([]ShiftSalesResult, error)
+
+	// GetShiftPayments returns the money received while a shift was open grouped by
+	// payment type
+	GetShiftPayments(ctx context.Context, shiftID uuid.UUID) ([]ShiftPaymentResult, error)
+
+	// GetInventoryValuation returns the cost and retail value of stock on hand per category,
+	// highest cost value first. Products without a category are grouped as Uncategorized.
+	GetInventoryValuation(ctx context.Context) ([]InventoryValuationResult, error)
+
+	// GetSlowMovers returns products holding stock that sold at most maxSold units in
+	// completed orders over the last `days` days, least recently sold first
+	GetSlowMovers(ctx context.Context, days, maxSold int) ([]SlowMoverResult, error)
+
+	// GetSupplierPerformance returns purchase counts, lead time and amounts per supplier
+	// for purchases dated in [start, end), highest spend first
+	GetSupplierPerformance(ctx context.Context, start, end time.Time) ([]SupplierPerformanceResult, error)
 }
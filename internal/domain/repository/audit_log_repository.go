@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// AuditLogRepository defines the interface for audit log data operations
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *entity.AuditLog) error
+	// List returns audit logs across all tenants (super-admin use)
+	List(ctx context.Context, params *AuditLogFilterParams) ([]entity.AuditLog, int64, error)
+}
+
+// AuditLogFilterParams contains filtering parameters for audit log queries
+type AuditLogFilterParams struct {
+	Pagination     *pagination.PaginationParams
+	ActorID        *uuid.UUID
+	ImpersonatedBy *uuid.UUID
+	TenantID       *uuid.UUID
+	Action         string
+	ResourceType   string
+	ResourceID     string
+	StartDate      *time.Time
+	EndDate        *time.Time
+}
@@ -11,6 +11,9 @@ import (
 // CustomerRepository defines the interface for customer data operations
 type CustomerRepository interface {
 	Create(ctx context.Context, customer *entity.Customer) error
+	CreateBatch(ctx context.Context, customers []entity.Customer) error
+	// ListContacts returns the email and phone of every customer in the tenant, for duplicate checks
+	ListContacts(ctx context.Context) ([]entity.Customer, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Customer, error)
 	GetByEmail(ctx context.Context, email string) (*entity.Customer, error)
 	Update(ctx context.Context, customer *entity.Customer) error
@@ -19,15 +22,42 @@ type CustomerRepository interface {
 	List(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Customer, int64, error)
 	// ListWithCursor returns customers using cursor-based pagination. If skipUserFilter is true, returns all customers.
 	ListWithCursor(ctx context.Context, userID uuid.UUID, params *pagination.CursorParams, search string, skipUserFilter bool) ([]entity.Customer, error)
+	// ListDeleted returns soft-deleted customers (the trash). If skipUserFilter is true, returns all of them.
+	ListDeleted(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Customer, int64, error)
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*entity.Customer, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	HardDelete(ctx context.Context, id uuid.UUID) error
+	// CountReferences returns how many orders and quotations reference the customer
+	CountReferences(ctx context.Context, id uuid.UUID) (int64, error)
 }
 
 // SupplierRepository defines the interface for supplier data operations
 type SupplierRepository interface {
 	Create(ctx context.Context, supplier *entity.Supplier) error
+	CreateBatch(ctx context.Context, suppliers []entity.Supplier) error
+	// ListContacts returns the email and phone of every supplier in the tenant, for duplicate checks
+	ListContacts(ctx context.Context) ([]entity.Supplier, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Supplier, error)
 	GetByEmail(ctx context.Context, email string) (*entity.Supplier, error)
 	Update(ctx context.Context, supplier *entity.Supplier) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	// List returns suppliers. If skipUserFilter is true, returns all suppliers.
 	List(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Supplier, int64, error)
+	// ListDeleted returns soft-deleted suppliers (the trash). If skipUserFilter is true, returns all of them.
+	ListDeleted(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Supplier, int64, error)
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*entity.Supplier, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	HardDelete(ctx context.Context, id uuid.UUID) error
+	// CountReferences returns how many purchases reference the supplier
+	CountReferences(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+// SupplierProductRepository defines the interface for the supplier-product price catalog
+type SupplierProductRepository interface {
+	// Upsert records the last cost and purchase time of each supplier/product pair
+	Upsert(ctx context.Context, items []entity.SupplierProduct) error
+	// ListBySupplier returns a supplier's products, most recently purchased first
+	ListBySupplier(ctx context.Context, supplierID uuid.UUID, params *pagination.PaginationParams) ([]entity.SupplierProduct, int64, error)
+	// ListByProducts returns every supplier's last cost for the given products
+	ListByProducts(ctx context.Context, productIDs []uuid.UUID) ([]entity.SupplierProduct, error)
 }
@@ -9,10 +9,15 @@ import (
 
 // IdempotencyRepository defines the interface for idempotency key operations
 type IdempotencyRepository interface {
-	// GetByKey retrieves an idempotency key by its key string and user ID
-	GetByKey(ctx context.Context, key string, userID uuid.UUID) (*entity.IdempotencyKey, error)
-	// Create stores a new idempotency key
-	Create(ctx context.Context, ikey *entity.IdempotencyKey) error
+	// GetByKey retrieves an idempotency key by its tenant, user and key string
+	GetByKey(ctx context.Context, tenantID, userID uuid.UUID, key string) (*entity.IdempotencyKey, error)
+	// Reserve stores a pending key (ResponseCode 0) unless an unexpired key with the same
+	// scope exists; an expired one is replaced. Reports whether the key was reserved.
+	Reserve(ctx context.Context, ikey *entity.IdempotencyKey) (bool, error)
+	// Complete stores the response of a reserved key and its final expiry
+	Complete(ctx context.Context, ikey *entity.IdempotencyKey) error
+	// Delete removes a key so the request can be retried
+	Delete(ctx context.Context, id uuid.UUID) error
 	// DeleteExpired removes expired idempotency keys (for cleanup)
 	DeleteExpired(ctx context.Context) error
 }
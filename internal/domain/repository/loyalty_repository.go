@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// LoyaltyRepository defines the interface for the customer loyalty points ledger. The
+// write methods update the ledger and the customer's balance together, so they must
+// run inside a transaction.
+type LoyaltyRepository interface {
+	// Record adds an entry and applies its points to the customer's balance. If the
+	// entry's order already has an entry of the same type nothing changes and it
+	// reports false, so retried completions and cancellations never apply twice.
+	Record(ctx context.Context, entry *entity.LoyaltyTransaction) (bool, error)
+	// Redeem adds a redemption entry (negative points) and takes the points off the
+	// customer's balance. It reports false, changing nothing, if the balance is too low.
+	Redeem(ctx context.Context, entry *entity.LoyaltyTransaction) (bool, error)
+	// ListByOrder returns an order's ledger entries
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]entity.LoyaltyTransaction, error)
+	// ListByCustomer returns a customer's ledger entries, newest first
+	ListByCustomer(ctx context.Context, customerID uuid.UUID, params *pagination.PaginationParams) ([]entity.LoyaltyTransaction, int64, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+)
+
+// OrderPaymentRepository defines the interface for the ledger of money received on orders
+type OrderPaymentRepository interface {
+	Create(ctx context.Context, payment *entity.OrderPayment) error
+	// ListByOrders returns the payments on the orders made before the given time,
+	// oldest first
+	ListByOrders(ctx context.Context, orderIDs []uuid.UUID, before time.Time) ([]entity.OrderPayment, error)
+}
@@ -20,8 +20,24 @@ type OrderRepository interface {
 	List(ctx context.Context, userID uuid.UUID, params *OrderFilterParams) ([]entity.Order, int64, error)
 	ListWithCursor(ctx context.Context, userID uuid.UUID, params *OrderCursorFilterParams) ([]entity.Order, error)
 	GetWithDetails(ctx context.Context, id uuid.UUID) (*entity.Order, error)
-	UpdateStatus(ctx context.Context, id uuid.UUID, status enum.OrderStatus) error
+	UpdateStatus(ctx context.Context, id uuid.UUID, status enum.OrderStatus, updatedBy uuid.UUID) error
+	// GetByIDs retrieves multiple orders by their IDs in a single query
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Order, error)
+	// UpdateStatusBatch sets the status of several orders in a single statement
+	UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status enum.OrderStatus, updatedBy uuid.UUID) error
+	// ClearStockReserved marks an order's reservation as committed or released. It
+	// returns apperror.ErrVersionConflict if the order no longer holds a reservation,
+	// so concurrent settlements apply the stock only once.
+	ClearStockReserved(ctx context.Context, id uuid.UUID) error
+	// SetRefundShift records the shift whose cash drawer refunded a cancelled order
+	SetRefundShift(ctx context.Context, id, shiftID uuid.UUID) error
 	GetDueOrders(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams) ([]entity.Order, int64, error)
+	// ScanForExport calls fn with successive batches of up to batchSize orders matching
+	// params, oldest first. It pages by (created_at, id) so memory use stays flat however
+	// many orders match; pagination and sort params are ignored.
+	ScanForExport(ctx context.Context, userID uuid.UUID, params *OrderFilterParams, batchSize int, fn func([]entity.Order) error) error
+	// ListByCustomer returns a customer's non-cancelled orders dated on or before until, oldest first
+	ListByCustomer(ctx context.Context, customerID uuid.UUID, until time.Time) ([]entity.Order, error)
 }
 
 // OrderFilterParams contains filtering parameters for order queries
@@ -32,6 +48,8 @@ type OrderFilterParams struct {
 	CustomerID     *uuid.UUID
 	StartDate      *time.Time
 	EndDate        *time.Time
+	PaymentType    *string // Case-insensitive, e.g. "cash" or "credit"
+	HasDue         *bool   // true: only orders with an outstanding due; false: only settled orders
 	SortBy         string
 	SortOrder      string
 	SkipUserFilter bool // If true, returns all orders (for super-admin)
@@ -45,6 +63,8 @@ type OrderCursorFilterParams struct {
 	CustomerID     *uuid.UUID
 	StartDate      *time.Time
 	EndDate        *time.Time
+	PaymentType    *string
+	HasDue         *bool
 	SkipUserFilter bool // If true, returns all orders (for super-admin)
 }
 
@@ -17,11 +17,20 @@ type ProductRepository interface {
 	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Product, error)
 	GetBySlug(ctx context.Context, slug string) (*entity.Product, error)
 	GetByCode(ctx context.Context, code string) (*entity.Product, error)
+	// ExistsByCode reports whether a product uses the code, without loading it
+	ExistsByCode(ctx context.Context, code string) (bool, error)
 	Update(ctx context.Context, product *entity.Product) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// HardDelete permanently removes a product with its gallery images and supplier links
+	HardDelete(ctx context.Context, id uuid.UUID) error
+	// CountReferences returns how many order, purchase and quotation lines reference the product
+	CountReferences(ctx context.Context, id uuid.UUID) (int64, error)
 	List(ctx context.Context, userID uuid.UUID, params *ProductFilterParams) ([]entity.Product, int64, error)
 	ListWithCursor(ctx context.Context, userID uuid.UUID, params *ProductCursorFilterParams) ([]entity.Product, error)
 	GetLowStock(ctx context.Context, userID uuid.UUID) ([]entity.Product, error)
+	// GetBelowMinStock returns products with a minimum stock level whose quantity is at or
+	// below it, ordered by how far below they are
+	GetBelowMinStock(ctx context.Context, userID uuid.UUID) ([]entity.Product, error)
 	UpdateQuantity(ctx context.Context, id uuid.UUID, quantity int) error
 	// UpdateQuantityBatch updates quantities for multiple products in a batch
 	UpdateQuantityBatch(ctx context.Context, updates map[uuid.UUID]int) error
@@ -30,32 +39,75 @@ type ProductRepository interface {
 	AtomicDecrementQuantity(ctx context.Context, id uuid.UUID, amount int) (bool, error)
 	// AtomicDecrementBatch atomically decrements stock for multiple products.
 	// Returns map of product IDs that failed (insufficient stock) and any error.
-	// If any product fails, the entire transaction is rolled back.
+	// If any product fails, the entire transaction is rolled back. Products that allow
+	// negative stock never fail; their quantity may drop below zero.
 	AtomicDecrementBatch(ctx context.Context, decrements map[uuid.UUID]int) (failedIDs []uuid.UUID, err error)
 	// AtomicIncrementBatch atomically increments stock for multiple products (for cancellations/returns).
 	AtomicIncrementBatch(ctx context.Context, increments map[uuid.UUID]int) error
+	// AtomicReserveBatch atomically reserves stock for multiple products, leaving quantity untouched.
+	// Like AtomicDecrementBatch it returns the products without enough available stock and
+	// reserves nothing if there are any.
+	AtomicReserveBatch(ctx context.Context, reservations map[uuid.UUID]int) (failedIDs []uuid.UUID, err error)
+	// ReleaseReservedBatch returns reserved stock to available without changing quantity
+	ReleaseReservedBatch(ctx context.Context, reservations map[uuid.UUID]int) error
+	// CommitReservedBatch turns reservations into real decrements of quantity
+	CommitReservedBatch(ctx context.Context, reservations map[uuid.UUID]int) error
+}
+
+// ProductImageRepository defines the interface for product gallery data operations
+type ProductImageRepository interface {
+	Create(ctx context.Context, image *entity.ProductImage) error
+	// ListByProduct returns a product's images ordered by sort order
+	ListByProduct(ctx context.Context, productID uuid.UUID) ([]entity.ProductImage, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// SaveGallery writes the sort order and primary flag of each image and copies the
+	// primary image URL to the product's legacy product_image column (NULL if none)
+	SaveGallery(ctx context.Context, productID uuid.UUID, images []entity.ProductImage) error
+}
+
+// StockStatus filters products by stock on hand
+type StockStatus string
+
+const (
+	StockStatusAll StockStatus = "all" // No filter
+	StockStatusIn  StockStatus = "in"  // quantity above zero
+	StockStatusOut StockStatus = "out" // quantity zero or below
+	StockStatusLow StockStatus = "low" // Available stock at or below quantity_alert, like LowStock
+)
+
+// IsValid reports whether s is a known stock status
+func (s StockStatus) IsValid() bool {
+	switch s {
+	case StockStatusAll, StockStatusIn, StockStatusOut, StockStatusLow:
+		return true
+	}
+	return false
 }
 
 // ProductFilterParams contains filtering parameters for product queries
 type ProductFilterParams struct {
-	Pagination     *pagination.PaginationParams
-	Search         string
-	CategoryID     *uuid.UUID
-	UnitID         *uuid.UUID
-	LowStock       bool
-	SortBy         string
-	SortOrder      string
-	SkipUserFilter bool // If true, returns all products (for super-admin)
+	Pagination      *pagination.PaginationParams
+	Search          string
+	CategoryID      *uuid.UUID
+	UnitID          *uuid.UUID
+	LowStock        bool
+	StockStatus     StockStatus // Empty lists every product
+	IncludeInactive bool        // If true, inactive products are listed too
+	SortBy          string
+	SortOrder       string
+	SkipUserFilter  bool // If true, returns all products (for super-admin)
 }
 
 // ProductCursorFilterParams contains cursor-based filtering parameters for product queries
 type ProductCursorFilterParams struct {
-	Cursor         *pagination.CursorParams
-	Search         string
-	CategoryID     *uuid.UUID
-	UnitID         *uuid.UUID
-	LowStock       bool
-	SkipUserFilter bool // If true, returns all products (for super-admin)
+	Cursor          *pagination.CursorParams
+	Search          string
+	CategoryID      *uuid.UUID
+	UnitID          *uuid.UUID
+	LowStock        bool
+	StockStatus     StockStatus // Empty lists every product
+	IncludeInactive bool        // If true, inactive products are listed too
+	SkipUserFilter  bool        // If true, returns all products (for super-admin)
 }
 
 // CategoryRepository defines the interface for category data operations
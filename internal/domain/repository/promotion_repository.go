@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// PromotionRepository defines the interface for promotion data operations
+type PromotionRepository interface {
+	Create(ctx context.Context, promotion *entity.Promotion) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Promotion, error)
+	// GetByCode looks a promotion up by its code, ignoring case
+	GetByCode(ctx context.Context, code string) (*entity.Promotion, error)
+	Update(ctx context.Context, promotion *entity.Promotion) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, params *pagination.PaginationParams, search string) ([]entity.Promotion, int64, error)
+	// Use counts one use of an active promotion valid at now. It reports false,
+	// changing nothing, if the promotion is inactive, outside its validity window or
+	// has no uses left, so concurrent orders can't exceed the usage limit.
+	Use(ctx context.Context, id uuid.UUID, now time.Time) (bool, error)
+}
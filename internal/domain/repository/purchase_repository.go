@@ -55,4 +55,6 @@ type PurchaseDetailRepository interface {
 	GetByPurchaseID(ctx context.Context, purchaseID uuid.UUID) ([]entity.PurchaseDetail, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	DeleteByPurchaseID(ctx context.Context, purchaseID uuid.UUID) error
+	// PendingProductIDs returns which of the products are on a purchase still pending
+	PendingProductIDs(ctx context.Context, productIDs []uuid.UUID) ([]uuid.UUID, error)
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/entity"
@@ -13,13 +14,14 @@ import (
 type QuotationRepository interface {
 	Create(ctx context.Context, quotation *entity.Quotation) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Quotation, error)
+	// LockForUpdate row-locks the quotation for the rest of the current transaction
+	LockForUpdate(ctx context.Context, id uuid.UUID) error
 	GetByReference(ctx context.Context, reference string) (*entity.Quotation, error)
 	Update(ctx context.Context, quotation *entity.Quotation) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, userID uuid.UUID, params *QuotationFilterParams) ([]entity.Quotation, int64, error)
 	GetWithDetails(ctx context.Context, id uuid.UUID) (*entity.Quotation, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status enum.QuotationStatus) error
-	GetNextReferenceNumber(ctx context.Context) (int, error)
 }
 
 // QuotationFilterParams contains filtering parameters for quotation queries
@@ -28,6 +30,8 @@ type QuotationFilterParams struct {
 	Search     string
 	Status     *enum.QuotationStatus
 	CustomerID *uuid.UUID
+	StartDate  *time.Time
+	EndDate    *time.Time
 	SortBy     string
 	SortOrder  string
 }
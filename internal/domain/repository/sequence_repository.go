@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// SequenceRepository hands out per-tenant sequential numbers
+type SequenceRepository interface {
+	// Next atomically increments the named sequence for the tenant and returns the new value.
+	// It is safe under concurrency and joins the caller's transaction if one is in ctx.
+	Next(ctx context.Context, tenantID uuid.UUID, name string) (int64, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// ShiftRepository defines the interface for cashier shift data operations
+type ShiftRepository interface {
+	Create(ctx context.Context, shift *entity.Shift) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Shift, error)
+	// GetOpenByUser returns the user's open shift, or nil if they have none
+	GetOpenByUser(ctx context.Context, userID uuid.UUID) (*entity.Shift, error)
+	// Close saves the closing fields of a shift that is still open. It reports false,
+	// changing nothing, if the shift was closed in the meantime.
+	Close(ctx context.Context, shift *entity.Shift) (bool, error)
+	// List returns shifts newest first, only userID's if it is set
+	List(ctx context.Context, params *pagination.PaginationParams, userID *uuid.UUID) ([]entity.Shift, int64, error)
+}
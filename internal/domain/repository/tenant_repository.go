@@ -34,8 +34,8 @@ type TenantRepository interface {
 	// RemoveMember removes a user from a tenant
 	RemoveMember(ctx context.Context, tenantID, userID uuid.UUID) error
 
-	// GetMembers retrieves all members of a tenant
-	GetMembers(ctx context.Context, tenantID uuid.UUID) ([]entity.TenantMembership, error)
+	// GetMembers retrieves members of a tenant with pagination, optionally searching by name or email
+	GetMembers(ctx context.Context, tenantID uuid.UUID, params *pagination.PaginationParams, search string) ([]entity.TenantMembership, int64, error)
 
 	// IsMember checks if a user is a member of a tenant
 	IsMember(ctx context.Context, tenantID, userID uuid.UUID) (bool, error)
@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+// TxManager runs a unit of work inside a single database transaction.
+// Repositories called with the context passed to fn participate in the transaction;
+// returning an error from fn rolls everything back.
+type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -16,10 +16,50 @@ import (
 
 // NewPostgresDB creates a new PostgreSQL database connection
 func NewPostgresDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := openPostgres(cfg.DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Successfully connected to PostgreSQL database")
+	return db, nil
+}
+
+// NewReplicaDB connects to the read replica configured by DB_REPLICA_DSN. Without one,
+// or if the replica cannot be reached, it returns primary so reads keep working.
+func NewReplicaDB(cfg *config.DatabaseConfig, primary *gorm.DB) *gorm.DB {
+	if cfg.ReplicaDSN == "" {
+		return primary
+	}
+
+	db, err := openPostgres(cfg.ReplicaDSN)
+	if err == nil {
+		err = pingDB(db)
+	}
+	if err != nil {
+		log.Printf("Warning: read replica unavailable, reading from the primary: %v", err)
+		return primary
+	}
+
+	log.Println("Successfully connected to PostgreSQL read replica")
+	return db
+}
+
+// pingDB checks that the database answers
+func pingDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Ping()
+}
+
+// openPostgres opens a connection pool to the database at dsn
+func openPostgres(dsn string) (*gorm.DB, error) {
 	logLevel := logger.Info
 
 	db, err := gorm.Open(postgres.New(postgres.Config{
-		DSN:                  cfg.DSN(),
+		DSN:                  dsn,
 		PreferSimpleProtocol: true, // disables implicit prepared statement usage
 	}), &gorm.Config{
 		Logger: logger.Default.LogMode(logLevel),
@@ -38,7 +78,6 @@ func NewPostgresDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 
-	log.Println("Successfully connected to PostgreSQL database")
 	return db, nil
 }
 
@@ -81,7 +120,66 @@ func AutoMigrate(db *gorm.DB) error {
 		}
 	}
 
-	err := db.AutoMigrate(
+	// Drop old global unique indexes; the tenant-scoped composite indexes are created by AutoMigrate
+	indexesToDrop := []string{
+		"idx_products_slug",
+		"idx_products_code",
+		"idx_categories_slug",
+		"idx_units_slug",
+		"idx_idempotency_keys_key", // Keys are now unique per tenant and user
+		// Product slugs and codes are now unique among undeleted products only, so a
+		// deleted product's code can be reused (idx_tenant_product_live_*)
+		"idx_tenant_product_slug",
+		"idx_tenant_product_code",
+	}
+
+	for _, idx := range indexesToDrop {
+		if err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", idx)).Error; err != nil {
+			log.Printf("Warning: failed to drop index %s: %v", idx, err)
+		}
+	}
+
+	// Quotation and purchase amounts used to be decimal currency units; convert them to
+	// cents before AutoMigrate sees the bigint fields. The columns are converted together
+	// or not at all: AutoMigrate would cast a column left behind without the * 100.
+	centsColumns := []struct {
+		table  string
+		column string
+	}{
+		{"quotations", "tax_amount"},
+		{"quotations", "discount_amount"},
+		{"quotations", "shipping_amount"},
+		{"quotations", "total_amount"},
+		{"quotation_details", "unit_price"},
+		{"quotation_details", "sub_total"},
+		{"purchases", "total_amount"},
+		{"purchases", "tax_amount"},
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, c := range centsColumns {
+			query := fmt.Sprintf(`
+				DO $$
+				BEGIN
+					IF EXISTS (SELECT 1 FROM information_schema.columns
+						WHERE table_schema = current_schema() AND table_name = '%s'
+						AND column_name = '%s' AND data_type = 'numeric') THEN
+						ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;
+						ALTER TABLE %s ALTER COLUMN %s TYPE bigint USING ROUND(%s * 100);
+					END IF;
+				END $$;
+			`, c.table, c.column, c.table, c.column, c.table, c.column, c.column)
+			if err := tx.Exec(query).Error; err != nil {
+				return fmt.Errorf("failed to convert %s.%s to cents: %w", c.table, c.column, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = db.AutoMigrate(
 		// Tenant entities (must be first for foreign key references)
 		&entity.Tenant{},
 		&entity.TenantMembership{},
@@ -96,14 +194,20 @@ func AutoMigrate(db *gorm.DB) error {
 		&entity.Category{},
 		&entity.Unit{},
 		&entity.Product{},
+		&entity.ProductImage{},
 
 		// CRM entities
 		&entity.Customer{},
 		&entity.Supplier{},
+		&entity.SupplierProduct{},
+		&entity.Promotion{},
+		&entity.Shift{},
 
 		// Transaction entities
 		&entity.Order{},
 		&entity.OrderDetail{},
+		&entity.LoyaltyTransaction{},
+		&entity.OrderPayment{},
 		&entity.Purchase{},
 		&entity.PurchaseDetail{},
 		&entity.Quotation{},
@@ -112,6 +216,8 @@ func AutoMigrate(db *gorm.DB) error {
 		// System entities
 		&entity.IdempotencyKey{},
 		&entity.UserSettings{},
+		&entity.AuditLog{},
+		&entity.Sequence{},
 
 		// Payment entities
 		&entity.MpesaTransaction{},
@@ -121,6 +227,69 @@ func AutoMigrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	// Records created before created_by existed were created by their owner
+	for _, table := range []string{"orders", "products", "customers", "suppliers"} {
+		if err := db.Exec(fmt.Sprintf("UPDATE %s SET created_by = user_id WHERE created_by IS NULL", table)).Error; err != nil {
+			log.Printf("Warning: failed to backfill %s.created_by: %v", table, err)
+		}
+	}
+
+	// Order lines created before product snapshots existed take the product's current
+	// name and code
+	if err := db.Exec(`
+		UPDATE order_details od
+		SET product_name = p.name, product_code = p.code
+		FROM products p
+		WHERE p.id = od.product_id AND od.product_name = ''`).Error; err != nil {
+		log.Printf("Warning: failed to backfill order_details product snapshots: %v", err)
+	}
+
+	// The quotation prefix used to default to "QUO-" without being applied; quotations
+	// were always numbered QT-, so keep that for tenants still on the old default
+	if err := db.Exec(`
+		UPDATE tenants
+		SET settings = jsonb_set(settings, '{quotation_prefix}', '"QT-"')
+		WHERE settings->>'quotation_prefix' = 'QUO-'`).Error; err != nil {
+		log.Printf("Warning: failed to migrate quotation prefixes: %v", err)
+	}
+
+	// Google accounts used to be recorded only as provider/provider_id
+	if err := db.Exec(`
+		UPDATE users SET google_id = provider_id
+		WHERE provider = 'google' AND provider_id IS NOT NULL AND google_id IS NULL`).Error; err != nil {
+		log.Printf("Warning: failed to backfill users.google_id: %v", err)
+	}
+
+	// Purchases approved before received_at existed were last updated when approved
+	if err := db.Exec(`
+		UPDATE purchases SET received_at = updated_at
+		WHERE status = 1 AND received_at IS NULL`).Error; err != nil {
+		log.Printf("Warning: failed to backfill purchases.received_at: %v", err)
+	}
+
+	// Orders taken before the payments ledger existed get one payment for what they
+	// kept, dated and assigned to the shift of the order
+	if err := db.Exec(`
+		INSERT INTO order_payments (id, tenant_id, order_id, shift_id, payment_type, amount, paid_at, created_by, created_at)
+		SELECT gen_random_uuid(), o.tenant_id, o.id, o.shift_id, COALESCE(o.payment_type, ''), o.pay - o.change, o.created_at, o.created_by, NOW()
+		FROM orders o
+		WHERE o.pay - o.change > 0
+			AND NOT EXISTS (SELECT 1 FROM order_payments p WHERE p.order_id = o.id)`).Error; err != nil {
+		log.Printf("Warning: failed to backfill order_payments: %v", err)
+	}
+
+	// Start quotation sequences after the highest existing QT-NNNNNN reference so
+	// numbers issued before sequences existed are never reused
+	if err := db.Exec(`
+		INSERT INTO sequences (tenant_id, name, value, updated_at)
+		SELECT tenant_id, ?, MAX(CAST(SUBSTRING(reference FROM 4) AS BIGINT)), NOW()
+		FROM quotations
+		WHERE reference ~ '^QT-[0-9]+$'
+		GROUP BY tenant_id
+		ON CONFLICT (tenant_id, name) DO NOTHING`, entity.SequenceQuotation).Error; err != nil {
+		log.Printf("Warning: failed to seed quotation sequences: %v", err)
+	}
+
 	log.Println("Database migrations completed successfully")
 	return nil
 }
@@ -140,15 +309,20 @@ func SeedDefaultData(db *gorm.DB) error {
 		{Name: "manage-suppliers", GuardName: "web"},
 		{Name: "manage-categories", GuardName: "web"},
 		{Name: "manage-units", GuardName: "web"},
+		{Name: "manage-promotions", GuardName: "web"},
 		{Name: "manage-users", GuardName: "web"},
 		{Name: "view-reports", GuardName: "web"},
+		{Name: "export-data", GuardName: "web"}, // Streamed full-dataset exports
 	}
 
+	var newPermissions []entity.Permission
 	for i := range permissions {
 		var existing entity.Permission
 		if err := db.Where("name = ?", permissions[i].Name).First(&existing).Error; err != nil {
 			if err := db.Create(&permissions[i]).Error; err != nil {
 				log.Printf("Warning: failed to create permission %s: %v", permissions[i].Name, err)
+			} else {
+				newPermissions = append(newPermissions, permissions[i])
 			}
 		}
 	}
@@ -183,6 +357,18 @@ func SeedDefaultData(db *gorm.DB) error {
 		}
 	}
 
+	// Roles seeded with all permissions also get the ones added since they were created
+	if len(newPermissions) > 0 {
+		for _, role := range []*entity.Role{&superAdminRole, &adminRole} {
+			if role.ID == 0 {
+				continue
+			}
+			if err := db.Model(role).Association("Permissions").Append(newPermissions); err != nil {
+				log.Printf("Warning: failed to grant new permissions to %s: %v", role.Name, err)
+			}
+		}
+	}
+
 	// Create staff role with limited permissions
 	staffPermissions := []string{
 		"view-dashboard",
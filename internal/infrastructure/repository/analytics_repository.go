@@ -63,7 +63,7 @@ func (r *analyticsRepository) GetDailySalesReport(ctx context.Context, dr *domai
 		TotalRevenue float64
 		OrdersCount  int
 	}
-	err := r.db.WithContext(ctx).Raw(`
+	err := dbFromContext(ctx, r.db).Raw(`
 		SELECT COALESCE(SUM(total), 0) / 100.0 as total_revenue, COUNT(*) as orders_count
 		FROM orders
 		WHERE `+whereClause, args...).Scan(&result).Error
@@ -96,18 +96,18 @@ func (r *analyticsRepository) GetTopProducts(ctx context.Context, limit int, dr
 	whereClause, args = applyDateRange(whereClause, args, dr, "o.order_date")
 	args = append(args, limit)
 
-	err := r.db.WithContext(ctx).Raw(`
+	err := dbFromContext(ctx, r.db).Raw(`
 		SELECT 
-			p.id as product_id,
-			p.name as product_name,
-			p.code as product_code,
+			od.product_id,
+			COALESCE(MAX(p.name), MAX(od.product_name)) as product_name,
+			COALESCE(MAX(p.code), MAX(od.product_code)) as product_code,
 			COALESCE(SUM(od.quantity), 0) as quantity_sold,
 			COALESCE(SUM(od.total), 0) / 100.0 as revenue
 		FROM order_details od
-		JOIN products p ON p.id = od.product_id
+		LEFT JOIN products p ON p.id = od.product_id AND p.deleted_at IS NULL
 		JOIN orders o ON o.id = od.order_id
 		WHERE `+whereClause+`
-		GROUP BY p.id, p.name, p.code
+		GROUP BY od.product_id
 		ORDER BY revenue DESC
 		LIMIT ?
 	`, args...).Scan(&results).Error
@@ -134,7 +134,7 @@ func (r *analyticsRepository) GetSalesByCategory(ctx context.Context, dr *domain
 
 	// First get total sales for percentage calculation
 	var totalSales float64
-	err := r.db.WithContext(ctx).Raw(`
+	err := dbFromContext(ctx, r.db).Raw(`
 		SELECT COALESCE(SUM(od.total), 0) / 100.0
 		FROM order_details od
 		JOIN orders o ON o.id = od.order_id
@@ -144,7 +144,7 @@ func (r *analyticsRepository) GetSalesByCategory(ctx context.Context, dr *domain
 	}
 
 	// Get sales by category
-	err = r.db.WithContext(ctx).Raw(`
+	err = dbFromContext(ctx, r.db).Raw(`
 		SELECT 
 			COALESCE(c.id, '00000000-0000-0000-0000-000000000000') as category_id,
 			COALESCE(c.name, 'Uncategorized') as category_name,
@@ -187,7 +187,7 @@ func (r *analyticsRepository) GetTopCustomers(ctx context.Context, limit int, dr
 	whereClause, args = applyDateRange(whereClause, args, dr, "o.order_date")
 	args = append(args, limit)
 
-	err := r.db.WithContext(ctx).Raw(`
+	err := dbFromContext(ctx, r.db).Raw(`
 		SELECT 
 			c.id as customer_id,
 			c.name as customer_name,
@@ -229,7 +229,7 @@ func (r *analyticsRepository) GetDailySales(ctx context.Context, days int, dr *d
 		args = append(args, startOfDay.Format("2006-01-02"), endOfDay.Format("2006-01-02"))
 
 		var revenue sql.NullFloat64
-		err := r.db.WithContext(ctx).Raw(`
+		err := dbFromContext(ctx, r.db).Raw(`
 			SELECT COALESCE(SUM(total), 0) / 100.0
 			FROM orders
 			WHERE `+baseWhereClause+`
@@ -267,7 +267,7 @@ func (r *analyticsRepository) GetTotalRevenue(ctx context.Context, dr *domainRep
 	whereClause, args = applyDateRange(whereClause, args, dr, "order_date")
 
 	var revenue float64
-	err := r.db.WithContext(ctx).Raw(`
+	err := dbFromContext(ctx, r.db).Raw(`
 		SELECT COALESCE(SUM(total), 0) / 100.0
 		FROM orders
 		WHERE `+whereClause,
@@ -291,7 +291,7 @@ func (r *analyticsRepository) GetMonthlyRevenue(ctx context.Context) (float64, e
 	args = append(args, tenantArgs...)
 
 	var revenue float64
-	err := r.db.WithContext(ctx).Raw(`
+	err := dbFromContext(ctx, r.db).Raw(`
 		SELECT COALESCE(SUM(total), 0) / 100.0
 		FROM orders
 		WHERE `+whereClause,
@@ -311,10 +311,266 @@ func (r *analyticsRepository) GetTotalPurchasesAmount(ctx context.Context) (floa
 	}
 
 	var total float64
-	err := r.db.WithContext(ctx).Raw(`
-		SELECT COALESCE(SUM(total_amount), 0)
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT COALESCE(SUM(total_amount), 0) / 100.0
 		FROM purchases
 		WHERE `+whereClause, args...).Scan(&total).Error
 
 	return total, err
 }
+
+// GetSalesSeries buckets completed orders with date_trunc. Profit is the line total minus
+// the product's current buying price per unit, so it excludes VAT like the line totals do.
+func (r *analyticsRepository) GetSalesSeries(ctx context.Context, start, end time.Time, granularity domainRepo.SalesGranularity) ([]domainRepo.SalesSeriesPoint, error) {
+	var results []domainRepo.SalesSeriesPoint
+
+	tenantFilter, tenantArgs := r.getTenantFilter(ctx, "o")
+	whereClause := "o.order_status = 1 AND o.deleted_at IS NULL"
+	args := []interface{}{string(granularity)}
+
+	if tenantFilter != "" {
+		whereClause += " AND " + tenantFilter
+		args = append(args, tenantArgs...)
+	}
+	whereClause, args = applyDateRange(whereClause, args, &domainRepo.DateRange{Start: start, End: end}, "o.order_date")
+
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT
+			s.bucket,
+			COALESCE(SUM(s.total), 0) / 100.0 as revenue,
+			COALESCE(SUM(s.profit), 0) / 100.0 as profit,
+			COUNT(*) as orders_count
+		FROM (
+			SELECT
+				date_trunc(?, o.order_date::timestamp) as bucket,
+				o.total,
+				(
+					SELECT COALESCE(SUM(od.total - od.quantity * p.buying_price), 0)
+					FROM order_details od
+					JOIN products p ON p.id = od.product_id
+					WHERE od.order_id = o.id AND od.deleted_at IS NULL
+				) as profit
+			FROM orders o
+			WHERE `+whereClause+`
+		) s
+		GROUP BY s.bucket
+		ORDER BY s.bucket
+	`, args...).Scan(&results).Error
+
+	return results, err
+}
+
+// GetSalesByHour buckets by created_at, since order_date carries no time of day
+func (r *analyticsRepository) GetSalesByHour(ctx context.Context, start, end time.Time) ([]domainRepo.SalesBucketResult, error) {
+	return r.salesByBucket(ctx, start, end, "EXTRACT(hour FROM o.created_at)")
+}
+
+func (r *analyticsRepository) GetSalesByWeekday(ctx context.Context, start, end time.Time) ([]domainRepo.SalesBucketResult, error) {
+	return r.salesByBucket(ctx, start, end, "EXTRACT(dow FROM o.order_date)")
+}
+
+// salesByBucket groups completed orders dated in [start, end) by the integer SQL
+// expression bucketExpr over orders o
+func (r *analyticsRepository) salesByBucket(ctx context.Context, start, end time.Time, bucketExpr string) ([]domainRepo.SalesBucketResult, error) {
+	var results []domainRepo.SalesBucketResult
+
+	tenantFilter, tenantArgs := r.getTenantFilter(ctx, "o")
+	whereClause := "o.order_status = 1 AND o.deleted_at IS NULL"
+	args := []interface{}{}
+
+	if tenantFilter != "" {
+		whereClause += " AND " + tenantFilter
+		args = append(args, tenantArgs...)
+	}
+	whereClause, args = applyDateRange(whereClause, args, &domainRepo.DateRange{Start: start, End: end}, "o.order_date")
+
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT
+			`+bucketExpr+`::int as bucket,
+			COUNT(*) as orders_count,
+			COALESCE(SUM(o.total), 0) / 100.0 as revenue
+		FROM orders o
+		WHERE `+whereClause+`
+		GROUP BY 1
+		ORDER BY 1
+	`, args...).Scan(&results).Error
+
+	return results, err
+}
+
+func (r *analyticsRepository) GetShiftSales(ctx context.Context, shiftID uuid.UUID) ([]domainRepo.ShiftSalesResult, error) {
+	return r.shiftOrders(ctx, "o.shift_id", shiftID)
+}
+
+func (r *analyticsRepository) GetShiftRefunds(ctx context.Context, shiftID uuid.UUID) ([]domainRepo.ShiftSalesResult, error) {
+	return r.shiftOrders(ctx, "o.refund_shift_id", shiftID)
+}
+
+// shiftOrders totals the orders o whose shift column is shiftID per payment type and
+// cancellation
+func (r *analyticsRepository) shiftOrders(ctx context.Context, column string, shiftID uuid.UUID) ([]domainRepo.ShiftSalesResult, error) {
+	var results []domainRepo.ShiftSalesResult
+
+	tenantFilter, tenantArgs := r.getTenantFilter(ctx, "o")
+	whereClause := "o.deleted_at IS NULL AND " + column + " = ?"
+	args := []interface{}{shiftID}
+
+	if tenantFilter != "" {
+		whereClause += " AND " + tenantFilter
+		args = append(args, tenantArgs...)
+	}
+
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT
+			LOWER(COALESCE(o.payment_type, '')) as payment_type,
+			o.order_status = 2 as cancelled,
+			COUNT(*) as orders_count,
+			COALESCE(SUM(o.total), 0) as total,
+			COALESCE(SUM(o.vat), 0) as vat,
+			COALESCE(SUM(o.pay - o.change), 0) as received,
+			COALESCE(SUM(o.due), 0) as due
+		FROM orders o
+		WHERE `+whereClause+`
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, args...).Scan(&results).Error
+
+	return results, err
+}
+
+func (r *analyticsRepository) GetShiftPayments(ctx context.Context, shiftID uuid.UUID) ([]domainRepo.ShiftPaymentResult, error) {
+	var results []domainRepo.ShiftPaymentResult
+
+	tenantFilter, tenantArgs := r.getTenantFilter(ctx, "p")
+	whereClause := "p.shift_id = ?"
+	args := []interface{}{shiftID}
+
+	if tenantFilter != "" {
+		whereClause += " AND " + tenantFilter
+		args = append(args, tenantArgs...)
+	}
+
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT
+			LOWER(COALESCE(p.payment_type, '')) as payment_type,
+			COUNT(*) as payments_count,
+			COALESCE(SUM(p.amount), 0) as amount
+		FROM order_payments p
+		WHERE `+whereClause+`
+		GROUP BY 1
+		ORDER BY 1
+	`, args...).Scan(&results).Error
+
+	return results, err
+}
+
+// GetInventoryValuation values positive stock only, so oversold products don't reduce the total
+func (r *analyticsRepository) GetInventoryValuation(ctx context.Context) ([]domainRepo.InventoryValuationResult, error) {
+	var results []domainRepo.InventoryValuationResult
+
+	tenantFilter, tenantArgs := r.getTenantFilter(ctx, "p")
+	whereClause := "p.deleted_at IS NULL AND p.quantity > 0"
+	args := []interface{}{}
+
+	if tenantFilter != "" {
+		whereClause += " AND " + tenantFilter
+		args = append(args, tenantArgs...)
+	}
+
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT
+			COALESCE(c.id, '00000000-0000-0000-0000-000000000000') as category_id,
+			COALESCE(c.name, 'Uncategorized') as category_name,
+			COUNT(*) as product_count,
+			COALESCE(SUM(p.quantity), 0) as quantity,
+			COALESCE(SUM(p.quantity::bigint * p.buying_price), 0) / 100.0 as cost_value,
+			COALESCE(SUM(p.quantity::bigint * p.selling_price), 0) / 100.0 as retail_value
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id AND c.deleted_at IS NULL
+		WHERE `+whereClause+`
+		GROUP BY c.id, c.name
+		ORDER BY cost_value DESC
+	`, args...).Scan(&results).Error
+
+	return results, err
+}
+
+// GetSlowMovers returns products with stock on hand and few or no sales in the window
+func (r *analyticsRepository) GetSlowMovers(ctx context.Context, days, maxSold int) ([]domainRepo.SlowMoverResult, error) {
+	var results []domainRepo.SlowMoverResult
+
+	since := time.Now().AddDate(0, 0, -days)
+	salesWhere := "o.order_status = 1 AND o.deleted_at IS NULL AND od.deleted_at IS NULL"
+	productWhere := "p.deleted_at IS NULL AND p.quantity > 0"
+	args := []interface{}{since}
+
+	if tenantFilter, tenantArgs := r.getTenantFilter(ctx, "o"); tenantFilter != "" {
+		salesWhere += " AND " + tenantFilter
+		args = append(args, tenantArgs...)
+	}
+	if tenantFilter, tenantArgs := r.getTenantFilter(ctx, "p"); tenantFilter != "" {
+		productWhere += " AND " + tenantFilter
+		args = append(args, tenantArgs...)
+	}
+	args = append(args, maxSold)
+
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT
+			p.id as product_id,
+			p.name as product_name,
+			p.code as product_code,
+			p.quantity,
+			COALESCE(s.quantity_sold, 0) as quantity_sold,
+			s.last_sold_at,
+			p.quantity::bigint * p.buying_price / 100.0 as stock_value
+		FROM products p
+		LEFT JOIN (
+			SELECT
+				od.product_id,
+				SUM(CASE WHEN o.order_date >= ? THEN od.quantity ELSE 0 END) as quantity_sold,
+				MAX(o.order_date) as last_sold_at
+			FROM order_details od
+			JOIN orders o ON o.id = od.order_id
+			WHERE `+salesWhere+`
+			GROUP BY od.product_id
+		) s ON s.product_id = p.id
+		WHERE `+productWhere+` AND COALESCE(s.quantity_sold, 0) <= ?
+		ORDER BY s.last_sold_at ASC NULLS FIRST, stock_value DESC
+	`, args...).Scan(&results).Error
+
+	return results, err
+}
+
+// GetSupplierPerformance measures lead time in whole days, since purchases are dated
+// without a time of day
+func (r *analyticsRepository) GetSupplierPerformance(ctx context.Context, start, end time.Time) ([]domainRepo.SupplierPerformanceResult, error) {
+	var results []domainRepo.SupplierPerformanceResult
+
+	tenantFilter, tenantArgs := r.getTenantFilter(ctx, "p")
+	whereClause := "p.deleted_at IS NULL"
+	args := []interface{}{}
+
+	if tenantFilter != "" {
+		whereClause += " AND " + tenantFilter
+		args = append(args, tenantArgs...)
+	}
+	whereClause, args = applyDateRange(whereClause, args, &domainRepo.DateRange{Start: start, End: end}, "p.date")
+
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT
+			p.supplier_id,
+			COALESCE(MAX(s.name), 'No supplier') as supplier_name,
+			COUNT(*) as purchases_count,
+			COUNT(p.received_at) as received_count,
+			AVG(p.received_at::date - p.date) as avg_lead_time_days,
+			COALESCE(SUM(p.total_amount), 0) / 100.0 as ordered_amount,
+			COALESCE(SUM(p.total_amount) FILTER (WHERE p.received_at IS NOT NULL), 0) / 100.0 as received_amount
+		FROM purchases p
+		LEFT JOIN suppliers s ON s.id = p.supplier_id
+		WHERE `+whereClause+`
+		GROUP BY p.supplier_id
+		ORDER BY received_amount DESC, purchases_count DESC
+	`, args...).Scan(&results).Error
+
+	return results, err
+}
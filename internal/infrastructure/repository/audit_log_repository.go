@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *gorm.DB) domainRepo.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, log *entity.AuditLog) error {
+	return dbFromContext(ctx, r.db).Create(log).Error
+}
+
+func (r *auditLogRepository) List(ctx context.Context, params *domainRepo.AuditLogFilterParams) ([]entity.AuditLog, int64, error) {
+	var logs []entity.AuditLog
+	var total int64
+
+	query := dbFromContext(ctx, r.db).Model(&entity.AuditLog{})
+
+	if params.ActorID != nil {
+		query = query.Where("actor_id = ?", *params.ActorID)
+	}
+	if params.ImpersonatedBy != nil {
+		query = query.Where("impersonated_by = ?", *params.ImpersonatedBy)
+	}
+
+	if params.TenantID != nil {
+		query = query.Where("tenant_id = ?", *params.TenantID)
+	}
+
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+
+	if params.ResourceType != "" {
+		query = query.Where("resource_type = ?", params.ResourceType)
+	}
+
+	if params.ResourceID != "" {
+		query = query.Where("resource_id = ?", params.ResourceID)
+	}
+
+	if params.StartDate != nil {
+		query = query.Where("created_at >= ?", *params.StartDate)
+	}
+
+	if params.EndDate != nil {
+		query = query.Where("created_at < ?", params.EndDate.AddDate(0, 0, 1))
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	params.Pagination.Validate()
+	err := query.Offset(params.Pagination.Offset()).Limit(params.Pagination.PerPage).
+		Order("created_at DESC").
+		Find(&logs).Error
+
+	return logs, total, err
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/sangkips/investify-api/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+// countRows sets total to the number of rows query matches, following params.Count.
+// Under CountNone total is left alone; the page query must then use pageLimit and
+// trimPage so the service can still work out has_next.
+func countRows(query *gorm.DB, params *pagination.PaginationParams, total *int64) error {
+	switch params.Count {
+	case pagination.CountNone:
+		return nil
+	case pagination.CountEstimate:
+		return estimateRows(query, total)
+	default:
+		return query.Count(total).Error
+	}
+}
+
+// estimateRows reads the planner's row estimate for query instead of counting.
+// Unlike pg_class.reltuples on its own, the estimate takes the query's filters into
+// account, so a tenant-scoped list never reports the size of the whole table.
+func estimateRows(query *gorm.DB, total *int64) error {
+	stmt := query.Session(&gorm.Session{DryRun: true}).Select("1").Find(&[]int{}).Statement
+	if stmt.Error != nil {
+		return stmt.Error
+	}
+
+	var plan string
+	row := stmt.ConnPool.QueryRowContext(stmt.Context, "EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...)
+	if err := row.Scan(&plan); err != nil {
+		return err
+	}
+
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explain); err != nil {
+		return err
+	}
+	if len(explain) > 0 {
+		*total = int64(explain[0].Plan.Rows)
+	}
+	return nil
+}
+
+// pageLimit returns how many rows to fetch for a page. CountNone fetches one extra
+// row to find out whether a next page exists.
+func pageLimit(params *pagination.PaginationParams) int {
+	if params.Count == pagination.CountNone {
+		return params.PerPage + 1
+	}
+	return params.PerPage
+}
+
+// trimPage drops the look-ahead row fetched under CountNone and sets total to the
+// lower bound pagination.NewPaginationForParams expects. Other modes pass through.
+func trimPage[T any](items []T, params *pagination.PaginationParams, total *int64) []T {
+	if params.Count != pagination.CountNone {
+		return items
+	}
+	*total = int64(params.Offset() + len(items))
+	if len(items) > params.PerPage {
+		items = items[:params.PerPage]
+	}
+	return items
+}
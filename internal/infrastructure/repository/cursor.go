@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"github.com/sangkips/investify-api/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+// applyCursor adds the keyset condition and ordering for a cursor page.
+//
+// Next pages are read ascending after the cursor. Previous pages are read
+// descending before the cursor (so LIMIT keeps the rows nearest to it) and must
+// be passed through orderCursorResults to restore ascending order.
+func applyCursor(query *gorm.DB, params *pagination.CursorParams) (*gorm.DB, error) {
+	cursor, err := params.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Direction == pagination.CursorDirectionPrev {
+		if cursor != nil {
+			query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+		}
+		return query.Order("created_at DESC, id DESC"), nil
+	}
+
+	if cursor != nil {
+		query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+	return query.Order("created_at ASC, id ASC"), nil
+}
+
+// orderCursorResults reverses a previous-page result set back into ascending order
+func orderCursorResults[T any](items []T, params *pagination.CursorParams) []T {
+	if params.Direction == pagination.CursorDirectionPrev {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return items
+}
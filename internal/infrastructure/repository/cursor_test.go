@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+type cursorRow struct {
+	ID        string
+	CreatedAt time.Time
+}
+
+func (cursorRow) TableName() string { return "rows" }
+
+func TestApplyCursorPrevSQL(t *testing.T) {
+	params := &pagination.CursorParams{
+		Cursor:    pagination.EncodeCursor("b", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Direction: pagination.CursorDirectionPrev,
+		Limit:     2,
+	}
+
+	query, err := applyCursor(dryRunDB(t).Model(&cursorRow{}), params)
+	if err != nil {
+		t.Fatalf("applyCursor: %v", err)
+	}
+	sql := query.Limit(params.Limit + 1).Find(&[]cursorRow{}).Statement.SQL.String()
+
+	for _, want := range []string{"(created_at, id) < ($1, $2)", "ORDER BY created_at DESC, id DESC", "LIMIT 3"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("SQL %q does not contain %q", sql, want)
+		}
+	}
+}
+
+// fetchCursorPage runs the query applyCursor builds against rows in memory, then
+// post-processes the result the way the repositories and services do.
+func fetchCursorPage(t *testing.T, rows []cursorRow, params *pagination.CursorParams) ([]cursorRow, *pagination.CursorPagination) {
+	t.Helper()
+	cursor, err := params.DecodeCursor()
+	if err != nil {
+		t.Fatalf("decode cursor: %v", err)
+	}
+
+	less := func(a, b cursorRow) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+	prev := params.Direction == pagination.CursorDirectionPrev
+
+	var matched []cursorRow
+	for _, r := range rows {
+		if cursor != nil {
+			c := cursorRow{ID: cursor.ID, CreatedAt: cursor.CreatedAt}
+			if prev && !less(r, c) || !prev && !less(c, r) {
+				continue
+			}
+		}
+		matched = append(matched, r)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if prev {
+			return less(matched[j], matched[i])
+		}
+		return less(matched[i], matched[j])
+	})
+	if len(matched) > params.Limit+1 {
+		matched = matched[:params.Limit+1]
+	}
+
+	items := orderCursorResults(matched, params)
+	p, items := pagination.NewCursorPagination(items, params,
+		func(r cursorRow) string { return r.ID },
+		func(r cursorRow) time.Time { return r.CreatedAt })
+	return items, p
+}
+
+func TestCursorPagingForwardThenBackward(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []cursorRow{
+		{ID: "a", CreatedAt: base},
+		{ID: "b", CreatedAt: base.Add(time.Minute)},
+		{ID: "c", CreatedAt: base.Add(time.Minute)}, // Same timestamp as b; id breaks the tie
+		{ID: "d", CreatedAt: base.Add(2 * time.Minute)},
+		{ID: "e", CreatedAt: base.Add(3 * time.Minute)},
+	}
+	ids := func(items []cursorRow) string {
+		s := make([]string, len(items))
+		for i, r := range items {
+			s[i] = r.ID
+		}
+		return strings.Join(s, ",")
+	}
+
+	params := &pagination.CursorParams{Direction: pagination.CursorDirectionNext, Limit: 2}
+	page1, p1 := fetchCursorPage(t, rows, params)
+	if got := ids(page1); got != "a,b" || !p1.HasNext || p1.HasPrev {
+		t.Fatalf("page 1 = %s (next %v, prev %v), want a,b with only a next page", got, p1.HasNext, p1.HasPrev)
+	}
+
+	params = &pagination.CursorParams{Cursor: *p1.NextCursor, Direction: pagination.CursorDirectionNext, Limit: 2}
+	page2, p2 := fetchCursorPage(t, rows, params)
+	if got := ids(page2); got != "c,d" || !p2.HasNext || !p2.HasPrev {
+		t.Fatalf("page 2 = %s (next %v, prev %v), want c,d with both neighbours", got, p2.HasNext, p2.HasPrev)
+	}
+
+	params = &pagination.CursorParams{Cursor: *p2.NextCursor, Direction: pagination.CursorDirectionNext, Limit: 2}
+	page3, p3 := fetchCursorPage(t, rows, params)
+	if got := ids(page3); got != "e" || p3.HasNext || !p3.HasPrev {
+		t.Fatalf("page 3 = %s (next %v, prev %v), want e with only a previous page", got, p3.HasNext, p3.HasPrev)
+	}
+
+	params = &pagination.CursorParams{Cursor: *p3.PrevCursor, Direction: pagination.CursorDirectionPrev, Limit: 2}
+	back2, b2 := fetchCursorPage(t, rows, params)
+	if got := ids(back2); got != "c,d" || !b2.HasNext || !b2.HasPrev {
+		t.Fatalf("back to page 2 = %s (next %v, prev %v), want c,d with both neighbours", got, b2.HasNext, b2.HasPrev)
+	}
+
+	params = &pagination.CursorParams{Cursor: *b2.PrevCursor, Direction: pagination.CursorDirectionPrev, Limit: 2}
+	back1, b1 := fetchCursorPage(t, rows, params)
+	if got := ids(back1); got != "a,b" || !b1.HasNext || b1.HasPrev {
+		t.Fatalf("back to page 1 = %s (next %v, prev %v), want a,b with only a next page", got, b1.HasNext, b1.HasPrev)
+	}
+}
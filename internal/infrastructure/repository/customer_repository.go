@@ -9,6 +9,7 @@ import (
 	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
 	"github.com/sangkips/investify-api/pkg/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type customerRepository struct {
@@ -21,12 +22,30 @@ func NewCustomerRepository(db *gorm.DB) domainRepo.CustomerRepository {
 }
 
 func (r *customerRepository) Create(ctx context.Context, customer *entity.Customer) error {
-	return r.db.WithContext(ctx).Create(customer).Error
+	return dbFromContext(ctx, r.db).Create(customer).Error
+}
+
+// CreateBatch creates multiple customers in a single operation
+func (r *customerRepository) CreateBatch(ctx context.Context, customers []entity.Customer) error {
+	if len(customers) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, r.db).CreateInBatches(customers, 100).Error
+}
+
+// ListContacts returns the ID, email and phone of every customer in the tenant
+func (r *customerRepository) ListContacts(ctx context.Context) ([]entity.Customer, error) {
+	var customers []entity.Customer
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Select("id", "email", "phone").Find(&customers).Error
+	return customers, err
 }
 
 func (r *customerRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Customer, error) {
 	var customer entity.Customer
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&customer, "id = ?", id).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Preload("CreatedBy").Preload("UpdatedBy").
+		First(&customer, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -35,26 +54,29 @@ func (r *customerRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity
 
 func (r *customerRepository) GetByEmail(ctx context.Context, email string) (*entity.Customer, error) {
 	var customer entity.Customer
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&customer, "email = ?", email).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&customer, "email = ?", email).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	return &customer, err
 }
 
+// Update saves the customer if nobody changed it since it was read, or returns
+// apperror.ErrVersionConflict
 func (r *customerRepository) Update(ctx context.Context, customer *entity.Customer) error {
-	return r.db.WithContext(ctx).Save(customer).Error
+	// The loyalty balance is only changed through the ledger, never by a profile save
+	return saveVersioned(dbFromContext(ctx, r.db), customer, &customer.Version, "CreatedBy", "UpdatedBy", "LoyaltyPoints")
 }
 
 func (r *customerRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.Customer{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Customer{}, "id = ?", id).Error
 }
 
 func (r *customerRepository) List(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Customer, int64, error) {
 	var customers []entity.Customer
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Customer{}).Scopes(TenantScope(ctx))
+	query := dbFromContext(ctx, r.db).Model(&entity.Customer{}).Scopes(TenantScope(ctx))
 	if !skipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -64,14 +86,15 @@ func (r *customerRepository) List(ctx context.Context, userID uuid.UUID, params
 			"%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
-	if err := query.Count(&total).Error; err != nil {
+	if err := countRows(query, params, &total); err != nil {
 		return nil, 0, err
 	}
 
 	params.Validate()
-	err := query.Offset(params.Offset()).Limit(params.PerPage).
+	err := query.Offset(params.Offset()).Limit(pageLimit(params)).
 		Order("name ASC").
 		Find(&customers).Error
+	customers = trimPage(customers, params, &total)
 
 	return customers, total, err
 }
@@ -82,7 +105,7 @@ func (r *customerRepository) ListWithCursor(ctx context.Context, userID uuid.UUI
 	var customers []entity.Customer
 
 	params.Validate()
-	query := r.db.WithContext(ctx).Model(&entity.Customer{}).Scopes(TenantScope(ctx))
+	query := dbFromContext(ctx, r.db).Model(&entity.Customer{}).Scopes(TenantScope(ctx))
 	if !skipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -92,29 +115,79 @@ func (r *customerRepository) ListWithCursor(ctx context.Context, userID uuid.UUI
 			"%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
-	// Decode cursor if provided
-	cursor, err := params.DecodeCursor()
+	query, err := applyCursor(query, params)
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply cursor-based filtering using created_at and id
-	if cursor != nil {
-		if params.Direction == pagination.CursorDirectionNext {
-			// For next page: get items created after the cursor, ordered by created_at, id
-			query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
-		} else {
-			// For prev page: get items created before the cursor
-			query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
-		}
-	}
-
 	// Fetch limit+1 to detect hasMore
 	err = query.Limit(params.Limit + 1).
-		Order("created_at ASC, id ASC").
 		Find(&customers).Error
 
-	return customers, err
+	return orderCursorResults(customers, params), err
+}
+
+// ListDeleted returns soft-deleted customers, most recently deleted first
+func (r *customerRepository) ListDeleted(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Customer, int64, error) {
+	var customers []entity.Customer
+	var total int64
+
+	query := dbFromContext(ctx, r.db).Unscoped().Model(&entity.Customer{}).Scopes(TenantScope(ctx)).
+		Where("deleted_at IS NOT NULL")
+	if !skipUserFilter && userID != uuid.Nil {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	if search != "" {
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR phone ILIKE ?",
+			"%"+search+"%", "%"+search+"%", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	params.Validate()
+	err := query.Offset(params.Offset()).Limit(params.PerPage).
+		Order("deleted_at DESC").
+		Find(&customers).Error
+
+	return customers, total, err
+}
+
+// GetDeletedByID retrieves a soft-deleted customer
+func (r *customerRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entity.Customer, error) {
+	var customer entity.Customer
+	err := dbFromContext(ctx, r.db).Unscoped().Scopes(TenantScope(ctx)).
+		Where("deleted_at IS NOT NULL").First(&customer, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &customer, err
+}
+
+// Restore clears the soft-delete marker of a customer
+func (r *customerRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return dbFromContext(ctx, r.db).Unscoped().Model(&entity.Customer{}).
+		Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// HardDelete permanently removes a customer, including a soft-deleted one
+func (r *customerRepository) HardDelete(ctx context.Context, id uuid.UUID) error {
+	return dbFromContext(ctx, r.db).Unscoped().Delete(&entity.Customer{}, "id = ?", id).Error
+}
+
+// CountReferences counts the orders and quotations (deleted or not) that point at a customer
+func (r *customerRepository) CountReferences(ctx context.Context, id uuid.UUID) (int64, error) {
+	var orders, quotations int64
+	db := dbFromContext(ctx, r.db)
+	if err := db.Unscoped().Model(&entity.Order{}).Where("customer_id = ?", id).Count(&orders).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Unscoped().Model(&entity.Quotation{}).Where("customer_id = ?", id).Count(&quotations).Error; err != nil {
+		return 0, err
+	}
+	return orders + quotations, nil
 }
 
 type supplierRepository struct {
@@ -127,12 +200,30 @@ func NewSupplierRepository(db *gorm.DB) domainRepo.SupplierRepository {
 }
 
 func (r *supplierRepository) Create(ctx context.Context, supplier *entity.Supplier) error {
-	return r.db.WithContext(ctx).Create(supplier).Error
+	return dbFromContext(ctx, r.db).Create(supplier).Error
+}
+
+// CreateBatch creates multiple suppliers in a single operation
+func (r *supplierRepository) CreateBatch(ctx context.Context, suppliers []entity.Supplier) error {
+	if len(suppliers) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, r.db).CreateInBatches(suppliers, 100).Error
+}
+
+// ListContacts returns the ID, email and phone of every supplier in the tenant
+func (r *supplierRepository) ListContacts(ctx context.Context) ([]entity.Supplier, error) {
+	var suppliers []entity.Supplier
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Select("id", "email", "phone").Find(&suppliers).Error
+	return suppliers, err
 }
 
 func (r *supplierRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Supplier, error) {
 	var supplier entity.Supplier
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&supplier, "id = ?", id).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Preload("CreatedBy").Preload("UpdatedBy").
+		First(&supplier, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -141,7 +232,7 @@ func (r *supplierRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity
 
 func (r *supplierRepository) GetByEmail(ctx context.Context, email string) (*entity.Supplier, error) {
 	var supplier entity.Supplier
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&supplier, "email = ?", email).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&supplier, "email = ?", email).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -149,18 +240,18 @@ func (r *supplierRepository) GetByEmail(ctx context.Context, email string) (*ent
 }
 
 func (r *supplierRepository) Update(ctx context.Context, supplier *entity.Supplier) error {
-	return r.db.WithContext(ctx).Save(supplier).Error
+	return dbFromContext(ctx, r.db).Omit("CreatedBy", "UpdatedBy").Save(supplier).Error
 }
 
 func (r *supplierRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.Supplier{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Supplier{}, "id = ?", id).Error
 }
 
 func (r *supplierRepository) List(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Supplier, int64, error) {
 	var suppliers []entity.Supplier
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Supplier{}).Scopes(TenantScope(ctx))
+	query := dbFromContext(ctx, r.db).Model(&entity.Supplier{}).Scopes(TenantScope(ctx))
 	if !skipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -181,3 +272,117 @@ func (r *supplierRepository) List(ctx context.Context, userID uuid.UUID, params
 
 	return suppliers, total, err
 }
+
+// ListDeleted returns soft-deleted suppliers, most recently deleted first
+func (r *supplierRepository) ListDeleted(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Supplier, int64, error) {
+	var suppliers []entity.Supplier
+	var total int64
+
+	query := dbFromContext(ctx, r.db).Unscoped().Model(&entity.Supplier{}).Scopes(TenantScope(ctx)).
+		Where("deleted_at IS NOT NULL")
+	if !skipUserFilter && userID != uuid.Nil {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	if search != "" {
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR phone ILIKE ? OR shopname ILIKE ?",
+			"%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	params.Validate()
+	err := query.Offset(params.Offset()).Limit(params.PerPage).
+		Order("deleted_at DESC").
+		Find(&suppliers).Error
+
+	return suppliers, total, err
+}
+
+// GetDeletedByID retrieves a soft-deleted supplier
+func (r *supplierRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entity.Supplier, error) {
+	var supplier entity.Supplier
+	err := dbFromContext(ctx, r.db).Unscoped().Scopes(TenantScope(ctx)).
+		Where("deleted_at IS NOT NULL").First(&supplier, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &supplier, err
+}
+
+// Restore clears the soft-delete marker of a supplier
+func (r *supplierRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return dbFromContext(ctx, r.db).Unscoped().Model(&entity.Supplier{}).
+		Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// HardDelete permanently removes a supplier, including a soft-deleted one
+func (r *supplierRepository) HardDelete(ctx context.Context, id uuid.UUID) error {
+	db := dbFromContext(ctx, r.db)
+	if err := db.Delete(&entity.SupplierProduct{}, "supplier_id = ?", id).Error; err != nil {
+		return err
+	}
+	return db.Unscoped().Delete(&entity.Supplier{}, "id = ?", id).Error
+}
+
+// CountReferences counts the purchases (deleted or not) that point at a supplier
+func (r *supplierRepository) CountReferences(ctx context.Context, id uuid.UUID) (int64, error) {
+	var purchases int64
+	err := dbFromContext(ctx, r.db).Unscoped().Model(&entity.Purchase{}).
+		Where("supplier_id = ?", id).Count(&purchases).Error
+	return purchases, err
+}
+
+type supplierProductRepository struct {
+	db *gorm.DB
+}
+
+// NewSupplierProductRepository creates a new supplier product repository
+func NewSupplierProductRepository(db *gorm.DB) domainRepo.SupplierProductRepository {
+	return &supplierProductRepository{db: db}
+}
+
+// Upsert inserts the supplier/product pairs, overwriting the last cost and purchase
+// time of pairs that already exist
+func (r *supplierProductRepository) Upsert(ctx context.Context, items []entity.SupplierProduct) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "supplier_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_cost", "last_purchased_at", "updated_at"}),
+	}).Create(&items).Error
+}
+
+func (r *supplierProductRepository) ListBySupplier(ctx context.Context, supplierID uuid.UUID, params *pagination.PaginationParams) ([]entity.SupplierProduct, int64, error) {
+	var items []entity.SupplierProduct
+	var total int64
+
+	query := dbFromContext(ctx, r.db).Model(&entity.SupplierProduct{}).Scopes(TenantScope(ctx)).
+		Where("supplier_id = ?", supplierID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	params.Validate()
+	err := query.Offset(params.Offset()).Limit(params.PerPage).
+		Preload("Product").
+		Order("last_purchased_at DESC").
+		Find(&items).Error
+
+	return items, total, err
+}
+
+func (r *supplierProductRepository) ListByProducts(ctx context.Context, productIDs []uuid.UUID) ([]entity.SupplierProduct, error) {
+	if len(productIDs) == 0 {
+		return []entity.SupplierProduct{}, nil
+	}
+	var items []entity.SupplierProduct
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Where("product_id IN ?", productIDs).
+		Find(&items).Error
+	return items, err
+}
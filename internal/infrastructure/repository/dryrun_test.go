@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunConn stands in for the database connection of a dry-run handle. Dry runs never
+// send statements, but transactions still begin, commit and roll back on the connection.
+type dryRunConn struct{}
+
+var errDryRun = errors.New("dry run: no database")
+
+func (dryRunConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errDryRun
+}
+
+func (dryRunConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, errDryRun
+}
+
+func (dryRunConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errDryRun
+}
+
+func (dryRunConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func (c dryRunConn) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return c, nil
+}
+
+func (dryRunConn) Commit() error   { return nil }
+func (dryRunConn) Rollback() error { return nil }
+
+// dryRunDB returns a postgres GORM handle that builds SQL without a database
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dryRunConn{}}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	return db
+}
+
+// capturedStatement is the SQL and bound variables of a statement built on a dry run
+type capturedStatement struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// captureSQL records the SQL of every query run on db
+func captureSQL(t *testing.T, db *gorm.DB) *[]string {
+	t.Helper()
+	var sqls []string
+	err := db.Callback().Query().After("gorm:query").Register("test:capture_sql", func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Statement.SQL.String())
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	return &sqls
+}
+
+// captureUpdates records every update statement run on db
+func captureUpdates(t *testing.T, db *gorm.DB) *[]capturedStatement {
+	t.Helper()
+	var stmts []capturedStatement
+	err := db.Callback().Update().After("gorm:update").Register("test:capture_updates", func(tx *gorm.DB) {
+		stmts = append(stmts, capturedStatement{SQL: tx.Statement.SQL.String(), Vars: tx.Statement.Vars})
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	return &stmts
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/sangkips/investify-api/internal/domain/entity"
 	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type idempotencyRepository struct {
@@ -20,10 +21,10 @@ func NewIdempotencyRepository(db *gorm.DB) domainRepo.IdempotencyRepository {
 	return &idempotencyRepository{db: db}
 }
 
-func (r *idempotencyRepository) GetByKey(ctx context.Context, key string, userID uuid.UUID) (*entity.IdempotencyKey, error) {
+func (r *idempotencyRepository) GetByKey(ctx context.Context, tenantID, userID uuid.UUID, key string) (*entity.IdempotencyKey, error) {
 	var ikey entity.IdempotencyKey
-	err := r.db.WithContext(ctx).
-		Where("key = ? AND user_id = ?", key, userID).
+	err := dbFromContext(ctx, r.db).
+		Where("tenant_id = ? AND user_id = ? AND key = ?", tenantID, userID, key).
 		First(&ikey).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -32,12 +33,40 @@ func (r *idempotencyRepository) GetByKey(ctx context.Context, key string, userID
 	return &ikey, err
 }
 
-func (r *idempotencyRepository) Create(ctx context.Context, ikey *entity.IdempotencyKey) error {
-	return r.db.WithContext(ctx).Create(ikey).Error
+func (r *idempotencyRepository) Reserve(ctx context.Context, ikey *entity.IdempotencyKey) (bool, error) {
+	db := dbFromContext(ctx, r.db)
+
+	// Free the scope if the previous use of this key has expired
+	err := db.Where("tenant_id = ? AND user_id = ? AND key = ? AND expires_at < ?",
+		ikey.TenantID, ikey.UserID, ikey.Key, time.Now()).
+		Delete(&entity.IdempotencyKey{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	// The unique scope index makes concurrent reservations race-free
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(ikey)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, ikey *entity.IdempotencyKey) error {
+	return dbFromContext(ctx, r.db).
+		Model(&entity.IdempotencyKey{}).
+		Where("id = ?", ikey.ID).
+		Updates(map[string]interface{}{
+			"response_code":    ikey.ResponseCode,
+			"response_body":    ikey.ResponseBody,
+			"response_headers": ikey.ResponseHeaders,
+			"expires_at":       ikey.ExpiresAt,
+		}).Error
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFromContext(ctx, r.db).Delete(&entity.IdempotencyKey{}, "id = ?", id).Error
 }
 
 func (r *idempotencyRepository) DeleteExpired(ctx context.Context) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Where("expires_at < ?", time.Now()).
 		Delete(&entity.IdempotencyKey{}).Error
 }
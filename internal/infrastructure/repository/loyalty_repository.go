@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type loyaltyRepository struct {
+	db *gorm.DB
+}
+
+// NewLoyaltyRepository creates a new loyalty ledger repository
+func NewLoyaltyRepository(db *gorm.DB) domainRepo.LoyaltyRepository {
+	return &loyaltyRepository{db: db}
+}
+
+// Record relies on the unique (order_id, type) index: a duplicate entry inserts no
+// row and the balance is left alone
+func (r *loyaltyRepository) Record(ctx context.Context, entry *entity.LoyaltyTransaction) (bool, error) {
+	db := dbFromContext(ctx, r.db)
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	err := db.Model(&entity.Customer{}).
+		Where("id = ?", entry.CustomerID).
+		Update("loyalty_points", gorm.Expr("loyalty_points + ?", entry.Points)).Error
+	return err == nil, err
+}
+
+// Redeem takes the points off in a single conditional update so concurrent
+// redemptions can't overdraw the balance
+func (r *loyaltyRepository) Redeem(ctx context.Context, entry *entity.LoyaltyTransaction) (bool, error) {
+	db := dbFromContext(ctx, r.db)
+
+	result := db.Model(&entity.Customer{}).
+		Where("id = ? AND loyalty_points >= ?", entry.CustomerID, -entry.Points).
+		Update("loyalty_points", gorm.Expr("loyalty_points + ?", entry.Points))
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	if err := db.Create(entry).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *loyaltyRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]entity.LoyaltyTransaction, error) {
+	var entries []entity.LoyaltyTransaction
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *loyaltyRepository) ListByCustomer(ctx context.Context, customerID uuid.UUID, params *pagination.PaginationParams) ([]entity.LoyaltyTransaction, int64, error) {
+	var entries []entity.LoyaltyTransaction
+	var total int64
+
+	query := dbFromContext(ctx, r.db).Model(&entity.LoyaltyTransaction{}).Scopes(TenantScope(ctx)).
+		Where("customer_id = ?", customerID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	params.Validate()
+	err := query.Offset(params.Offset()).Limit(params.PerPage).
+		Order("created_at DESC").
+		Find(&entries).Error
+
+	return entries, total, err
+}
@@ -20,13 +20,13 @@ func NewMpesaTransactionRepository(db *gorm.DB) *MpesaTransactionRepository {
 
 // Create creates a new M-Pesa transaction record
 func (r *MpesaTransactionRepository) Create(ctx context.Context, tx *entity.MpesaTransaction) error {
-	return r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Create(tx).Error
+	return dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).Create(tx).Error
 }
 
 // GetByID retrieves a transaction by ID (tenant-scoped)
 func (r *MpesaTransactionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.MpesaTransaction, error) {
 	var tx entity.MpesaTransaction
-	result := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).Where("id = ?", id).First(&tx)
+	result := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).Where("id = ?", id).First(&tx)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -40,7 +40,7 @@ func (r *MpesaTransactionRepository) GetByID(ctx context.Context, id uuid.UUID)
 // This is intentionally NOT tenant-scoped because Safaricom callbacks don't carry tenant context.
 func (r *MpesaTransactionRepository) GetByCheckoutRequestID(ctx context.Context, checkoutRequestID string) (*entity.MpesaTransaction, error) {
 	var tx entity.MpesaTransaction
-	result := r.db.WithContext(ctx).Where("checkout_request_id = ?", checkoutRequestID).First(&tx)
+	result := dbFromContext(ctx, r.db).Where("checkout_request_id = ?", checkoutRequestID).First(&tx)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -53,7 +53,7 @@ func (r *MpesaTransactionRepository) GetByCheckoutRequestID(ctx context.Context,
 // GetByOrderID retrieves all transactions for an order (tenant-scoped)
 func (r *MpesaTransactionRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]entity.MpesaTransaction, error) {
 	var transactions []entity.MpesaTransaction
-	result := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).
+	result := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
 		Where("order_id = ?", orderID).
 		Order("created_at DESC").
 		Find(&transactions)
@@ -65,5 +65,5 @@ func (r *MpesaTransactionRepository) GetByOrderID(ctx context.Context, orderID u
 
 // Update updates an existing transaction
 func (r *MpesaTransactionRepository) Update(ctx context.Context, tx *entity.MpesaTransaction) error {
-	return r.db.WithContext(ctx).Save(tx).Error
+	return dbFromContext(ctx, r.db).Save(tx).Error
 }
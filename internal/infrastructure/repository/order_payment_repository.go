@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type orderPaymentRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderPaymentRepository creates a new order payment repository
+func NewOrderPaymentRepository(db *gorm.DB) domainRepo.OrderPaymentRepository {
+	return &orderPaymentRepository{db: db}
+}
+
+func (r *orderPaymentRepository) Create(ctx context.Context, payment *entity.OrderPayment) error {
+	return dbFromContext(ctx, r.db).Create(payment).Error
+}
+
+func (r *orderPaymentRepository) ListByOrders(ctx context.Context, orderIDs []uuid.UUID, before time.Time) ([]entity.OrderPayment, error) {
+	if len(orderIDs) == 0 {
+		return nil, nil
+	}
+
+	var payments []entity.OrderPayment
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Where("order_id IN ? AND paid_at < ?", orderIDs, before).
+		Order("paid_at ASC").
+		Find(&payments).Error
+	return payments, err
+}
@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/entity"
 	"github.com/sangkips/investify-api/internal/domain/enum"
 	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/pkg/apperror"
 	"github.com/sangkips/investify-api/pkg/pagination"
 	"gorm.io/gorm"
 )
@@ -22,12 +24,12 @@ func NewOrderRepository(db *gorm.DB) domainRepo.OrderRepository {
 }
 
 func (r *orderRepository) Create(ctx context.Context, order *entity.Order) error {
-	return r.db.WithContext(ctx).Create(order).Error
+	return dbFromContext(ctx, r.db).Create(order).Error
 }
 
 func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
 	var order entity.Order
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Scopes(TenantScope(ctx)).
 		Preload("Customer").
 		First(&order, "id = ?", id).Error
@@ -39,32 +41,61 @@ func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Or
 
 func (r *orderRepository) GetByInvoiceNo(ctx context.Context, invoiceNo string) (*entity.Order, error) {
 	var order entity.Order
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&order, "invoice_no = ?", invoiceNo).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&order, "invoice_no = ?", invoiceNo).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	return &order, err
 }
 
+// Update saves the order if nobody changed it since it was read, or returns
+// apperror.ErrVersionConflict
 func (r *orderRepository) Update(ctx context.Context, order *entity.Order) error {
-	return r.db.WithContext(ctx).Save(order).Error
+	return saveVersioned(dbFromContext(ctx, r.db), order, &order.Version, "CreatedBy", "UpdatedBy")
 }
 
 func (r *orderRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.Order{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Order{}, "id = ?", id).Error
 }
 
 func (r *orderRepository) List(ctx context.Context, userID uuid.UUID, params *domainRepo.OrderFilterParams) ([]entity.Order, int64, error) {
 	var orders []entity.Order
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Order{}).Scopes(TenantScope(ctx))
+	query := r.filtered(ctx, userID, params)
+	if err := countRows(query, params.Pagination, &total); err != nil {
+		return nil, 0, err
+	}
+
+	// Sorting
+	sortBy := "created_at"
+	sortOrder := "DESC"
+	if params.SortBy != "" {
+		sortBy = params.SortBy
+	}
+	if params.SortOrder != "" && (params.SortOrder == "ASC" || params.SortOrder == "asc") {
+		sortOrder = "ASC"
+	}
+
+	params.Pagination.Validate()
+	err := query.Offset(params.Pagination.Offset()).Limit(pageLimit(params.Pagination)).
+		Preload("Customer").
+		Order(sortBy + " " + sortOrder).
+		Find(&orders).Error
+	orders = trimPage(orders, params.Pagination, &total)
+
+	return orders, total, err
+}
+
+// filtered returns the tenant's orders matching the filter params
+func (r *orderRepository) filtered(ctx context.Context, userID uuid.UUID, params *domainRepo.OrderFilterParams) *gorm.DB {
+	query := dbFromContext(ctx, r.db).Model(&entity.Order{}).Scopes(TenantScope(ctx))
 	if !params.SkipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
 
 	if params.Search != "" {
-		query = query.Where("invoice_no ILIKE ?", "%"+params.Search+"%")
+		query = searchOrders(query, params.Search)
 	}
 
 	if params.Status != nil {
@@ -83,34 +114,66 @@ func (r *orderRepository) List(ctx context.Context, userID uuid.UUID, params *do
 		query = query.Where("order_date <= ?", *params.EndDate)
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	if params.PaymentType != nil {
+		query = query.Where("LOWER(payment_type) = LOWER(?)", *params.PaymentType)
 	}
 
-	// Sorting
-	sortBy := "created_at"
-	sortOrder := "DESC"
-	if params.SortBy != "" {
-		sortBy = params.SortBy
-	}
-	if params.SortOrder != "" && (params.SortOrder == "ASC" || params.SortOrder == "asc") {
-		sortOrder = "ASC"
+	if params.HasDue != nil {
+		if *params.HasDue {
+			query = query.Where("due > 0")
+		} else {
+			query = query.Where("due <= 0")
+		}
 	}
 
-	params.Pagination.Validate()
-	err := query.Offset(params.Pagination.Offset()).Limit(params.Pagination.PerPage).
-		Preload("Customer").
-		Order(sortBy + " " + sortOrder).
-		Find(&orders).Error
+	return query
+}
 
-	return orders, total, err
+// searchOrders matches orders by invoice number or by customer name. The customer
+// match is a subquery on the order's tenant rather than a join so the orders' own
+// columns stay unambiguous; it is resolved through the customer_id index.
+func searchOrders(query *gorm.DB, search string) *gorm.DB {
+	pattern := "%" + search + "%"
+	return query.Where(`invoice_no ILIKE ? OR customer_id IN (
+		SELECT c.id FROM customers c WHERE c.tenant_id = orders.tenant_id AND c.name ILIKE ?
+	)`, pattern, pattern)
+}
+
+func (r *orderRepository) ScanForExport(ctx context.Context, userID uuid.UUID, params *domainRepo.OrderFilterParams, batchSize int, fn func([]entity.Order) error) error {
+	var after *entity.Order
+	for {
+		query := r.filtered(ctx, userID, params)
+		if after != nil {
+			query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+		}
+
+		var batch []entity.Order
+		if err := query.Order("created_at ASC, id ASC").Limit(batchSize).
+			Preload("Customer").
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		after = &batch[len(batch)-1]
+	}
 }
 
 func (r *orderRepository) GetWithDetails(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
 	var order entity.Order
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Scopes(TenantScope(ctx)).
 		Preload("Customer").
+		Preload("CreatedBy").
+		Preload("UpdatedBy").
 		Preload("Details.Product").
 		Preload("Details.Product.Category").
 		First(&order, "id = ?", id).Error
@@ -120,17 +183,63 @@ func (r *orderRepository) GetWithDetails(ctx context.Context, id uuid.UUID) (*en
 	return &order, err
 }
 
-func (r *orderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status enum.OrderStatus) error {
-	return r.db.WithContext(ctx).Model(&entity.Order{}).
+func (r *orderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status enum.OrderStatus, updatedBy uuid.UUID) error {
+	return dbFromContext(ctx, r.db).Model(&entity.Order{}).
 		Where("id = ?", id).
-		Update("order_status", status).Error
+		Updates(map[string]interface{}{
+			"order_status": status,
+			"updated_by":   updatedBy,
+			"version":      versionBump,
+		}).Error
+}
+
+func (r *orderRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Order, error) {
+	if len(ids) == 0 {
+		return []entity.Order{}, nil
+	}
+	var orders []entity.Order
+	err := dbFromContext(ctx, r.db).
+		Scopes(TenantScope(ctx)).
+		Where("id IN ?", ids).
+		Find(&orders).Error
+	return orders, err
+}
+
+func (r *orderRepository) UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status enum.OrderStatus, updatedBy uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, r.db).Model(&entity.Order{}).
+		Scopes(TenantScope(ctx)).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"order_status": status,
+			"updated_by":   updatedBy,
+			"version":      versionBump,
+		}).Error
+}
+
+func (r *orderRepository) ClearStockReserved(ctx context.Context, id uuid.UUID) error {
+	result := dbFromContext(ctx, r.db).Model(&entity.Order{}).
+		Where("id = ? AND stock_reserved", id).
+		Updates(map[string]interface{}{"stock_reserved": false, "version": versionBump})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return apperror.ErrVersionConflict
+	}
+	return result.Error
+}
+
+func (r *orderRepository) SetRefundShift(ctx context.Context, id, shiftID uuid.UUID) error {
+	return dbFromContext(ctx, r.db).Model(&entity.Order{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"refund_shift_id": shiftID, "version": versionBump}).Error
 }
 
 func (r *orderRepository) GetDueOrders(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams) ([]entity.Order, int64, error) {
 	var orders []entity.Order
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Order{}).Scopes(TenantScope(ctx)).
+	query := dbFromContext(ctx, r.db).Model(&entity.Order{}).Scopes(TenantScope(ctx)).
 		Where("due > 0")
 	if userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
@@ -149,18 +258,29 @@ func (r *orderRepository) GetDueOrders(ctx context.Context, userID uuid.UUID, pa
 	return orders, total, err
 }
 
+// ListByCustomer returns a customer's non-cancelled orders dated on or before until,
+// oldest first
+func (r *orderRepository) ListByCustomer(ctx context.Context, customerID uuid.UUID, until time.Time) ([]entity.Order, error) {
+	var orders []entity.Order
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Where("customer_id = ? AND order_status <> ? AND order_date <= ?", customerID, enum.OrderStatusCancel, until).
+		Order("order_date ASC, created_at ASC").
+		Find(&orders).Error
+	return orders, err
+}
+
 // ListWithCursor returns orders using cursor-based pagination
 func (r *orderRepository) ListWithCursor(ctx context.Context, userID uuid.UUID, params *domainRepo.OrderCursorFilterParams) ([]entity.Order, error) {
 	var orders []entity.Order
 
 	params.Cursor.Validate()
-	query := r.db.WithContext(ctx).Model(&entity.Order{})
-	if !params.SkipUserFilter {
+	query := dbFromContext(ctx, r.db).Model(&entity.Order{}).Scopes(TenantScope(ctx))
+	if !params.SkipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
 
 	if params.Search != "" {
-		query = query.Where("invoice_no ILIKE ?", "%"+params.Search+"%")
+		query = searchOrders(query, params.Search)
 	}
 
 	if params.Status != nil {
@@ -179,25 +299,28 @@ func (r *orderRepository) ListWithCursor(ctx context.Context, userID uuid.UUID,
 		query = query.Where("order_date <= ?", *params.EndDate)
 	}
 
-	cursor, err := params.Cursor.DecodeCursor()
-	if err != nil {
-		return nil, err
+	if params.PaymentType != nil {
+		query = query.Where("LOWER(payment_type) = LOWER(?)", *params.PaymentType)
 	}
 
-	if cursor != nil {
-		if params.Cursor.Direction == pagination.CursorDirectionNext {
-			query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
+	if params.HasDue != nil {
+		if *params.HasDue {
+			query = query.Where("due > 0")
 		} else {
-			query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+			query = query.Where("due <= 0")
 		}
 	}
 
+	query, err := applyCursor(query, params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
 	err = query.Limit(params.Cursor.Limit + 1).
 		Preload("Customer").
-		Order("created_at ASC, id ASC").
 		Find(&orders).Error
 
-	return orders, err
+	return orderCursorResults(orders, params.Cursor), err
 }
 
 type orderDetailRepository struct {
@@ -210,16 +333,16 @@ func NewOrderDetailRepository(db *gorm.DB) domainRepo.OrderDetailRepository {
 }
 
 func (r *orderDetailRepository) Create(ctx context.Context, detail *entity.OrderDetail) error {
-	return r.db.WithContext(ctx).Create(detail).Error
+	return dbFromContext(ctx, r.db).Create(detail).Error
 }
 
 func (r *orderDetailRepository) CreateBatch(ctx context.Context, details []entity.OrderDetail) error {
-	return r.db.WithContext(ctx).Create(&details).Error
+	return dbFromContext(ctx, r.db).Create(&details).Error
 }
 
 func (r *orderDetailRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]entity.OrderDetail, error) {
 	var details []entity.OrderDetail
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Product").
 		Where("order_id = ?", orderID).
 		Find(&details).Error
@@ -227,9 +350,9 @@ func (r *orderDetailRepository) GetByOrderID(ctx context.Context, orderID uuid.U
 }
 
 func (r *orderDetailRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.OrderDetail{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.OrderDetail{}, "id = ?", id).Error
 }
 
 func (r *orderDetailRepository) DeleteByOrderID(ctx context.Context, orderID uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.OrderDetail{}, "order_id = ?", orderID).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.OrderDetail{}, "order_id = ?", orderID).Error
 }
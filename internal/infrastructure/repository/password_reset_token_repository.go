@@ -21,13 +21,13 @@ func NewPasswordResetTokenRepository(db *gorm.DB) repository.PasswordResetTokenR
 
 // Create stores a new password reset token
 func (r *passwordResetTokenRepository) Create(ctx context.Context, token *entity.PasswordResetToken) error {
-	return r.db.WithContext(ctx).Create(token).Error
+	return dbFromContext(ctx, r.db).Create(token).Error
 }
 
 // GetByToken retrieves a token by its value
 func (r *passwordResetTokenRepository) GetByToken(ctx context.Context, token string) (*entity.PasswordResetToken, error) {
 	var resetToken entity.PasswordResetToken
-	err := r.db.WithContext(ctx).Where("token = ?", token).First(&resetToken).Error
+	err := dbFromContext(ctx, r.db).Where("token = ?", token).First(&resetToken).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -39,7 +39,7 @@ func (r *passwordResetTokenRepository) GetByToken(ctx context.Context, token str
 
 // MarkAsUsed marks a token as used
 func (r *passwordResetTokenRepository) MarkAsUsed(ctx context.Context, token string) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&entity.PasswordResetToken{}).
 		Where("token = ?", token).
 		Update("used", true).Error
@@ -47,14 +47,14 @@ func (r *passwordResetTokenRepository) MarkAsUsed(ctx context.Context, token str
 
 // DeleteByEmail deletes all tokens for a specific email
 func (r *passwordResetTokenRepository) DeleteByEmail(ctx context.Context, email string) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Where("email = ?", email).
 		Delete(&entity.PasswordResetToken{}).Error
 }
 
 // DeleteExpired deletes all expired tokens
 func (r *passwordResetTokenRepository) DeleteExpired(ctx context.Context) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Where("expires_at < ?", time.Now()).
 		Delete(&entity.PasswordResetToken{}).Error
 }
@@ -21,21 +21,22 @@ func NewProductRepository(db *gorm.DB) domainRepo.ProductRepository {
 }
 
 func (r *productRepository) Create(ctx context.Context, product *entity.Product) error {
-	return r.db.WithContext(ctx).Create(product).Error
+	return dbFromContext(ctx, r.db).Create(product).Error
 }
 
 func (r *productRepository) CreateBatch(ctx context.Context, products []entity.Product) error {
 	if len(products) == 0 {
 		return nil
 	}
-	return r.db.WithContext(ctx).CreateInBatches(products, 100).Error
+	return dbFromContext(ctx, r.db).CreateInBatches(products, 100).Error
 }
 
 func (r *productRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Product, error) {
 	var product entity.Product
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Scopes(TenantScope(ctx)).
-		Preload("Category").Preload("Unit").
+		Preload("Category").Preload("Unit").Preload("PurchaseUnit").Preload("Images", orderImages).
+		Preload("CreatedBy").Preload("UpdatedBy").
 		First(&product, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
@@ -45,9 +46,10 @@ func (r *productRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 
 func (r *productRepository) GetBySlug(ctx context.Context, slug string) (*entity.Product, error) {
 	var product entity.Product
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Scopes(TenantScope(ctx)).
-		Preload("Category").Preload("Unit").
+		Preload("Category").Preload("Unit").Preload("PurchaseUnit").Preload("Images", orderImages).
+		Preload("CreatedBy").Preload("UpdatedBy").
 		First(&product, "slug = ?", slug).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
@@ -61,9 +63,9 @@ func (r *productRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]en
 		return []entity.Product{}, nil
 	}
 	var products []entity.Product
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Scopes(TenantScope(ctx)).
-		Preload("Category").Preload("Unit").
+		Preload("Category").Preload("Unit").Preload("PurchaseUnit").
 		Where("id IN ?", ids).
 		Find(&products).Error
 	return products, err
@@ -71,26 +73,72 @@ func (r *productRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]en
 
 func (r *productRepository) GetByCode(ctx context.Context, code string) (*entity.Product, error) {
 	var product entity.Product
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&product, "code = ?", code).Error
+	err := dbFromContext(ctx, r.db).
+		Scopes(TenantScope(ctx)).
+		Preload("Category").Preload("Unit").Preload("PurchaseUnit").Preload("Images", orderImages).
+		Preload("CreatedBy").Preload("UpdatedBy").
+		First(&product, "code = ?", code).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	return &product, err
 }
 
+func (r *productRepository) ExistsByCode(ctx context.Context, code string) (bool, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).Model(&entity.Product{}).
+		Scopes(TenantScope(ctx)).
+		Where("code = ?", code).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update saves the product if nobody changed it since it was read, or returns
+// apperror.ErrVersionConflict
 func (r *productRepository) Update(ctx context.Context, product *entity.Product) error {
-	return r.db.WithContext(ctx).Save(product).Error
+	// Gallery images are managed through the product image repository; editors are
+	// only referenced, never written through the product
+	return saveVersioned(dbFromContext(ctx, r.db), product, &product.Version, "Images", "CreatedBy", "UpdatedBy")
 }
 
 func (r *productRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.Product{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Product{}, "id = ?", id).Error
+}
+
+// HardDelete permanently removes a product, its gallery images and its supplier links.
+// Run it in a transaction so a failure leaves the product intact.
+func (r *productRepository) HardDelete(ctx context.Context, id uuid.UUID) error {
+	db := dbFromContext(ctx, r.db)
+	if err := db.Delete(&entity.ProductImage{}, "product_id = ?", id).Error; err != nil {
+		return err
+	}
+	if err := db.Delete(&entity.SupplierProduct{}, "product_id = ?", id).Error; err != nil {
+		return err
+	}
+	return db.Unscoped().Delete(&entity.Product{}, "id = ?", id).Error
+}
+
+// CountReferences counts the order, purchase and quotation lines (deleted or not) that
+// point at a product
+func (r *productRepository) CountReferences(ctx context.Context, id uuid.UUID) (int64, error) {
+	var total int64
+	db := dbFromContext(ctx, r.db)
+	for _, model := range []interface{}{&entity.OrderDetail{}, &entity.PurchaseDetail{}, &entity.QuotationDetail{}} {
+		var count int64
+		if err := db.Unscoped().Model(model).Where("product_id = ?", id).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
 }
 
 func (r *productRepository) List(ctx context.Context, userID uuid.UUID, params *domainRepo.ProductFilterParams) ([]entity.Product, int64, error) {
 	var products []entity.Product
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Product{}).Scopes(TenantScope(ctx))
+	query := dbFromContext(ctx, r.db).Model(&entity.Product{}).Scopes(TenantScope(ctx))
 	// Optional user filter within tenant
 	if !params.SkipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
@@ -110,10 +158,16 @@ func (r *productRepository) List(ctx context.Context, userID uuid.UUID, params *
 	}
 
 	if params.LowStock {
-		query = query.Where("quantity <= quantity_alert")
+		query = query.Where(lowStockCondition)
 	}
 
-	if err := query.Count(&total).Error; err != nil {
+	query = filterStockStatus(query, params.StockStatus)
+
+	if !params.IncludeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	if err := countRows(query, params.Pagination, &total); err != nil {
 		return nil, 0, err
 	}
 
@@ -128,29 +182,59 @@ func (r *productRepository) List(ctx context.Context, userID uuid.UUID, params *
 	}
 
 	params.Pagination.Validate()
-	err := query.Offset(params.Pagination.Offset()).Limit(params.Pagination.PerPage).
-		Preload("Category").Preload("Unit").
+	err := query.Offset(params.Pagination.Offset()).Limit(pageLimit(params.Pagination)).
+		Preload("Category").Preload("Unit").Preload("PurchaseUnit").Preload("Images", orderImages).
 		Order(sortBy + " " + sortOrder).
 		Find(&products).Error
+	products = trimPage(products, params.Pagination, &total)
 
 	return products, total, err
 }
 
+// lowStockCondition matches products whose available stock (on hand less reserved) is at or
+// below their alert level, so fully reserved stock shows up as low
+const lowStockCondition = "quantity - reserved_quantity <= quantity_alert"
+
+// filterStockStatus narrows a product query to the given stock status
+func filterStockStatus(query *gorm.DB, status domainRepo.StockStatus) *gorm.DB {
+	switch status {
+	case domainRepo.StockStatusIn:
+		return query.Where("quantity > 0")
+	case domainRepo.StockStatusOut:
+		return query.Where("quantity <= 0")
+	case domainRepo.StockStatusLow:
+		return query.Where(lowStockCondition)
+	}
+	return query
+}
+
 func (r *productRepository) GetLowStock(ctx context.Context, userID uuid.UUID) ([]entity.Product, error) {
 	var products []entity.Product
-	query := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).
-		Where("quantity <= quantity_alert")
+	query := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Where(lowStockCondition)
+	if userID != uuid.Nil {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Preload("Category").Preload("Unit").Preload("PurchaseUnit").Find(&products).Error
+	return products, err
+}
+
+func (r *productRepository) GetBelowMinStock(ctx context.Context, userID uuid.UUID) ([]entity.Product, error) {
+	var products []entity.Product
+	// Inactive products are off the shelf, so they aren't suggested for reordering
+	query := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Where("min_stock > 0 AND quantity <= min_stock AND is_active = ?", true)
 	if userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
-	err := query.Preload("Category").Preload("Unit").Find(&products).Error
+	err := query.Order("min_stock - quantity DESC").Find(&products).Error
 	return products, err
 }
 
 func (r *productRepository) UpdateQuantity(ctx context.Context, id uuid.UUID, quantity int) error {
-	return r.db.WithContext(ctx).Model(&entity.Product{}).
+	return dbFromContext(ctx, r.db).Model(&entity.Product{}).
 		Where("id = ?", id).
-		Update("quantity", quantity).Error
+		Updates(map[string]interface{}{"quantity": quantity, "version": versionBump}).Error
 }
 
 // UpdateQuantityBatch updates quantities for multiple products in a single transaction
@@ -158,11 +242,11 @@ func (r *productRepository) UpdateQuantityBatch(ctx context.Context, updates map
 	if len(updates) == 0 {
 		return nil
 	}
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		for id, quantity := range updates {
 			if err := tx.Model(&entity.Product{}).
 				Where("id = ?", id).
-				Update("quantity", quantity).Error; err != nil {
+				Updates(map[string]interface{}{"quantity": quantity, "version": versionBump}).Error; err != nil {
 				return err
 			}
 		}
@@ -170,12 +254,16 @@ func (r *productRepository) UpdateQuantityBatch(ctx context.Context, updates map
 	})
 }
 
+// sufficientStockCondition matches the product with the given ID if its available stock
+// covers the given amount, or if it may go below zero (services, pre-order items)
+const sufficientStockCondition = "id = ? AND (allow_negative_stock OR quantity - reserved_quantity >= ?)"
+
 // AtomicDecrementQuantity atomically decrements stock only if sufficient quantity exists.
-// Uses: UPDATE products SET quantity = quantity - amount WHERE id = ? AND quantity >= amount
+// Uses: UPDATE products SET quantity = quantity - amount WHERE sufficientStockCondition
 func (r *productRepository) AtomicDecrementQuantity(ctx context.Context, id uuid.UUID, amount int) (bool, error) {
-	result := r.db.WithContext(ctx).Model(&entity.Product{}).
-		Where("id = ? AND quantity >= ?", id, amount).
-		Update("quantity", gorm.Expr("quantity - ?", amount))
+	result := dbFromContext(ctx, r.db).Model(&entity.Product{}).
+		Where(sufficientStockCondition, id, amount).
+		Updates(map[string]interface{}{"quantity": gorm.Expr("quantity - ?", amount), "version": versionBump})
 
 	if result.Error != nil {
 		return false, result.Error
@@ -194,11 +282,11 @@ func (r *productRepository) AtomicDecrementBatch(ctx context.Context, decrements
 
 	var failedIDs []uuid.UUID
 
-	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err := dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		for id, amount := range decrements {
 			result := tx.Model(&entity.Product{}).
-				Where("id = ? AND quantity >= ?", id, amount).
-				Update("quantity", gorm.Expr("quantity - ?", amount))
+				Where(sufficientStockCondition, id, amount).
+				Updates(map[string]interface{}{"quantity": gorm.Expr("quantity - ?", amount), "version": versionBump})
 
 			if result.Error != nil {
 				return result.Error
@@ -231,11 +319,89 @@ func (r *productRepository) AtomicIncrementBatch(ctx context.Context, increments
 		return nil
 	}
 
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		for id, amount := range increments {
 			if err := tx.Model(&entity.Product{}).
 				Where("id = ?", id).
-				Update("quantity", gorm.Expr("quantity + ?", amount)).Error; err != nil {
+				Updates(map[string]interface{}{"quantity": gorm.Expr("quantity + ?", amount), "version": versionBump}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AtomicReserveBatch atomically reserves stock for multiple products in a single transaction.
+// If any product has too little available stock, the entire transaction is rolled back.
+func (r *productRepository) AtomicReserveBatch(ctx context.Context, reservations map[uuid.UUID]int) ([]uuid.UUID, error) {
+	if len(reservations) == 0 {
+		return nil, nil
+	}
+
+	var failedIDs []uuid.UUID
+
+	err := dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for id, amount := range reservations {
+			result := tx.Model(&entity.Product{}).
+				Where(sufficientStockCondition, id, amount).
+				Updates(map[string]interface{}{"reserved_quantity": gorm.Expr("reserved_quantity + ?", amount), "version": versionBump})
+
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				failedIDs = append(failedIDs, id)
+			}
+		}
+
+		if len(failedIDs) > 0 {
+			return gorm.ErrInvalidTransaction
+		}
+
+		return nil
+	})
+
+	if err == gorm.ErrInvalidTransaction && len(failedIDs) > 0 {
+		return failedIDs, nil
+	}
+
+	return failedIDs, err
+}
+
+// ReleaseReservedBatch releases reserved stock for multiple products (for cancelled reservations).
+func (r *productRepository) ReleaseReservedBatch(ctx context.Context, reservations map[uuid.UUID]int) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+
+	return dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for id, amount := range reservations {
+			if err := tx.Model(&entity.Product{}).
+				Where("id = ?", id).
+				Updates(map[string]interface{}{"reserved_quantity": gorm.Expr("GREATEST(reserved_quantity - ?, 0)", amount), "version": versionBump}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CommitReservedBatch takes reserved stock off quantity for multiple products (for completed reservations).
+func (r *productRepository) CommitReservedBatch(ctx context.Context, reservations map[uuid.UUID]int) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+
+	return dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for id, amount := range reservations {
+			if err := tx.Model(&entity.Product{}).
+				Where("id = ?", id).
+				Updates(map[string]interface{}{
+					"quantity":          gorm.Expr("quantity - ?", amount),
+					"reserved_quantity": gorm.Expr("GREATEST(reserved_quantity - ?, 0)", amount),
+					"version":           versionBump,
+				}).Error; err != nil {
 				return err
 			}
 		}
@@ -248,8 +414,8 @@ func (r *productRepository) ListWithCursor(ctx context.Context, userID uuid.UUID
 	var products []entity.Product
 
 	params.Cursor.Validate()
-	query := r.db.WithContext(ctx).Model(&entity.Product{})
-	if !params.SkipUserFilter {
+	query := dbFromContext(ctx, r.db).Model(&entity.Product{}).Scopes(TenantScope(ctx))
+	if !params.SkipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
 
@@ -267,30 +433,75 @@ func (r *productRepository) ListWithCursor(ctx context.Context, userID uuid.UUID
 	}
 
 	if params.LowStock {
-		query = query.Where("quantity <= quantity_alert")
+		query = query.Where(lowStockCondition)
 	}
 
-	// Decode cursor if provided
-	cursor, err := params.Cursor.DecodeCursor()
-	if err != nil {
-		return nil, err
+	query = filterStockStatus(query, params.StockStatus)
+
+	if !params.IncludeInactive {
+		query = query.Where("is_active = ?", true)
 	}
 
-	if cursor != nil {
-		if params.Cursor.Direction == pagination.CursorDirectionNext {
-			query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
-		} else {
-			query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
-		}
+	query, err := applyCursor(query, params.Cursor)
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch limit+1 to detect hasMore
-	err = query.Limit(params.Cursor.Limit + 1).
-		Preload("Category").Preload("Unit").
-		Order("created_at ASC, id ASC").
+	err = query.Limit(params.Cursor.Limit+1).
+		Preload("Category").Preload("Unit").Preload("PurchaseUnit").Preload("Images", orderImages).
 		Find(&products).Error
 
-	return products, err
+	return orderCursorResults(products, params.Cursor), err
+}
+
+// orderImages preloads a product's gallery in display order
+func orderImages(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC, created_at ASC")
+}
+
+type productImageRepository struct {
+	db *gorm.DB
+}
+
+// NewProductImageRepository creates a new product image repository
+func NewProductImageRepository(db *gorm.DB) domainRepo.ProductImageRepository {
+	return &productImageRepository{db: db}
+}
+
+func (r *productImageRepository) Create(ctx context.Context, image *entity.ProductImage) error {
+	return dbFromContext(ctx, r.db).Create(image).Error
+}
+
+func (r *productImageRepository) ListByProduct(ctx context.Context, productID uuid.UUID) ([]entity.ProductImage, error) {
+	var images []entity.ProductImage
+	err := dbFromContext(ctx, r.db).
+		Scopes(TenantScope(ctx), orderImages).
+		Where("product_id = ?", productID).
+		Find(&images).Error
+	return images, err
+}
+
+func (r *productImageRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).Delete(&entity.ProductImage{}, "id = ?", id).Error
+}
+
+func (r *productImageRepository) SaveGallery(ctx context.Context, productID uuid.UUID, images []entity.ProductImage) error {
+	db := dbFromContext(ctx, r.db)
+	var primaryURL *string
+	for _, image := range images {
+		if image.IsPrimary {
+			primaryURL = &image.URL
+		}
+		err := db.Model(&entity.ProductImage{}).
+			Where("id = ?", image.ID).
+			Updates(map[string]interface{}{"sort_order": image.SortOrder, "is_primary": image.IsPrimary}).Error
+		if err != nil {
+			return err
+		}
+	}
+	// Only the image column is written so concurrent stock updates aren't overwritten
+	return db.Model(&entity.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{"product_image": primaryURL, "version": versionBump}).Error
 }
 
 type categoryRepository struct {
@@ -303,12 +514,12 @@ func NewCategoryRepository(db *gorm.DB) domainRepo.CategoryRepository {
 }
 
 func (r *categoryRepository) Create(ctx context.Context, category *entity.Category) error {
-	return r.db.WithContext(ctx).Create(category).Error
+	return dbFromContext(ctx, r.db).Create(category).Error
 }
 
 func (r *categoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Category, error) {
 	var category entity.Category
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&category, "id = ?", id).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&category, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -317,7 +528,7 @@ func (r *categoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity
 
 func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (*entity.Category, error) {
 	var category entity.Category
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&category, "slug = ?", slug).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&category, "slug = ?", slug).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -325,18 +536,18 @@ func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (*entit
 }
 
 func (r *categoryRepository) Update(ctx context.Context, category *entity.Category) error {
-	return r.db.WithContext(ctx).Save(category).Error
+	return dbFromContext(ctx, r.db).Save(category).Error
 }
 
 func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.Category{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Category{}, "id = ?", id).Error
 }
 
 func (r *categoryRepository) List(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Category, int64, error) {
 	var categories []entity.Category
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Category{}).Scopes(TenantScope(ctx))
+	query := dbFromContext(ctx, r.db).Model(&entity.Category{}).Scopes(TenantScope(ctx))
 	if !skipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -351,12 +562,22 @@ func (r *categoryRepository) List(ctx context.Context, userID uuid.UUID, params
 
 	params.Validate()
 	err := query.Offset(params.Offset()).Limit(params.PerPage).
+		Select("categories.*, COALESCE(pc.product_count, 0) AS product_count").
+		Joins(productCountJoin("categories", "category_id")).
 		Order("name ASC").
 		Find(&categories).Error
 
 	return categories, total, err
 }
 
+// productCountJoin left-joins the number of non-deleted products per foreign key
+// as pc.product_count. Aggregating in a derived table keeps the outer query's
+// unqualified columns (tenant_id, user_id, name) unambiguous.
+func productCountJoin(table, fkColumn string) string {
+	return "LEFT JOIN (SELECT " + fkColumn + ", COUNT(*) AS product_count FROM products" +
+		" WHERE deleted_at IS NULL GROUP BY " + fkColumn + ") pc ON pc." + fkColumn + " = " + table + ".id"
+}
+
 type unitRepository struct {
 	db *gorm.DB
 }
@@ -367,12 +588,12 @@ func NewUnitRepository(db *gorm.DB) domainRepo.UnitRepository {
 }
 
 func (r *unitRepository) Create(ctx context.Context, unit *entity.Unit) error {
-	return r.db.WithContext(ctx).Create(unit).Error
+	return dbFromContext(ctx, r.db).Create(unit).Error
 }
 
 func (r *unitRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Unit, error) {
 	var unit entity.Unit
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&unit, "id = ?", id).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&unit, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -381,7 +602,7 @@ func (r *unitRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Uni
 
 func (r *unitRepository) GetBySlug(ctx context.Context, slug string) (*entity.Unit, error) {
 	var unit entity.Unit
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&unit, "slug = ?", slug).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&unit, "slug = ?", slug).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -389,18 +610,18 @@ func (r *unitRepository) GetBySlug(ctx context.Context, slug string) (*entity.Un
 }
 
 func (r *unitRepository) Update(ctx context.Context, unit *entity.Unit) error {
-	return r.db.WithContext(ctx).Save(unit).Error
+	return dbFromContext(ctx, r.db).Save(unit).Error
 }
 
 func (r *unitRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.Unit{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Unit{}, "id = ?", id).Error
 }
 
 func (r *unitRepository) List(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams, search string, skipUserFilter bool) ([]entity.Unit, int64, error) {
 	var units []entity.Unit
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Unit{}).Scopes(TenantScope(ctx))
+	query := dbFromContext(ctx, r.db).Model(&entity.Unit{}).Scopes(TenantScope(ctx))
 	if !skipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -415,6 +636,8 @@ func (r *unitRepository) List(ctx context.Context, userID uuid.UUID, params *pag
 
 	params.Validate()
 	err := query.Offset(params.Offset()).Limit(params.PerPage).
+		Select("units.*, COALESCE(pc.product_count, 0) AS product_count").
+		Joins(productCountJoin("units", "unit_id")).
 		Order("name ASC").
 		Find(&units).Error
 
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
+)
+
+func TestGetLowStockCountsReservedStock(t *testing.T) {
+	db := dryRunDB(t)
+	sqls := captureSQL(t, db)
+	ctx := WithTenant(context.Background(), uuid.New())
+
+	if _, err := NewProductRepository(db).GetLowStock(ctx, uuid.Nil); err != nil {
+		t.Fatalf("GetLowStock: %v", err)
+	}
+
+	if len(*sqls) == 0 || !strings.Contains((*sqls)[0], lowStockCondition) {
+		t.Fatalf("low-stock query %q does not compare available stock", *sqls)
+	}
+}
+
+func TestFilterStockStatusLowCountsReservedStock(t *testing.T) {
+	query := filterStockStatus(dryRunDB(t).Model(&cursorRow{}), domainRepo.StockStatusLow)
+	sql := query.Find(&[]cursorRow{}).Statement.SQL.String()
+	if !strings.Contains(sql, lowStockCondition) {
+		t.Errorf("SQL %q does not contain %q", sql, lowStockCondition)
+	}
+}
+
+func TestStockBatchesGuardEachProduct(t *testing.T) {
+	// A mixed cart: one product may go below zero, the other may not. The database
+	// decides per row from allow_negative_stock, so every product's statement must
+	// carry the guard with its own ID and amount.
+	service, stocked := uuid.New(), uuid.New()
+	cart := map[uuid.UUID]int{service: 3, stocked: 2}
+	wantWhere := "WHERE (id = $3 AND (allow_negative_stock OR quantity - reserved_quantity >= $4))"
+
+	batches := map[string]func(domainRepo.ProductRepository) ([]uuid.UUID, error){
+		"decrement": func(r domainRepo.ProductRepository) ([]uuid.UUID, error) {
+			return r.AtomicDecrementBatch(context.Background(), cart)
+		},
+		"reserve": func(r domainRepo.ProductRepository) ([]uuid.UUID, error) {
+			return r.AtomicReserveBatch(context.Background(), cart)
+		},
+	}
+
+	for name, batch := range batches {
+		t.Run(name, func(t *testing.T) {
+			db := dryRunDB(t)
+			stmts := captureUpdates(t, db)
+
+			if _, err := batch(NewProductRepository(db)); err != nil {
+				t.Fatalf("batch: %v", err)
+			}
+
+			if len(*stmts) != len(cart) {
+				t.Fatalf("ran %d updates, want one per product", len(*stmts))
+			}
+			guarded := make(map[uuid.UUID]int)
+			for _, stmt := range *stmts {
+				if !strings.Contains(stmt.SQL, wantWhere) {
+					t.Errorf("update %q does not contain %q", stmt.SQL, wantWhere)
+					continue
+				}
+				id, _ := stmt.Vars[len(stmt.Vars)-2].(uuid.UUID)
+				amount, _ := stmt.Vars[len(stmt.Vars)-1].(int)
+				guarded[id] = amount
+			}
+			for id, amount := range cart {
+				if guarded[id] != amount {
+					t.Errorf("product %s guarded for %d, want %d", id, guarded[id], amount)
+				}
+			}
+		})
+	}
+}
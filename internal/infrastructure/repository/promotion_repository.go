@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+// NewPromotionRepository creates a new promotion repository
+func NewPromotionRepository(db *gorm.DB) domainRepo.PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+func (r *promotionRepository) Create(ctx context.Context, promotion *entity.Promotion) error {
+	// Select every column so is_active: false is written rather than replaced by the
+	// column default
+	return dbFromContext(ctx, r.db).Select("*").Create(promotion).Error
+}
+
+func (r *promotionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Promotion, error) {
+	var promotion entity.Promotion
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&promotion, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &promotion, err
+}
+
+func (r *promotionRepository) GetByCode(ctx context.Context, code string) (*entity.Promotion, error) {
+	var promotion entity.Promotion
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		First(&promotion, "code = ?", strings.ToUpper(strings.TrimSpace(code))).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &promotion, err
+}
+
+func (r *promotionRepository) Update(ctx context.Context, promotion *entity.Promotion) error {
+	// used_count only changes through Use
+	return dbFromContext(ctx, r.db).Omit("UsedCount", "CreatedBy", "UpdatedBy").Save(promotion).Error
+}
+
+func (r *promotionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFromContext(ctx, r.db).Delete(&entity.Promotion{}, "id = ?", id).Error
+}
+
+func (r *promotionRepository) List(ctx context.Context, params *pagination.PaginationParams, search string) ([]entity.Promotion, int64, error) {
+	var promotions []entity.Promotion
+	var total int64
+
+	query := dbFromContext(ctx, r.db).Model(&entity.Promotion{}).Scopes(TenantScope(ctx))
+	if search != "" {
+		query = query.Where("code ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	params.Validate()
+	err := query.Offset(params.Offset()).Limit(params.PerPage).
+		Order("created_at DESC").
+		Find(&promotions).Error
+
+	return promotions, total, err
+}
+
+// Use checks the limit and counts the use in a single conditional update
+func (r *promotionRepository) Use(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.db).Model(&entity.Promotion{}).Scopes(TenantScope(ctx)).
+		Where("id = ? AND is_active", id).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Where("usage_limit IS NULL OR used_count < usage_limit").
+		Update("used_count", gorm.Expr("used_count + 1"))
+	return result.RowsAffected > 0, result.Error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/entity"
@@ -22,12 +23,12 @@ func NewPurchaseRepository(db *gorm.DB) domainRepo.PurchaseRepository {
 }
 
 func (r *purchaseRepository) Create(ctx context.Context, purchase *entity.Purchase) error {
-	return r.db.WithContext(ctx).Create(purchase).Error
+	return dbFromContext(ctx, r.db).Create(purchase).Error
 }
 
 func (r *purchaseRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Purchase, error) {
 	var purchase entity.Purchase
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Scopes(TenantScope(ctx)).
 		Preload("Supplier").
 		Preload("CreatedBy").
@@ -40,7 +41,7 @@ func (r *purchaseRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity
 
 func (r *purchaseRepository) GetByPurchaseNo(ctx context.Context, purchaseNo string) (*entity.Purchase, error) {
 	var purchase entity.Purchase
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&purchase, "purchase_no = ?", purchaseNo).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&purchase, "purchase_no = ?", purchaseNo).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -48,18 +49,18 @@ func (r *purchaseRepository) GetByPurchaseNo(ctx context.Context, purchaseNo str
 }
 
 func (r *purchaseRepository) Update(ctx context.Context, purchase *entity.Purchase) error {
-	return r.db.WithContext(ctx).Save(purchase).Error
+	return dbFromContext(ctx, r.db).Save(purchase).Error
 }
 
 func (r *purchaseRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.Purchase{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Purchase{}, "id = ?", id).Error
 }
 
 func (r *purchaseRepository) List(ctx context.Context, userID uuid.UUID, params *domainRepo.PurchaseFilterParams) ([]entity.Purchase, int64, error) {
 	var purchases []entity.Purchase
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Purchase{}).Scopes(TenantScope(ctx))
+	query := dbFromContext(ctx, r.db).Model(&entity.Purchase{}).Scopes(TenantScope(ctx))
 	if !params.SkipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
@@ -109,12 +110,14 @@ func (r *purchaseRepository) List(ctx context.Context, userID uuid.UUID, params
 
 func (r *purchaseRepository) GetWithDetails(ctx context.Context, id uuid.UUID) (*entity.Purchase, error) {
 	var purchase entity.Purchase
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Scopes(TenantScope(ctx)).
 		Preload("Supplier").
 		Preload("CreatedBy").
 		Preload("UpdatedBy").
 		Preload("Details.Product").
+		Preload("Details.Product.Unit").
+		Preload("Details.Product.PurchaseUnit").
 		First(&purchase, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
@@ -122,20 +125,26 @@ func (r *purchaseRepository) GetWithDetails(ctx context.Context, id uuid.UUID) (
 	return &purchase, err
 }
 
+// UpdateStatus sets a purchase's status. Approving a purchase receives its stock, so
+// it also records the time as received_at.
 func (r *purchaseRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status enum.PurchaseStatus, updatedBy uuid.UUID) error {
-	return r.db.WithContext(ctx).Model(&entity.Purchase{}).
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_by": updatedBy,
+	}
+	if status == enum.PurchaseStatusApproved {
+		updates["received_at"] = time.Now()
+	}
+	return dbFromContext(ctx, r.db).Model(&entity.Purchase{}).
 		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"status":     status,
-			"updated_by": updatedBy,
-		}).Error
+		Updates(updates).Error
 }
 
 func (r *purchaseRepository) GetPendingPurchases(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams) ([]entity.Purchase, int64, error) {
 	var purchases []entity.Purchase
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Purchase{}).Scopes(TenantScope(ctx)).
+	query := dbFromContext(ctx, r.db).Model(&entity.Purchase{}).Scopes(TenantScope(ctx)).
 		Where("status = ?", enum.PurchaseStatusPending)
 	if userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
@@ -159,8 +168,8 @@ func (r *purchaseRepository) ListWithCursor(ctx context.Context, userID uuid.UUI
 	var purchases []entity.Purchase
 
 	params.Cursor.Validate()
-	query := r.db.WithContext(ctx).Model(&entity.Purchase{})
-	if !params.SkipUserFilter {
+	query := dbFromContext(ctx, r.db).Model(&entity.Purchase{}).Scopes(TenantScope(ctx))
+	if !params.SkipUserFilter && userID != uuid.Nil {
 		query = query.Where("user_id = ?", userID)
 	}
 
@@ -184,25 +193,16 @@ func (r *purchaseRepository) ListWithCursor(ctx context.Context, userID uuid.UUI
 		query = query.Where("date <= ?", *params.EndDate)
 	}
 
-	cursor, err := params.Cursor.DecodeCursor()
+	query, err := applyCursor(query, params.Cursor)
 	if err != nil {
 		return nil, err
 	}
 
-	if cursor != nil {
-		if params.Cursor.Direction == pagination.CursorDirectionNext {
-			query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
-		} else {
-			query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
-		}
-	}
-
 	err = query.Limit(params.Cursor.Limit + 1).
 		Preload("Supplier").
-		Order("created_at ASC, id ASC").
 		Find(&purchases).Error
 
-	return purchases, err
+	return orderCursorResults(purchases, params.Cursor), err
 }
 
 type purchaseDetailRepository struct {
@@ -215,16 +215,16 @@ func NewPurchaseDetailRepository(db *gorm.DB) domainRepo.PurchaseDetailRepositor
 }
 
 func (r *purchaseDetailRepository) Create(ctx context.Context, detail *entity.PurchaseDetail) error {
-	return r.db.WithContext(ctx).Create(detail).Error
+	return dbFromContext(ctx, r.db).Create(detail).Error
 }
 
 func (r *purchaseDetailRepository) CreateBatch(ctx context.Context, details []entity.PurchaseDetail) error {
-	return r.db.WithContext(ctx).Create(&details).Error
+	return dbFromContext(ctx, r.db).Create(&details).Error
 }
 
 func (r *purchaseDetailRepository) GetByPurchaseID(ctx context.Context, purchaseID uuid.UUID) ([]entity.PurchaseDetail, error) {
 	var details []entity.PurchaseDetail
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Product").
 		Where("purchase_id = ?", purchaseID).
 		Find(&details).Error
@@ -232,9 +232,27 @@ func (r *purchaseDetailRepository) GetByPurchaseID(ctx context.Context, purchase
 }
 
 func (r *purchaseDetailRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.PurchaseDetail{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.PurchaseDetail{}, "id = ?", id).Error
 }
 
 func (r *purchaseDetailRepository) DeleteByPurchaseID(ctx context.Context, purchaseID uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.PurchaseDetail{}, "purchase_id = ?", purchaseID).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.PurchaseDetail{}, "purchase_id = ?", purchaseID).Error
+}
+
+func (r *purchaseDetailRepository) PendingProductIDs(ctx context.Context, productIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	db := dbFromContext(ctx, r.db)
+	pending := db.Model(&entity.Purchase{}).Scopes(TenantScope(ctx)).
+		Select("id").
+		Where("status = ?", enum.PurchaseStatusPending)
+
+	var ids []uuid.UUID
+	err := db.Model(&entity.PurchaseDetail{}).
+		Where("purchase_id IN (?) AND product_id IN ?", pending, productIDs).
+		Distinct().
+		Pluck("product_id", &ids).Error
+	return ids, err
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/sangkips/investify-api/internal/domain/enum"
 	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type quotationRepository struct {
@@ -21,12 +22,23 @@ func NewQuotationRepository(db *gorm.DB) domainRepo.QuotationRepository {
 }
 
 func (r *quotationRepository) Create(ctx context.Context, quotation *entity.Quotation) error {
-	return r.db.WithContext(ctx).Create(quotation).Error
+	return dbFromContext(ctx, r.db).Create(quotation).Error
+}
+
+// LockForUpdate takes a row lock on the quotation until the surrounding transaction
+// ends, serializing concurrent writers of the same quotation
+func (r *quotationRepository) LockForUpdate(ctx context.Context, id uuid.UUID) error {
+	var quotation entity.Quotation
+	return dbFromContext(ctx, r.db).
+		Scopes(TenantScope(ctx)).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&quotation, "id = ?", id).Error
 }
 
 func (r *quotationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Quotation, error) {
 	var quotation entity.Quotation
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Scopes(TenantScope(ctx)).
 		Preload("Customer").
 		First(&quotation, "id = ?", id).Error
@@ -38,7 +50,7 @@ func (r *quotationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entit
 
 func (r *quotationRepository) GetByReference(ctx context.Context, reference string) (*entity.Quotation, error) {
 	var quotation entity.Quotation
-	err := r.db.WithContext(ctx).Scopes(TenantScope(ctx)).First(&quotation, "reference = ?", reference).Error
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).First(&quotation, "reference = ?", reference).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -46,18 +58,18 @@ func (r *quotationRepository) GetByReference(ctx context.Context, reference stri
 }
 
 func (r *quotationRepository) Update(ctx context.Context, quotation *entity.Quotation) error {
-	return r.db.WithContext(ctx).Save(quotation).Error
+	return dbFromContext(ctx, r.db).Save(quotation).Error
 }
 
 func (r *quotationRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.Quotation{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Quotation{}, "id = ?", id).Error
 }
 
 func (r *quotationRepository) List(ctx context.Context, userID uuid.UUID, params *domainRepo.QuotationFilterParams) ([]entity.Quotation, int64, error) {
 	var quotations []entity.Quotation
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Quotation{}).Scopes(TenantScope(ctx))
+	query := dbFromContext(ctx, r.db).Model(&entity.Quotation{}).Scopes(TenantScope(ctx))
 
 	// Only filter by user_id if a non-zero userID is provided (super-admin can see all)
 	if userID != uuid.Nil {
@@ -77,6 +89,14 @@ func (r *quotationRepository) List(ctx context.Context, userID uuid.UUID, params
 		query = query.Where("customer_id = ?", *params.CustomerID)
 	}
 
+	if params.StartDate != nil {
+		query = query.Where("date >= ?", *params.StartDate)
+	}
+
+	if params.EndDate != nil {
+		query = query.Where("date <= ?", *params.EndDate)
+	}
+
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -102,7 +122,7 @@ func (r *quotationRepository) List(ctx context.Context, userID uuid.UUID, params
 
 func (r *quotationRepository) GetWithDetails(ctx context.Context, id uuid.UUID) (*entity.Quotation, error) {
 	var quotation entity.Quotation
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Scopes(TenantScope(ctx)).
 		Preload("Customer").
 		Preload("Details.Product").
@@ -114,17 +134,11 @@ func (r *quotationRepository) GetWithDetails(ctx context.Context, id uuid.UUID)
 }
 
 func (r *quotationRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status enum.QuotationStatus) error {
-	return r.db.WithContext(ctx).Model(&entity.Quotation{}).
+	return dbFromContext(ctx, r.db).Model(&entity.Quotation{}).
 		Where("id = ?", id).
 		Update("status", status).Error
 }
 
-func (r *quotationRepository) GetNextReferenceNumber(ctx context.Context) (int, error) {
-	var count int64
-	err := r.db.WithContext(ctx).Model(&entity.Quotation{}).Scopes(TenantScope(ctx)).Count(&count).Error
-	return int(count) + 1, err
-}
-
 type quotationDetailRepository struct {
 	db *gorm.DB
 }
@@ -135,16 +149,16 @@ func NewQuotationDetailRepository(db *gorm.DB) domainRepo.QuotationDetailReposit
 }
 
 func (r *quotationDetailRepository) Create(ctx context.Context, detail *entity.QuotationDetail) error {
-	return r.db.WithContext(ctx).Create(detail).Error
+	return dbFromContext(ctx, r.db).Create(detail).Error
 }
 
 func (r *quotationDetailRepository) CreateBatch(ctx context.Context, details []entity.QuotationDetail) error {
-	return r.db.WithContext(ctx).Create(&details).Error
+	return dbFromContext(ctx, r.db).Create(&details).Error
 }
 
 func (r *quotationDetailRepository) GetByQuotationID(ctx context.Context, quotationID uuid.UUID) ([]entity.QuotationDetail, error) {
 	var details []entity.QuotationDetail
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Product").
 		Where("quotation_id = ?", quotationID).
 		Find(&details).Error
@@ -152,9 +166,9 @@ func (r *quotationDetailRepository) GetByQuotationID(ctx context.Context, quotat
 }
 
 func (r *quotationDetailRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.QuotationDetail{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.QuotationDetail{}, "id = ?", id).Error
 }
 
 func (r *quotationDetailRepository) DeleteByQuotationID(ctx context.Context, quotationID uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.QuotationDetail{}, "quotation_id = ?", quotationID).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.QuotationDetail{}, "quotation_id = ?", quotationID).Error
 }
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type sequenceRepository struct {
+	db *gorm.DB
+}
+
+// NewSequenceRepository creates a new sequence repository
+func NewSequenceRepository(db *gorm.DB) domainRepo.SequenceRepository {
+	return &sequenceRepository{db: db}
+}
+
+// Next uses a single upsert so the increment happens under the row lock:
+// concurrent callers for the same tenant and name are serialized and never share a value.
+func (r *sequenceRepository) Next(ctx context.Context, tenantID uuid.UUID, name string) (int64, error) {
+	var value int64
+	err := dbFromContext(ctx, r.db).Raw(`
+		INSERT INTO sequences (tenant_id, name, value, updated_at)
+		VALUES (?, ?, 1, NOW())
+		ON CONFLICT (tenant_id, name)
+		DO UPDATE SET value = sequences.value + 1, updated_at = NOW()
+		RETURNING value`, tenantID, name).Scan(&value).Error
+	return value, err
+}
@@ -21,7 +21,7 @@ func NewSettingsRepository(db *gorm.DB) repository.SettingsRepository {
 // GetByUserID retrieves settings by user ID
 func (r *settingsRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.UserSettings, error) {
 	var settings entity.UserSettings
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settings).Error
+	err := dbFromContext(ctx, r.db).Where("user_id = ?", userID).First(&settings).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -33,10 +33,10 @@ func (r *settingsRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 
 // Create creates new user settings
 func (r *settingsRepository) Create(ctx context.Context, settings *entity.UserSettings) error {
-	return r.db.WithContext(ctx).Create(settings).Error
+	return dbFromContext(ctx, r.db).Create(settings).Error
 }
 
 // Update updates existing user settings
 func (r *settingsRepository) Update(ctx context.Context, settings *entity.UserSettings) error {
-	return r.db.WithContext(ctx).Save(settings).Error
+	return dbFromContext(ctx, r.db).Save(settings).Error
 }
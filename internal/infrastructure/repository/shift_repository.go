@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type shiftRepository struct {
+	db *gorm.DB
+}
+
+// NewShiftRepository creates a new shift repository
+func NewShiftRepository(db *gorm.DB) domainRepo.ShiftRepository {
+	return &shiftRepository{db: db}
+}
+
+func (r *shiftRepository) Create(ctx context.Context, shift *entity.Shift) error {
+	return dbFromContext(ctx, r.db).Omit("User").Create(shift).Error
+}
+
+func (r *shiftRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Shift, error) {
+	var shift entity.Shift
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Preload("User").
+		First(&shift, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &shift, err
+}
+
+func (r *shiftRepository) GetOpenByUser(ctx context.Context, userID uuid.UUID) (*entity.Shift, error) {
+	var shift entity.Shift
+	err := dbFromContext(ctx, r.db).Scopes(TenantScope(ctx)).
+		Preload("User").
+		First(&shift, "user_id = ? AND closed_at IS NULL", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &shift, err
+}
+
+// Close only matches an open shift, so two closes can't both count the drawer
+func (r *shiftRepository) Close(ctx context.Context, shift *entity.Shift) (bool, error) {
+	result := dbFromContext(ctx, r.db).Model(&entity.Shift{}).Scopes(TenantScope(ctx)).
+		Where("id = ? AND closed_at IS NULL", shift.ID).
+		Updates(map[string]interface{}{
+			"closed_at":     shift.ClosedAt,
+			"expected_cash": shift.ExpectedCash,
+			"counted_cash":  shift.CountedCash,
+			"closing_notes": shift.ClosingNotes,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *shiftRepository) List(ctx context.Context, params *pagination.PaginationParams, userID *uuid.UUID) ([]entity.Shift, int64, error) {
+	var shifts []entity.Shift
+	var total int64
+
+	query := dbFromContext(ctx, r.db).Model(&entity.Shift{}).Scopes(TenantScope(ctx))
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	params.Validate()
+	err := query.Preload("User").
+		Offset(params.Offset()).Limit(params.PerPage).
+		Order("opened_at DESC").
+		Find(&shifts).Error
+
+	return shifts, total, err
+}
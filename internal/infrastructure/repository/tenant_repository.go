@@ -21,12 +21,12 @@ func NewTenantRepository(db *gorm.DB) domainRepo.TenantRepository {
 }
 
 func (r *tenantRepository) Create(ctx context.Context, tenant *entity.Tenant) error {
-	return r.db.WithContext(ctx).Create(tenant).Error
+	return dbFromContext(ctx, r.db).Create(tenant).Error
 }
 
 func (r *tenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Tenant, error) {
 	var tenant entity.Tenant
-	err := r.db.WithContext(ctx).First(&tenant, "id = ?", id).Error
+	err := dbFromContext(ctx, r.db).First(&tenant, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -35,7 +35,7 @@ func (r *tenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.T
 
 func (r *tenantRepository) GetBySlug(ctx context.Context, slug string) (*entity.Tenant, error) {
 	var tenant entity.Tenant
-	err := r.db.WithContext(ctx).First(&tenant, "slug = ?", slug).Error
+	err := dbFromContext(ctx, r.db).First(&tenant, "slug = ?", slug).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -43,18 +43,18 @@ func (r *tenantRepository) GetBySlug(ctx context.Context, slug string) (*entity.
 }
 
 func (r *tenantRepository) Update(ctx context.Context, tenant *entity.Tenant) error {
-	return r.db.WithContext(ctx).Save(tenant).Error
+	return dbFromContext(ctx, r.db).Save(tenant).Error
 }
 
 func (r *tenantRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.Tenant{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Tenant{}, "id = ?", id).Error
 }
 
 func (r *tenantRepository) GetUserTenants(ctx context.Context, userID uuid.UUID, params *pagination.PaginationParams) ([]entity.Tenant, int64, error) {
 	var tenants []entity.Tenant
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Tenant{}).
+	query := dbFromContext(ctx, r.db).Model(&entity.Tenant{}).
 		Joins("JOIN tenant_memberships ON tenant_memberships.tenant_id = tenants.id").
 		Where("tenant_memberships.user_id = ?", userID)
 
@@ -62,32 +62,56 @@ func (r *tenantRepository) GetUserTenants(ctx context.Context, userID uuid.UUID,
 		return nil, 0, err
 	}
 
+	// Select only tenant columns so membership columns (e.g. created_at) don't shadow them, and
+	// order by join date so the first row is the user's default tenant
 	params.Validate()
-	err := query.Offset(params.Offset()).Limit(params.PerPage).Find(&tenants).Error
+	err := query.Select("tenants.*").
+		Order("tenant_memberships.created_at ASC, tenants.id ASC").
+		Offset(params.Offset()).Limit(params.PerPage).
+		Find(&tenants).Error
 	return tenants, total, err
 }
 
 func (r *tenantRepository) AddMember(ctx context.Context, membership *entity.TenantMembership) error {
-	return r.db.WithContext(ctx).Create(membership).Error
+	return dbFromContext(ctx, r.db).Create(membership).Error
 }
 
 func (r *tenantRepository) RemoveMember(ctx context.Context, tenantID, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Delete(&entity.TenantMembership{}, "tenant_id = ? AND user_id = ?", tenantID, userID).Error
 }
 
-func (r *tenantRepository) GetMembers(ctx context.Context, tenantID uuid.UUID) ([]entity.TenantMembership, error) {
+func (r *tenantRepository) GetMembers(ctx context.Context, tenantID uuid.UUID, params *pagination.PaginationParams, search string) ([]entity.TenantMembership, int64, error) {
 	var members []entity.TenantMembership
-	err := r.db.WithContext(ctx).
+	var total int64
+
+	query := dbFromContext(ctx, r.db).
+		Model(&entity.TenantMembership{}).
+		Joins("JOIN users ON users.id = tenant_memberships.user_id").
+		Where("tenant_memberships.tenant_id = ? AND users.deleted_at IS NULL", tenantID)
+
+	if search != "" {
+		like := "%" + search + "%"
+		query = query.Where("users.first_name ILIKE ? OR users.last_name ILIKE ? OR users.email ILIKE ? OR CONCAT(users.first_name, ' ', users.last_name) ILIKE ?",
+			like, like, like, like)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	params.Validate()
+	err := query.Select("tenant_memberships.*").
 		Preload("User").
-		Where("tenant_id = ?", tenantID).
+		Order("tenant_memberships.created_at ASC").
+		Offset(params.Offset()).Limit(params.PerPage).
 		Find(&members).Error
-	return members, err
+	return members, total, err
 }
 
 func (r *tenantRepository) IsMember(ctx context.Context, tenantID, userID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&entity.TenantMembership{}).
 		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
 		Count(&count).Error
@@ -96,7 +120,7 @@ func (r *tenantRepository) IsMember(ctx context.Context, tenantID, userID uuid.U
 
 func (r *tenantRepository) GetMembership(ctx context.Context, tenantID, userID uuid.UUID) (*entity.TenantMembership, error) {
 	var membership entity.TenantMembership
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		First(&membership, "tenant_id = ? AND user_id = ?", tenantID, userID).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
@@ -105,7 +129,7 @@ func (r *tenantRepository) GetMembership(ctx context.Context, tenantID, userID u
 }
 
 func (r *tenantRepository) UpdateMemberRole(ctx context.Context, tenantID, userID uuid.UUID, role string) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&entity.TenantMembership{}).
 		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
 		Update("role", role).Error
@@ -113,7 +137,7 @@ func (r *tenantRepository) UpdateMemberRole(ctx context.Context, tenantID, userI
 
 func (r *tenantRepository) SlugExists(ctx context.Context, slug string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&entity.Tenant{}).
 		Where("slug = ?", slug).
 		Count(&count).Error
@@ -124,26 +148,28 @@ func (r *tenantRepository) ListAll(ctx context.Context, params *pagination.Pagin
 	var tenants []entity.Tenant
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Tenant{})
+	query := dbFromContext(ctx, r.db).Model(&entity.Tenant{})
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	params.Validate()
-	err := query.Offset(params.Offset()).Limit(params.PerPage).Find(&tenants).Error
+	err := query.Order("created_at DESC").
+		Offset(params.Offset()).Limit(params.PerPage).
+		Find(&tenants).Error
 	return tenants, total, err
 }
 
 func (r *tenantRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&entity.Tenant{}).Count(&count).Error
+	err := dbFromContext(ctx, r.db).Model(&entity.Tenant{}).Count(&count).Error
 	return count, err
 }
 
 func (r *tenantRepository) GetAdminEmails(ctx context.Context, tenantID uuid.UUID) ([]string, error) {
 	var emails []string
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&entity.TenantMembership{}).
 		Select("users.email").
 		Joins("JOIN users ON users.id = tenant_memberships.user_id").
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// txKey is the context key for the active transaction
+const txKey ctxKey = "db_tx"
+
+type txManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager creates a new transaction manager
+func NewTxManager(db *gorm.DB) domainRepo.TxManager {
+	return &txManager{db: db}
+}
+
+// WithinTransaction runs fn in a transaction. Nested calls reuse the outer transaction.
+func (m *txManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey, tx))
+	})
+}
+
+// dbFromContext returns the transaction bound to ctx if there is one, otherwise db.
+// All repositories obtain their handle through this so they join an active transaction.
+func dbFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -21,12 +21,12 @@ func NewUserRepository(db *gorm.DB) domainRepo.UserRepository {
 }
 
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return dbFromContext(ctx, r.db).Create(user).Error
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error
+	err := dbFromContext(ctx, r.db).First(&user, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -35,7 +35,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Use
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).First(&user, "email = ?", email).Error
+	err := dbFromContext(ctx, r.db).First(&user, "email = ?", email).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -44,45 +44,56 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).First(&user, "username = ?", username).Error
+	err := dbFromContext(ctx, r.db).First(&user, "username = ?", username).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	return &user, err
 }
 
-func (r *userRepository) GetByProviderID(ctx context.Context, provider string, providerID string) (*entity.User, error) {
+func (r *userRepository) GetByGoogleID(ctx context.Context, googleID string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).First(&user, "provider = ? AND provider_id = ?", provider, providerID).Error
+	err := dbFromContext(ctx, r.db).First(&user, "google_id = ?", googleID).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	return &user, err
 }
 
+func (r *userRepository) IsActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	var active []bool
+	err := dbFromContext(ctx, r.db).Model(&entity.User{}).
+		Where("id = ?", id).
+		Pluck("is_active", &active).Error
+	return len(active) == 1 && active[0], err
+}
+
 func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return dbFromContext(ctx, r.db).Save(user).Error
 }
 
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&entity.User{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.User{}, "id = ?", id).Error
 }
 
 func (r *userRepository) List(ctx context.Context, params *pagination.PaginationParams, search string) ([]entity.User, int64, error) {
 	var users []entity.User
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.User{})
+	query := dbFromContext(ctx, r.db).Model(&entity.User{})
 
-	// Apply tenant filtering via tenant_memberships unless skip_tenant_scope is set
+	// Apply tenant filtering via tenant_memberships unless skip_tenant_scope is set.
+	// Users are not tenant-owned rows, so TenantScope can't be used directly.
 	skipTenantScope, _ := ctx.Value(SkipTenantScopeKey).(bool)
 	if !skipTenantScope {
 		tenantID, ok := GetTenantID(ctx)
-		if ok {
-			// Filter users that belong to the current tenant
-			query = query.Joins("INNER JOIN tenant_memberships ON tenant_memberships.user_id = users.id").
-				Where("tenant_memberships.tenant_id = ?", tenantID)
+		if !ok || tenantID == uuid.Nil {
+			// Fail-safe, as TenantScope: no tenant context lists no users
+			return []entity.User{}, 0, nil
 		}
+		// Filter users that belong to the current tenant
+		query = query.Joins("INNER JOIN tenant_memberships ON tenant_memberships.user_id = users.id").
+			Where("tenant_memberships.tenant_id = ?", tenantID)
 	}
 
 	if search != "" {
@@ -106,7 +117,7 @@ func (r *userRepository) List(ctx context.Context, params *pagination.Pagination
 
 func (r *userRepository) GetWithRoles(ctx context.Context, id uuid.UUID) (*entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Roles.Permissions").
 		First(&user, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -116,14 +127,14 @@ func (r *userRepository) GetWithRoles(ctx context.Context, id uuid.UUID) (*entit
 }
 
 func (r *userRepository) AssignRole(ctx context.Context, userID uuid.UUID, roleID uint) error {
-	return r.db.WithContext(ctx).Exec(
+	return dbFromContext(ctx, r.db).Exec(
 		"INSERT INTO model_has_roles (model_id, role_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
 		userID, roleID,
 	).Error
 }
 
 func (r *userRepository) RemoveRole(ctx context.Context, userID uuid.UUID, roleID uint) error {
-	return r.db.WithContext(ctx).Exec(
+	return dbFromContext(ctx, r.db).Exec(
 		"DELETE FROM model_has_roles WHERE model_id = ? AND role_id = ?",
 		userID, roleID,
 	).Error
@@ -139,12 +150,12 @@ func NewRoleRepository(db *gorm.DB) domainRepo.RoleRepository {
 }
 
 func (r *roleRepository) Create(ctx context.Context, role *entity.Role) error {
-	return r.db.WithContext(ctx).Create(role).Error
+	return dbFromContext(ctx, r.db).Create(role).Error
 }
 
 func (r *roleRepository) GetByID(ctx context.Context, id uint) (*entity.Role, error) {
 	var role entity.Role
-	err := r.db.WithContext(ctx).First(&role, id).Error
+	err := dbFromContext(ctx, r.db).First(&role, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -153,7 +164,7 @@ func (r *roleRepository) GetByID(ctx context.Context, id uint) (*entity.Role, er
 
 func (r *roleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
 	var role entity.Role
-	err := r.db.WithContext(ctx).First(&role, "name = ?", name).Error
+	err := dbFromContext(ctx, r.db).First(&role, "name = ?", name).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -161,22 +172,22 @@ func (r *roleRepository) GetByName(ctx context.Context, name string) (*entity.Ro
 }
 
 func (r *roleRepository) Update(ctx context.Context, role *entity.Role) error {
-	return r.db.WithContext(ctx).Save(role).Error
+	return dbFromContext(ctx, r.db).Save(role).Error
 }
 
 func (r *roleRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&entity.Role{}, id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Role{}, id).Error
 }
 
 func (r *roleRepository) List(ctx context.Context) ([]entity.Role, error) {
 	var roles []entity.Role
-	err := r.db.WithContext(ctx).Preload("Permissions").Find(&roles).Error
+	err := dbFromContext(ctx, r.db).Preload("Permissions").Find(&roles).Error
 	return roles, err
 }
 
 func (r *roleRepository) GetWithPermissions(ctx context.Context, id uint) (*entity.Role, error) {
 	var role entity.Role
-	err := r.db.WithContext(ctx).Preload("Permissions").First(&role, id).Error
+	err := dbFromContext(ctx, r.db).Preload("Permissions").First(&role, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -187,11 +198,11 @@ func (r *roleRepository) SyncPermissions(ctx context.Context, roleID uint, permi
 	role := &entity.Role{ID: roleID}
 	var permissions []entity.Permission
 	if len(permissionIDs) > 0 {
-		if err := r.db.WithContext(ctx).Find(&permissions, permissionIDs).Error; err != nil {
+		if err := dbFromContext(ctx, r.db).Find(&permissions, permissionIDs).Error; err != nil {
 			return err
 		}
 	}
-	return r.db.WithContext(ctx).Model(role).Association("Permissions").Replace(permissions)
+	return dbFromContext(ctx, r.db).Model(role).Association("Permissions").Replace(permissions)
 }
 
 type permissionRepository struct {
@@ -204,12 +215,12 @@ func NewPermissionRepository(db *gorm.DB) domainRepo.PermissionRepository {
 }
 
 func (r *permissionRepository) Create(ctx context.Context, permission *entity.Permission) error {
-	return r.db.WithContext(ctx).Create(permission).Error
+	return dbFromContext(ctx, r.db).Create(permission).Error
 }
 
 func (r *permissionRepository) GetByID(ctx context.Context, id uint) (*entity.Permission, error) {
 	var permission entity.Permission
-	err := r.db.WithContext(ctx).First(&permission, id).Error
+	err := dbFromContext(ctx, r.db).First(&permission, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -218,7 +229,7 @@ func (r *permissionRepository) GetByID(ctx context.Context, id uint) (*entity.Pe
 
 func (r *permissionRepository) GetByName(ctx context.Context, name string) (*entity.Permission, error) {
 	var permission entity.Permission
-	err := r.db.WithContext(ctx).First(&permission, "name = ?", name).Error
+	err := dbFromContext(ctx, r.db).First(&permission, "name = ?", name).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -226,15 +237,15 @@ func (r *permissionRepository) GetByName(ctx context.Context, name string) (*ent
 }
 
 func (r *permissionRepository) Update(ctx context.Context, permission *entity.Permission) error {
-	return r.db.WithContext(ctx).Save(permission).Error
+	return dbFromContext(ctx, r.db).Save(permission).Error
 }
 
 func (r *permissionRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&entity.Permission{}, id).Error
+	return dbFromContext(ctx, r.db).Delete(&entity.Permission{}, id).Error
 }
 
 func (r *permissionRepository) List(ctx context.Context) ([]entity.Permission, error) {
 	var permissions []entity.Permission
-	err := r.db.WithContext(ctx).Order("name ASC").Find(&permissions).Error
+	err := dbFromContext(ctx, r.db).Order("name ASC").Find(&permissions).Error
 	return permissions, err
 }
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+func TestUserListScopedToTenant(t *testing.T) {
+	db := dryRunDB(t)
+	sqls := captureSQL(t, db)
+	ctx := WithTenant(context.Background(), uuid.New())
+
+	if _, _, err := NewUserRepository(db).List(ctx, pagination.DefaultPagination(), ""); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	if len(*sqls) == 0 {
+		t.Fatal("List ran no queries")
+	}
+	for _, sql := range *sqls {
+		if !strings.Contains(sql, "INNER JOIN tenant_memberships ON tenant_memberships.user_id = users.id") ||
+			!strings.Contains(sql, "tenant_memberships.tenant_id = $") {
+			t.Errorf("query is not scoped to the tenant: %s", sql)
+		}
+	}
+}
+
+func TestUserListWithoutTenantListsNobody(t *testing.T) {
+	db := dryRunDB(t)
+	sqls := captureSQL(t, db)
+
+	users, total, err := NewUserRepository(db).List(context.Background(), pagination.DefaultPagination(), "")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(users) != 0 || total != 0 || len(*sqls) != 0 {
+		t.Errorf("got %d users, total %d after %d queries; want none without a tenant", len(users), total, len(*sqls))
+	}
+}
+
+func TestUserListSuperAdminSeesAllTenants(t *testing.T) {
+	db := dryRunDB(t)
+	sqls := captureSQL(t, db)
+	ctx := context.WithValue(context.Background(), SkipTenantScopeKey, true)
+
+	if _, _, err := NewUserRepository(db).List(ctx, pagination.DefaultPagination(), ""); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	for _, sql := range *sqls {
+		if strings.Contains(sql, "tenant_memberships") {
+			t.Errorf("super-admin query is scoped to a tenant: %s", sql)
+		}
+	}
+}
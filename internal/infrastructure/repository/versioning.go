@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"github.com/sangkips/investify-api/pkg/apperror"
+	"gorm.io/gorm"
+)
+
+// versionBump advances the version column of a versioned row (products, orders,
+// customers). Every write to such a row includes it, so a concurrent read-modify-save
+// of the same row notices the change.
+var versionBump = gorm.Expr("version + 1")
+
+// saveVersioned writes every column of model except omit, like Save, but only while its
+// row is still at *version, and advances *version. If the row was changed since model
+// was read it writes nothing and returns apperror.ErrVersionConflict.
+func saveVersioned(db *gorm.DB, model interface{}, version *int, omit ...string) error {
+	read := *version
+	*version = read + 1
+
+	result := db.Model(model).Where("version = ?", read).Select("*").Omit(omit...).Updates(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = apperror.ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = read
+		return result.Error
+	}
+	return nil
+}
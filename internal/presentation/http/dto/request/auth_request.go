@@ -34,9 +34,14 @@ type ResetPasswordRequest struct {
 	PasswordConfirm string `json:"password_confirm" binding:"required,eqfield=Password"`
 }
 
+// StepUpRequest represents a re-authentication request
+type StepUpRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
 // ChangePasswordRequest represents a password change request
 type ChangePasswordRequest struct {
-	CurrentPassword string `json:"current_password" binding:"required"`
+	CurrentPassword string `json:"current_password"` // Not needed to set a first password
 	NewPassword     string `json:"new_password" binding:"required,min=8"`
 	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=NewPassword"`
 }
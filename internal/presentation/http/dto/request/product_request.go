@@ -1,46 +1,84 @@
 package request
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/pkg/nullable"
+)
 
 // CreateProductRequest represents a product creation request
 type CreateProductRequest struct {
-	CategoryID    *uuid.UUID `json:"category_id"`
-	UnitID        *uuid.UUID `json:"unit_id"`
-	Name          string     `json:"name" binding:"required,min=2,max=255"`
-	Code          string     `json:"code" binding:"omitempty,max=100"`
-	Quantity      int        `json:"quantity" binding:"min=0"`
-	QuantityAlert int        `json:"quantity_alert" binding:"min=0"`
-	BuyingPrice   float64    `json:"buying_price" binding:"min=0"`
-	SellingPrice  float64    `json:"selling_price" binding:"min=0"`
-	Tax           int        `json:"tax" binding:"min=0,max=100"`
-	TaxType       int        `json:"tax_type" binding:"min=0,max=1"`
-	Notes         *string    `json:"notes"`
+	CategoryID          *uuid.UUID `json:"category_id"`
+	UnitID              *uuid.UUID `json:"unit_id"`
+	PurchaseUnitID      *uuid.UUID `json:"purchase_unit_id"`
+	PiecesPerUnit       int        `json:"pieces_per_unit" binding:"omitempty,min=1"`
+	PreferredSupplierID *uuid.UUID `json:"preferred_supplier_id"`
+	Name                string     `json:"name" binding:"required,min=2,max=255"`
+	Code                string     `json:"code" binding:"omitempty,max=100"`
+	Quantity            int        `json:"quantity" binding:"min=0"`
+	QuantityAlert       int        `json:"quantity_alert" binding:"min=0"`
+	MinStock            int        `json:"min_stock" binding:"min=0"`
+	MaxStock            int        `json:"max_stock" binding:"min=0"`
+	ReorderQty          int        `json:"reorder_qty" binding:"min=0"`
+	AllowNegativeStock  bool       `json:"allow_negative_stock"`
+	BuyingPrice         float64    `json:"buying_price" binding:"min=0"`
+	SellingPrice        float64    `json:"selling_price" binding:"min=0"`
+	Tax                 int        `json:"tax" binding:"min=0,max=100"`
+	TaxType             int        `json:"tax_type" binding:"min=0,max=1"`
+	Notes               *string    `json:"notes"`
 }
 
-// UpdateProductRequest represents a product update request
+// UpdateProductRequest represents a partial product update request (PUT or PATCH).
+// Omitted fields are left untouched. The nullable fields (category_id, unit_id,
+// purchase_unit_id, preferred_supplier_id, notes) are cleared by sending an explicit null.
+// version, if sent, must be the product's current version or the update is rejected with 409.
 type UpdateProductRequest struct {
-	CategoryID    *uuid.UUID `json:"category_id"`
-	UnitID        *uuid.UUID `json:"unit_id"`
-	Name          *string    `json:"name" binding:"omitempty,min=2,max=255"`
-	Code          *string    `json:"code" binding:"omitempty,min=1,max=100"`
-	Quantity      *int       `json:"quantity" binding:"omitempty,min=0"`
-	QuantityAlert *int       `json:"quantity_alert" binding:"omitempty,min=0"`
-	BuyingPrice   *float64   `json:"buying_price" binding:"omitempty,min=0"`
-	SellingPrice  *float64   `json:"selling_price" binding:"omitempty,min=0"`
-	Tax           *int       `json:"tax" binding:"omitempty,min=0,max=100"`
-	TaxType       *int       `json:"tax_type" binding:"omitempty,min=0,max=1"`
-	Notes         *string    `json:"notes"`
+	Version             *int                      `json:"version" binding:"omitempty,min=1"`
+	CategoryID          nullable.Field[uuid.UUID] `json:"category_id"`
+	UnitID              nullable.Field[uuid.UUID] `json:"unit_id"`
+	PurchaseUnitID      nullable.Field[uuid.UUID] `json:"purchase_unit_id"`
+	PiecesPerUnit       *int                      `json:"pieces_per_unit" binding:"omitempty,min=1"`
+	PreferredSupplierID nullable.Field[uuid.UUID] `json:"preferred_supplier_id"`
+	Name                *string                   `json:"name" binding:"omitempty,min=2,max=255"`
+	Code                *string                   `json:"code" binding:"omitempty,min=1,max=100"`
+	Quantity            *int                      `json:"quantity" binding:"omitempty,min=0"`
+	QuantityAlert       *int                      `json:"quantity_alert" binding:"omitempty,min=0"`
+	MinStock            *int                      `json:"min_stock" binding:"omitempty,min=0"`
+	MaxStock            *int                      `json:"max_stock" binding:"omitempty,min=0"`
+	ReorderQty          *int                      `json:"reorder_qty" binding:"omitempty,min=0"`
+	AllowNegativeStock  *bool                     `json:"allow_negative_stock"`
+	IsActive            *bool                     `json:"is_active"`
+	BuyingPrice         *float64                  `json:"buying_price" binding:"omitempty,min=0"`
+	SellingPrice        *float64                  `json:"selling_price" binding:"omitempty,min=0"`
+	Tax                 *int                      `json:"tax" binding:"omitempty,min=0,max=100"`
+	TaxType             *int                      `json:"tax_type" binding:"omitempty,min=0,max=1"`
+	Notes               nullable.Field[string]    `json:"notes"`
+}
+
+// AddProductImageRequest represents adding an image to a product's gallery
+type AddProductImageRequest struct {
+	URL       string `json:"url" binding:"required,url,max=500"`
+	IsPrimary bool   `json:"is_primary"`
+}
+
+// ReorderProductImagesRequest sets the order of a product's gallery. image_ids must list
+// every image of the product; primary_image_id optionally changes the primary image.
+type ReorderProductImagesRequest struct {
+	ImageIDs       []uuid.UUID `json:"image_ids" binding:"required"`
+	PrimaryImageID *uuid.UUID  `json:"primary_image_id"`
 }
 
 // ProductFilterRequest represents product filter parameters
 type ProductFilterRequest struct {
-	Search     string `form:"search"`
-	CategoryID string `form:"category_id"`
-	UnitID     string `form:"unit_id"`
-	LowStock   bool   `form:"low_stock"`
-	SortBy     string `form:"sort_by"`
-	SortOrder  string `form:"sort_order"`
-	Page       int    `form:"page"`
-	PerPage    int    `form:"per_page"`
-	Limit      int    `form:"limit"` // For cursor-based pagination
+	Search          string `form:"search"`
+	CategoryID      string `form:"category_id"`
+	UnitID          string `form:"unit_id"`
+	LowStock        bool   `form:"low_stock"`
+	StockStatus     string `form:"stock_status"` // in, out, low or all
+	IncludeInactive bool   `form:"include_inactive"`
+	SortBy          string `form:"sort_by"`
+	SortOrder       string `form:"sort_order"`
+	Page            int    `form:"page"`
+	PerPage         int    `form:"per_page"`
+	Count           string `form:"count"`
+	Limit           int    `form:"limit"` // For cursor-based pagination
 }
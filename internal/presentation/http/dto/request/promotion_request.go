@@ -0,0 +1,21 @@
+package request
+
+import (
+	"time"
+
+	"github.com/sangkips/investify-api/internal/domain/enum"
+)
+
+// PromotionRequest represents a promotion create or update request. Updates replace
+// every field, so omitted optional fields are cleared.
+type PromotionRequest struct {
+	Code           string             `json:"code" binding:"required,min=2,max=50"`
+	Description    *string            `json:"description"`
+	Type           enum.PromotionType `json:"type" binding:"required,oneof=percent fixed"`
+	Value          float64            `json:"value" binding:"required,gt=0"`
+	MinOrderAmount float64            `json:"min_order_amount" binding:"min=0"`
+	StartsAt       *time.Time         `json:"starts_at"`
+	EndsAt         *time.Time         `json:"ends_at"`
+	UsageLimit     *int               `json:"usage_limit" binding:"omitempty,min=1"`
+	IsActive       *bool              `json:"is_active"` // Defaults to true
+}
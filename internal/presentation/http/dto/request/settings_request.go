@@ -0,0 +1,21 @@
+package request
+
+// UpdateSettingsRequest represents a partial user settings update.
+// Omitted fields are left unchanged.
+type UpdateSettingsRequest struct {
+	Language           *string `json:"language" binding:"omitempty,min=2,max=10"`
+	Timezone           *string `json:"timezone" binding:"omitempty,max=50"`
+	Currency           *string `json:"currency" binding:"omitempty,len=3,alpha"`
+	DateFormat         *string `json:"date_format" binding:"omitempty,oneof=DD/MM/YYYY MM/DD/YYYY YYYY-MM-DD DD-MM-YYYY"`
+	EmailNotifications *bool   `json:"email_notifications"`
+	PushNotifications  *bool   `json:"push_notifications"`
+	OrderAlerts        *bool   `json:"order_alerts"`
+	LowStockAlerts     *bool   `json:"low_stock_alerts"`
+	MarketingEmails    *bool   `json:"marketing_emails"`
+	Theme              *string `json:"theme" binding:"omitempty,oneof=light dark system"`
+	CompactMode        *bool   `json:"compact_mode"`
+	ShowAnimations     *bool   `json:"show_animations"`
+	TwoFactorAuth      *bool   `json:"two_factor_auth"`
+	SessionTimeout     *string `json:"session_timeout" binding:"omitempty,numeric"`
+	LoginAlerts        *bool   `json:"login_alerts"`
+}
@@ -0,0 +1,13 @@
+package request
+
+// OpenShiftRequest represents opening a cashier shift
+type OpenShiftRequest struct {
+	OpeningFloat float64 `json:"opening_float" binding:"min=0"` // Cash put in the drawer
+	Notes        *string `json:"notes"`
+}
+
+// CloseShiftRequest represents closing a cashier shift after counting the drawer
+type CloseShiftRequest struct {
+	CountedCash *float64 `json:"counted_cash" binding:"required,min=0"`
+	Notes       *string  `json:"notes"`
+}
@@ -24,9 +24,13 @@ type Meta struct {
 	RequestID string `json:"request_id"`
 }
 
-// newMeta creates metadata for the response
+// newMeta creates metadata for the response.
+// The request ID is the one assigned by RequestIDMiddleware so clients can quote it to support.
 func newMeta(c *gin.Context) *Meta {
-	requestID := c.GetHeader("X-Request-ID")
+	requestID := c.GetString("request_id")
+	if requestID == "" {
+		requestID = c.GetHeader("X-Request-ID")
+	}
 	if requestID == "" {
 		requestID = uuid.New().String()
 	}
@@ -46,8 +50,14 @@ func Success(c *gin.Context, statusCode int, message string, data interface{}) {
 	})
 }
 
-// SuccessWithPagination sends a success response with pagination
+// SuccessWithPagination sends a success response with pagination, adding links to the
+// first, previous, next and last pages of the request's URL
 func SuccessWithPagination[T any](c *gin.Context, statusCode int, message string, result *pagination.PaginatedResult[T]) {
+	if result != nil && result.Pagination != nil {
+		withLinks := *result.Pagination
+		withLinks.Links = pagination.NewLinks(c.Request.URL, &withLinks)
+		result = pagination.NewPaginatedResult(result.Items, &withLinks)
+	}
 	c.JSON(statusCode, APIResponse{
 		Success: true,
 		Message: message,
@@ -121,6 +131,11 @@ func BadRequest(c *gin.Context, message string) {
 	ErrorWithCode(c, 400, message)
 }
 
+// TooManyRequests sends a 429 Too Many Requests response
+func TooManyRequests(c *gin.Context, message string) {
+	ErrorWithCode(c, 429, message)
+}
+
 // InternalServerError sends a 500 Internal Server Error response
 func InternalServerError(c *gin.Context, message string) {
 	ErrorWithCode(c, 500, message)
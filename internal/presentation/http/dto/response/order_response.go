@@ -0,0 +1,98 @@
+package response
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/pkg/money"
+)
+
+// orderFields and orderDetailFields drop the entities' MarshalJSON so the
+// response types below can add fields next to the promoted ones
+type orderFields entity.Order
+type orderDetailFields entity.OrderDetail
+
+// OrderWithProfit is an order as staff with cost visibility see it: the usual order
+// JSON plus profit and margin for every line and for the order as a whole.
+//
+// Cost is the product's current buying price times the quantity; orders do not
+// record what the stock cost at the time of sale. Lines whose product has been
+// deleted have no known cost and are left out of the order totals.
+type OrderWithProfit struct {
+	orderFields
+	SubTotal float64                 `json:"sub_total"`
+	VAT      float64                 `json:"vat"`
+	Total    float64                 `json:"total"`
+	Rounding float64                 `json:"rounding"`
+	Promo    float64                 `json:"promo_discount"`
+	Loyalty  float64                 `json:"loyalty_discount"`
+	Pay      float64                 `json:"pay"`
+	Due      float64                 `json:"due"`
+	Change   float64                 `json:"change"`
+	Details  []OrderDetailWithProfit `json:"details,omitempty"`
+	Profit   float64                 `json:"profit"` // Sum of line profits
+	Margin   float64                 `json:"margin"` // Profit as a percentage of the costed lines' total
+}
+
+// OrderDetailWithProfit is an order line with its profit and margin
+type OrderDetailWithProfit struct {
+	orderDetailFields
+	UnitCost  float64  `json:"unit_cost"`
+	Total     float64  `json:"total"`
+	TaxAmount float64  `json:"tax_amount"`
+	Cost      *float64 `json:"cost,omitempty"`   // Buying price * quantity; nil if unknown
+	Profit    *float64 `json:"profit,omitempty"` // Line total - cost
+	Margin    *float64 `json:"margin,omitempty"` // Profit as a percentage of the line total
+}
+
+// NewOrderWithProfit builds the profit view of an order loaded with its details and products
+func NewOrderWithProfit(order *entity.Order) *OrderWithProfit {
+	out := &OrderWithProfit{
+		orderFields: orderFields(*order),
+		SubTotal:    money.FromCents(order.SubTotal),
+		VAT:         money.FromCents(order.VAT),
+		Total:       money.FromCents(order.Total),
+		Rounding:    money.FromCents(order.Rounding),
+		Promo:       money.FromCents(order.PromoDiscount),
+		Loyalty:     money.FromCents(order.LoyaltyValue),
+		Pay:         money.FromCents(order.Pay),
+		Due:         money.FromCents(order.Due),
+		Change:      money.FromCents(order.Change),
+		Details:     make([]OrderDetailWithProfit, len(order.Details)),
+	}
+
+	var costedTotal, profitTotal int64
+	for i, detail := range order.Details {
+		line := OrderDetailWithProfit{
+			orderDetailFields: orderDetailFields(detail),
+			UnitCost:          money.FromCents(detail.UnitCost),
+			Total:             money.FromCents(detail.Total),
+		}
+
+		if detail.Product.ID != uuid.Nil {
+			cost := detail.Product.BuyingPrice * int64(detail.Quantity)
+			profit := detail.Total - cost
+			costDecimal, profitDecimal := money.FromCents(cost), money.FromCents(profit)
+			margin := marginPercent(profit, detail.Total)
+			line.Cost, line.Profit, line.Margin = &costDecimal, &profitDecimal, &margin
+
+			costedTotal += detail.Total
+			profitTotal += profit
+		}
+
+		out.Details[i] = line
+	}
+
+	out.Profit = money.FromCents(profitTotal)
+	out.Margin = marginPercent(profitTotal, costedTotal)
+	return out
+}
+
+// marginPercent returns profit as a percentage of revenue, rounded to two decimals
+func marginPercent(profit, revenue int64) float64 {
+	if revenue == 0 {
+		return 0
+	}
+	return math.Round(float64(profit)/float64(revenue)*10000) / 100
+}
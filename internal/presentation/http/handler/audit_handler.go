@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/application/service"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// AuditHandler handles audit log HTTP requests
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// List handles listing audit logs (super-admin only)
+func (h *AuditHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "15"))
+
+	params := &repository.AuditLogFilterParams{
+		Pagination: &pagination.PaginationParams{
+			Page:    page,
+			PerPage: perPage,
+		},
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+	}
+
+	if actorIDStr := c.Query("actor_id"); actorIDStr != "" {
+		actorID, err := uuid.Parse(actorIDStr)
+		if err != nil {
+			response.BadRequest(c, "Invalid actor_id")
+			return
+		}
+		params.ActorID = &actorID
+	}
+
+	if impersonatorStr := c.Query("impersonated_by"); impersonatorStr != "" {
+		impersonatorID, err := uuid.Parse(impersonatorStr)
+		if err != nil {
+			response.BadRequest(c, "Invalid impersonated_by")
+			return
+		}
+		params.ImpersonatedBy = &impersonatorID
+	}
+
+	if tenantIDStr := c.Query("tenant_id"); tenantIDStr != "" {
+		tenantID, err := uuid.Parse(tenantIDStr)
+		if err != nil {
+			response.BadRequest(c, "Invalid tenant_id")
+			return
+		}
+		params.TenantID = &tenantID
+	}
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			params.StartDate = &startDate
+		}
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			params.EndDate = &endDate
+		}
+	}
+
+	result, err := h.auditService.ListAuditLogs(c.Request.Context(), params)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithPagination(c, 200, "Audit logs retrieved successfully", result)
+}
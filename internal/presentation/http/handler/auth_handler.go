@@ -3,13 +3,16 @@ package handler
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"net/http"
 	"net/url"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/application/service"
 	"github.com/sangkips/investify-api/internal/presentation/http/dto/request"
 	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
 	"github.com/sangkips/investify-api/pkg/apperror"
+	"github.com/sangkips/investify-api/pkg/utils"
 )
 
 // AuthHandler handles authentication-related HTTP requests
@@ -26,7 +29,7 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req request.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -61,7 +64,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req request.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -92,7 +95,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req request.RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -169,7 +172,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		StoreEmail   *string `json:"store_email"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -194,6 +197,51 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	})
 }
 
+// UploadPhoto handles uploading a profile photo from the multipart "photo" field
+func (h *AuthHandler) UploadPhoto(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	file, header, err := c.Request.FormFile("photo")
+	if bodyTooLarge(c, err) {
+		return
+	}
+	if err != nil {
+		response.BadRequest(c, "Photo is required. Use form field 'photo' to upload an image.")
+		return
+	}
+	defer file.Close()
+
+	user, err := h.authService.UploadProfilePhoto(c.Request.Context(), *userID, file, header.Size)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Profile photo updated successfully", gin.H{
+		"user": user,
+	})
+}
+
+// Deactivate handles deactivating the current user's own account
+func (h *AuthHandler) Deactivate(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.authService.DeactivateAccount(c.Request.Context(), *userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Account deactivated successfully", nil)
+}
+
 // ChangePassword handles password change
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	userID := GetUserID(c)
@@ -202,16 +250,30 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// An impersonator must not take over the account by setting its password
+	if _, impersonated := c.Get("impersonated_by"); impersonated {
+		response.Forbidden(c, "Changing the password is not available while impersonating")
+		return
+	}
+
 	var req request.ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
+	var stepUp bool
+	if claimsVal, ok := c.Get("token_claims"); ok {
+		if claims, ok := claimsVal.(*utils.JWTClaims); ok {
+			stepUp = claims.StepUp
+		}
+	}
+
 	err := h.authService.ChangePassword(c.Request.Context(), &service.ChangePasswordInput{
 		UserID:          *userID,
 		CurrentPassword: req.CurrentPassword,
 		NewPassword:     req.NewPassword,
+		StepUp:          stepUp,
 	})
 	if err != nil {
 		if apperror.IsAppError(err) {
@@ -225,11 +287,52 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	response.OK(c, "Password changed successfully", nil)
 }
 
+// StepUp handles re-authentication for actions that require a step-up token
+func (h *AuthHandler) StepUp(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Impersonators don't know the user's password and must not act with step-up rights
+	if _, impersonated := c.Get("impersonated_by"); impersonated {
+		response.Forbidden(c, "Step-up is not available while impersonating")
+		return
+	}
+
+	var req request.StepUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	// Keep the tenant of the token being stepped up
+	var tenantID uuid.UUID
+	if claimsVal, ok := c.Get("token_claims"); ok {
+		if claims, ok := claimsVal.(*utils.JWTClaims); ok {
+			tenantID = claims.TenantID
+		}
+	}
+
+	output, err := h.authService.StepUp(c.Request.Context(), &service.StepUpInput{
+		UserID:   *userID,
+		TenantID: tenantID,
+		Password: req.Password,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Re-authentication successful", output)
+}
+
 // ForgotPassword handles forgot password request
 func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	var req request.ForgotPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -246,7 +349,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	var req request.ResetPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -322,6 +425,10 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 		State: state,
 	})
 	if err != nil {
+		if appErr := apperror.GetAppError(err); appErr.Code == http.StatusConflict {
+			redirectError(appErr.Message)
+			return
+		}
 		redirectError("Authentication failed")
 		return
 	}
@@ -332,6 +439,9 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 	q.Set("access_token", output.AccessToken)
 	q.Set("refresh_token", output.RefreshToken)
 	q.Set("token_type", "Bearer")
+	if output.StepUpToken != "" {
+		q.Set("step_up_token", output.StepUpToken)
+	}
 	if output.IsNewUser {
 		q.Set("is_new_user", "true")
 	}
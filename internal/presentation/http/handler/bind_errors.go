@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/pkg/apperror"
+)
+
+func init() {
+	// Name fields in validation errors the way clients send them (the json or form
+	// tag) rather than by their Go struct names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.Split(field.Tag.Get(tag), ",")[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// bindError responds to a failed ShouldBindJSON. Validation and type errors are
+// returned per field so the client can highlight the offending input; anything
+// else (malformed JSON, an empty body) gets a plain 400.
+func bindError(c *gin.Context, err error) {
+	if bodyTooLarge(c, err) {
+		return
+	}
+	if fieldErrors := bindFieldErrors(err); len(fieldErrors) > 0 {
+		response.ValidationError(c, fieldErrors)
+		return
+	}
+	if errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Request body is required")
+		return
+	}
+	response.BadRequest(c, "Invalid request body")
+}
+
+// bodyTooLarge responds with 413 if err comes from reading a request body past the
+// limit set by the BodyLimit middleware, and reports whether it did
+func bodyTooLarge(c *gin.Context, err error) bool {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return false
+	}
+	response.Error(c, apperror.NewRequestTooLargeError(maxErr.Limit))
+	return true
+}
+
+// bindFieldErrors converts validator and JSON type errors into field errors. It
+// returns nil for errors that can't be tied to a field.
+func bindFieldErrors(err error) []apperror.FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fieldErrors := make([]apperror.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fieldErrors = append(fieldErrors, apperror.FieldError{
+				Field:   fieldPath(fe),
+				Message: validationMessage(fe),
+			})
+		}
+		return fieldErrors
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []apperror.FieldError{{
+			Field:   typeErr.Field,
+			Message: "must be " + jsonTypeName(typeErr.Type),
+		}}
+	}
+
+	return nil
+}
+
+// fieldPath returns the path of the failed field as the client sent it, e.g.
+// "items[0].quantity". Namespaces of named request types start with the type name,
+// which is the same in both namespaces and is dropped; anonymous request structs
+// have no such prefix.
+func fieldPath(fe validator.FieldError) string {
+	namespace, structNamespace := fe.Namespace(), fe.StructNamespace()
+	i := strings.Index(namespace, ".")
+	if i >= 0 && strings.HasPrefix(structNamespace, namespace[:i+1]) {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// validationMessage describes a failed validation rule in plain words
+func validationMessage(fe validator.FieldError) string {
+	param := fe.Param()
+	isString := fe.Kind() == reflect.String
+	isList := fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map
+
+	switch fe.Tag() {
+	case "required", "required_without", "required_with", "required_if":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "min", "gte":
+		switch {
+		case isString:
+			return fmt.Sprintf("must be at least %s characters", param)
+		case isList:
+			return fmt.Sprintf("must have at least %s items", param)
+		}
+		return "must be at least " + param
+	case "max", "lte":
+		switch {
+		case isString:
+			return fmt.Sprintf("must be at most %s characters", param)
+		case isList:
+			return fmt.Sprintf("must have at most %s items", param)
+		}
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lt":
+		return "must be less than " + param
+	case "len":
+		if isString {
+			return fmt.Sprintf("must be exactly %s characters", param)
+		}
+		return "must have exactly " + param + " items"
+	case "eqfield":
+		return "must match " + param
+	}
+	return "is invalid"
+}
+
+// jsonTypeName names a Go type as a JSON value, for type mismatch messages
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	}
+	return "an object"
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -9,16 +12,18 @@ import (
 	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
 	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
 	"github.com/sangkips/investify-api/pkg/pagination"
+	"github.com/sangkips/investify-api/pkg/utils"
 )
 
 // CustomerHandler handles customer-related HTTP requests
 type CustomerHandler struct {
-	customerService *service.CustomerService
+	customerService  *service.CustomerService
+	statementService *service.StatementService
 }
 
 // NewCustomerHandler creates a new customer handler
-func NewCustomerHandler(customerService *service.CustomerService) *CustomerHandler {
-	return &CustomerHandler{customerService: customerService}
+func NewCustomerHandler(customerService *service.CustomerService, statementService *service.StatementService) *CustomerHandler {
+	return &CustomerHandler{customerService: customerService, statementService: statementService}
 }
 
 // List handles listing customers (supports both page-based and cursor-based pagination)
@@ -45,6 +50,7 @@ func (h *CustomerHandler) List(c *gin.Context) {
 	params := &pagination.PaginationParams{
 		Page:    page,
 		PerPage: perPage,
+		Count:   pagination.ParseCountMode(c.Query("count")),
 	}
 
 	// For super admins, skip tenant scope to see all customers
@@ -120,9 +126,10 @@ func (h *CustomerHandler) Create(c *gin.Context) {
 		AccountHolder *string `json:"account_holder"`
 		AccountNumber *string `json:"account_number"`
 		BankName      *string `json:"bank_name"`
+		TaxExempt     bool    `json:"tax_exempt"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -136,6 +143,7 @@ func (h *CustomerHandler) Create(c *gin.Context) {
 		AccountHolder: req.AccountHolder,
 		AccountNumber: req.AccountNumber,
 		BankName:      req.BankName,
+		TaxExempt:     req.TaxExempt,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -162,6 +170,69 @@ func (h *CustomerHandler) Get(c *gin.Context) {
 	response.OK(c, "Customer retrieved successfully", customer)
 }
 
+// Loyalty handles getting a customer's loyalty points balance and ledger
+func (h *CustomerHandler) Loyalty(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "15"))
+	params := &pagination.PaginationParams{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	loyalty, err := h.customerService.GetCustomerLoyalty(c.Request.Context(), id, params)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Customer loyalty retrieved successfully", loyalty)
+}
+
+// Statement handles downloading a customer's account statement as a PDF.
+// Optional query params: from and to (YYYY-MM-DD); to defaults to today.
+func (h *CustomerHandler) Statement(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			response.BadRequest(c, "Invalid 'to' date, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	var from *time.Time
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.BadRequest(c, "Invalid 'from' date, expected YYYY-MM-DD")
+			return
+		}
+		from = &t
+	}
+
+	st, err := h.statementService.CustomerStatement(c.Request.Context(), id, from, to)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s.pdf", utils.Slugify(st.Customer.Name), to.Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/pdf", service.RenderStatementPDF(st))
+}
+
 // Update handles updating a customer
 func (h *CustomerHandler) Update(c *gin.Context) {
 	userID := GetUserID(c)
@@ -179,6 +250,7 @@ func (h *CustomerHandler) Update(c *gin.Context) {
 	}
 
 	var req struct {
+		Version       *int    `json:"version" binding:"omitempty,min=1"` // Rejected with 409 unless it is the current version
 		Name          *string `json:"name"`
 		Email         *string `json:"email"`
 		Phone         *string `json:"phone"`
@@ -187,9 +259,10 @@ func (h *CustomerHandler) Update(c *gin.Context) {
 		AccountHolder *string `json:"account_holder"`
 		AccountNumber *string `json:"account_number"`
 		BankName      *string `json:"bank_name"`
+		TaxExempt     *bool   `json:"tax_exempt"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -197,6 +270,7 @@ func (h *CustomerHandler) Update(c *gin.Context) {
 		UserID:        *userID,
 		ID:            id,
 		IsSuperAdmin:  isSuperAdmin,
+		Version:       req.Version,
 		Name:          req.Name,
 		Email:         req.Email,
 		Phone:         req.Phone,
@@ -205,6 +279,7 @@ func (h *CustomerHandler) Update(c *gin.Context) {
 		AccountHolder: req.AccountHolder,
 		AccountNumber: req.AccountNumber,
 		BankName:      req.BankName,
+		TaxExempt:     req.TaxExempt,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -230,7 +305,10 @@ func (h *CustomerHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.customerService.DeleteCustomer(c.Request.Context(), *userID, id, isSuperAdmin); err != nil {
+	// permanent=true removes the record for good; it is refused while other records reference it
+	permanent := c.Query("permanent") == "true"
+
+	if err := h.customerService.DeleteCustomer(c.Request.Context(), *userID, id, isSuperAdmin, permanent); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -238,6 +316,88 @@ func (h *CustomerHandler) Delete(c *gin.Context) {
 	response.NoContent(c)
 }
 
+// Restore handles bringing a customer back from the trash
+func (h *CustomerHandler) Restore(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+
+	customer, err := h.customerService.RestoreCustomer(c.Request.Context(), *userID, id, IsSuperAdmin(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Customer restored successfully", customer)
+}
+
+// Trash handles listing soft-deleted customers
+func (h *CustomerHandler) Trash(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "15"))
+
+	params := &pagination.PaginationParams{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	result, err := h.customerService.ListDeletedCustomers(c.Request.Context(), *userID, params, c.Query("search"), IsSuperAdmin(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithPagination(c, 200, "Deleted customers retrieved successfully", result)
+}
+
+// Import handles bulk customer import from CSV or XLSX files
+// Expected columns: name,email,phone,kra_pin,address,account_holder,account_number,bank_name
+func (h *CustomerHandler) Import(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	rows, ok := parseImportFile(c, func(r importRecord) service.ImportCustomerRow {
+		return service.ImportCustomerRow{
+			Name:          r.String(0),
+			Email:         r.String(1),
+			Phone:         r.String(2),
+			KRAPin:        r.String(3),
+			Address:       r.String(4),
+			AccountHolder: r.String(5),
+			AccountNumber: r.String(6),
+			BankName:      r.String(7),
+		}
+	})
+	if !ok {
+		return
+	}
+
+	result, err := h.customerService.ImportCustomers(c.Request.Context(), *userID, rows)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Customer import completed", result)
+}
+
 // SupplierHandler handles supplier-related HTTP requests
 type SupplierHandler struct {
 	supplierService *service.SupplierService
@@ -309,7 +469,7 @@ func (h *SupplierHandler) Create(c *gin.Context) {
 		BankName      *string `json:"bank_name"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -351,6 +511,30 @@ func (h *SupplierHandler) Get(c *gin.Context) {
 	response.OK(c, "Supplier retrieved successfully", supplier)
 }
 
+// Products handles listing the products bought from a supplier with their last cost
+func (h *SupplierHandler) Products(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "15"))
+	params := &pagination.PaginationParams{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	result, err := h.supplierService.ListSupplierProducts(c.Request.Context(), id, params)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithPagination(c, 200, "Supplier products retrieved successfully", result)
+}
+
 // Update handles updating a supplier
 func (h *SupplierHandler) Update(c *gin.Context) {
 	userID := GetUserID(c)
@@ -380,7 +564,7 @@ func (h *SupplierHandler) Update(c *gin.Context) {
 		BankName      *string `json:"bank_name"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -423,10 +607,97 @@ func (h *SupplierHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.supplierService.DeleteSupplier(c.Request.Context(), *userID, id, isSuperAdmin); err != nil {
+	// permanent=true removes the record for good; it is refused while other records reference it
+	permanent := c.Query("permanent") == "true"
+
+	if err := h.supplierService.DeleteSupplier(c.Request.Context(), *userID, id, isSuperAdmin, permanent); err != nil {
 		response.Error(c, err)
 		return
 	}
 
 	response.NoContent(c)
 }
+
+// Restore handles bringing a supplier back from the trash
+func (h *SupplierHandler) Restore(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid supplier ID")
+		return
+	}
+
+	supplier, err := h.supplierService.RestoreSupplier(c.Request.Context(), *userID, id, IsSuperAdmin(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Supplier restored successfully", supplier)
+}
+
+// Trash handles listing soft-deleted suppliers
+func (h *SupplierHandler) Trash(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "15"))
+
+	params := &pagination.PaginationParams{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	result, err := h.supplierService.ListDeletedSuppliers(c.Request.Context(), *userID, params, c.Query("search"), IsSuperAdmin(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithPagination(c, 200, "Deleted suppliers retrieved successfully", result)
+}
+
+// Import handles bulk supplier import from CSV or XLSX files
+// Expected columns: name,email,phone,kra_pin,address,shopname,type,account_holder,account_number,bank_name
+func (h *SupplierHandler) Import(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	rows, ok := parseImportFile(c, func(r importRecord) service.ImportSupplierRow {
+		return service.ImportSupplierRow{
+			Name:          r.String(0),
+			Email:         r.String(1),
+			Phone:         r.String(2),
+			KRAPin:        r.String(3),
+			Address:       r.String(4),
+			ShopName:      r.String(5),
+			Type:          r.String(6),
+			AccountHolder: r.String(7),
+			AccountNumber: r.String(8),
+			BankName:      r.String(9),
+		}
+	})
+	if !ok {
+		return
+	}
+
+	result, err := h.supplierService.ImportSuppliers(c.Request.Context(), *userID, rows)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Supplier import completed", result)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+)
+
+// EnumOption is one value of an integer enum with the label the API returns for it
+type EnumOption struct {
+	Value int    `json:"value"`
+	Label string `json:"label"`
+}
+
+// EnumHandler serves the values of the integer enums requests accept
+type EnumHandler struct {
+	enums map[string][]EnumOption
+}
+
+// NewEnumHandler creates a new enum handler
+func NewEnumHandler() *EnumHandler {
+	return &EnumHandler{enums: map[string][]EnumOption{
+		"order_status":     enumOptions(enum.OrderStatuses()),
+		"purchase_status":  enumOptions(enum.PurchaseStatuses()),
+		"quotation_status": enumOptions(enum.QuotationStatuses()),
+		"tax_type":         enumOptions(enum.TaxTypes()),
+	}}
+}
+
+// enumOptions pairs each value with its label
+func enumOptions[T interface {
+	~int
+	String() string
+}](values []T) []EnumOption {
+	options := make([]EnumOption, len(values))
+	for i, v := range values {
+		options[i] = EnumOption{Value: int(v), Label: v.String()}
+	}
+	return options
+}
+
+// List handles listing every enum's values and labels, for building dropdowns
+func (h *EnumHandler) List(c *gin.Context) {
+	response.OK(c, "Enums retrieved successfully", h.enums)
+}
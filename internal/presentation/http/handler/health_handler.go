@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sangkips/investify-api/pkg/email"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds how long the readiness probe waits for the database
+const readinessTimeout = 2 * time.Second
+
+// HealthHandler handles liveness/readiness probes
+type HealthHandler struct {
+	db           *gorm.DB
+	emailService *email.EmailService
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(db *gorm.DB, emailService *email.EmailService) *HealthHandler {
+	return &HealthHandler{db: db, emailService: emailService}
+}
+
+// Ready reports whether the service can serve traffic by pinging the database.
+// Returns 503 if the database is unreachable. Email delivery counters are included
+// for monitoring but never affect readiness.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "unavailable",
+			"checks": gin.H{"database": gin.H{"status": "down", "error": err.Error()}},
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	start := time.Now()
+	pingErr := sqlDB.PingContext(ctx)
+	latency := time.Since(start)
+
+	stats := sqlDB.Stats()
+	dbCheck := gin.H{
+		"status":     "up",
+		"latency_ms": latency.Milliseconds(),
+		"pool": gin.H{
+			"max_open":   stats.MaxOpenConnections,
+			"open":       stats.OpenConnections,
+			"in_use":     stats.InUse,
+			"idle":       stats.Idle,
+			"wait_count": stats.WaitCount,
+		},
+	}
+
+	if pingErr != nil {
+		dbCheck["status"] = "down"
+		dbCheck["error"] = pingErr.Error()
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "unavailable",
+			"checks": gin.H{"database": dbCheck},
+			"email":  h.emailService.Stats(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"checks": gin.H{"database": dbCheck},
+		"email":  h.emailService.Stats(),
+	})
+}
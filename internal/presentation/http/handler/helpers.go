@@ -55,3 +55,13 @@ func IsSuperAdmin(c *gin.Context) bool {
 	}
 	return false
 }
+
+// HasPermission checks if the user has the given permission
+func HasPermission(c *gin.Context, permission string) bool {
+	for _, p := range GetUserPermissions(c) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/application/service"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/internal/presentation/http/middleware"
+	"github.com/sangkips/investify-api/pkg/utils"
+)
+
+// ImpersonationHandler handles super-admin impersonation HTTP requests
+type ImpersonationHandler struct {
+	impersonationService *service.ImpersonationService
+}
+
+// NewImpersonationHandler creates a new impersonation handler
+func NewImpersonationHandler(impersonationService *service.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{impersonationService: impersonationService}
+}
+
+// Start handles issuing an impersonation token (super-admin only)
+func (h *ImpersonationHandler) Start(c *gin.Context) {
+	adminID := GetUserID(c)
+	if adminID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req struct {
+		UserID   uuid.UUID  `json:"user_id" binding:"required"`
+		TenantID *uuid.UUID `json:"tenant_id"`
+		Reason   string     `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	// The service records the session with its reason
+	middleware.MarkAuditRecorded(c)
+
+	session, err := h.impersonationService.Start(c.Request.Context(), &service.StartImpersonationInput{
+		AdminID:   *adminID,
+		UserID:    req.UserID,
+		TenantID:  req.TenantID,
+		Reason:    req.Reason,
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Impersonation started", session)
+}
+
+// End handles ending the impersonation session of the current token
+func (h *ImpersonationHandler) End(c *gin.Context) {
+	claimsVal, _ := c.Get("token_claims")
+	claims, _ := claimsVal.(*utils.JWTClaims)
+
+	middleware.MarkAuditRecorded(c)
+
+	if err := h.impersonationService.End(claims, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Impersonation ended", nil)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/xuri/excelize/v2"
+)
+
+// importRecord is one data row of an import file, addressed by column index
+type importRecord []string
+
+// String returns the trimmed value of column i, or "" if the row is shorter
+func (r importRecord) String(i int) string {
+	if i >= len(r) {
+		return ""
+	}
+	return strings.TrimSpace(r[i])
+}
+
+// Int returns column i as an int; invalid or missing values are 0
+func (r importRecord) Int(i int) int {
+	v, _ := strconv.Atoi(r.String(i))
+	return v
+}
+
+// Float returns column i as a float64; invalid or missing values are 0
+func (r importRecord) Float(i int) float64 {
+	v, _ := strconv.ParseFloat(r.String(i), 64)
+	return v
+}
+
+// parseImportFile reads the uploaded CSV or XLSX file from the "file" form field and
+// maps every data row with mapRow. The first row is treated as the header. On failure
+// it writes the error response and returns false.
+func parseImportFile[T any](c *gin.Context, mapRow func(importRecord) T) ([]T, bool) {
+	file, header, err := c.Request.FormFile("file")
+	if bodyTooLarge(c, err) {
+		return nil, false
+	}
+	if err != nil {
+		response.BadRequest(c, "File is required. Use form field 'file' to upload a CSV or XLSX file.")
+		return nil, false
+	}
+	defer file.Close()
+
+	filename := strings.ToLower(header.Filename)
+	var records [][]string
+
+	switch {
+	case strings.HasSuffix(filename, ".csv"):
+		records, err = readCSV(file)
+	case strings.HasSuffix(filename, ".xlsx"):
+		records, err = readXLSX(file)
+	default:
+		response.BadRequest(c, "Unsupported file format. Please upload a .csv or .xlsx file.")
+		return nil, false
+	}
+
+	if err != nil {
+		response.BadRequest(c, "Failed to parse file: "+err.Error())
+		return nil, false
+	}
+
+	var rows []T
+	if len(records) > 1 {
+		for _, record := range records[1:] { // Skip header row
+			if len(record) < 1 {
+				continue
+			}
+			rows = append(rows, mapRow(importRecord(record)))
+		}
+	}
+
+	if len(rows) == 0 {
+		response.BadRequest(c, "File contains no data rows")
+		return nil, false
+	}
+
+	return rows, true
+}
+
+// readCSV reads all records of a CSV file
+func readCSV(file io.Reader) ([][]string, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // Trailing empty columns are often dropped
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV format: %w", err)
+	}
+	return records, nil
+}
+
+// readXLSX reads all rows of the first sheet of an XLSX file
+func readXLSX(file io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX format: %w", err)
+	}
+	defer f.Close()
+
+	sheetName := f.GetSheetName(0)
+	if sheetName == "" {
+		return nil, fmt.Errorf("no sheets found in XLSX file")
+	}
+
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet: %w", err)
+	}
+	return rows, nil
+}
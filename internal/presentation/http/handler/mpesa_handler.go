@@ -31,7 +31,7 @@ func (h *MpesaHandler) InitiateSTKPush(c *gin.Context) {
 
 	var req request.STKPushRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body. Required fields: order_id, phone_number")
+		bindError(c, err)
 		return
 	}
 
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"strconv"
 	"time"
 
@@ -50,6 +52,7 @@ func (h *OrderHandler) List(c *gin.Context) {
 		Pagination: &pagination.PaginationParams{
 			Page:    page,
 			PerPage: perPage,
+			Count:   pagination.ParseCountMode(c.Query("count")),
 		},
 		Search:         search,
 		SortBy:         c.Query("sort_by"),
@@ -83,6 +86,16 @@ func (h *OrderHandler) List(c *gin.Context) {
 		}
 	}
 
+	if paymentType := c.Query("payment_type"); paymentType != "" {
+		params.PaymentType = &paymentType
+	}
+
+	if hasDueStr := c.Query("has_due"); hasDueStr != "" {
+		if hasDue, err := strconv.ParseBool(hasDueStr); err == nil {
+			params.HasDue = &hasDue
+		}
+	}
+
 	// For super admins, skip tenant scope to see all orders
 	ctx := c.Request.Context()
 	if isSuperAdmin {
@@ -149,6 +162,16 @@ func (h *OrderHandler) listWithCursor(c *gin.Context, userID uuid.UUID, isSuperA
 		}
 	}
 
+	if paymentType := c.Query("payment_type"); paymentType != "" {
+		params.PaymentType = &paymentType
+	}
+
+	if hasDueStr := c.Query("has_due"); hasDueStr != "" {
+		if hasDue, err := strconv.ParseBool(hasDueStr); err == nil {
+			params.HasDue = &hasDue
+		}
+	}
+
 	// For super admins, skip tenant scope to see all orders
 	ctx := c.Request.Context()
 	if isSuperAdmin {
@@ -180,17 +203,20 @@ func (h *OrderHandler) Create(c *gin.Context) {
 	}
 
 	var req struct {
-		CustomerID  *uuid.UUID `json:"customer_id"`
-		PaymentType string     `json:"payment_type"`
-		Pay         float64    `json:"pay"`
-		Items       []struct {
+		CustomerID   *uuid.UUID `json:"customer_id"`
+		PaymentType  string     `json:"payment_type"`
+		Pay          float64    `json:"pay"`
+		ReserveStock bool       `json:"reserve_stock"` // Hold stock until the order is paid or completed
+		RedeemPoints int64      `json:"redeem_points" binding:"gte=0"`
+		PromoCode    string     `json:"promo_code" binding:"max=50"`
+		Items        []struct {
 			ProductID uuid.UUID `json:"product_id"`
 			Quantity  int       `json:"quantity"`
 			UnitCost  float64   `json:"unit_cost"`
 		} `json:"items" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -204,17 +230,21 @@ func (h *OrderHandler) Create(c *gin.Context) {
 	}
 
 	order, err := h.orderService.CreateOrder(c.Request.Context(), &service.CreateOrderInput{
-		UserID:      *userID,
-		CustomerID:  req.CustomerID,
-		PaymentType: req.PaymentType,
-		Pay:         req.Pay,
-		Items:       items,
+		UserID:       *userID,
+		CustomerID:   req.CustomerID,
+		PaymentType:  req.PaymentType,
+		Pay:          req.Pay,
+		Items:        items,
+		ReserveStock: req.ReserveStock,
+		RedeemPoints: req.RedeemPoints,
+		PromoCode:    req.PromoCode,
 	})
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
+	c.Header("Location", c.Request.URL.Path+"/"+order.ID.String())
 	response.Created(c, "Order created successfully", order)
 }
 
@@ -232,6 +262,12 @@ func (h *OrderHandler) Get(c *gin.Context) {
 		return
 	}
 
+	// Line costs and margins are only shown to users who may see reports
+	if IsSuperAdmin(c) || HasPermission(c, "view-reports") {
+		response.OK(c, "Order retrieved successfully", response.NewOrderWithProfit(order))
+		return
+	}
+
 	response.OK(c, "Order retrieved successfully", order)
 }
 
@@ -253,11 +289,14 @@ func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 		Status int `json:"status" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
-	if err := h.orderService.UpdateOrderStatus(c.Request.Context(), *userID, id, enum.OrderStatus(req.Status)); err != nil {
+	// Managers may cancel orders outside the tenant's cancellation window
+	canOverride := IsSuperAdmin(c) || HasPermission(c, "manage-users")
+
+	if err := h.orderService.UpdateOrderStatus(c.Request.Context(), *userID, id, enum.OrderStatus(req.Status), canOverride); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -265,6 +304,33 @@ func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 	response.OK(c, "Order status updated successfully", nil)
 }
 
+// BulkUpdateStatus handles setting the status of many orders at once and reports the
+// outcome per order
+func (h *OrderHandler) BulkUpdateStatus(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req struct {
+		OrderIDs []uuid.UUID      `json:"order_ids" binding:"required"`
+		Status   enum.OrderStatus `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	results, err := h.orderService.BulkUpdateOrderStatus(c.Request.Context(), *userID, req.OrderIDs, req.Status)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Order statuses updated", results)
+}
+
 // Cancel handles canceling an order
 func (h *OrderHandler) Cancel(c *gin.Context) {
 	userID := GetUserID(c)
@@ -279,7 +345,10 @@ func (h *OrderHandler) Cancel(c *gin.Context) {
 		return
 	}
 
-	if err := h.orderService.CancelOrder(c.Request.Context(), *userID, id); err != nil {
+	// Managers may cancel orders outside the tenant's cancellation window
+	canOverride := IsSuperAdmin(c) || HasPermission(c, "manage-users")
+
+	if err := h.orderService.CancelOrder(c.Request.Context(), *userID, id, canOverride); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -287,6 +356,32 @@ func (h *OrderHandler) Cancel(c *gin.Context) {
 	response.OK(c, "Order cancelled successfully", nil)
 }
 
+// EmailReceipt handles emailing an order's receipt to the customer or to a given address
+func (h *OrderHandler) EmailReceipt(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid order ID")
+		return
+	}
+
+	// The body is optional; without an email the customer's stored address is used
+	var req struct {
+		Email string `json:"email" binding:"omitempty,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		bindError(c, err)
+		return
+	}
+
+	sentTo, err := h.orderService.EmailOrderReceipt(c.Request.Context(), id, req.Email)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Receipt queued for delivery", gin.H{"email": sentTo})
+}
+
 // GetDueOrders handles getting orders with dues
 func (h *OrderHandler) GetDueOrders(c *gin.Context) {
 	userID := GetUserID(c)
@@ -334,7 +429,7 @@ func (h *OrderHandler) PayDue(c *gin.Context) {
 		MpesaPhone  string  `json:"mpesa_phone"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -358,7 +453,7 @@ func (h *OrderHandler) PayDue(c *gin.Context) {
 		return
 	}
 
-	if err := h.orderService.PayDue(c.Request.Context(), *userID, id, req.Amount, isSuperAdmin); err != nil {
+	if err := h.orderService.PayDue(c.Request.Context(), *userID, id, req.Amount, req.PaymentType, isSuperAdmin); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -43,11 +43,34 @@ func (h *PrinterHandler) TestPrint(c *gin.Context) {
 	})
 }
 
+// OrderReceipt returns an order's receipt data without printing it, so clients can
+// render their own preview
+func (h *PrinterHandler) OrderReceipt(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid order ID")
+		return
+	}
+
+	cashierID := uuid.Nil
+	if userID := GetUserID(c); userID != nil {
+		cashierID = *userID
+	}
+
+	receipt, err := h.printerService.GetOrderReceipt(c.Request.Context(), id, cashierID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Order receipt retrieved successfully", receipt)
+}
+
 // PrintReceipt prints a receipt for an order or quotation.
 func (h *PrinterHandler) PrintReceipt(c *gin.Context) {
 	var req request.PrintReceiptRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request: "+err.Error())
+		bindError(c, err)
 		return
 	}
 
@@ -59,9 +82,14 @@ func (h *PrinterHandler) PrintReceipt(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
+	cashierID := uuid.Nil
+	if userID := GetUserID(c); userID != nil {
+		cashierID = *userID
+	}
+
 	switch req.Type {
 	case "order":
-		receipt, err := h.printerService.PrintOrderReceipt(ctx, id)
+		receipt, err := h.printerService.PrintOrderReceipt(ctx, id, cashierID)
 		if err != nil {
 			// If receipt was built but printing failed, return receipt with warning
 			if receipt != nil {
@@ -1,12 +1,6 @@
 package handler
 
 import (
-	"encoding/csv"
-	"fmt"
-	"io"
-	"strconv"
-	"strings"
-
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/application/service"
@@ -16,7 +10,6 @@ import (
 	"github.com/sangkips/investify-api/internal/presentation/http/dto/request"
 	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
 	"github.com/sangkips/investify-api/pkg/pagination"
-	"github.com/xuri/excelize/v2"
 )
 
 // ProductHandler handles product-related HTTP requests
@@ -50,17 +43,24 @@ func (h *ProductHandler) List(c *gin.Context) {
 		response.BadRequest(c, "Invalid query parameters")
 		return
 	}
+	if filter.StockStatus != "" && !repository.StockStatus(filter.StockStatus).IsValid() {
+		response.BadRequest(c, "Invalid stock_status: use in, out, low or all")
+		return
+	}
 
 	params := &repository.ProductFilterParams{
 		Pagination: &pagination.PaginationParams{
 			Page:    filter.Page,
 			PerPage: filter.PerPage,
+			Count:   pagination.ParseCountMode(filter.Count),
 		},
-		Search:         filter.Search,
-		LowStock:       filter.LowStock,
-		SortBy:         filter.SortBy,
-		SortOrder:      filter.SortOrder,
-		SkipUserFilter: isSuperAdmin,
+		Search:          filter.Search,
+		LowStock:        filter.LowStock,
+		StockStatus:     repository.StockStatus(filter.StockStatus),
+		IncludeInactive: filter.IncludeInactive,
+		SortBy:          filter.SortBy,
+		SortOrder:       filter.SortOrder,
+		SkipUserFilter:  isSuperAdmin,
 	}
 
 	if filter.CategoryID != "" {
@@ -106,6 +106,10 @@ func (h *ProductHandler) listWithCursor(c *gin.Context, userID uuid.UUID, isSupe
 		response.BadRequest(c, "Invalid query parameters")
 		return
 	}
+	if filter.StockStatus != "" && !repository.StockStatus(filter.StockStatus).IsValid() {
+		response.BadRequest(c, "Invalid stock_status: use in, out, low or all")
+		return
+	}
 
 	limit := 15
 	if filter.Limit > 0 {
@@ -120,9 +124,11 @@ func (h *ProductHandler) listWithCursor(c *gin.Context, userID uuid.UUID, isSupe
 			Direction: pagination.CursorDirection(direction),
 			Limit:     limit,
 		},
-		Search:         filter.Search,
-		LowStock:       filter.LowStock,
-		SkipUserFilter: isSuperAdmin,
+		Search:          filter.Search,
+		LowStock:        filter.LowStock,
+		StockStatus:     repository.StockStatus(filter.StockStatus),
+		IncludeInactive: filter.IncludeInactive,
+		SkipUserFilter:  isSuperAdmin,
 	}
 
 	if filter.CategoryID != "" {
@@ -171,23 +177,30 @@ func (h *ProductHandler) Create(c *gin.Context) {
 
 	var req request.CreateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body: "+err.Error())
+		bindError(c, err)
 		return
 	}
 
 	product, err := h.productService.CreateProduct(c.Request.Context(), &service.CreateProductInput{
-		UserID:        *userID,
-		CategoryID:    req.CategoryID,
-		UnitID:        req.UnitID,
-		Name:          req.Name,
-		Code:          req.Code,
-		Quantity:      req.Quantity,
-		QuantityAlert: req.QuantityAlert,
-		BuyingPrice:   req.BuyingPrice,
-		SellingPrice:  req.SellingPrice,
-		Tax:           req.Tax,
-		TaxType:       req.TaxType,
-		Notes:         req.Notes,
+		UserID:              *userID,
+		CategoryID:          req.CategoryID,
+		UnitID:              req.UnitID,
+		PurchaseUnitID:      req.PurchaseUnitID,
+		PiecesPerUnit:       req.PiecesPerUnit,
+		PreferredSupplierID: req.PreferredSupplierID,
+		Name:                req.Name,
+		Code:                req.Code,
+		Quantity:            req.Quantity,
+		QuantityAlert:       req.QuantityAlert,
+		MinStock:            req.MinStock,
+		MaxStock:            req.MaxStock,
+		ReorderQty:          req.ReorderQty,
+		AllowNegativeStock:  req.AllowNegativeStock,
+		BuyingPrice:         req.BuyingPrice,
+		SellingPrice:        req.SellingPrice,
+		Tax:                 req.Tax,
+		TaxType:             req.TaxType,
+		Notes:               req.Notes,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -214,7 +227,24 @@ func (h *ProductHandler) Get(c *gin.Context) {
 	response.OK(c, "Product retrieved successfully", product)
 }
 
-// Update handles updating a product
+// GetByCode handles looking up a product by its code
+func (h *ProductHandler) GetByCode(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		response.BadRequest(c, "Product code is required")
+		return
+	}
+
+	product, err := h.productService.GetProductByCode(c.Request.Context(), code)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Product retrieved successfully", product)
+}
+
+// Update handles updating a product (PUT or PATCH; both apply a partial update)
 func (h *ProductHandler) Update(c *gin.Context) {
 	userID := GetUserID(c)
 	if userID == nil {
@@ -232,25 +262,34 @@ func (h *ProductHandler) Update(c *gin.Context) {
 
 	var req request.UpdateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body: "+err.Error())
+		bindError(c, err)
 		return
 	}
 
 	product, err := h.productService.UpdateProduct(c.Request.Context(), &service.UpdateProductInput{
-		UserID:        *userID,
-		ProductSlug:   slug,
-		SkipUserCheck: isSuperAdmin,
-		CategoryID:    req.CategoryID,
-		UnitID:        req.UnitID,
-		Name:          req.Name,
-		Code:          req.Code,
-		Quantity:      req.Quantity,
-		QuantityAlert: req.QuantityAlert,
-		BuyingPrice:   req.BuyingPrice,
-		SellingPrice:  req.SellingPrice,
-		Tax:           req.Tax,
-		TaxType:       req.TaxType,
-		Notes:         req.Notes,
+		UserID:              *userID,
+		ProductSlug:         slug,
+		Version:             req.Version,
+		SkipUserCheck:       isSuperAdmin,
+		CategoryID:          req.CategoryID,
+		UnitID:              req.UnitID,
+		PurchaseUnitID:      req.PurchaseUnitID,
+		PiecesPerUnit:       req.PiecesPerUnit,
+		PreferredSupplierID: req.PreferredSupplierID,
+		Name:                req.Name,
+		Code:                req.Code,
+		Quantity:            req.Quantity,
+		QuantityAlert:       req.QuantityAlert,
+		MinStock:            req.MinStock,
+		MaxStock:            req.MaxStock,
+		ReorderQty:          req.ReorderQty,
+		AllowNegativeStock:  req.AllowNegativeStock,
+		IsActive:            req.IsActive,
+		BuyingPrice:         req.BuyingPrice,
+		SellingPrice:        req.SellingPrice,
+		Tax:                 req.Tax,
+		TaxType:             req.TaxType,
+		Notes:               req.Notes,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -284,195 +323,182 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 	response.NoContent(c)
 }
 
-// GetLowStock handles getting low stock products
-func (h *ProductHandler) GetLowStock(c *gin.Context) {
+// BulkDelete handles deleting many products at once and reports the outcome per product
+func (h *ProductHandler) BulkDelete(c *gin.Context) {
 	userID := GetUserID(c)
 	if userID == nil {
 		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
-	products, err := h.productService.GetLowStockProducts(c.Request.Context(), *userID)
+	var req struct {
+		Products []string `json:"products" binding:"required"` // Slugs or IDs
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	results, err := h.productService.BulkDeleteProducts(c.Request.Context(), *userID, req.Products, IsSuperAdmin(c))
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	response.OK(c, "Low stock products retrieved successfully", products)
+	response.OK(c, "Products deleted", results)
 }
 
-// ImportProducts handles bulk product import from CSV or XLSX files
-func (h *ProductHandler) ImportProducts(c *gin.Context) {
+// AddImage handles adding an image to a product's gallery
+func (h *ProductHandler) AddImage(c *gin.Context) {
 	userID := GetUserID(c)
 	if userID == nil {
 		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
-	file, header, err := c.Request.FormFile("file")
-	if err != nil {
-		response.BadRequest(c, "File is required. Use form field 'file' to upload a CSV or XLSX file.")
+	var req request.AddProductImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
 		return
 	}
-	defer file.Close()
-
-	filename := strings.ToLower(header.Filename)
-	var rows []service.ImportProductRow
 
-	switch {
-	case strings.HasSuffix(filename, ".csv"):
-		rows, err = parseCSV(file)
-	case strings.HasSuffix(filename, ".xlsx"):
-		rows, err = parseXLSX(file)
-	default:
-		response.BadRequest(c, "Unsupported file format. Please upload a .csv or .xlsx file.")
+	product, err := h.productService.AddProductImage(c.Request.Context(), &service.AddProductImageInput{
+		UserID:        *userID,
+		ProductSlug:   c.Param("slug"),
+		SkipUserCheck: IsSuperAdmin(c),
+		URL:           req.URL,
+		IsPrimary:     req.IsPrimary,
+	})
+	if err != nil {
+		response.Error(c, err)
 		return
 	}
 
-	if err != nil {
-		response.BadRequest(c, "Failed to parse file: "+err.Error())
+	response.Created(c, "Product image added successfully", product)
+}
+
+// DeleteImage handles removing an image from a product's gallery
+func (h *ProductHandler) DeleteImage(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
-	if len(rows) == 0 {
-		response.BadRequest(c, "File contains no data rows")
+	imageID, err := uuid.Parse(c.Param("imageID"))
+	if err != nil {
+		response.BadRequest(c, "Invalid image ID")
 		return
 	}
 
-	result, err := h.productService.ImportProducts(c.Request.Context(), *userID, rows)
+	product, err := h.productService.DeleteProductImage(c.Request.Context(), *userID, c.Param("slug"), imageID, IsSuperAdmin(c))
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	response.OK(c, "Product import completed", result)
+	response.OK(c, "Product image deleted successfully", product)
 }
 
-// parseCSV parses a CSV file into ImportProductRow slices
-// Expected columns: name,code,quantity,quantity_alert,buying_price,selling_price,tax,tax_type,notes,category,unit
-func parseCSV(file io.Reader) ([]service.ImportProductRow, error) {
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("invalid CSV format: %w", err)
+// ReorderImages handles setting the order and primary image of a product's gallery
+func (h *ProductHandler) ReorderImages(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
 	}
 
-	if len(records) < 2 {
-		return nil, nil // Only header or empty
+	var req request.ReorderProductImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
 	}
 
-	var rows []service.ImportProductRow
-	for _, record := range records[1:] { // Skip header row
-		if len(record) < 1 {
-			continue
-		}
-		row := service.ImportProductRow{}
-		if len(record) > 0 {
-			row.Name = strings.TrimSpace(record[0])
-		}
-		if len(record) > 1 {
-			row.Code = strings.TrimSpace(record[1])
-		}
-		if len(record) > 2 {
-			row.Quantity, _ = strconv.Atoi(strings.TrimSpace(record[2]))
-		}
-		if len(record) > 3 {
-			row.QuantityAlert, _ = strconv.Atoi(strings.TrimSpace(record[3]))
-		}
-		if len(record) > 4 {
-			row.BuyingPrice, _ = strconv.ParseFloat(strings.TrimSpace(record[4]), 64)
-		}
-		if len(record) > 5 {
-			row.SellingPrice, _ = strconv.ParseFloat(strings.TrimSpace(record[5]), 64)
-		}
-		if len(record) > 6 {
-			row.Tax, _ = strconv.Atoi(strings.TrimSpace(record[6]))
-		}
-		if len(record) > 7 {
-			row.TaxType, _ = strconv.Atoi(strings.TrimSpace(record[7]))
-		}
-		if len(record) > 8 {
-			row.Notes = strings.TrimSpace(record[8])
-		}
-		if len(record) > 9 {
-			row.CategoryName = strings.TrimSpace(record[9])
-		}
-		if len(record) > 10 {
-			row.UnitName = strings.TrimSpace(record[10])
-		}
-		rows = append(rows, row)
+	product, err := h.productService.ReorderProductImages(c.Request.Context(), &service.ReorderProductImagesInput{
+		UserID:         *userID,
+		ProductSlug:    c.Param("slug"),
+		SkipUserCheck:  IsSuperAdmin(c),
+		ImageIDs:       req.ImageIDs,
+		PrimaryImageID: req.PrimaryImageID,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
 	}
 
-	return rows, nil
+	response.OK(c, "Product images reordered successfully", product)
 }
 
-// parseXLSX parses an XLSX file into ImportProductRow slices
-// Reads the first sheet; first row is treated as header
-// Expected columns: name,code,quantity,quantity_alert,buying_price,selling_price,tax,tax_type,notes,category,unit
-func parseXLSX(file io.Reader) ([]service.ImportProductRow, error) {
-	f, err := excelize.OpenReader(file)
+// GetLowStock handles getting low stock products
+func (h *ProductHandler) GetLowStock(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	products, err := h.productService.GetLowStockProducts(c.Request.Context(), *userID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid XLSX format: %w", err)
+		response.Error(c, err)
+		return
 	}
-	defer f.Close()
 
-	sheetName := f.GetSheetName(0)
-	if sheetName == "" {
-		return nil, fmt.Errorf("no sheets found in XLSX file")
+	response.OK(c, "Low stock products retrieved successfully", products)
+}
+
+// ReorderSuggestions handles listing products at or below their minimum stock with
+// suggested purchase quantities
+func (h *ProductHandler) ReorderSuggestions(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
 	}
 
-	xlsxRows, err := f.GetRows(sheetName)
+	suggestions, err := h.productService.GetReorderSuggestions(c.Request.Context(), *userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read sheet: %w", err)
+		response.Error(c, err)
+		return
 	}
 
-	if len(xlsxRows) < 2 {
-		return nil, nil // Only header or empty
+	response.OK(c, "Reorder suggestions retrieved successfully", suggestions)
+}
+
+// ImportProducts handles bulk product import from CSV or XLSX files
+// Expected columns: name,code,quantity,quantity_alert,buying_price,selling_price,tax,tax_type,notes,category,unit
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
 	}
 
-	var rows []service.ImportProductRow
-	for _, record := range xlsxRows[1:] { // Skip header row
-		if len(record) < 1 {
-			continue
-		}
-		row := service.ImportProductRow{}
-		if len(record) > 0 {
-			row.Name = strings.TrimSpace(record[0])
-		}
-		if len(record) > 1 {
-			row.Code = strings.TrimSpace(record[1])
-		}
-		if len(record) > 2 {
-			row.Quantity, _ = strconv.Atoi(strings.TrimSpace(record[2]))
+	rows, ok := parseImportFile(c, func(r importRecord) service.ImportProductRow {
+		return service.ImportProductRow{
+			Name:          r.String(0),
+			Code:          r.String(1),
+			Quantity:      r.Int(2),
+			QuantityAlert: r.Int(3),
+			BuyingPrice:   r.Float(4),
+			SellingPrice:  r.Float(5),
+			Tax:           r.Int(6),
+			TaxType:       r.Int(7),
+			Notes:         r.String(8),
+			CategoryName:  r.String(9),
+			UnitName:      r.String(10),
 		}
-		if len(record) > 3 {
-			row.QuantityAlert, _ = strconv.Atoi(strings.TrimSpace(record[3]))
-		}
-		if len(record) > 4 {
-			row.BuyingPrice, _ = strconv.ParseFloat(strings.TrimSpace(record[4]), 64)
-		}
-		if len(record) > 5 {
-			row.SellingPrice, _ = strconv.ParseFloat(strings.TrimSpace(record[5]), 64)
-		}
-		if len(record) > 6 {
-			row.Tax, _ = strconv.Atoi(strings.TrimSpace(record[6]))
-		}
-		if len(record) > 7 {
-			row.TaxType, _ = strconv.Atoi(strings.TrimSpace(record[7]))
-		}
-		if len(record) > 8 {
-			row.Notes = strings.TrimSpace(record[8])
-		}
-		if len(record) > 9 {
-			row.CategoryName = strings.TrimSpace(record[9])
-		}
-		if len(record) > 10 {
-			row.UnitName = strings.TrimSpace(record[10])
-		}
-		rows = append(rows, row)
+	})
+	if !ok {
+		return
 	}
 
-	return rows, nil
+	result, err := h.productService.ImportProducts(c.Request.Context(), *userID, rows)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Product import completed", result)
 }
 
 // CategoryHandler handles category-related HTTP requests
@@ -534,16 +560,18 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 	}
 
 	var req struct {
-		Name string `json:"name" binding:"required"`
+		Name                 string `json:"name" binding:"required"`
+		DefaultQuantityAlert int    `json:"default_quantity_alert" binding:"min=0"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
 	category, err := h.categoryService.CreateCategory(c.Request.Context(), &service.CreateCategoryInput{
-		UserID: *userID,
-		Name:   req.Name,
+		UserID:               *userID,
+		Name:                 req.Name,
+		DefaultQuantityAlert: req.DefaultQuantityAlert,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -553,6 +581,31 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 	response.Created(c, "Category created successfully", category)
 }
 
+// BatchCreate handles creating several categories at once
+func (h *CategoryHandler) BatchCreate(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req struct {
+		Names []string `json:"names" binding:"required,min=1,max=200"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	result, err := h.categoryService.BatchCreateCategories(c.Request.Context(), *userID, req.Names)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, "Categories processed successfully", result)
+}
+
 // Update handles updating a category
 func (h *CategoryHandler) Update(c *gin.Context) {
 	userID := GetUserID(c)
@@ -570,18 +623,20 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 	}
 
 	var req struct {
-		Name string `json:"name" binding:"required"`
+		Name                 string `json:"name" binding:"required"`
+		DefaultQuantityAlert *int   `json:"default_quantity_alert" binding:"omitempty,min=0"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
 	category, err := h.categoryService.UpdateCategory(c.Request.Context(), &service.UpdateCategoryInput{
-		UserID:       *userID,
-		ID:           id,
-		IsSuperAdmin: isSuperAdmin,
-		Name:         req.Name,
+		UserID:               *userID,
+		ID:                   id,
+		IsSuperAdmin:         isSuperAdmin,
+		Name:                 req.Name,
+		DefaultQuantityAlert: req.DefaultQuantityAlert,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -675,7 +730,7 @@ func (h *UnitHandler) Create(c *gin.Context) {
 		ShortCode string `json:"short_code"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -692,6 +747,39 @@ func (h *UnitHandler) Create(c *gin.Context) {
 	response.Created(c, "Unit created successfully", unit)
 }
 
+// BatchCreate handles creating several units at once
+func (h *UnitHandler) BatchCreate(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req struct {
+		Units []struct {
+			Name      string `json:"name"`
+			ShortCode string `json:"short_code"`
+		} `json:"units" binding:"required,min=1,max=200"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	inputs := make([]service.CreateUnitInput, len(req.Units))
+	for i, u := range req.Units {
+		inputs[i] = service.CreateUnitInput{Name: u.Name, ShortCode: u.ShortCode}
+	}
+
+	result, err := h.unitService.BatchCreateUnits(c.Request.Context(), *userID, inputs)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, "Units processed successfully", result)
+}
+
 // Update handles updating a unit
 func (h *UnitHandler) Update(c *gin.Context) {
 	userID := GetUserID(c)
@@ -713,7 +801,7 @@ func (h *UnitHandler) Update(c *gin.Context) {
 		ShortCode string `json:"short_code"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/application/service"
+	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/request"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// PromotionHandler handles promotion-related HTTP requests
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(promotionService *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{promotionService: promotionService}
+}
+
+// promotionInput maps a request onto the service input; is_active defaults to true
+func promotionInput(req *request.PromotionRequest) service.PromotionInput {
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	return service.PromotionInput{
+		Code:           req.Code,
+		Description:    req.Description,
+		Type:           req.Type,
+		Value:          req.Value,
+		MinOrderAmount: req.MinOrderAmount,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		UsageLimit:     req.UsageLimit,
+		IsActive:       isActive,
+	}
+}
+
+// List handles listing promotions
+func (h *PromotionHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "15"))
+	search := c.Query("search")
+
+	params := &pagination.PaginationParams{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	// For super admins, skip tenant scope to see all promotions
+	ctx := c.Request.Context()
+	if IsSuperAdmin(c) {
+		ctx = infraRepo.WithSkipTenantScope(ctx, true)
+		// Allow super admin to filter by specific tenant if provided
+		if tenantIDStr := c.Query("tenant_id"); tenantIDStr != "" {
+			if tenantID, err := uuid.Parse(tenantIDStr); err == nil {
+				ctx = infraRepo.WithTenant(ctx, tenantID)
+				ctx = infraRepo.WithSkipTenantScope(ctx, false)
+			}
+		}
+	}
+
+	result, err := h.promotionService.ListPromotions(ctx, params, search)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithPagination(c, 200, "Promotions retrieved successfully", result)
+}
+
+// Get handles getting a promotion by ID
+func (h *PromotionHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid promotion ID")
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotion(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Promotion retrieved successfully", promotion)
+}
+
+// Create handles creating a promotion
+func (h *PromotionHandler) Create(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req request.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(c.Request.Context(), &service.CreatePromotionInput{
+		UserID:         *userID,
+		PromotionInput: promotionInput(&req),
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, "Promotion created successfully", promotion)
+}
+
+// Update handles replacing a promotion
+func (h *PromotionHandler) Update(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid promotion ID")
+		return
+	}
+
+	var req request.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(c.Request.Context(), &service.UpdatePromotionInput{
+		UserID:         *userID,
+		ID:             id,
+		PromotionInput: promotionInput(&req),
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Promotion updated successfully", promotion)
+}
+
+// Delete handles deleting a promotion
+func (h *PromotionHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid promotion ID")
+		return
+	}
+
+	if err := h.promotionService.DeletePromotion(c.Request.Context(), id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
@@ -115,7 +115,7 @@ func (h *PurchaseHandler) Create(c *gin.Context) {
 		} `json:"items" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -142,6 +142,23 @@ func (h *PurchaseHandler) Create(c *gin.Context) {
 	response.Created(c, "Purchase created successfully", purchase)
 }
 
+// AutoGenerate handles creating draft purchases from reorder suggestions
+func (h *PurchaseHandler) AutoGenerate(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	purchases, err := h.purchaseService.AutoGeneratePurchases(c.Request.Context(), *userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, "Draft purchases generated successfully", purchases)
+}
+
 // Get handles getting a single purchase
 func (h *PurchaseHandler) Get(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
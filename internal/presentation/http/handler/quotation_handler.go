@@ -27,12 +27,14 @@ func NewQuotationHandler(quotationService *service.QuotationService) *QuotationH
 type CreateQuotationRequest struct {
 	CustomerID         *string                `json:"customer_id"`
 	Date               string                 `json:"date" binding:"required"`
+	TaxMode            string                 `json:"tax_mode" binding:"omitempty,oneof=flat per_product"`
 	TaxPercentage      float64                `json:"tax_percentage"`
 	DiscountPercentage float64                `json:"discount_percentage"`
 	ShippingAmount     float64                `json:"shipping_amount"`
 	Note               *string                `json:"note"`
 	Status             int                    `json:"status"`
 	Items              []QuotationItemRequest `json:"items" binding:"required,min=1"`
+	CheckStock         bool                   `json:"check_stock"`
 }
 
 // QuotationItemRequest represents a line item in the request
@@ -75,6 +77,25 @@ func (h *QuotationHandler) List(c *gin.Context) {
 		}
 	}
 
+	var customerID *uuid.UUID
+	if customerIDStr := c.Query("customer_id"); customerIDStr != "" {
+		if parsed, err := uuid.Parse(customerIDStr); err == nil {
+			customerID = &parsed
+		}
+	}
+
+	var startDate, endDate *time.Time
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			startDate = &parsed
+		}
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			endDate = &parsed
+		}
+	}
+
 	// For super admins, skip tenant scope to see all quotations
 	ctx := c.Request.Context()
 	if isSuperAdmin {
@@ -95,8 +116,11 @@ func (h *QuotationHandler) List(c *gin.Context) {
 			Page:    page,
 			PerPage: perPage,
 		},
-		Search: search,
-		Status: status,
+		Search:     search,
+		Status:     status,
+		CustomerID: customerID,
+		StartDate:  startDate,
+		EndDate:    endDate,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -133,7 +157,7 @@ func (h *QuotationHandler) Create(c *gin.Context) {
 
 	var req CreateQuotationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body: "+err.Error())
+		bindError(c, err)
 		return
 	}
 
@@ -174,11 +198,13 @@ func (h *QuotationHandler) Create(c *gin.Context) {
 		UserID:             *userID,
 		CustomerID:         customerID,
 		Date:               date,
+		TaxMode:            enum.QuotationTaxMode(req.TaxMode),
 		TaxPercentage:      req.TaxPercentage,
 		DiscountPercentage: req.DiscountPercentage,
 		ShippingAmount:     req.ShippingAmount,
 		Note:               req.Note,
 		Status:             enum.QuotationStatus(req.Status),
+		CheckStock:         req.CheckStock,
 		Items:              items,
 	})
 	if err != nil {
@@ -207,7 +233,7 @@ func (h *QuotationHandler) Update(c *gin.Context) {
 
 	var req CreateQuotationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body: "+err.Error())
+		bindError(c, err)
 		return
 	}
 
@@ -250,11 +276,13 @@ func (h *QuotationHandler) Update(c *gin.Context) {
 		IsSuperAdmin:       isSuperAdmin,
 		CustomerID:         customerID,
 		Date:               date,
+		TaxMode:            enum.QuotationTaxMode(req.TaxMode),
 		TaxPercentage:      req.TaxPercentage,
 		DiscountPercentage: req.DiscountPercentage,
 		ShippingAmount:     req.ShippingAmount,
 		Note:               req.Note,
 		Status:             enum.QuotationStatus(req.Status),
+		CheckStock:         req.CheckStock,
 		Items:              items,
 	})
 	if err != nil {
@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/application/service"
+	"github.com/sangkips/investify-api/internal/domain/enum"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/internal/presentation/http/middleware"
+)
+
+// maxConcurrentExports is how many streamed exports a tenant may run at once
+const maxConcurrentExports = 2
+
+// ReportHandler handles report HTTP requests
+type ReportHandler struct {
+	reportService *service.ReportService
+
+	mu      sync.Mutex
+	exports map[uuid.UUID]int // Streamed exports running per tenant
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService, exports: make(map[uuid.UUID]int)}
+}
+
+// SalesSeries handles revenue and profit over time.
+// Query params: granularity (daily, weekly, monthly; default daily) and start/end
+// (YYYY-MM-DD, inclusive; default the last 30 days).
+func (h *ReportHandler) SalesSeries(c *gin.Context) {
+	var granularity repository.SalesGranularity
+	switch c.DefaultQuery("granularity", "daily") {
+	case "daily":
+		granularity = repository.GranularityDay
+	case "weekly":
+		granularity = repository.GranularityWeek
+	case "monthly":
+		granularity = repository.GranularityMonth
+	default:
+		response.BadRequest(c, "Invalid granularity: use daily, weekly or monthly")
+		return
+	}
+
+	start, end, ok := reportDateRange(c)
+	if !ok {
+		return
+	}
+
+	series, err := h.reportService.GetSalesSeries(c.Request.Context(), start, end, granularity)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Sales series retrieved successfully", series)
+}
+
+// SalesByHour handles order count and revenue per hour of the day, for staffing.
+// Query params: start/end (YYYY-MM-DD, inclusive; default the last 30 days).
+func (h *ReportHandler) SalesByHour(c *gin.Context) {
+	start, end, ok := reportDateRange(c)
+	if !ok {
+		return
+	}
+
+	sales, err := h.reportService.GetSalesByHour(c.Request.Context(), start, end)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Sales by hour retrieved successfully", sales)
+}
+
+// SalesByWeekday handles order count and revenue per day of the week.
+// Query params: start/end (YYYY-MM-DD, inclusive; default the last 30 days).
+func (h *ReportHandler) SalesByWeekday(c *gin.Context) {
+	start, end, ok := reportDateRange(c)
+	if !ok {
+		return
+	}
+
+	sales, err := h.reportService.GetSalesByWeekday(c.Request.Context(), start, end)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Sales by weekday retrieved successfully", sales)
+}
+
+// SupplierPerformance handles lead time, fill rate and spend per supplier.
+// Query params: start/end (YYYY-MM-DD, inclusive, on the purchase date; default the last 30 days).
+func (h *ReportHandler) SupplierPerformance(c *gin.Context) {
+	start, end, ok := reportDateRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetSupplierPerformance(c.Request.Context(), start, end)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Supplier performance retrieved successfully", report)
+}
+
+// reportDateRange parses the start/end query params (YYYY-MM-DD, inclusive), defaulting
+// to the last 30 days. It writes a 400 and returns false if either is malformed.
+func reportDateRange(c *gin.Context) (start, end time.Time, ok bool) {
+	now := time.Now()
+	end = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start = end.AddDate(0, 0, -29)
+
+	var err error
+	if v := c.Query("start"); v != "" {
+		if start, err = time.Parse("2006-01-02", v); err != nil {
+			response.BadRequest(c, "Invalid start date, expected YYYY-MM-DD")
+			return start, end, false
+		}
+	}
+	if v := c.Query("end"); v != "" {
+		if end, err = time.Parse("2006-01-02", v); err != nil {
+			response.BadRequest(c, "Invalid end date, expected YYYY-MM-DD")
+			return start, end, false
+		}
+	}
+	return start, end, true
+}
+
+// InventoryValuation handles the cost and retail value of stock on hand per category
+func (h *ReportHandler) InventoryValuation(c *gin.Context) {
+	valuation, err := h.reportService.GetInventoryValuation(c.Request.Context())
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Inventory valuation retrieved successfully", valuation)
+}
+
+// SlowMovers handles products in stock that haven't sold recently.
+// Query params: days (look-back window, default 90) and max_sold (units sold in the
+// window at or below which a product counts as slow, default 0).
+func (h *ReportHandler) SlowMovers(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil {
+		response.BadRequest(c, "Invalid days")
+		return
+	}
+	maxSold, err := strconv.Atoi(c.DefaultQuery("max_sold", "0"))
+	if err != nil {
+		response.BadRequest(c, "Invalid max_sold")
+		return
+	}
+
+	slowMovers, err := h.reportService.GetSlowMovers(c.Request.Context(), days, maxSold)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Slow movers retrieved successfully", slowMovers)
+}
+
+// TopProducts handles the best selling products by revenue.
+// Query params: limit (1-100, default 10) and start_date/end_date (YYYY-MM-DD,
+// inclusive; either may be omitted to leave that side of the range open).
+func (h *ReportHandler) TopProducts(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		response.BadRequest(c, "Invalid limit")
+		return
+	}
+
+	var start, end *time.Time
+	if v := c.Query("start_date"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date, expected YYYY-MM-DD")
+			return
+		}
+		start = &t
+	}
+	if v := c.Query("end_date"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date, expected YYYY-MM-DD")
+			return
+		}
+		end = &t
+	}
+
+	topProducts, err := h.reportService.GetTopProducts(c.Request.Context(), limit, start, end)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Top products retrieved successfully", topProducts)
+}
+
+// Orders handles the orders report. With stream=true every matching order is written
+// as one JSON object per line (NDJSON), oldest first, with no page cap; this needs the
+// export-data permission and a tenant runs at most two streams at a time.
+// Filters: status, customer_id, start_date/end_date (YYYY-MM-DD), payment_type, has_due.
+func (h *ReportHandler) Orders(c *gin.Context) {
+	if c.Query("stream") != "true" {
+		c.JSON(200, gin.H{"message": "Orders report - Coming soon"})
+		return
+	}
+
+	if !HasPermission(c, "export-data") {
+		response.Forbidden(c, "Streaming exports require the export-data permission")
+		return
+	}
+
+	params := &repository.OrderFilterParams{SkipUserFilter: true}
+	if v := c.Query("status"); v != "" {
+		status, err := strconv.Atoi(v)
+		if err != nil {
+			response.BadRequest(c, "Invalid status")
+			return
+		}
+		orderStatus := enum.OrderStatus(status)
+		params.Status = &orderStatus
+	}
+	if v := c.Query("customer_id"); v != "" {
+		customerID, err := uuid.Parse(v)
+		if err != nil {
+			response.BadRequest(c, "Invalid customer_id")
+			return
+		}
+		params.CustomerID = &customerID
+	}
+	if v := c.Query("start_date"); v != "" {
+		start, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date, expected YYYY-MM-DD")
+			return
+		}
+		params.StartDate = &start
+	}
+	if v := c.Query("end_date"); v != "" {
+		end, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date, expected YYYY-MM-DD")
+			return
+		}
+		params.EndDate = &end
+	}
+	if v := c.Query("payment_type"); v != "" {
+		params.PaymentType = &v
+	}
+	if v := c.Query("has_due"); v != "" {
+		hasDue, err := strconv.ParseBool(v)
+		if err != nil {
+			response.BadRequest(c, "Invalid has_due")
+			return
+		}
+		params.HasDue = &hasDue
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	if !h.startExport(tenantID) {
+		response.TooManyRequests(c, "Too many exports running, try again when one has finished")
+		return
+	}
+	defer h.finishExport(tenantID)
+
+	enc := json.NewEncoder(c.Writer)
+	err := h.reportService.StreamOrders(c.Request.Context(), params, func(rows []service.OrderExportRow) error {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Status(200)
+		}
+		for i := range rows {
+			if err := enc.Encode(&rows[i]); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err == nil {
+		if !c.Writer.Written() {
+			// No orders matched: an empty stream
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Status(200)
+		}
+		return
+	}
+
+	if !c.Writer.Written() {
+		response.Error(c, err)
+		return
+	}
+	// Headers are already sent, so flag the truncated stream in-band
+	log.Printf("Orders export for tenant %s failed: %v", tenantID, err)
+	_ = enc.Encode(gin.H{"error": "Export interrupted"})
+}
+
+// startExport reserves one of the tenant's export slots; it returns false if all are in use
+func (h *ReportHandler) startExport(tenantID uuid.UUID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.exports[tenantID] >= maxConcurrentExports {
+		return false
+	}
+	h.exports[tenantID]++
+	return true
+}
+
+// finishExport frees an export slot taken by startExport
+func (h *ReportHandler) finishExport(tenantID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.exports[tenantID]--; h.exports[tenantID] <= 0 {
+		delete(h.exports, tenantID)
+	}
+}
@@ -3,7 +3,9 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/sangkips/investify-api/internal/application/service"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/request"
 	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/internal/presentation/http/middleware"
 )
 
 // SettingsHandler handles settings-related HTTP requests
@@ -16,7 +18,7 @@ func NewSettingsHandler(settingsService *service.SettingsService) *SettingsHandl
 	return &SettingsHandler{settingsService: settingsService}
 }
 
-// GetSettings retrieves user settings
+// GetSettings retrieves the user's effective settings
 func (h *SettingsHandler) GetSettings(c *gin.Context) {
 	userID := GetUserID(c)
 	if userID == nil {
@@ -24,7 +26,7 @@ func (h *SettingsHandler) GetSettings(c *gin.Context) {
 		return
 	}
 
-	settings, err := h.settingsService.GetSettings(c.Request.Context(), *userID)
+	settings, err := h.settingsService.GetSettings(c.Request.Context(), *userID, middleware.GetTenantID(c))
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -33,7 +35,8 @@ func (h *SettingsHandler) GetSettings(c *gin.Context) {
 	response.OK(c, "Settings retrieved successfully", settings)
 }
 
-// UpdateSettings updates user settings
+// UpdateSettings updates user settings. Both PUT and PATCH accept a partial body;
+// omitted fields keep their current value.
 func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
 	userID := GetUserID(c)
 	if userID == nil {
@@ -41,31 +44,15 @@ func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Language           string `json:"language"`
-		Timezone           string `json:"timezone"`
-		Currency           string `json:"currency"`
-		DateFormat         string `json:"date_format"`
-		EmailNotifications bool   `json:"email_notifications"`
-		PushNotifications  bool   `json:"push_notifications"`
-		OrderAlerts        bool   `json:"order_alerts"`
-		LowStockAlerts     bool   `json:"low_stock_alerts"`
-		MarketingEmails    bool   `json:"marketing_emails"`
-		Theme              string `json:"theme"`
-		CompactMode        bool   `json:"compact_mode"`
-		ShowAnimations     bool   `json:"show_animations"`
-		TwoFactorAuth      bool   `json:"two_factor_auth"`
-		SessionTimeout     string `json:"session_timeout"`
-		LoginAlerts        bool   `json:"login_alerts"`
-	}
-
+	var req request.UpdateSettingsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
 	settings, err := h.settingsService.UpdateSettings(c.Request.Context(), &service.UpdateSettingsInput{
 		UserID:             *userID,
+		TenantID:           middleware.GetTenantID(c),
 		Language:           req.Language,
 		Timezone:           req.Timezone,
 		Currency:           req.Currency,
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/application/service"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/request"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/pkg/pagination"
+)
+
+// ShiftHandler handles cashier shift HTTP requests
+type ShiftHandler struct {
+	shiftService *service.ShiftService
+}
+
+// NewShiftHandler creates a new shift handler
+func NewShiftHandler(shiftService *service.ShiftService) *ShiftHandler {
+	return &ShiftHandler{shiftService: shiftService}
+}
+
+// canViewAllShifts reports whether the user may see and close other cashiers' shifts
+func canViewAllShifts(c *gin.Context) bool {
+	return IsSuperAdmin(c) || HasPermission(c, "view-reports")
+}
+
+// List handles listing shifts. Cashiers see their own; users who can view reports see
+// every cashier's and may filter by user_id.
+func (h *ShiftHandler) List(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "15"))
+	params := &pagination.PaginationParams{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	filter := userID
+	if canViewAllShifts(c) {
+		filter = nil
+		if v := c.Query("user_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				response.BadRequest(c, "Invalid user ID")
+				return
+			}
+			filter = &id
+		}
+	}
+
+	result, err := h.shiftService.ListShifts(c.Request.Context(), params, filter)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithPagination(c, 200, "Shifts retrieved successfully", result)
+}
+
+// Open handles opening a shift for the current user
+func (h *ShiftHandler) Open(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req request.OpenShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	shift, err := h.shiftService.OpenShift(c.Request.Context(), *userID, req.OpeningFloat, req.Notes)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, "Shift opened successfully", shift)
+}
+
+// Current handles getting the current user's open shift
+func (h *ShiftHandler) Current(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	shift, err := h.shiftService.CurrentShift(c.Request.Context(), *userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Shift retrieved successfully", shift)
+}
+
+// Get handles getting a shift by ID
+func (h *ShiftHandler) Get(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid shift ID")
+		return
+	}
+
+	shift, err := h.shiftService.GetShift(c.Request.Context(), id, *userID, canViewAllShifts(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Shift retrieved successfully", shift)
+}
+
+// Close handles closing a shift with the counted cash; it responds with the Z-report
+func (h *ShiftHandler) Close(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid shift ID")
+		return
+	}
+	h.close(c, id)
+}
+
+// CloseCurrent handles closing the current user's open shift with the counted cash
+func (h *ShiftHandler) CloseCurrent(c *gin.Context) {
+	h.close(c, uuid.Nil)
+}
+
+// close closes shift id, or the user's open shift if id is uuid.Nil
+func (h *ShiftHandler) close(c *gin.Context, id uuid.UUID) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req request.CloseShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	report, err := h.shiftService.CloseShift(c.Request.Context(), &service.CloseShiftInput{
+		UserID:      *userID,
+		ID:          id,
+		CloseAll:    canViewAllShifts(c),
+		CountedCash: *req.CountedCash,
+		Notes:       req.Notes,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Shift closed successfully", report)
+}
+
+// Report handles a shift's Z-report, up to now while the shift is open
+func (h *ShiftHandler) Report(c *gin.Context) {
+	userID := GetUserID(c)
+	if userID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid shift ID")
+		return
+	}
+
+	report, err := h.shiftService.GetShiftReport(c.Request.Context(), id, *userID, canViewAllShifts(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Shift report retrieved successfully", report)
+}
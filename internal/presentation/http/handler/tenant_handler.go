@@ -33,7 +33,7 @@ func (h *TenantHandler) Create(c *gin.Context) {
 		Slug string `json:"slug"` // Optional
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -64,7 +64,8 @@ func (h *TenantHandler) GetCurrentTenant(c *gin.Context) {
 			return
 		}
 		response.OK(c, "Tenant retrieved successfully", gin.H{
-			"tenant": tenant,
+			"tenant":   tenant,
+			"features": tenant.Settings.EffectiveFeatureFlags(),
 		})
 		return
 	}
@@ -90,7 +91,8 @@ func (h *TenantHandler) GetCurrentTenant(c *gin.Context) {
 
 	// Return the first tenant as the current/default tenant
 	response.OK(c, "Tenant retrieved successfully", gin.H{
-		"tenant": result.Tenants[0],
+		"tenant":   result.Tenants[0],
+		"features": result.Tenants[0].Settings.EffectiveFeatureFlags(),
 	})
 }
 
@@ -149,7 +151,7 @@ func (h *TenantHandler) UpdateTenant(c *gin.Context) {
 		Settings *entity.TenantSettings `json:"settings"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -168,7 +170,7 @@ func (h *TenantHandler) UpdateTenant(c *gin.Context) {
 	})
 }
 
-// ListMembers returns all members of the current tenant
+// ListMembers returns a page of members of the current tenant (searchable by name or email)
 func (h *TenantHandler) ListMembers(c *gin.Context) {
 	tenantID := middleware.GetTenantID(c)
 	if tenantID == uuid.Nil {
@@ -176,15 +178,20 @@ func (h *TenantHandler) ListMembers(c *gin.Context) {
 		return
 	}
 
-	members, err := h.tenantService.GetTenantMembers(c.Request.Context(), tenantID)
+	var params pagination.PaginationParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		response.BadRequest(c, "Invalid pagination parameters")
+		return
+	}
+	params.Validate()
+
+	result, err := h.tenantService.GetTenantMembers(c.Request.Context(), tenantID, &params, c.Query("search"))
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	response.OK(c, "Members retrieved successfully", gin.H{
-		"members": members,
-	})
+	response.SuccessWithPagination(c, 200, "Members retrieved successfully", result)
 }
 
 // InviteMember invites a user to the current tenant
@@ -200,7 +207,7 @@ func (h *TenantHandler) InviteMember(c *gin.Context) {
 		Role   string    `json:"role" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -259,7 +266,7 @@ func (h *TenantHandler) UpdateMemberRole(c *gin.Context) {
 		Role string `json:"role" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -304,7 +311,7 @@ func (h *TenantHandler) AssignUserToTenant(c *gin.Context) {
 		Role     string    `json:"role"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
 		return
 	}
 
@@ -9,6 +9,7 @@ import (
 	"github.com/sangkips/investify-api/internal/application/service"
 	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
 	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/internal/presentation/http/middleware"
 )
 
 // UserHandler handles user management HTTP requests
@@ -29,9 +30,16 @@ func (h *UserHandler) List(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
-	// If user is super admin, we want to see all users across all tenants
+	// Tenant admins see their own tenant's members; super admins see all users
+	// across all tenants unless they filter by one
 	if IsSuperAdmin(c) {
 		ctx = infraRepo.WithSkipTenantScope(ctx, true)
+		if tenantIDStr := c.Query("tenant_id"); tenantIDStr != "" {
+			if tenantID, err := uuid.Parse(tenantIDStr); err == nil {
+				ctx = infraRepo.WithTenant(ctx, tenantID)
+				ctx = infraRepo.WithSkipTenantScope(ctx, false)
+			}
+		}
 	}
 
 	output, err := h.userService.ListUsers(ctx, &service.ListUsersInput{
@@ -54,6 +62,7 @@ func (h *UserHandler) List(c *gin.Context) {
 			"email":      user.Email,
 			"username":   user.Username,
 			"photo":      user.Photo,
+			"is_active":  user.IsActive,
 			"roles":      user.Roles,
 			"created_at": user.CreatedAt,
 			"updated_at": user.UpdatedAt,
@@ -97,6 +106,7 @@ func (h *UserHandler) Get(c *gin.Context) {
 			"username":    user.Username,
 			"photo":       user.Photo,
 			"store_name":  user.StoreName,
+			"is_active":   user.IsActive,
 			"roles":       user.Roles,
 			"permissions": user.GetPermissions(),
 			"created_at":  user.CreatedAt,
@@ -121,13 +131,21 @@ func (h *UserHandler) UpdateRoles(c *gin.Context) {
 
 	var req UpdateRolesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body")
+		bindError(c, err)
+		return
+	}
+
+	currentUserID := GetUserID(c)
+	if currentUserID == nil {
+		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
 	user, err := h.userService.UpdateUserRoles(c.Request.Context(), &service.UpdateUserRolesInput{
-		UserID:  userID,
-		RoleIDs: req.RoleIDs,
+		ActorID:  *currentUserID,
+		TenantID: middleware.GetTenantID(c),
+		UserID:   userID,
+		RoleIDs:  req.RoleIDs,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -171,6 +189,59 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	response.OK(c, "User deleted successfully", nil)
 }
 
+// UpdateStatusRequest represents the request body for deactivating or reactivating a user
+type UpdateStatusRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
+
+// UpdateStatus handles deactivating or reactivating a user
+func (h *UserHandler) UpdateStatus(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	currentUserID := GetUserID(c)
+	if currentUserID == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	user, err := h.userService.SetUserStatus(c.Request.Context(), &service.SetUserStatusInput{
+		ActorID:           *currentUserID,
+		ActorIsSuperAdmin: IsSuperAdmin(c),
+		TenantID:          middleware.GetTenantID(c),
+		UserID:            userID,
+		IsActive:          *req.IsActive,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	message := "User deactivated successfully"
+	if user.IsActive {
+		message = "User reactivated successfully"
+	}
+	response.OK(c, message, gin.H{
+		"user": gin.H{
+			"id":         user.ID,
+			"first_name": user.FirstName,
+			"last_name":  user.LastName,
+			"email":      user.Email,
+			"username":   user.Username,
+			"is_active":  user.IsActive,
+		},
+	})
+}
+
 // ListRoles handles listing all available roles
 func (h *UserHandler) ListRoles(c *gin.Context) {
 	roles, err := h.userService.ListRoles(c.Request.Context())
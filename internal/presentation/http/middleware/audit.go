@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/application/service"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+)
+
+// auditRecordedKey marks requests whose handler already recorded an audit entry
+const auditRecordedKey = "audit_recorded"
+
+// MarkAuditRecorded stops AuditMiddleware from recording the request; for handlers
+// that record their own, more detailed entry
+func MarkAuditRecorded(c *gin.Context) {
+	c.Set(auditRecordedKey, true)
+}
+
+// auditActions maps mutating HTTP methods to audit actions
+var auditActions = map[string]string{
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "update",
+	http.MethodDelete: "delete",
+}
+
+// AuditMiddleware records successful mutating requests (POST, PUT, PATCH, DELETE) to the audit log.
+// It must run after AuthMiddleware so the actor is known.
+func AuditMiddleware(auditService *service.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		action, mutating := auditActions[c.Request.Method]
+		if !mutating {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		// Only record requests that actually changed something
+		status := c.Writer.Status()
+		if status < 200 || status >= 300 {
+			return
+		}
+
+		// Handlers that write a more detailed entry themselves opt out
+		if c.GetBool(auditRecordedKey) {
+			return
+		}
+
+		actorVal, exists := c.Get("user_id")
+		if !exists {
+			return
+		}
+		actorID, ok := actorVal.(uuid.UUID)
+		if !ok || actorID == uuid.Nil {
+			return
+		}
+
+		route := c.FullPath()
+		entry := &entity.AuditLog{
+			ActorID:      actorID,
+			Action:       action,
+			ResourceType: auditResourceType(route),
+			Metadata: map[string]interface{}{
+				"method":     c.Request.Method,
+				"route":      route,
+				"path":       c.Request.URL.Path,
+				"status":     status,
+				"client_ip":  c.ClientIP(),
+				"user_agent": c.Request.UserAgent(),
+			},
+		}
+
+		if tenantID := GetTenantID(c); tenantID != uuid.Nil {
+			entry.TenantID = &tenantID
+		}
+
+		// Flag actions a super-admin performed while impersonating the actor
+		if impersonator, ok := c.Get("impersonated_by"); ok {
+			if impersonatorID, ok := impersonator.(uuid.UUID); ok {
+				entry.ImpersonatedBy = &impersonatorID
+				entry.Metadata["impersonated"] = true
+			}
+		}
+
+		// The first route parameter (":id", ":slug", ":user_id", ...) identifies the resource
+		if len(c.Params) > 0 {
+			entry.ResourceID = c.Params[0].Value
+		}
+
+		auditService.Record(entry)
+	}
+}
+
+// auditResourceType derives the resource type from a route pattern,
+// e.g. "/api/v1/products/:slug" -> "products", "/api/v2/admin/tenants/assign-user" -> "tenants"
+func auditResourceType(route string) string {
+	route = apiRoute(route)
+	for _, segment := range strings.Split(route, "/") {
+		if segment == "" || segment == "admin" || strings.HasPrefix(segment, ":") {
+			continue
+		}
+		return segment
+	}
+	return "unknown"
+}
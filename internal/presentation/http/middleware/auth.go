@@ -6,13 +6,16 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/repository"
 	infraRepo "github.com/sangkips/investify-api/internal/infrastructure/repository"
 	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
 	"github.com/sangkips/investify-api/pkg/utils"
 )
 
-// AuthMiddleware creates a JWT authentication middleware
-func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
+// AuthMiddleware creates a JWT authentication middleware. Tokens of users who have been
+// deactivated since they were issued are refused; revocations in the JWT manager are
+// only known to the instance that made them, the user's status is known to all.
+func AuthMiddleware(jwtManager *utils.JWTManager, userRepo repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -39,11 +42,29 @@ func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		active, err := userRepo.IsActive(c.Request.Context(), claims.UserID)
+		if err != nil {
+			response.InternalServerError(c, "Failed to check account status")
+			c.Abort()
+			return
+		}
+		if !active {
+			response.Unauthorized(c, "This account has been deactivated")
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_roles", claims.Roles)
 		c.Set("user_permissions", claims.Permissions)
+		c.Set("token_claims", claims)
+
+		// Impersonation tokens name the super-admin acting as the user
+		if claims.ImpersonatedBy != nil {
+			c.Set("impersonated_by", *claims.ImpersonatedBy)
+		}
 
 		// Set tenant_id from JWT claims in Gin context
 		c.Set("tenant_id", claims.TenantID)
@@ -90,6 +111,22 @@ func OptionalAuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
 	}
 }
 
+// RequireStepUp rejects requests not made with a step-up token from POST /auth/step-up,
+// so sensitive actions need a recent password re-entry. It must run after AuthMiddleware.
+func RequireStepUp() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claimsVal, _ := c.Get("token_claims")
+		claims, ok := claimsVal.(*utils.JWTClaims)
+		if !ok || !claims.StepUp {
+			response.Forbidden(c, "This action requires recent re-authentication; get a step-up token from /api/v1/auth/step-up")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RequirePermission creates a middleware that requires a specific permission
 func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/pkg/apperror"
+)
+
+// RouteBodyLimit overrides the default body size limit for routes whose path template
+// below the API version (e.g. "/products/import") starts with PathPrefix
+type RouteBodyLimit struct {
+	PathPrefix string
+	MaxBytes   int64
+}
+
+// BodyLimitConfig holds the request body size limits
+type BodyLimitConfig struct {
+	MaxBytes int64            // Default limit for every route; zero or less disables it
+	Routes   []RouteBodyLimit // Per-route overrides, e.g. for file imports; the first matching prefix wins
+}
+
+// BodyLimit caps the size of request bodies so a huge upload can't exhaust memory.
+// Requests declaring a larger Content-Length are rejected with 413 straight away; other
+// bodies are cut off once they pass the limit, failing the handler's read with an
+// *http.MaxBytesError that it reports as 413.
+func BodyLimit(cfg BodyLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := cfg.limitFor(apiRoute(c.FullPath()))
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			response.Error(c, apperror.NewRequestTooLargeError(limit))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// limitFor returns the body size limit of a route
+func (cfg BodyLimitConfig) limitFor(route string) int64 {
+	if route != "" {
+		for _, r := range cfg.Routes {
+			if strings.HasPrefix(route, r.PathPrefix) {
+				return r.MaxBytes
+			}
+		}
+	}
+	return cfg.MaxBytes
+}
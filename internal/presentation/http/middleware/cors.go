@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"log"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -8,15 +9,48 @@ import (
 	"github.com/sangkips/investify-api/internal/config"
 )
 
-// CORSMiddleware creates a CORS middleware with the provided configuration
+// exposedHeaders are the response headers browsers may always read
+var exposedHeaders = []string{
+	"Content-Length",
+	"Content-Type",
+	"Content-Disposition",
+	"X-Request-ID",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"Retry-After",
+	"Deprecation",
+	"Sunset",
+	"Link",
+}
+
+// CORSMiddleware creates a CORS middleware with the provided configuration.
+// Origins may use one wildcard, e.g. "https://*.investify.com" for tenant subdomains.
+// Allowed origins are echoed back; "*" is only honoured without credentials.
 func CORSMiddleware(cfg *config.CORSConfig) gin.HandlerFunc {
 	corsConfig := cors.Config{
 		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     cfg.AllowedMethods,
 		AllowHeaders:     cfg.AllowedHeaders,
-		ExposeHeaders:    []string{"Content-Length", "Content-Type", "X-Request-ID"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		ExposeHeaders:    append(append([]string{}, exposedHeaders...), cfg.ExposedHeaders...),
+		AllowCredentials: cfg.AllowCredentials,
+		AllowWildcard:    true,
+		MaxAge:           cfg.MaxAge,
+	}
+
+	if corsConfig.MaxAge <= 0 {
+		corsConfig.MaxAge = 12 * time.Hour
+	}
+
+	// A credentialed response may not carry "Access-Control-Allow-Origin: *"
+	if corsConfig.AllowCredentials {
+		for _, origin := range corsConfig.AllowOrigins {
+			if origin == "*" {
+				log.Printf("Warning: CORS origin \"*\" cannot be combined with credentials; credentials disabled")
+				corsConfig.AllowCredentials = false
+				break
+			}
+		}
 	}
 
 	// If no origins are configured, allow common development origins
@@ -43,18 +77,21 @@ func CORSMiddleware(cfg *config.CORSConfig) gin.HandlerFunc {
 			"X-Request-ID",
 			"Origin",
 			"Idempotency-Key",
+			TenantSlugHeader,
 		}
 	} else {
-		// Ensure Idempotency-Key is in the allowed headers
-		hasIdempotencyKey := false
-		for _, h := range corsConfig.AllowHeaders {
-			if h == "Idempotency-Key" {
-				hasIdempotencyKey = true
-				break
+		// Ensure the headers the API depends on are in the allowed headers
+		for _, required := range []string{"Idempotency-Key", TenantSlugHeader} {
+			found := false
+			for _, h := range corsConfig.AllowHeaders {
+				if h == required {
+					found = true
+					break
+				}
+			}
+			if !found {
+				corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, required)
 			}
-		}
-		if !hasIdempotencyKey {
-			corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, "Idempotency-Key")
 		}
 	}
 
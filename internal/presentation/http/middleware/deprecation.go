@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiVersionPrefix matches the version prefix of API routes, e.g. "/api/v1"
+var apiVersionPrefix = regexp.MustCompile(`^/api/v\d+`)
+
+// apiRoute returns a path without its API version prefix, so every version of a route
+// looks the same: "/api/v2/reports/sales-series" becomes "/reports/sales-series"
+func apiRoute(path string) string {
+	return apiVersionPrefix.ReplaceAllString(path, "")
+}
+
+// DeprecationConfig describes routes of an API version that are slated for removal
+type DeprecationConfig struct {
+	Routes     []string  // Route prefixes below the version prefix, e.g. "/quotations"; "/" covers every route
+	Deprecated time.Time // When the routes were deprecated; zero sends "Deprecation: true"
+	Sunset     time.Time // When the routes will be removed; zero omits the Sunset header
+	Successor  string    // Base path of the replacing version, e.g. "/api/v2"; empty omits the Link header
+}
+
+// Deprecation marks responses of deprecated routes with the Deprecation (RFC 9745) and
+// Sunset (RFC 8594) headers, and links the same route in the successor version.
+// It must be added to a version's route group before its routes are registered.
+func Deprecation(cfg DeprecationConfig) gin.HandlerFunc {
+	deprecation := "true"
+	if !cfg.Deprecated.IsZero() {
+		deprecation = "@" + strconv.FormatInt(cfg.Deprecated.Unix(), 10)
+	}
+	var sunset string
+	if !cfg.Sunset.IsZero() {
+		sunset = cfg.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		if route := apiRoute(c.FullPath()); route != "" && deprecatedRoute(cfg.Routes, route) {
+			c.Header("Deprecation", deprecation)
+			if sunset != "" {
+				c.Header("Sunset", sunset)
+			}
+			if cfg.Successor != "" {
+				c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, cfg.Successor, apiRoute(c.Request.URL.Path)))
+			}
+		}
+		c.Next()
+	}
+}
+
+// deprecatedRoute reports whether the route template starts with one of the prefixes
+func deprecatedRoute(prefixes []string, route string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(route, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+)
+
+// RequireFeature rejects requests with 403 unless the active tenant has the feature
+// flag on (see entity.TenantSettings.FeatureFlags). Requests without a tenant are
+// judged by the flag's default. It must run after TenantResolver.
+func RequireFeature(tenantRepo repository.TenantRepository, feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var settings entity.TenantSettings
+		if tenant, ok := c.Get("tenant"); ok {
+			if t, ok := tenant.(*entity.Tenant); ok && t != nil {
+				settings = t.Settings
+			}
+		} else if tenantID := GetTenantID(c); tenantID != uuid.Nil {
+			tenant, err := tenantRepo.GetByID(c.Request.Context(), tenantID)
+			if err != nil {
+				response.InternalServerError(c, "Failed to load tenant")
+				c.Abort()
+				return
+			}
+			if tenant != nil {
+				settings = tenant.Settings
+			}
+		}
+
+		if !settings.FeatureEnabled(feature) {
+			response.Forbidden(c, "This feature is not enabled for your organization")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
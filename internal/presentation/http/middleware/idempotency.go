@@ -2,13 +2,22 @@ package middleware
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sangkips/investify-api/internal/domain/entity"
 	"github.com/sangkips/investify-api/internal/domain/repository"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+	"github.com/sangkips/investify-api/pkg/apperror"
 )
 
 const (
@@ -16,8 +25,15 @@ const (
 	IdempotencyKeyHeader = "Idempotency-Key"
 	// IdempotencyKeyTTL is how long keys are valid
 	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyPendingTTL is how long an in-flight key blocks retries; it bounds the
+	// wait if the server dies before the request finishes
+	IdempotencyPendingTTL = 5 * time.Minute
 )
 
+// replayedHeaders are the response headers stored with a cached response and sent
+// again on replay, so a retried create still gets its Location
+var replayedHeaders = []string{"Content-Type", "Location"}
+
 // IdempotencyConfig holds configuration for the idempotency middleware
 type IdempotencyConfig struct {
 	Repo repository.IdempotencyRepository
@@ -63,39 +79,8 @@ func Idempotency(config IdempotencyConfig) gin.HandlerFunc {
 			return
 		}
 
-		// Check if this key was already processed
-		existing, err := config.Repo.GetByKey(c.Request.Context(), idempotencyKey, userID)
-		if err != nil {
-			c.Next()
-			return
-		}
-
-		// If key exists and not expired, return cached response
-		if existing != nil && !existing.IsExpired() {
-			c.Header("X-Idempotency-Replayed", "true")
-			c.Data(existing.ResponseCode, "application/json", []byte(existing.ResponseBody))
-			c.Abort()
-			return
-		}
-
-		// Capture the response
-		blw := &responseWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
-		c.Writer = blw
-
-		// Process the request
-		c.Next()
-
-		// Store the idempotency key with response
-		ikey := &entity.IdempotencyKey{
-			Key:          idempotencyKey,
-			UserID:       userID,
-			Endpoint:     c.Request.Method + " " + c.FullPath(),
-			ResponseCode: c.Writer.Status(),
-			ResponseBody: blw.body.String(),
-			ExpiresAt:    time.Now().Add(IdempotencyKeyTTL),
-		}
-
-		_ = config.Repo.Create(c.Request.Context(), ikey)
+		// Every response is cached, errors included
+		processIdempotent(c, config.Repo, userID, idempotencyKey, func(int) bool { return true })
 	}
 }
 
@@ -111,10 +96,7 @@ func IdempotencyRequired(config IdempotencyConfig) gin.HandlerFunc {
 		// Require idempotency key
 		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
 		if idempotencyKey == "" {
-			c.JSON(400, gin.H{
-				"success": false,
-				"message": "Idempotency-Key header is required for this request",
-			})
+			response.BadRequest(c, "Idempotency-Key header is required for this request")
 			c.Abort()
 			return
 		}
@@ -122,68 +104,169 @@ func IdempotencyRequired(config IdempotencyConfig) gin.HandlerFunc {
 		// Get user ID from context
 		userIDValue, exists := c.Get("user_id")
 		if !exists {
-			c.JSON(401, gin.H{
-				"success": false,
-				"message": "User not authenticated",
-			})
+			response.Unauthorized(c, "User not authenticated")
 			c.Abort()
 			return
 		}
 		userID, ok := userIDValue.(uuid.UUID)
 		if !ok {
-			c.JSON(401, gin.H{
-				"success": false,
-				"message": "Invalid user ID",
-			})
+			response.Unauthorized(c, "Invalid user ID")
 			c.Abort()
 			return
 		}
 
-		// Check if this key was already processed
-		existing, err := config.Repo.GetByKey(c.Request.Context(), idempotencyKey, userID)
-		if err != nil {
-			c.JSON(500, gin.H{
-				"success": false,
-				"message": "Failed to check idempotency key",
-			})
-			c.Abort()
-			return
+		// Only successful responses (2xx) are cached. A 4xx/5xx releases the key, so a
+		// corrected or retried request with the same key runs again.
+		processIdempotent(c, config.Repo, userID, idempotencyKey, func(status int) bool {
+			return status >= 200 && status < 300
+		})
+	}
+}
+
+// processIdempotent reserves the key for the tenant and user, runs the request and
+// caches its response if keep(status) is true. A key that is already completed replays
+// the cached response; one that is still in flight gets a 409 instead of running twice.
+// A key reused for a different method, path or body gets a 422 rather than the response
+// of the request it was first used for.
+func processIdempotent(c *gin.Context, repo repository.IdempotencyRepository, userID uuid.UUID, key string, keep func(status int) bool) {
+	ctx := c.Request.Context()
+	requestHash, err := hashRequestBody(c)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(c, apperror.NewRequestTooLargeError(tooLarge.Limit))
+		} else {
+			response.BadRequest(c, "Failed to read request body")
 		}
+		c.Abort()
+		return
+	}
 
-		// If key exists and not expired, return cached response
-		if existing != nil && !existing.IsExpired() {
-			c.Header("X-Idempotency-Replayed", "true")
+	ikey := &entity.IdempotencyKey{
+		ID:          uuid.New(),
+		TenantID:    GetTenantID(c),
+		UserID:      userID,
+		Key:         key,
+		Endpoint:    c.Request.Method + " " + c.Request.URL.Path,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(IdempotencyPendingTTL),
+	}
 
-			// Parse and return the cached response
-			var cachedResponse map[string]interface{}
-			if err := json.Unmarshal([]byte(existing.ResponseBody), &cachedResponse); err == nil {
-				c.JSON(existing.ResponseCode, cachedResponse)
-			} else {
-				c.Data(existing.ResponseCode, "application/json", []byte(existing.ResponseBody))
-			}
+	reserved, err := repo.Reserve(ctx, ikey)
+	if err != nil {
+		response.InternalServerError(c, "Failed to check idempotency key")
+		c.Abort()
+		return
+	}
+
+	if !reserved {
+		existing, err := repo.GetByKey(ctx, ikey.TenantID, userID, key)
+		if err != nil || existing == nil {
+			response.InternalServerError(c, "Failed to check idempotency key")
+			c.Abort()
+			return
+		}
+		if existing.Endpoint != ikey.Endpoint || existing.RequestHash != ikey.RequestHash {
+			response.ErrorWithCode(c, 422, "Idempotency-Key was already used for a different request")
 			c.Abort()
 			return
 		}
+		if existing.IsPending() {
+			response.ErrorWithCode(c, 409, "A request with this Idempotency-Key is still being processed")
+			c.Abort()
+			return
+		}
+		replayResponse(c, existing)
+		return
+	}
 
-		// Capture the response
-		blw := &responseWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
-		c.Writer = blw
+	// Capture the response
+	blw := &responseWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+	c.Writer = blw
 
-		// Process the request
-		c.Next()
+	// Process the request
+	c.Next()
 
-		// Only store successful responses (2xx status codes)
-		if c.Writer.Status() >= 200 && c.Writer.Status() < 300 {
-			ikey := &entity.IdempotencyKey{
-				Key:          idempotencyKey,
-				UserID:       userID,
-				Endpoint:     c.Request.Method + " " + c.FullPath(),
-				ResponseCode: c.Writer.Status(),
-				ResponseBody: blw.body.String(),
-				ExpiresAt:    time.Now().Add(IdempotencyKeyTTL),
-			}
+	// Finish with a fresh context so a client disconnect doesn't leave the key pending
+	bg := context.WithoutCancel(ctx)
+	status := c.Writer.Status()
+	if !keep(status) {
+		if err := repo.Delete(bg, ikey.ID); err != nil {
+			log.Printf("Idempotency: failed to release key %s: %v", key, err)
+		}
+		return
+	}
+
+	headers := make(map[string]string, len(replayedHeaders))
+	for _, name := range replayedHeaders {
+		if v := c.Writer.Header().Get(name); v != "" {
+			headers[name] = v
+		}
+	}
+	headersJSON, _ := json.Marshal(headers)
+
+	ikey.ResponseCode = status
+	ikey.ResponseBody = blw.body.String()
+	ikey.ResponseHeaders = string(headersJSON)
+	ikey.ExpiresAt = time.Now().Add(IdempotencyKeyTTL)
+	if err := repo.Complete(bg, ikey); err != nil {
+		log.Printf("Idempotency: failed to store response for key %s: %v", key, err)
+	}
+}
 
-			_ = config.Repo.Create(c.Request.Context(), ikey)
+// hashRequestBody returns the hex SHA-256 of the request body and puts the body back
+// for the handler to read
+func hashRequestBody(c *gin.Context) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			return "", err
 		}
 	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replayResponse sends a cached response with its original status code and headers
+func replayResponse(c *gin.Context, ikey *entity.IdempotencyKey) {
+	contentType := "application/json; charset=utf-8"
+	if ikey.ResponseHeaders != "" {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(ikey.ResponseHeaders), &headers); err == nil {
+			for name, v := range headers {
+				if name == "Content-Type" {
+					contentType = v
+					continue
+				}
+				c.Header(name, v)
+			}
+		}
+	}
+
+	c.Header("X-Idempotency-Replayed", "true")
+	c.Data(ikey.ResponseCode, contentType, []byte(ikey.ResponseBody))
+	c.Abort()
+}
+
+// StartIdempotencyCleanup deletes expired idempotency keys every interval until ctx
+// is cancelled
+func StartIdempotencyCleanup(ctx context.Context, repo repository.IdempotencyRepository, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := repo.DeleteExpired(ctx); err != nil {
+					log.Printf("Idempotency: failed to delete expired keys: %v", err)
+				}
+			}
+		}
+	}()
 }
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/domain/entity"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
+)
+
+// fakeIdempotencyRepo keeps keys in memory, scoped like the database unique index
+type fakeIdempotencyRepo struct {
+	mu   sync.Mutex
+	keys map[string]*entity.IdempotencyKey
+}
+
+func idempotencyScope(tenantID, userID uuid.UUID, key string) string {
+	return tenantID.String() + "/" + userID.String() + "/" + key
+}
+
+func (r *fakeIdempotencyRepo) GetByKey(ctx context.Context, tenantID, userID uuid.UUID, key string) (*entity.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ikey, ok := r.keys[idempotencyScope(tenantID, userID, key)]; ok {
+		copied := *ikey
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeIdempotencyRepo) Reserve(ctx context.Context, ikey *entity.IdempotencyKey) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	scope := idempotencyScope(ikey.TenantID, ikey.UserID, ikey.Key)
+	if existing, ok := r.keys[scope]; ok && !existing.IsExpired() {
+		return false, nil
+	}
+	copied := *ikey
+	r.keys[scope] = &copied
+	return true, nil
+}
+
+func (r *fakeIdempotencyRepo) Complete(ctx context.Context, ikey *entity.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *ikey
+	r.keys[idempotencyScope(ikey.TenantID, ikey.UserID, ikey.Key)] = &copied
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for scope, ikey := range r.keys {
+		if ikey.ID == id {
+			delete(r.keys, scope)
+		}
+	}
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) DeleteExpired(ctx context.Context) error {
+	return nil
+}
+
+func TestIdempotencyReplaysCreatedOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &fakeIdempotencyRepo{keys: make(map[string]*entity.IdempotencyKey)}
+	userID, tenantID := uuid.New(), uuid.New()
+
+	var created int
+	router := gin.New()
+	router.POST("/orders", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("tenant_id", tenantID)
+	}, IdempotencyRequired(IdempotencyConfig{Repo: repo}), func(c *gin.Context) {
+		created++
+		id := uuid.New()
+		c.Header("Location", "/api/v1/orders/"+id.String())
+		response.Created(c, "Order created successfully", gin.H{"id": id})
+	})
+
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set(IdempotencyKeyHeader, "order-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := post()
+	retry := post()
+
+	if first.Code != http.StatusCreated || retry.Code != http.StatusCreated {
+		t.Fatalf("status = %d then %d, want 201 both times", first.Code, retry.Code)
+	}
+	if created != 1 {
+		t.Errorf("handler ran %d times, want once", created)
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("replayed body %s, want %s", retry.Body, first.Body)
+	}
+	if retry.Header().Get("Location") != first.Header().Get("Location") {
+		t.Errorf("replayed Location %q, want %q", retry.Header().Get("Location"), first.Header().Get("Location"))
+	}
+	if retry.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("replayed Content-Type %q, want %q", retry.Header().Get("Content-Type"), first.Header().Get("Content-Type"))
+	}
+	if retry.Header().Get("X-Idempotency-Replayed") != "true" {
+		t.Error("retry was not marked as replayed")
+	}
+}
+
+func TestIdempotencyRejectsKeyReusedForDifferentRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &fakeIdempotencyRepo{keys: make(map[string]*entity.IdempotencyKey)}
+	userID, tenantID := uuid.New(), uuid.New()
+
+	var paid int
+	router := gin.New()
+	router.POST("/orders/:id/pay", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("tenant_id", tenantID)
+	}, IdempotencyRequired(IdempotencyConfig{Repo: repo}), func(c *gin.Context) {
+		paid++
+		response.OK(c, "Payment recorded", nil)
+	})
+
+	pay := func(orderID uuid.UUID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID.String()+"/pay", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "pay-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	orderID := uuid.New()
+	if w := pay(orderID, `{"amount":10}`); w.Code != http.StatusOK {
+		t.Fatalf("first payment status = %d, want 200", w.Code)
+	}
+	if w := pay(orderID, `{"amount":10}`); w.Code != http.StatusOK || w.Header().Get("X-Idempotency-Replayed") != "true" {
+		t.Errorf("identical retry status = %d, replayed %q; want a replayed 200", w.Code, w.Header().Get("X-Idempotency-Replayed"))
+	}
+	if w := pay(uuid.New(), `{"amount":10}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reused for another order: status = %d, want 422", w.Code)
+	}
+	if w := pay(orderID, `{"amount":20}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reused with another body: status = %d, want 422", w.Code)
+	}
+	if paid != 1 {
+		t.Errorf("handler ran %d times, want once", paid)
+	}
+}
@@ -1,24 +1,17 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// LoggerMiddleware creates a structured logging middleware
+// LoggerMiddleware creates a structured JSON logging middleware.
+// It must run after RequestIDMiddleware so every record carries the request ID.
 func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Generate request ID if not present
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			requestID = uuid.New().String()
-		}
-		c.Set("request_id", requestID)
-		c.Header("X-Request-ID", requestID)
-
 		// Start timer
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -27,38 +20,39 @@ func LoggerMiddleware() gin.HandlerFunc {
 		// Process request
 		c.Next()
 
-		// Calculate latency
-		latency := time.Since(start)
-
-		// Get status code
 		statusCode := c.Writer.Status()
 
-		// Get client IP
-		clientIP := c.ClientIP()
-
-		// Get method
-		method := c.Request.Method
-
-		// Build full path
+		attrs := []any{
+			"request_id", c.GetString("request_id"),
+			"method", c.Request.Method,
+			"path", path,
+			"status", statusCode,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		}
 		if raw != "" {
-			path = path + "?" + raw
+			attrs = append(attrs, "query", raw)
 		}
-
-		// Log the request
-		log.Printf("[%s] %s | %d | %v | %s | %s",
-			requestID[:8],
-			method,
-			statusCode,
-			latency,
-			clientIP,
-			path,
-		)
-
-		// Log errors if any
-		if len(c.Errors) > 0 {
-			for _, e := range c.Errors {
-				log.Printf("[%s] Error: %v", requestID[:8], e.Err)
+		if tenantID := GetTenantID(c); tenantID != uuid.Nil {
+			attrs = append(attrs, "tenant_id", tenantID.String())
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			if id, ok := userID.(uuid.UUID); ok && id != uuid.Nil {
+				attrs = append(attrs, "user_id", id.String())
 			}
 		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.Errors())
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case statusCode >= 500:
+			level = slog.LevelError
+		case statusCode >= 400:
+			level = slog.LevelWarn
+		}
+
+		slog.Log(c.Request.Context(), level, "http_request", attrs...)
 	}
 }
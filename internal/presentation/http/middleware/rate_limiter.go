@@ -1,34 +1,58 @@
 package middleware
 
 import (
-	"net/http"
+	"math"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sangkips/investify-api/internal/presentation/http/dto/response"
 	"golang.org/x/time/rate"
 )
 
 // TenantRateLimiter provides per-tenant rate limiting to prevent noisy neighbor issues
 type TenantRateLimiter struct {
-	limiters    map[uuid.UUID]*rateLimiterEntry
+	limiters    map[limiterKey]*rateLimiterEntry
 	mu          sync.RWMutex
 	rate        rate.Limit // requests per second
 	burst       int        // maximum burst size
+	routes      []RouteLimit
+	bypassRoles []string
 	cleanupTick time.Duration
 	entryTTL    time.Duration
 }
 
+// limiterKey identifies a tenant's bucket; route is the index of the matching route
+// override, or -1 for the default limit
+type limiterKey struct {
+	tenantID uuid.UUID
+	route    int
+}
+
 type rateLimiterEntry struct {
 	limiter  *rate.Limiter
 	lastSeen time.Time
 }
 
+// RouteLimit overrides the default rate for routes whose path template below the API
+// version (e.g. "/reports/sales-series") starts with PathPrefix. Requests matching an
+// override draw from a separate per-tenant bucket instead of the default one, shared by
+// every API version.
+type RouteLimit struct {
+	PathPrefix        string
+	RequestsPerSecond float64
+	BurstSize         int
+}
+
 // RateLimiterConfig holds configuration for the rate limiter
 type RateLimiterConfig struct {
 	RequestsPerSecond float64       // Rate of requests allowed per second
 	BurstSize         int           // Maximum burst size
+	Routes            []RouteLimit  // Per-route overrides; the first matching prefix wins
+	BypassRoles       []string      // Users with any of these roles are not limited
 	CleanupInterval   time.Duration // How often to clean up stale entries
 	EntryTTL          time.Duration // How long to keep unused entries
 }
@@ -46,9 +70,11 @@ func DefaultRateLimiterConfig() RateLimiterConfig {
 // NewTenantRateLimiter creates a new per-tenant rate limiter
 func NewTenantRateLimiter(cfg RateLimiterConfig) *TenantRateLimiter {
 	rl := &TenantRateLimiter{
-		limiters:    make(map[uuid.UUID]*rateLimiterEntry),
+		limiters:    make(map[limiterKey]*rateLimiterEntry),
 		rate:        rate.Limit(cfg.RequestsPerSecond),
 		burst:       cfg.BurstSize,
+		routes:      cfg.Routes,
+		bypassRoles: cfg.BypassRoles,
 		cleanupTick: cfg.CleanupInterval,
 		entryTTL:    cfg.EntryTTL,
 	}
@@ -59,10 +85,40 @@ func NewTenantRateLimiter(cfg RateLimiterConfig) *TenantRateLimiter {
 	return rl
 }
 
-// getLimiter returns the rate limiter for a specific tenant
-func (rl *TenantRateLimiter) getLimiter(tenantID uuid.UUID) *rate.Limiter {
+// matchRoute returns the index of the first route override matching the path template,
+// or -1 if the default limit applies
+func (rl *TenantRateLimiter) matchRoute(fullPath string) int {
+	fullPath = apiRoute(fullPath)
+	for i, route := range rl.routes {
+		if strings.HasPrefix(fullPath, route.PathPrefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+// limits returns the rate and burst of a bucket
+func (rl *TenantRateLimiter) limits(route int) (rate.Limit, int) {
+	if route < 0 {
+		return rl.rate, rl.burst
+	}
+	return rate.Limit(rl.routes[route].RequestsPerSecond), rl.routes[route].BurstSize
+}
+
+// bypassed reports whether the user holds a role that is exempt from rate limiting
+func (rl *TenantRateLimiter) bypassed(c *gin.Context) bool {
+	for _, role := range rl.bypassRoles {
+		if hasRole(c, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// getLimiter returns the rate limiter for a tenant's bucket
+func (rl *TenantRateLimiter) getLimiter(key limiterKey) *rate.Limiter {
 	rl.mu.RLock()
-	entry, exists := rl.limiters[tenantID]
+	entry, exists := rl.limiters[key]
 	rl.mu.RUnlock()
 
 	if exists {
@@ -78,13 +134,13 @@ func (rl *TenantRateLimiter) getLimiter(tenantID uuid.UUID) *rate.Limiter {
 	defer rl.mu.Unlock()
 
 	// Double check after acquiring write lock
-	if entry, exists := rl.limiters[tenantID]; exists {
+	if entry, exists := rl.limiters[key]; exists {
 		entry.lastSeen = time.Now()
 		return entry.limiter
 	}
 
-	limiter := rate.NewLimiter(rl.rate, rl.burst)
-	rl.limiters[tenantID] = &rateLimiterEntry{
+	limiter := rate.NewLimiter(rl.limits(key.route))
+	rl.limiters[key] = &rateLimiterEntry{
 		limiter:  limiter,
 		lastSeen: time.Now(),
 	}
@@ -108,17 +164,20 @@ func (rl *TenantRateLimiter) cleanup() {
 	defer rl.mu.Unlock()
 
 	cutoff := time.Now().Add(-rl.entryTTL)
-	for tenantID, entry := range rl.limiters {
+	for key, entry := range rl.limiters {
 		if entry.lastSeen.Before(cutoff) {
-			delete(rl.limiters, tenantID)
+			delete(rl.limiters, key)
 		}
 	}
 }
 
-// Middleware returns a Gin middleware that applies per-tenant rate limiting
+// Middleware returns a Gin middleware that applies per-tenant rate limiting.
+// Every limited response carries X-RateLimit-Limit (the burst size),
+// X-RateLimit-Remaining and X-RateLimit-Reset (seconds until the bucket is full again);
+// rejected requests also get Retry-After in seconds.
 func (rl *TenantRateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get tenant ID from context (set by TenantMiddleware or AuthMiddleware)
+		// Get tenant ID from context (set by AuthMiddleware or TenantResolver)
 		tenantID := GetTenantID(c)
 
 		// If no tenant ID, use a default limiter for unauthenticated requests
@@ -130,41 +189,46 @@ func (rl *TenantRateLimiter) Middleware() gin.HandlerFunc {
 			return
 		}
 
-		limiter := rl.getLimiter(tenantID)
-
-		if !limiter.Allow() {
-			c.Header("X-RateLimit-Limit", formatInt(rl.burst))
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("Retry-After", "1")
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"success": false,
-				"message": "Rate limit exceeded. Please try again later.",
-				"error":   "too_many_requests",
-			})
+		if rl.bypassed(c) {
+			c.Next()
 			return
 		}
 
-		// Add rate limit headers
-		c.Header("X-RateLimit-Limit", formatInt(rl.burst))
-		c.Header("X-RateLimit-Remaining", formatInt(int(limiter.Tokens())))
+		key := limiterKey{tenantID: tenantID, route: rl.matchRoute(c.FullPath())}
+		limiter := rl.getLimiter(key)
+		allowed := limiter.Allow()
+		setRateLimitHeaders(c, limiter)
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(secondsUntil(limiter, 1)))
+			response.TooManyRequests(c, "Rate limit exceeded. Please try again later.")
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
 
-// formatInt converts int to string for headers
-func formatInt(n int) string {
-	if n < 0 {
-		return "-" + formatIntPositive(-n)
-	}
-	return formatIntPositive(n)
+// setRateLimitHeaders writes the X-RateLimit-* headers from the bucket state
+func setRateLimitHeaders(c *gin.Context, limiter *rate.Limiter) {
+	tokens := limiter.Tokens()
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limiter.Burst()))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(int(math.Max(tokens, 0))))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(secondsUntil(limiter, float64(limiter.Burst()))))
 }
 
-func formatIntPositive(n int) string {
-	if n < 10 {
-		return string(rune('0' + n))
+// secondsUntil returns the whole seconds (rounded up) until the bucket holds `tokens`
+// tokens, or 0 if it already does
+func secondsUntil(limiter *rate.Limiter, tokens float64) int {
+	missing := tokens - limiter.Tokens()
+	if missing <= 0 {
+		return 0
+	}
+	if limiter.Limit() <= 0 {
+		return 1
 	}
-	return formatIntPositive(n/10) + string(rune('0'+n%10))
+	return int(math.Max(1, math.Ceil(missing/float64(limiter.Limit()))))
 }
 
 // Stats returns current statistics about the rate limiter
@@ -173,7 +237,8 @@ func (rl *TenantRateLimiter) Stats() map[string]interface{} {
 	defer rl.mu.RUnlock()
 
 	return map[string]interface{}{
-		"active_tenants":      len(rl.limiters),
+		"active_buckets":      len(rl.limiters),
+		"route_overrides":     len(rl.routes),
 		"rate_per_second":     float64(rl.rate),
 		"burst_size":          rl.burst,
 		"cleanup_interval_ms": rl.cleanupTick.Milliseconds(),
@@ -26,38 +26,67 @@ func ExtractTenantFromHost(host string) (string, error) {
 	return parts[0], nil
 }
 
-// TenantMiddleware validates tenant from subdomain and adds to context
-func TenantMiddleware(tenantRepo repository.TenantRepository) gin.HandlerFunc {
+// TenantSlugHeader lets clients pick the active tenant explicitly
+const TenantSlugHeader = "X-Tenant-Slug"
+
+// TenantResolver selects the request's tenant from the X-Tenant-Slug header or,
+// failing that, the request subdomain, and puts it in context (overriding the
+// tenant from the JWT). It must run after AuthMiddleware.
+//
+// An unknown slug in the header is a 404; an unknown subdomain is ignored so the
+// API host's own subdomain (e.g. "api.") does not break requests. Users who are not
+// members of the requested tenant get a 403, except super-admins.
+// Requests naming no tenant keep the tenant from the JWT.
+func TenantResolver(tenantRepo repository.TenantRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Extract tenant from subdomain
-		tenantSlug, err := ExtractTenantFromHost(c.Request.Host)
-		if err != nil {
-			// Allow requests without subdomain (for backwards compatibility during migration)
-			// In production, you may want to enforce this
-			c.Set("tenant_id", uuid.Nil)
-			c.Next()
-			return
+		ctx := c.Request.Context()
+
+		tenantSlug := strings.TrimSpace(c.GetHeader(TenantSlugHeader))
+		fromHeader := tenantSlug != ""
+		if !fromHeader {
+			slug, err := ExtractTenantFromHost(c.Request.Host)
+			if err != nil {
+				c.Next()
+				return
+			}
+			tenantSlug = slug
 		}
 
-		// Lookup tenant by slug
-		tenant, err := tenantRepo.GetBySlug(c.Request.Context(), tenantSlug)
-		if err != nil || tenant == nil {
-			response.NotFound(c, "Tenant not found")
+		tenant, err := tenantRepo.GetBySlug(ctx, tenantSlug)
+		if err != nil {
+			response.InternalServerError(c, "Failed to resolve tenant")
 			c.Abort()
 			return
 		}
+		if tenant == nil {
+			if fromHeader {
+				response.NotFound(c, "Tenant not found")
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
 
-		// Validate user has access to this tenant (if authenticated)
-		userIDVal, exists := c.Get("user_id")
-		if exists {
-			userID, ok := userIDVal.(uuid.UUID)
-			if ok && userID != uuid.Nil {
-				isMember, _ := tenantRepo.IsMember(c.Request.Context(), tenant.ID, userID)
-				if !isMember {
-					response.Forbidden(c, "Access denied to this tenant")
-					c.Abort()
-					return
-				}
+		if !hasRole(c, "super-admin") {
+			userID, _ := c.Get("user_id")
+			uid, ok := userID.(uuid.UUID)
+			if !ok || uid == uuid.Nil {
+				response.Forbidden(c, "Access denied to this tenant")
+				c.Abort()
+				return
+			}
+
+			isMember, err := tenantRepo.IsMember(ctx, tenant.ID, uid)
+			if err != nil {
+				response.InternalServerError(c, "Failed to verify tenant membership")
+				c.Abort()
+				return
+			}
+			if !isMember {
+				response.Forbidden(c, "Access denied to this tenant")
+				c.Abort()
+				return
 			}
 		}
 
@@ -66,13 +95,24 @@ func TenantMiddleware(tenantRepo repository.TenantRepository) gin.HandlerFunc {
 		c.Set("tenant", tenant)
 
 		// Also set tenant ID in request context (for services/repositories)
-		ctx := infraRepo.WithTenant(c.Request.Context(), tenant.ID)
-		c.Request = c.Request.WithContext(ctx)
+		c.Request = c.Request.WithContext(infraRepo.WithTenant(ctx, tenant.ID))
 
 		c.Next()
 	}
 }
 
+// hasRole reports whether the authenticated user has the given role
+func hasRole(c *gin.Context, role string) bool {
+	roles, _ := c.Get("user_roles")
+	userRoles, _ := roles.([]string)
+	for _, r := range userRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 // RequireTenant ensures a valid tenant context exists
 func RequireTenant() gin.HandlerFunc {
 	return func(c *gin.Context) {
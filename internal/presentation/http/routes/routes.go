@@ -1,10 +1,14 @@
 package routes
 
 import (
+	"context"
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sangkips/investify-api/internal/application/service"
 	"github.com/sangkips/investify-api/internal/config"
+	"github.com/sangkips/investify-api/internal/domain/entity"
 	domainRepo "github.com/sangkips/investify-api/internal/domain/repository"
 	"github.com/sangkips/investify-api/internal/presentation/http/handler"
 	"github.com/sangkips/investify-api/internal/presentation/http/middleware"
@@ -13,21 +17,28 @@ import (
 
 // Handlers holds all the HTTP handlers used for route registration.
 type Handlers struct {
-	Auth      *handler.AuthHandler
-	Tenant    *handler.TenantHandler
-	Product   *handler.ProductHandler
-	Category  *handler.CategoryHandler
-	Unit      *handler.UnitHandler
-	Order     *handler.OrderHandler
-	Purchase  *handler.PurchaseHandler
-	Customer  *handler.CustomerHandler
-	Supplier  *handler.SupplierHandler
-	Dashboard *handler.DashboardHandler
-	Quotation *handler.QuotationHandler
-	Settings  *handler.SettingsHandler
-	User      *handler.UserHandler
-	Printer   *handler.PrinterHandler
-	Mpesa     *handler.MpesaHandler
+	Auth          *handler.AuthHandler
+	Tenant        *handler.TenantHandler
+	Product       *handler.ProductHandler
+	Category      *handler.CategoryHandler
+	Unit          *handler.UnitHandler
+	Order         *handler.OrderHandler
+	Purchase      *handler.PurchaseHandler
+	Customer      *handler.CustomerHandler
+	Supplier      *handler.SupplierHandler
+	Promotion     *handler.PromotionHandler
+	Shift         *handler.ShiftHandler
+	Dashboard     *handler.DashboardHandler
+	Quotation     *handler.QuotationHandler
+	Settings      *handler.SettingsHandler
+	User          *handler.UserHandler
+	Printer       *handler.PrinterHandler
+	Report        *handler.ReportHandler
+	Mpesa         *handler.MpesaHandler
+	Audit         *handler.AuditHandler
+	Impersonation *handler.ImpersonationHandler
+	Health        *handler.HealthHandler
+	Enum          *handler.EnumHandler
 }
 
 // Deps holds shared dependencies needed by the routes.
@@ -35,6 +46,9 @@ type Deps struct {
 	JWTManager      *utils.JWTManager
 	Cfg             *config.Config
 	IdempotencyRepo domainRepo.IdempotencyRepository
+	AuditService    *service.AuditService
+	TenantRepo      domainRepo.TenantRepository
+	UserRepo        domainRepo.UserRepository
 }
 
 // Setup creates the Gin router and registers all routes.
@@ -43,47 +57,115 @@ func Setup(h *Handlers, deps *Deps) *gin.Engine {
 
 	// Global middleware
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestIDMiddleware())
 	router.Use(middleware.LoggerMiddleware())
 	router.Use(middleware.CORSMiddleware(&deps.Cfg.CORS))
 
-	// Health check endpoint
+	// Request body limits; file imports and uploads get the larger upload limit.
+	// Multipart files beyond the default limit are buffered on disk, not in memory.
+	upload := func(prefix string) middleware.RouteBodyLimit {
+		return middleware.RouteBodyLimit{PathPrefix: prefix, MaxBytes: deps.Cfg.Storage.UploadMaxSize}
+	}
+	router.Use(middleware.BodyLimit(middleware.BodyLimitConfig{
+		MaxBytes: deps.Cfg.App.MaxBodySize,
+		Routes: []middleware.RouteBodyLimit{
+			upload("/products/import"),
+			upload("/customers/import"),
+			upload("/suppliers/import"),
+			upload("/profile/photo"),
+		},
+	}))
+	if deps.Cfg.App.MaxBodySize > 0 {
+		router.MaxMultipartMemory = deps.Cfg.App.MaxBodySize
+	}
+
+	// Health check endpoints: /health is the liveness probe, /health/ready checks dependencies
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status":  "ok",
 			"service": deps.Cfg.App.Name,
 		})
 	})
+	router.GET("/health/ready", h.Health.Ready)
+
+	// Uploaded files (profile photos) under the path of STORAGE_PUBLIC_URL
+	if publicURL, err := url.Parse(deps.Cfg.Storage.PublicURL); err == nil && publicURL.Path != "" && publicURL.Path != "/" {
+		router.Static(publicURL.Path, deps.Cfg.Storage.Path)
+	}
 
-	// API v1 routes
+	// Sweep expired idempotency keys
+	middleware.StartIdempotencyCleanup(context.Background(), deps.IdempotencyRepo, time.Hour)
+
+	// Per-tenant rate limiter, shared by every API version
+	rl := deps.Cfg.RateLimit
+	heavy := func(prefix string) middleware.RouteLimit {
+		return middleware.RouteLimit{
+			PathPrefix:        prefix,
+			RequestsPerSecond: float64(rl.HeavyRequests) / float64(rl.HeavyDuration),
+			BurstSize:         rl.HeavyRequests,
+		}
+	}
+	rateLimiter := middleware.NewTenantRateLimiter(middleware.RateLimiterConfig{
+		RequestsPerSecond: float64(rl.Requests) / float64(rl.Duration),
+		BurstSize:         rl.Requests,
+		Routes: []middleware.RouteLimit{
+			heavy("/reports"),
+			heavy("/products/import"),
+			heavy("/customers/import"),
+			heavy("/suppliers/import"),
+			heavy("/customers/:id/statement.pdf"),
+		},
+		BypassRoles:     rl.BypassRoles,
+		CleanupInterval: 5 * time.Minute,
+		EntryTTL:        10 * time.Minute,
+	})
+
+	// API v1 routes; those listed in API_V1_DEPRECATED_ROUTES carry Deprecation/Sunset headers
+	v1Deprecation := middleware.DeprecationConfig{
+		Routes:     deps.Cfg.API.V1DeprecatedRoutes,
+		Deprecated: deps.Cfg.API.V1DeprecatedAt,
+		Sunset:     deps.Cfg.API.V1Sunset,
+	}
+	if deps.Cfg.API.V2Enabled {
+		v1Deprecation.Successor = "/api/v2"
+	}
 	v1 := router.Group("/api/v1")
-	{
-		// Public routes (no authentication required)
-		registerAuthRoutes(v1, h)
-
-		// M-Pesa callback (public — Safaricom calls this directly)
-		v1.POST("/mpesa/callback", h.Mpesa.Callback)
-
-		// Protected routes (authentication required)
-		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware(deps.JWTManager))
-
-		// Per-tenant rate limiter
-		rateLimiter := middleware.NewTenantRateLimiter(middleware.RateLimiterConfig{
-			RequestsPerSecond: float64(deps.Cfg.RateLimit.Requests) / float64(deps.Cfg.RateLimit.Duration),
-			BurstSize:         deps.Cfg.RateLimit.Requests,
-			CleanupInterval:   5 * time.Minute,
-			EntryTTL:          10 * time.Minute,
-		})
-		protected.Use(rateLimiter.Middleware())
+	v1.Use(middleware.Deprecation(v1Deprecation))
+	registerAPIRoutes(v1, h, deps, rateLimiter)
 
-		registerProtectedRoutes(protected, h, deps)
+	// API v2 routes share the v1 handlers until a route's contract changes
+	if deps.Cfg.API.V2Enabled {
+		registerAPIRoutes(router.Group("/api/v2"), h, deps, rateLimiter)
 	}
 
 	return router
 }
 
-func registerAuthRoutes(v1 *gin.RouterGroup, h *Handlers) {
-	auth := v1.Group("/auth")
+// registerAPIRoutes registers every route of an API version on api
+func registerAPIRoutes(api *gin.RouterGroup, h *Handlers, deps *Deps, rateLimiter *middleware.TenantRateLimiter) {
+	// Public routes (no authentication required)
+	registerAuthRoutes(api, h)
+
+	// M-Pesa callback (public — Safaricom calls this directly)
+	api.POST("/mpesa/callback", h.Mpesa.Callback)
+
+	// Protected routes (authentication required)
+	protected := api.Group("")
+	protected.Use(middleware.AuthMiddleware(deps.JWTManager, deps.UserRepo))
+
+	// Active tenant from X-Tenant-Slug or subdomain (falls back to the JWT tenant)
+	protected.Use(middleware.TenantResolver(deps.TenantRepo))
+
+	protected.Use(rateLimiter.Middleware())
+
+	// Audit trail for all mutating requests
+	protected.Use(middleware.AuditMiddleware(deps.AuditService))
+
+	registerProtectedRoutes(protected, h, deps)
+}
+
+func registerAuthRoutes(api *gin.RouterGroup, h *Handlers) {
+	auth := api.Group("/auth")
 	{
 		auth.POST("/login", h.Auth.Login)
 		auth.POST("/register", h.Auth.Register)
@@ -99,17 +181,25 @@ func registerAuthRoutes(v1 *gin.RouterGroup, h *Handlers) {
 func registerProtectedRoutes(protected *gin.RouterGroup, h *Handlers, deps *Deps) {
 	// Auth/Profile routes
 	protected.POST("/auth/logout", h.Auth.Logout)
+	protected.POST("/auth/step-up", h.Auth.StepUp)
+	protected.POST("/auth/end-impersonation", h.Impersonation.End)
 	protected.GET("/profile", h.Auth.GetProfile)
 	protected.PUT("/profile", h.Auth.UpdateProfile)
 	protected.PUT("/profile/password", h.Auth.ChangePassword)
+	protected.POST("/profile/photo", h.Auth.UploadPhoto)
+	protected.POST("/profile/deactivate", middleware.RequireStepUp(), h.Auth.Deactivate)
 
 	// Settings
 	protected.GET("/settings", h.Settings.GetSettings)
 	protected.PUT("/settings", h.Settings.UpdateSettings)
+	protected.PATCH("/settings", h.Settings.UpdateSettings)
 
 	// Dashboard
 	protected.GET("/dashboard", h.Dashboard.GetStats)
 
+	// Enum values and labels for building dropdowns
+	protected.GET("/enums", h.Enum.List)
+
 	// Tenants
 	registerTenantRoutes(protected, h)
 
@@ -125,6 +215,9 @@ func registerProtectedRoutes(protected *gin.RouterGroup, h *Handlers, deps *Deps
 	// Orders
 	registerOrderRoutes(protected, h, deps)
 
+	// Cashier shifts
+	registerShiftRoutes(protected, h)
+
 	// Purchases
 	registerPurchaseRoutes(protected, h)
 
@@ -134,11 +227,14 @@ func registerProtectedRoutes(protected *gin.RouterGroup, h *Handlers, deps *Deps
 	// Suppliers
 	registerSupplierRoutes(protected, h)
 
+	// Promotions
+	registerPromotionRoutes(protected, h)
+
 	// Quotations
 	registerQuotationRoutes(protected, h)
 
 	// Reports
-	registerReportRoutes(protected)
+	registerReportRoutes(protected, h)
 
 	// Users (Admin)
 	registerUserRoutes(protected, h)
@@ -153,10 +249,10 @@ func registerProtectedRoutes(protected *gin.RouterGroup, h *Handlers, deps *Deps
 	registerAdminRoutes(protected, h)
 
 	// Printer
-	registerPrinterRoutes(protected, h)
+	registerPrinterRoutes(protected, h, deps)
 
 	// M-Pesa payments
-	registerMpesaRoutes(protected, h)
+	registerMpesaRoutes(protected, h, deps)
 }
 
 func registerTenantRoutes(protected *gin.RouterGroup, h *Handlers) {
@@ -180,10 +276,17 @@ func registerProductRoutes(protected *gin.RouterGroup, h *Handlers) {
 		products.GET("", h.Product.List)
 		products.POST("", h.Product.Create)
 		products.POST("/import", h.Product.ImportProducts)
+		products.POST("/bulk-delete", h.Product.BulkDelete)
 		products.GET("/low-stock", h.Product.GetLowStock)
+		products.GET("/reorder-suggestions", h.Product.ReorderSuggestions)
+		products.GET("/by-code/:code", h.Product.GetByCode)
 		products.GET("/:slug", h.Product.Get)
 		products.PUT("/:slug", h.Product.Update)
+		products.PATCH("/:slug", h.Product.Update)
 		products.DELETE("/:slug", h.Product.Delete)
+		products.POST("/:slug/images", h.Product.AddImage)
+		products.PUT("/:slug/images", h.Product.ReorderImages)
+		products.DELETE("/:slug/images/:imageID", h.Product.DeleteImage)
 	}
 }
 
@@ -193,6 +296,7 @@ func registerCategoryRoutes(protected *gin.RouterGroup, h *Handlers) {
 	{
 		categories.GET("", h.Category.List)
 		categories.POST("", h.Category.Create)
+		categories.POST("/batch", h.Category.BatchCreate)
 		categories.PUT("/:id", h.Category.Update)
 		categories.DELETE("/:id", h.Category.Delete)
 	}
@@ -204,11 +308,38 @@ func registerUnitRoutes(protected *gin.RouterGroup, h *Handlers) {
 	{
 		units.GET("", h.Unit.List)
 		units.POST("", h.Unit.Create)
+		units.POST("/batch", h.Unit.BatchCreate)
 		units.PUT("/:id", h.Unit.Update)
 		units.DELETE("/:id", h.Unit.Delete)
 	}
 }
 
+func registerShiftRoutes(protected *gin.RouterGroup, h *Handlers) {
+	shifts := protected.Group("/shifts")
+	shifts.Use(middleware.RequirePermission("manage-orders"))
+	{
+		shifts.GET("", h.Shift.List)
+		shifts.POST("/open", h.Shift.Open)
+		shifts.GET("/current", h.Shift.Current)
+		shifts.POST("/close", h.Shift.CloseCurrent)
+		shifts.GET("/:id", h.Shift.Get)
+		shifts.POST("/:id/close", h.Shift.Close)
+		shifts.GET("/:id/report", h.Shift.Report)
+	}
+}
+
+func registerPromotionRoutes(protected *gin.RouterGroup, h *Handlers) {
+	promotions := protected.Group("/promotions")
+	promotions.Use(middleware.RequirePermission("manage-promotions"))
+	{
+		promotions.GET("", h.Promotion.List)
+		promotions.POST("", h.Promotion.Create)
+		promotions.GET("/:id", h.Promotion.Get)
+		promotions.PUT("/:id", h.Promotion.Update)
+		promotions.DELETE("/:id", h.Promotion.Delete)
+	}
+}
+
 func registerOrderRoutes(protected *gin.RouterGroup, h *Handlers, deps *Deps) {
 	orders := protected.Group("/orders")
 	orders.Use(middleware.RequirePermission("manage-orders"))
@@ -219,10 +350,16 @@ func registerOrderRoutes(protected *gin.RouterGroup, h *Handlers, deps *Deps) {
 			Repo: deps.IdempotencyRepo,
 		}), h.Order.Create)
 		orders.GET("/due", h.Order.GetDueOrders)
+		orders.POST("/bulk-status", h.Order.BulkUpdateStatus)
 		orders.GET("/:id", h.Order.Get)
 		orders.PUT("/:id/status", h.Order.UpdateStatus)
 		orders.POST("/:id/cancel", h.Order.Cancel)
-		orders.POST("/:id/pay", h.Order.PayDue)
+		// A retried payment must not be credited twice
+		orders.POST("/:id/pay", middleware.IdempotencyRequired(middleware.IdempotencyConfig{
+			Repo: deps.IdempotencyRepo,
+		}), h.Order.PayDue)
+		orders.GET("/:id/receipt", h.Printer.OrderReceipt)
+		orders.POST("/:id/email", h.Order.EmailReceipt)
 	}
 }
 
@@ -232,6 +369,7 @@ func registerPurchaseRoutes(protected *gin.RouterGroup, h *Handlers) {
 	{
 		purchases.GET("", h.Purchase.List)
 		purchases.POST("", h.Purchase.Create)
+		purchases.POST("/auto-generate", h.Purchase.AutoGenerate)
 		purchases.GET("/pending", h.Purchase.GetPending)
 		purchases.GET("/:id", h.Purchase.Get)
 		purchases.POST("/:id/approve", h.Purchase.Approve)
@@ -244,10 +382,15 @@ func registerCustomerRoutes(protected *gin.RouterGroup, h *Handlers) {
 	customers.Use(middleware.RequirePermission("manage-customers"))
 	{
 		customers.GET("", h.Customer.List)
+		customers.GET("/trash", h.Customer.Trash)
 		customers.POST("", h.Customer.Create)
+		customers.POST("/import", h.Customer.Import)
 		customers.GET("/:id", h.Customer.Get)
+		customers.GET("/:id/statement.pdf", h.Customer.Statement)
+		customers.GET("/:id/loyalty", h.Customer.Loyalty)
 		customers.PUT("/:id", h.Customer.Update)
 		customers.DELETE("/:id", h.Customer.Delete)
+		customers.POST("/:id/restore", h.Customer.Restore)
 	}
 }
 
@@ -256,10 +399,14 @@ func registerSupplierRoutes(protected *gin.RouterGroup, h *Handlers) {
 	suppliers.Use(middleware.RequirePermission("manage-suppliers"))
 	{
 		suppliers.GET("", h.Supplier.List)
+		suppliers.GET("/trash", h.Supplier.Trash)
 		suppliers.POST("", h.Supplier.Create)
+		suppliers.POST("/import", h.Supplier.Import)
 		suppliers.GET("/:id", h.Supplier.Get)
+		suppliers.GET("/:id/products", h.Supplier.Products)
 		suppliers.PUT("/:id", h.Supplier.Update)
 		suppliers.DELETE("/:id", h.Supplier.Delete)
+		suppliers.POST("/:id/restore", h.Supplier.Restore)
 	}
 }
 
@@ -275,19 +422,24 @@ func registerQuotationRoutes(protected *gin.RouterGroup, h *Handlers) {
 	}
 }
 
-func registerReportRoutes(protected *gin.RouterGroup) {
+func registerReportRoutes(protected *gin.RouterGroup, h *Handlers) {
 	reports := protected.Group("/reports")
 	reports.Use(middleware.RequirePermission("view-reports"))
 	{
-		reports.GET("/orders", func(c *gin.Context) {
-			c.JSON(200, gin.H{"message": "Orders report - Coming soon"})
-		})
+		reports.GET("/orders", h.Report.Orders)
 		reports.GET("/purchases", func(c *gin.Context) {
 			c.JSON(200, gin.H{"message": "Purchases report - Coming soon"})
 		})
 		reports.GET("/products", func(c *gin.Context) {
 			c.JSON(200, gin.H{"message": "Products report - Coming soon"})
 		})
+		reports.GET("/sales-series", h.Report.SalesSeries)
+		reports.GET("/sales-by-hour", h.Report.SalesByHour)
+		reports.GET("/sales-by-weekday", h.Report.SalesByWeekday)
+		reports.GET("/inventory-valuation", h.Report.InventoryValuation)
+		reports.GET("/slow-movers", h.Report.SlowMovers)
+		reports.GET("/suppliers", h.Report.SupplierPerformance)
+		reports.GET("/products/top", h.Report.TopProducts)
 	}
 }
 
@@ -298,6 +450,7 @@ func registerUserRoutes(protected *gin.RouterGroup, h *Handlers) {
 		users.GET("", h.User.List)
 		users.GET("/:id", h.User.Get)
 		users.PUT("/:id/roles", h.User.UpdateRoles)
+		users.PUT("/:id/status", h.User.UpdateStatus)
 		users.DELETE("/:id", h.User.Delete)
 	}
 }
@@ -323,11 +476,14 @@ func registerAdminRoutes(protected *gin.RouterGroup, h *Handlers) {
 	admin.Use(middleware.RequireRole("super-admin"))
 	{
 		admin.POST("/tenants/assign-user", h.Tenant.AssignUserToTenant)
+		admin.GET("/audit-logs", h.Audit.List)
+		admin.POST("/impersonate", middleware.RequireStepUp(), h.Impersonation.Start)
 	}
 }
 
-func registerPrinterRoutes(protected *gin.RouterGroup, h *Handlers) {
+func registerPrinterRoutes(protected *gin.RouterGroup, h *Handlers, deps *Deps) {
 	printerGroup := protected.Group("/printer")
+	printerGroup.Use(middleware.RequireFeature(deps.TenantRepo, entity.FeaturePrinting))
 	{
 		printerGroup.GET("/status", h.Printer.GetStatus)
 		printerGroup.POST("/test", h.Printer.TestPrint)
@@ -335,8 +491,9 @@ func registerPrinterRoutes(protected *gin.RouterGroup, h *Handlers) {
 	}
 }
 
-func registerMpesaRoutes(protected *gin.RouterGroup, h *Handlers) {
+func registerMpesaRoutes(protected *gin.RouterGroup, h *Handlers, deps *Deps) {
 	mpesa := protected.Group("/mpesa")
+	mpesa.Use(middleware.RequireFeature(deps.TenantRepo, entity.FeatureMpesa))
 	mpesa.Use(middleware.RequirePermission("manage-orders"))
 	{
 		mpesa.POST("/stkpush", h.Mpesa.InitiateSTKPush)
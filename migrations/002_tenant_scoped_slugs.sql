@@ -0,0 +1,31 @@
+-- Migration: Scope slug/code uniqueness per tenant
+-- Slugs (and product codes) used to be unique across the whole table, so one
+-- tenant creating "Beverages" blocked every other tenant from using that name.
+-- This migration:
+-- 1. Drops the legacy global unique constraints and indexes
+-- 2. Creates composite unique indexes on (tenant_id, slug) / (tenant_id, code)
+--
+-- AutoMigrate performs the same steps on startup; this file is for databases
+-- migrated by hand.
+
+-- Drop legacy global unique constraints (named by Postgres or GORM)
+ALTER TABLE products   DROP CONSTRAINT IF EXISTS products_slug_key;
+ALTER TABLE products   DROP CONSTRAINT IF EXISTS uni_products_slug;
+ALTER TABLE products   DROP CONSTRAINT IF EXISTS products_code_key;
+ALTER TABLE products   DROP CONSTRAINT IF EXISTS uni_products_code;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_slug_key;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS uni_categories_slug;
+ALTER TABLE units      DROP CONSTRAINT IF EXISTS units_slug_key;
+ALTER TABLE units      DROP CONSTRAINT IF EXISTS uni_units_slug;
+
+-- Drop legacy global unique indexes
+DROP INDEX IF EXISTS idx_products_slug;
+DROP INDEX IF EXISTS idx_products_code;
+DROP INDEX IF EXISTS idx_categories_slug;
+DROP INDEX IF EXISTS idx_units_slug;
+
+-- Tenant-scoped uniqueness
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_product_slug  ON products (tenant_id, slug);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_product_code  ON products (tenant_id, code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_category_slug ON categories (tenant_id, slug);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_unit_slug     ON units (tenant_id, slug);
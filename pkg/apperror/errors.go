@@ -2,6 +2,7 @@ package apperror
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 )
 
@@ -35,6 +36,8 @@ var (
 	ErrEmailNotVerified   = &AppError{Code: http.StatusForbidden, Message: "Email not verified"}
 	ErrTokenExpired       = &AppError{Code: http.StatusUnauthorized, Message: "Token has expired"}
 	ErrInvalidToken       = &AppError{Code: http.StatusUnauthorized, Message: "Invalid token"}
+	ErrAccountDeactivated = &AppError{Code: http.StatusForbidden, Message: "This account has been deactivated"}
+	ErrVersionConflict    = &AppError{Code: http.StatusConflict, Message: "This record was modified by someone else; reload it and try again"}
 )
 
 // NewAppError creates a new application error
@@ -78,6 +81,18 @@ func NewBadRequestError(message string) *AppError {
 	}
 }
 
+// NewRequestTooLargeError creates a 413 error for a request body over limit bytes
+func NewRequestTooLargeError(limit int64) *AppError {
+	size := fmt.Sprintf("%d KB", limit>>10)
+	if limit >= 1<<20 {
+		size = fmt.Sprintf("%.4g MB", float64(limit)/(1<<20))
+	}
+	return &AppError{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: "Request body is too large; the limit is " + size,
+	}
+}
+
 // IsAppError checks if an error is an AppError
 func IsAppError(err error) bool {
 	var appErr *AppError
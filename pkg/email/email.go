@@ -2,37 +2,65 @@ package email
 
 import (
 	"bytes"
-	"crypto/tls"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"html/template"
-	"net"
-	"net/smtp"
 	"net/url"
 	"time"
 )
 
-// EmailConfig holds SMTP configuration
+// EmailConfig holds email configuration
 type EmailConfig struct {
+	Transport string // smtp (default), sendgrid or ses
+
 	SMTPHost     string
 	SMTPPort     int
 	SMTPUsername string
 	SMTPPassword string
-	FromName     string
-	FromEmail    string
-	FrontendURL  string
+	// SMTPDisableTLS sends in plain text, for local servers such as MailHog; port 465
+	// otherwise always uses implicit TLS and other ports require STARTTLS
+	SMTPDisableTLS bool
+
+	SendGridAPIKey string
+
+	SESRegion          string
+	SESAccessKeyID     string
+	SESSecretAccessKey string
+
+	FromName    string
+	FromEmail   string
+	FrontendURL string
+
+	// Delivery queue; zero values use the defaults in queue.go
+	Workers    int // Concurrent senders; with SMTP each can keep a connection open
+	QueueSize  int // Emails that may wait for a worker before Send* calls fail
+	MaxRetries int // Retries after the first attempt for transient failures; negative disables retries
 }
 
-// EmailService handles email sending
+// EmailService handles email sending. Send* methods render the email and queue it;
+// a pool of workers delivers queued emails through the configured transport.
 type EmailService struct {
-	config EmailConfig
+	config    EmailConfig
+	transport EmailTransport
+	queue     *queue
 }
 
-// NewEmailService creates a new email service
-func NewEmailService(config EmailConfig) *EmailService {
-	return &EmailService{config: config}
+// NewEmailService creates a new email service and starts its delivery workers
+func NewEmailService(config EmailConfig) (*EmailService, error) {
+	transport, err := NewTransport(config)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &EmailService{config: config, transport: transport}
+	s.queue = newQueue(transport, config.Workers, config.QueueSize, config.MaxRetries)
+	return s, nil
 }
 
-// SendPasswordResetEmail sends a password reset email
+// SendPasswordResetEmail queues a password reset email
 func (s *EmailService) SendPasswordResetEmail(toEmail, token string) error {
 	// Build the reset URL
 	resetURL := fmt.Sprintf("%s/reset-password?token=%s&email=%s",
@@ -47,23 +75,28 @@ func (s *EmailService) SendPasswordResetEmail(toEmail, token string) error {
 		return fmt.Errorf("failed to render email template: %w", err)
 	}
 
-	// Build the email
+	// Queue the email; failures are logged with the token's reference, never the token
 	subject := "Reset Your Password - Investify"
-	message := s.buildHTMLEmail(toEmail, subject, htmlContent)
+	return s.enqueue(job{to: toEmail, subject: subject, htmlBody: htmlContent, ref: TokenRef(token)})
+}
 
-	// Send the email
-	return s.sendEmail(toEmail, message)
+// TokenRef identifies a secret token in logs by a prefix of its SHA-256 hash, so a
+// logged failure can be matched to a stored token without revealing it
+func TokenRef(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:8])
 }
 
 // LowStockProduct holds details of a product that has reached low stock
 type LowStockProduct struct {
 	Name          string
 	Code          string
-	Quantity      int
+	Quantity      int // Available: on hand less reserved
+	Reserved      int
 	QuantityAlert int
 }
 
-// SendLowStockAlertEmail sends a low stock alert email to an admin
+// SendLowStockAlertEmail queues a low stock alert email to an admin
 func (s *EmailService) SendLowStockAlertEmail(toEmail, orgName string, products []LowStockProduct) error {
 	htmlContent, err := s.renderLowStockAlertEmail(orgName, products)
 	if err != nil {
@@ -71,9 +104,7 @@ func (s *EmailService) SendLowStockAlertEmail(toEmail, orgName string, products
 	}
 
 	subject := fmt.Sprintf("⚠️ Low Stock Alert - %s", orgName)
-	message := s.buildHTMLEmail(toEmail, subject, htmlContent)
-
-	return s.sendEmail(toEmail, message)
+	return s.enqueue(job{to: toEmail, subject: subject, htmlBody: htmlContent})
 }
 
 // renderLowStockAlertEmail renders the low stock alert email template
@@ -103,99 +134,146 @@ func (s *EmailService) renderLowStockAlertEmail(orgName string, products []LowSt
 	return buf.String(), nil
 }
 
-// sendEmail sends an email using SMTP with timeout protection
-func (s *EmailService) sendEmail(to string, message []byte) error {
-	addr := net.JoinHostPort(s.config.SMTPHost, fmt.Sprintf("%d", s.config.SMTPPort))
+// OrderReceipt holds an order receipt with its amounts already formatted
+type OrderReceipt struct {
+	StoreName   string
+	TaxID       string
+	InvoiceNo   string
+	Date        string
+	Customer    string
+	PaymentType string
+	Currency    string
+	Items       []OrderReceiptItem
+	SubTotal    string
+	TaxLabel    string
+	VAT         string // "Exempt" for VAT-exempt customers; empty when no tax was charged
+	PromoLabel  string // Names the promo code, e.g. "Discount (SAVE10)"
+	Promo       string // Promotion discount, shown negative; empty when no code was applied
+	Discount    string // Redeemed loyalty points, shown negative; empty when none were redeemed
+	Rounding    string // Cash rounding; empty when there is none
+	Total       string
+	Paid        string // Paid, Due and Change are empty when zero
+	Due         string
+	Change      string
+	Footer      string
+}
 
-	// Create a dialer with timeout to prevent indefinite blocking
-	dialer := &net.Dialer{
-		Timeout: 30 * time.Second,
-	}
+// OrderReceiptItem is a line on an order receipt
+type OrderReceiptItem struct {
+	Name      string
+	Quantity  int
+	UnitPrice string
+	Total     string
+}
 
-	// Connect with timeout
-	conn, err := dialer.Dial("tcp", addr)
-	if err != nil {
-		return fmt.Errorf("failed to connect to SMTP server: %w", err)
-	}
-	defer conn.Close()
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
 
-	// Set read/write deadlines to prevent hanging during SMTP conversation
-	deadline := time.Now().Add(60 * time.Second)
-	if err := conn.SetDeadline(deadline); err != nil {
-		return fmt.Errorf("failed to set connection deadline: %w", err)
+// contentType returns the attachment's MIME type, defaulting to a generic binary type
+func (a Attachment) contentType() string {
+	if a.ContentType == "" {
+		return "application/octet-stream"
 	}
+	return a.ContentType
+}
 
-	// Create SMTP client
-	client, err := smtp.NewClient(conn, s.config.SMTPHost)
+// SendOrderReceiptEmail queues an order receipt to a customer, with an optional attachment
+func (s *EmailService) SendOrderReceiptEmail(toEmail string, receipt OrderReceipt, attachment *Attachment) error {
+	htmlContent, err := s.renderOrderReceiptEmail(receipt)
 	if err != nil {
-		return fmt.Errorf("failed to create SMTP client: %w", err)
-	}
-	defer client.Close()
-
-	// Start TLS for Gmail (required for port 587)
-	tlsConfig := &tls.Config{
-		ServerName: s.config.SMTPHost,
-	}
-	if err := client.StartTLS(tlsConfig); err != nil {
-		return fmt.Errorf("failed to start TLS: %w", err)
+		return fmt.Errorf("failed to render order receipt email: %w", err)
 	}
 
-	// Authenticate
-	auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
-	if err := client.Auth(auth); err != nil {
-		return fmt.Errorf("SMTP authentication failed: %w", err)
-	}
-
-	// Set sender
-	if err := client.Mail(s.config.FromEmail); err != nil {
-		return fmt.Errorf("failed to set sender: %w", err)
-	}
+	subject := fmt.Sprintf("Your receipt %s from %s", receipt.InvoiceNo, receipt.StoreName)
 
-	// Set recipient
-	if err := client.Rcpt(to); err != nil {
-		return fmt.Errorf("failed to set recipient: %w", err)
+	j := job{to: toEmail, subject: subject, htmlBody: htmlContent, ref: receipt.InvoiceNo}
+	if attachment != nil {
+		j.attachments = []Attachment{*attachment}
 	}
+	return s.enqueue(j)
+}
 
-	// Send the email body
-	wc, err := client.Data()
+// renderOrderReceiptEmail renders the order receipt email template
+func (s *EmailService) renderOrderReceiptEmail(receipt OrderReceipt) (string, error) {
+	tmpl, err := template.New("order_receipt").Parse(orderReceiptTemplate)
 	if err != nil {
-		return fmt.Errorf("failed to start email data: %w", err)
-	}
-
-	if _, err := wc.Write(message); err != nil {
-		wc.Close()
-		return fmt.Errorf("failed to write email body: %w", err)
+		return "", err
 	}
 
-	if err := wc.Close(); err != nil {
-		return fmt.Errorf("failed to close email data: %w", err)
+	data := struct {
+		OrderReceipt
+		AppName string
+		Year    int
+	}{
+		OrderReceipt: receipt,
+		AppName:      "Investify",
+		Year:         time.Now().Year(),
 	}
 
-	// Quit gracefully
-	if err := client.Quit(); err != nil {
-		// Log but don't fail - email was already sent
-		return nil
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
 	}
 
-	return nil
+	return buf.String(), nil
 }
 
-// buildHTMLEmail builds an HTML email message
-func (s *EmailService) buildHTMLEmail(to, subject, htmlBody string) []byte {
-	headers := fmt.Sprintf(
+// buildMessage builds a MIME email message: plain HTML, or multipart/mixed when
+// there are attachments. SMTP and SES send this raw message.
+func buildMessage(fromName, fromEmail, to, subject, htmlBody string, attachments []Attachment) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf,
 		"From: %s <%s>\r\n"+
 			"To: %s\r\n"+
 			"Subject: %s\r\n"+
-			"MIME-Version: 1.0\r\n"+
-			"Content-Type: text/html; charset=\"UTF-8\"\r\n"+
-			"\r\n",
-		s.config.FromName,
-		s.config.FromEmail,
+			"MIME-Version: 1.0\r\n",
+		fromName,
+		fromEmail,
 		to,
 		subject,
 	)
 
-	return []byte(headers + htmlBody)
+	if len(attachments) == 0 {
+		buf.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n\r\n")
+		buf.WriteString(htmlBody)
+		return buf.Bytes(), nil
+	}
+
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	boundary := "investify-" + hex.EncodeToString(nonce)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n", boundary)
+
+	// HTML body
+	fmt.Fprintf(&buf, "--%s\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s\r\n", boundary, htmlBody)
+
+	// Attachments, base64 encoded in 76 character lines
+	for _, attachment := range attachments {
+		fmt.Fprintf(&buf,
+			"--%s\r\n"+
+				"Content-Type: %s; name=\"%s\"\r\n"+
+				"Content-Transfer-Encoding: base64\r\n"+
+				"Content-Disposition: attachment; filename=\"%s\"\r\n"+
+				"\r\n",
+			boundary, attachment.contentType(), attachment.Filename, attachment.Filename,
+		)
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			buf.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		buf.WriteString(encoded + "\r\n")
+	}
+
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
 }
 
 // renderPasswordResetEmail renders the password reset email template
@@ -342,7 +420,8 @@ const lowStockAlertTemplate = `
                                     <tr style="background-color: #f7fafc;">
                                         <th style="padding: 12px 16px; text-align: left; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Product</th>
                                         <th style="padding: 12px 16px; text-align: left; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Code</th>
-                                        <th style="padding: 12px 16px; text-align: center; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Current Qty</th>
+                                        <th style="padding: 12px 16px; text-align: center; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Available</th>
+                                        <th style="padding: 12px 16px; text-align: center; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Reserved</th>
                                         <th style="padding: 12px 16px; text-align: center; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Alert At</th>
                                     </tr>
                                 </thead>
@@ -352,6 +431,7 @@ const lowStockAlertTemplate = `
                                         <td style="padding: 12px 16px; font-size: 14px; color: #2d3748; border-bottom: 1px solid #e2e8f0;">{{.Name}}</td>
                                         <td style="padding: 12px 16px; font-size: 14px; color: #718096; border-bottom: 1px solid #e2e8f0;">{{.Code}}</td>
                                         <td style="padding: 12px 16px; font-size: 14px; color: #e53e3e; font-weight: 600; text-align: center; border-bottom: 1px solid #e2e8f0;">{{.Quantity}}</td>
+                                        <td style="padding: 12px 16px; font-size: 14px; color: #718096; text-align: center; border-bottom: 1px solid #e2e8f0;">{{.Reserved}}</td>
                                         <td style="padding: 12px 16px; font-size: 14px; color: #718096; text-align: center; border-bottom: 1px solid #e2e8f0;">{{.QuantityAlert}}</td>
                                     </tr>
                                     {{end}}
@@ -382,3 +462,137 @@ const lowStockAlertTemplate = `
 </body>
 </html>
 `
+
+// orderReceiptTemplate is the HTML template for order receipt emails
+const orderReceiptTemplate = `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Receipt {{.InvoiceNo}}</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; background-color: #f4f7fa;">
+    <table role="presentation" style="width: 100%; border-collapse: collapse;">
+        <tr>
+            <td style="padding: 40px 0;">
+                <table role="presentation" style="max-width: 600px; margin: 0 auto; background-color: #ffffff; border-radius: 12px; overflow: hidden; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);">
+                    <!-- Header -->
+                    <tr>
+                        <td style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); padding: 40px 30px; text-align: center;">
+                            <h1 style="color: #ffffff; margin: 0; font-size: 28px; font-weight: 600;">{{.StoreName}}</h1>
+                            {{if .TaxID}}<p style="color: #e2e8f0; margin: 10px 0 0 0; font-size: 14px;">Tax ID: {{.TaxID}}</p>{{end}}
+                        </td>
+                    </tr>
+                    
+                    <!-- Content -->
+                    <tr>
+                        <td style="padding: 40px 30px;">
+                            <h2 style="color: #1a1a2e; margin: 0 0 20px 0; font-size: 24px; font-weight: 600;">Receipt {{.InvoiceNo}}</h2>
+                            
+                            <p style="color: #4a5568; font-size: 16px; line-height: 1.6; margin: 0 0 20px 0;">
+                                {{if .Customer}}Hello {{.Customer}},{{else}}Hello,{{end}}
+                            </p>
+                            
+                            <p style="color: #4a5568; font-size: 16px; line-height: 1.6; margin: 0 0 20px 0;">
+                                Thank you for your purchase on {{.Date}}{{if .PaymentType}}, paid by {{.PaymentType}}{{end}}. Amounts are in {{.Currency}}.
+                            </p>
+                            
+                            <!-- Items Table -->
+                            <table role="presentation" style="width: 100%; border-collapse: collapse; margin: 0 0 30px 0;">
+                                <thead>
+                                    <tr style="background-color: #f7fafc;">
+                                        <th style="padding: 12px 16px; text-align: left; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Item</th>
+                                        <th style="padding: 12px 16px; text-align: center; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Qty</th>
+                                        <th style="padding: 12px 16px; text-align: right; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Price</th>
+                                        <th style="padding: 12px 16px; text-align: right; font-size: 13px; font-weight: 600; color: #4a5568; border-bottom: 2px solid #e2e8f0;">Total</th>
+                                    </tr>
+                                </thead>
+                                <tbody>
+                                    {{range .Items}}
+                                    <tr>
+                                        <td style="padding: 12px 16px; font-size: 14px; color: #2d3748; border-bottom: 1px solid #e2e8f0;">{{.Name}}</td>
+                                        <td style="padding: 12px 16px; font-size: 14px; color: #718096; text-align: center; border-bottom: 1px solid #e2e8f0;">{{.Quantity}}</td>
+                                        <td style="padding: 12px 16px; font-size: 14px; color: #718096; text-align: right; border-bottom: 1px solid #e2e8f0;">{{.UnitPrice}}</td>
+                                        <td style="padding: 12px 16px; font-size: 14px; color: #2d3748; text-align: right; border-bottom: 1px solid #e2e8f0;">{{.Total}}</td>
+                                    </tr>
+                                    {{end}}
+                                </tbody>
+                                <tfoot>
+                                    <tr>
+                                        <td colspan="3" style="padding: 8px 16px; font-size: 14px; color: #718096; text-align: right;">Subtotal</td>
+                                        <td style="padding: 8px 16px; font-size: 14px; color: #2d3748; text-align: right;">{{.SubTotal}}</td>
+                                    </tr>
+                                    {{if .VAT}}
+                                    <tr>
+                                        <td colspan="3" style="padding: 8px 16px; font-size: 14px; color: #718096; text-align: right;">{{.TaxLabel}}</td>
+                                        <td style="padding: 8px 16px; font-size: 14px; color: #2d3748; text-align: right;">{{.VAT}}</td>
+                                    </tr>
+                                    {{end}}
+                                    {{if .Promo}}
+                                    <tr>
+                                        <td colspan="3" style="padding: 8px 16px; font-size: 14px; color: #718096; text-align: right;">{{.PromoLabel}}</td>
+                                        <td style="padding: 8px 16px; font-size: 14px; color: #2d3748; text-align: right;">{{.Promo}}</td>
+                                    </tr>
+                                    {{end}}
+                                    {{if .Discount}}
+                                    <tr>
+                                        <td colspan="3" style="padding: 8px 16px; font-size: 14px; color: #718096; text-align: right;">Loyalty discount</td>
+                                        <td style="padding: 8px 16px; font-size: 14px; color: #2d3748; text-align: right;">{{.Discount}}</td>
+                                    </tr>
+                                    {{end}}
+                                    {{if .Rounding}}
+                                    <tr>
+                                        <td colspan="3" style="padding: 8px 16px; font-size: 14px; color: #718096; text-align: right;">Rounding</td>
+                                        <td style="padding: 8px 16px; font-size: 14px; color: #2d3748; text-align: right;">{{.Rounding}}</td>
+                                    </tr>
+                                    {{end}}
+                                    <tr>
+                                        <td colspan="3" style="padding: 8px 16px; font-size: 16px; font-weight: 600; color: #1a1a2e; text-align: right;">Total</td>
+                                        <td style="padding: 8px 16px; font-size: 16px; font-weight: 600; color: #1a1a2e; text-align: right;">{{.Total}}</td>
+                                    </tr>
+                                    {{if .Paid}}
+                                    <tr>
+                                        <td colspan="3" style="padding: 8px 16px; font-size: 14px; color: #718096; text-align: right;">Paid</td>
+                                        <td style="padding: 8px 16px; font-size: 14px; color: #2d3748; text-align: right;">{{.Paid}}</td>
+                                    </tr>
+                                    {{end}}
+                                    {{if .Due}}
+                                    <tr>
+                                        <td colspan="3" style="padding: 8px 16px; font-size: 14px; color: #718096; text-align: right;">Due</td>
+                                        <td style="padding: 8px 16px; font-size: 14px; color: #e53e3e; font-weight: 600; text-align: right;">{{.Due}}</td>
+                                    </tr>
+                                    {{end}}
+                                    {{if .Change}}
+                                    <tr>
+                                        <td colspan="3" style="padding: 8px 16px; font-size: 14px; color: #718096; text-align: right;">Change</td>
+                                        <td style="padding: 8px 16px; font-size: 14px; color: #2d3748; text-align: right;">{{.Change}}</td>
+                                    </tr>
+                                    {{end}}
+                                </tfoot>
+                            </table>
+                            
+                            <p style="color: #718096; font-size: 14px; line-height: 1.6; margin: 0;">
+                                {{.Footer}}
+                            </p>
+                        </td>
+                    </tr>
+                    
+                    <!-- Footer -->
+                    <tr>
+                        <td style="background-color: #f8fafc; padding: 30px; text-align: center; border-top: 1px solid #e2e8f0;">
+                            <p style="color: #a0aec0; font-size: 14px; margin: 0 0 10px 0;">
+                                This email was sent by {{.AppName}} on behalf of {{.StoreName}}
+                            </p>
+                            <p style="color: #cbd5e0; font-size: 12px; margin: 0;">
+                                © {{.Year}} {{.AppName}}. All rights reserved.
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`
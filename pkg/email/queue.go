@@ -0,0 +1,164 @@
+package email
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultWorkers    = 2
+	defaultQueueSize  = 100
+	defaultMaxRetries = 3
+
+	retryBaseDelay = 2 * time.Second // Doubled after every failed attempt
+)
+
+var (
+	// ErrQueueFull is returned when more emails are waiting than the queue holds
+	ErrQueueFull = errors.New("email queue is full")
+	// ErrQueueClosed is returned for emails sent after Close
+	ErrQueueClosed = errors.New("email service is closed")
+)
+
+// job is a rendered email waiting for delivery
+type job struct {
+	to          string
+	subject     string
+	htmlBody    string
+	attachments []Attachment
+	ref         string // Optional reference included in failure logs
+}
+
+// Stats counts emails by outcome since the service started
+type Stats struct {
+	Queued   int64 `json:"queued"`   // Accepted by the queue
+	Sent     int64 `json:"sent"`     // Delivered to the transport
+	Retried  int64 `json:"retried"`  // Failed attempts that were retried
+	Failed   int64 `json:"failed"`   // Given up on and dead-lettered
+	Rejected int64 `json:"rejected"` // Refused because the queue was full or closed
+	Pending  int64 `json:"pending"`  // Waiting for a worker now
+}
+
+// queue is a buffered channel of emails drained by a pool of workers, so callers
+// never wait on the mail server
+type queue struct {
+	transport  EmailTransport
+	jobs       chan job
+	maxRetries int
+
+	queued, sent, retried, failed, rejected atomic.Int64
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// newQueue starts the delivery workers; non-positive settings use the defaults
+func newQueue(transport EmailTransport, workers, size, maxRetries int) *queue {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	} else if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	q := &queue{transport: transport, jobs: make(chan job, size), maxRetries: maxRetries}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// enqueue hands a rendered email to the delivery workers without waiting for it to be sent
+func (s *EmailService) enqueue(j job) error {
+	q := s.queue
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		q.rejected.Add(1)
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.jobs <- j:
+		q.queued.Add(1)
+		return nil
+	default:
+		q.rejected.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// Stats returns the delivery counters
+func (s *EmailService) Stats() Stats {
+	q := s.queue
+	return Stats{
+		Queued:   q.queued.Load(),
+		Sent:     q.sent.Load(),
+		Retried:  q.retried.Load(),
+		Failed:   q.failed.Load(),
+		Rejected: q.rejected.Load(),
+		Pending:  int64(len(q.jobs)),
+	}
+}
+
+// Close stops accepting emails, waits until the queued ones have been delivered
+// or dead-lettered, then closes the transport
+func (s *EmailService) Close() {
+	q := s.queue
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	q.wg.Wait()
+
+	if closer, ok := s.transport.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// work delivers queued emails until the queue is closed
+func (q *queue) work() {
+	defer q.wg.Done()
+
+	for j := range q.jobs {
+		q.deliver(j)
+	}
+}
+
+// deliver sends one email, retrying with backoff on transient failures. Emails that
+// still fail are written to the dead-letter log.
+func (q *queue) deliver(j job) {
+	for attempt := 0; ; attempt++ {
+		err := q.transport.Send(j.to, j.subject, j.htmlBody, j.attachments...)
+		if err == nil {
+			q.sent.Add(1)
+			return
+		}
+
+		if !isTransient(err) || attempt >= q.maxRetries {
+			q.failed.Add(1)
+			log.Printf("Email dead letter: to=%s subject=%q ref=%s attempts=%d: %v", j.to, j.subject, j.ref, attempt+1, err)
+			return
+		}
+
+		q.retried.Add(1)
+		delay := retryBaseDelay << attempt
+		log.Printf("Email to %s failed (ref=%s, attempt %d), retrying in %s: %v", j.to, j.ref, attempt+1, delay, err)
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,43 @@
+package email
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowTransport records sent emails, taking a moment over each
+type slowTransport struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (t *slowTransport) Send(to, subject, htmlBody string, attachments ...Attachment) error {
+	time.Sleep(time.Millisecond)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sent = append(t.sent, to)
+	return nil
+}
+
+func TestCloseDeliversQueuedEmails(t *testing.T) {
+	transport := &slowTransport{}
+	s := &EmailService{transport: transport}
+	s.queue = newQueue(transport, 2, 50, 0)
+
+	for i := 0; i < 20; i++ {
+		if err := s.enqueue(job{to: "user@example.com", subject: "Reset your password"}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	s.Close()
+
+	if len(transport.sent) != 20 {
+		t.Errorf("delivered %d emails before Close returned, want 20", len(transport.sent))
+	}
+	if err := s.enqueue(job{to: "user@example.com"}); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("enqueue after Close = %v, want ErrQueueClosed", err)
+	}
+}
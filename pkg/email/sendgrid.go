@@ -0,0 +1,100 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// sendGridTransport sends email through the SendGrid v3 mail send API
+type sendGridTransport struct {
+	apiKey    string
+	fromName  string
+	fromEmail string
+	client    *http.Client
+}
+
+func newSendGridTransport(config EmailConfig) *sendGridTransport {
+	return &sendGridTransport{
+		apiKey:    config.SendGridAPIKey,
+		fromName:  config.FromName,
+		fromEmail: config.FromEmail,
+		client:    &http.Client{Timeout: sendTimeout},
+	}
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridAttachment struct {
+	Content     []byte `json:"content"` // Base64 encoded by encoding/json
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+// Send posts one email to the SendGrid API
+func (t *sendGridTransport) Send(to, subject, htmlBody string, attachments ...Attachment) error {
+	msg := sendGridMessage{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: to}}}},
+		From:             sendGridAddress{Email: t.fromEmail, Name: t.fromName},
+		Subject:          subject,
+		Content:          []sendGridContent{{Type: "text/html", Value: htmlBody}},
+	}
+	for _, a := range attachments {
+		msg.Attachments = append(msg.Attachments, sendGridAttachment{
+			Content:     a.Data,
+			Type:        a.contentType(),
+			Filename:    a.Filename,
+			Disposition: "attachment",
+		})
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode SendGrid request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sendGridURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach SendGrid: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &apiError{provider: "SendGrid", status: resp.StatusCode, body: string(respBody)}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package email
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// sesTransport sends email through the Amazon SES v2 SendEmail API. Requests are
+// signed with AWS Signature Version 4.
+type sesTransport struct {
+	config EmailConfig
+	host   string
+	client *http.Client
+}
+
+func newSESTransport(config EmailConfig) *sesTransport {
+	return &sesTransport{
+		config: config,
+		host:   fmt.Sprintf("email.%s.amazonaws.com", config.SESRegion),
+		client: &http.Client{Timeout: sendTimeout},
+	}
+}
+
+const sesSendPath = "/v2/email/outbound-emails"
+
+type sesRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Raw struct {
+			Data []byte `json:"Data"` // Base64 encoded by encoding/json
+		} `json:"Raw"`
+	} `json:"Content"`
+}
+
+// Send posts one email to SES as a raw MIME message so attachments are kept
+func (t *sesTransport) Send(to, subject, htmlBody string, attachments ...Attachment) error {
+	message, err := buildMessage(t.config.FromName, t.config.FromEmail, to, subject, htmlBody, attachments)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	var payload sesRequest
+	payload.FromEmailAddress = t.config.FromEmail
+	payload.Destination.ToAddresses = []string{to}
+	payload.Content.Raw.Data = message
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode SES request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://"+t.host+sesSendPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	t.sign(req, body, time.Now().UTC())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach SES: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &apiError{provider: "SES", status: resp.StatusCode, body: string(respBody)}
+	}
+
+	return nil
+}
+
+// sign adds the AWS Signature Version 4 headers for a request with the given body
+func (t *sesTransport) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + t.config.SESRegion + "/ses/aws4_request"
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Host", t.host)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	const signedHeaders = "content-type;host;x-amz-date"
+	canonicalRequest := req.Method + "\n" +
+		sesSendPath + "\n" +
+		"\n" + // No query string
+		"content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + t.host + "\n" +
+		"x-amz-date:" + amzDate + "\n" +
+		"\n" +
+		signedHeaders + "\n" +
+		payloadHash
+
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+t.config.SESSecretAccessKey), date)
+	key = hmacSHA256(key, t.config.SESRegion)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.config.SESAccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
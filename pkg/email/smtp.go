@@ -0,0 +1,246 @@
+package email
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"sync"
+	"time"
+)
+
+const (
+	dialTimeout = 30 * time.Second // Connecting to the SMTP server
+	sendTimeout = 60 * time.Second // One SMTP conversation: connect and auth, or one message
+	idleTimeout = 30 * time.Second // Connections unused for longer are closed
+
+	implicitTLSPort = 465 // SMTPS: TLS from the first byte instead of STARTTLS
+)
+
+// ErrSTARTTLSUnavailable is returned when the server does not offer STARTTLS and
+// TLS has not been disabled; sending would otherwise expose the credentials
+var ErrSTARTTLSUnavailable = errors.New("SMTP server does not support STARTTLS; use port 465 for implicit TLS or set SMTP_DISABLE_TLS for local servers")
+
+// smtpTransport sends email over SMTP with PLAIN auth. Port 465 uses implicit TLS,
+// other ports upgrade with STARTTLS unless TLS is disabled for local development
+// servers such as MailHog or Mailpit. Connections
+// are kept open between emails instead of dialling, negotiating TLS and
+// authenticating for every message.
+type smtpTransport struct {
+	config EmailConfig
+	idle   chan *smtpConn
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newSMTPTransport(config EmailConfig) *smtpTransport {
+	size := config.Workers
+	if size <= 0 {
+		size = defaultWorkers
+	}
+
+	t := &smtpTransport{config: config, idle: make(chan *smtpConn, size), stop: make(chan struct{})}
+	go t.closeIdle()
+	return t
+}
+
+// Send delivers one email, reusing an open connection when one is available
+func (t *smtpTransport) Send(to, subject, htmlBody string, attachments ...Attachment) error {
+	message, err := buildMessage(t.config.FromName, t.config.FromEmail, to, subject, htmlBody, attachments)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	conn, err := t.get()
+	if err != nil {
+		return err
+	}
+
+	if err := conn.send(t.config.FromEmail, to, message); err != nil {
+		conn.close()
+		return err
+	}
+
+	t.put(conn)
+	return nil
+}
+
+// Close closes the idle connections
+func (t *smtpTransport) Close() error {
+	t.stopOnce.Do(func() { close(t.stop) })
+	for {
+		select {
+		case conn := <-t.idle:
+			conn.close()
+		default:
+			return nil
+		}
+	}
+}
+
+// get returns an open connection, dialling when none is idle. The server may have
+// dropped a connection that sat idle, so those are checked first.
+func (t *smtpTransport) get() (*smtpConn, error) {
+	for {
+		select {
+		case conn := <-t.idle:
+			if time.Since(conn.lastUsed) < idleTimeout && conn.alive() {
+				return conn, nil
+			}
+			conn.close()
+		default:
+			return t.dial()
+		}
+	}
+}
+
+// put keeps a connection for the next email, or closes it if enough are idle
+func (t *smtpTransport) put(conn *smtpConn) {
+	conn.lastUsed = time.Now()
+	select {
+	case t.idle <- conn:
+	default:
+		conn.close()
+	}
+}
+
+// closeIdle periodically closes connections that have been idle for idleTimeout
+func (t *smtpTransport) closeIdle() {
+	ticker := time.NewTicker(idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			for n := len(t.idle); n > 0; n-- {
+				select {
+				case conn := <-t.idle:
+					if time.Since(conn.lastUsed) >= idleTimeout {
+						conn.close()
+						continue
+					}
+					select {
+					case t.idle <- conn:
+					default:
+						conn.close()
+					}
+				default:
+				}
+			}
+		}
+	}
+}
+
+// smtpConn is an authenticated SMTP session
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// dial connects to the SMTP server, secures the connection and authenticates
+func (t *smtpTransport) dial() (*smtpConn, error) {
+	addr := net.JoinHostPort(t.config.SMTPHost, fmt.Sprintf("%d", t.config.SMTPPort))
+	tlsConfig := &tls.Config{ServerName: t.config.SMTPHost}
+	implicitTLS := t.config.SMTPPort == implicitTLSPort && !t.config.SMTPDisableTLS
+
+	// Dial with a timeout to prevent indefinite blocking
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	if implicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	// Bound the handshake so a stalled server cannot hang the worker
+	if err := conn.SetDeadline(time.Now().Add(sendTimeout)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set connection deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, t.config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+	c := &smtpConn{conn: conn, client: client}
+
+	// Upgrade with STARTTLS (e.g. Gmail on port 587)
+	if !implicitTLS && !t.config.SMTPDisableTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			c.close()
+			return nil, ErrSTARTTLSUnavailable
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			c.close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	// Local development servers usually accept mail without credentials
+	if t.config.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", t.config.SMTPUsername, t.config.SMTPPassword, t.config.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			c.close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// alive checks that the server still answers on the connection
+func (c *smtpConn) alive() bool {
+	if err := c.conn.SetDeadline(time.Now().Add(sendTimeout)); err != nil {
+		return false
+	}
+	return c.client.Noop() == nil
+}
+
+// send delivers one message over the session
+func (c *smtpConn) send(from, to string, message []byte) error {
+	if err := c.conn.SetDeadline(time.Now().Add(sendTimeout)); err != nil {
+		return fmt.Errorf("failed to set connection deadline: %w", err)
+	}
+
+	if err := c.client.Mail(from); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+
+	if err := c.client.Rcpt(to); err != nil {
+		return fmt.Errorf("failed to set recipient: %w", err)
+	}
+
+	wc, err := c.client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start email data: %w", err)
+	}
+
+	if _, err := wc.Write(message); err != nil {
+		wc.Close()
+		return fmt.Errorf("failed to write email body: %w", err)
+	}
+
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("failed to close email data: %w", err)
+	}
+
+	return nil
+}
+
+// close ends the session
+func (c *smtpConn) close() {
+	// Quit errors don't matter; the connection is closed either way
+	c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	c.client.Quit()
+	c.client.Close()
+}
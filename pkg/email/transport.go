@@ -0,0 +1,73 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"strings"
+)
+
+// EmailTransport delivers one rendered email. Send makes a single attempt; the
+// queue retries transient failures.
+type EmailTransport interface {
+	Send(to, subject, htmlBody string, attachments ...Attachment) error
+}
+
+// Supported values of EmailConfig.Transport
+const (
+	TransportSMTP     = "smtp"
+	TransportSendGrid = "sendgrid"
+	TransportSES      = "ses"
+)
+
+// NewTransport creates the transport selected by config.Transport, SMTP when empty
+func NewTransport(config EmailConfig) (EmailTransport, error) {
+	switch strings.ToLower(config.Transport) {
+	case "", TransportSMTP:
+		return newSMTPTransport(config), nil
+	case TransportSendGrid:
+		if config.SendGridAPIKey == "" {
+			return nil, errors.New("email transport sendgrid requires SENDGRID_API_KEY")
+		}
+		return newSendGridTransport(config), nil
+	case TransportSES:
+		if config.SESRegion == "" || config.SESAccessKeyID == "" || config.SESSecretAccessKey == "" {
+			return nil, errors.New("email transport ses requires AWS_SES_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		return newSESTransport(config), nil
+	default:
+		return nil, fmt.Errorf("unknown email transport %q (use smtp, sendgrid or ses)", config.Transport)
+	}
+}
+
+// apiError is a non-success response from an HTTP email API
+type apiError struct {
+	provider string
+	status   int
+	body     string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s API returned %d: %s", e.provider, e.status, e.body)
+}
+
+// isTransient reports whether a failed delivery is worth retrying. SMTP 4xx replies,
+// API rate limiting and server errors, and network errors are; SMTP 5xx replies
+// (bad recipient, failed auth) and other API client errors are not.
+func isTransient(err error) bool {
+	if errors.Is(err, ErrSTARTTLSUnavailable) {
+		return false
+	}
+
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.status == 429 || apiErr.status >= 500
+	}
+
+	return true
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+type ctxKey string
+
+// requestIDKey is the context key for the request ID
+const requestIDKey ctxKey = "request_id"
+
+// Init configures the process-wide structured JSON logger.
+// The standard library "log" package is routed through it as well,
+// so existing log.Printf calls are emitted as JSON records.
+func Init(debug bool) {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(handler))
+}
+
+// WithRequestID adds a request ID to the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID extracts the request ID from the context, or "" if absent
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// FromContext returns a logger annotated with the request ID carried by ctx (if any)
+func FromContext(ctx context.Context) *slog.Logger {
+	if requestID := RequestID(ctx); requestID != "" {
+		return slog.Default().With("request_id", requestID)
+	}
+	return slog.Default()
+}
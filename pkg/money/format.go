@@ -0,0 +1,117 @@
+package money
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when a tenant has no currency configured
+const DefaultCurrency = "KES"
+
+// currencySymbols maps ISO 4217 codes to their display symbols.
+// Codes not listed here are displayed as the code itself.
+var currencySymbols = map[string]string{
+	"KES": "KSh",
+	"UGX": "USh",
+	"TZS": "TSh",
+	"RWF": "FRw",
+	"NGN": "₦",
+	"GHS": "GH₵",
+	"ZAR": "R",
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"INR": "₹",
+}
+
+// separators holds the decimal and thousands separators for a locale
+type separators struct {
+	decimal   string
+	thousands string
+}
+
+// languageSeparators maps a locale's language subtag to its separators.
+// Languages not listed use "." for decimals and "," for thousands.
+var languageSeparators = map[string]separators{
+	"de": {decimal: ",", thousands: "."},
+	"es": {decimal: ",", thousands: "."},
+	"it": {decimal: ",", thousands: "."},
+	"nl": {decimal: ",", thousands: "."},
+	"pt": {decimal: ",", thousands: "."},
+	"id": {decimal: ",", thousands: "."},
+	"tr": {decimal: ",", thousands: "."},
+	"fr": {decimal: ",", thousands: " "},
+	"ru": {decimal: ",", thousands: " "},
+	"sv": {decimal: ",", thousands: " "},
+}
+
+// Formatter renders cent amounts for a currency and locale
+type Formatter struct {
+	Currency string
+	Symbol   string
+	sep      separators
+}
+
+// NewFormatter creates a formatter for an ISO 4217 currency code and a locale
+// such as "en-KE" or "fr_FR". Empty values fall back to KES and English separators.
+func NewFormatter(currency, locale string) Formatter {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		symbol = currency
+	}
+
+	lang := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	sep, ok := languageSeparators[lang]
+	if !ok {
+		sep = separators{decimal: ".", thousands: ","}
+	}
+
+	return Formatter{Currency: currency, Symbol: symbol, sep: sep}
+}
+
+// Number renders cents as a plain amount with locale separators, e.g. "1,234.50"
+func (f Formatter) Number(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	whole := strconv.FormatInt(cents/100, 10)
+	frac := cents % 100
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.sep.thousands)
+		}
+		b.WriteRune(digit)
+	}
+	b.WriteString(f.sep.decimal)
+	if frac < 10 {
+		b.WriteByte('0')
+	}
+	b.WriteString(strconv.FormatInt(frac, 10))
+
+	return b.String()
+}
+
+// Format renders cents with the currency symbol, e.g. "KSh 1,234.50"
+func (f Formatter) Format(cents int64) string {
+	return f.Symbol + " " + f.Number(cents)
+}
+
+// FormatCode renders cents with the ISO currency code, e.g. "KES 1,234.50".
+// Use it for output that must stay ASCII, such as ESC/POS receipts.
+func (f Formatter) FormatCode(cents int64) string {
+	return f.Currency + " " + f.Number(cents)
+}
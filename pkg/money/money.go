@@ -0,0 +1,34 @@
+package money
+
+import "math"
+
+// ToCents converts a decimal amount to integer cents, rounding to the nearest cent.
+// Plain truncation (int64(x * 100)) is wrong for values like 19.99, whose float
+// product is 1998.9999... and would truncate to 1998.
+func ToCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// FromCents converts integer cents to a decimal amount
+func FromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// ApplyRate multiplies an amount in cents by a rate (e.g. 0.16 for 16%) and rounds to the nearest cent
+func ApplyRate(cents int64, rate float64) int64 {
+	return int64(math.Round(float64(cents) * rate))
+}
+
+// RoundTo rounds an amount in cents to the nearest multiple of increment cents, halves
+// away from zero. An increment of one cent or less leaves the amount unchanged.
+func RoundTo(cents, increment int64) int64 {
+	if increment <= 1 {
+		return cents
+	}
+	return int64(math.Round(float64(cents)/float64(increment))) * increment
+}
+
+// Percent returns percentage% of an amount in cents, rounded to the nearest cent
+func Percent(cents int64, percentage float64) int64 {
+	return ApplyRate(cents, percentage/100)
+}
@@ -0,0 +1,30 @@
+package money
+
+import "testing"
+
+func TestToCents(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   int64
+	}{
+		{0, 0},
+		{0.07, 7},
+		{19.99, 1999},
+		{29.95, 2995},
+		{-19.99, -1999},
+	}
+
+	for _, tt := range tests {
+		if got := ToCents(tt.amount); got != tt.want {
+			t.Errorf("ToCents(%v) = %d, want %d", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestFromCentsRoundTrip(t *testing.T) {
+	for _, cents := range []int64{0, 7, 1999, 2995, 100000, -1999} {
+		if got := ToCents(FromCents(cents)); got != cents {
+			t.Errorf("ToCents(FromCents(%d)) = %d", cents, got)
+		}
+	}
+}
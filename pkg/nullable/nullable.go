@@ -0,0 +1,43 @@
+package nullable
+
+import "encoding/json"
+
+// Field is an optional JSON field that distinguishes an absent key from an explicit null.
+//
+//	{}               -> Set=false             (leave unchanged)
+//	{"notes": null}  -> Set=true,  Null=true  (clear)
+//	{"notes": "x"}   -> Set=true,  Null=false (set to Value)
+type Field[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON records that the key was present and whether it was null.
+// encoding/json only calls it for keys present in the payload, null included.
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+// MarshalJSON renders the field as null or its value
+func (f Field[T]) MarshalJSON() ([]byte, error) {
+	if !f.Set || f.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f.Value)
+}
+
+// Ptr returns nil for an explicit null, otherwise a pointer to the value.
+// Only meaningful when Set is true.
+func (f Field[T]) Ptr() *T {
+	if f.Null {
+		return nil
+	}
+	v := f.Value
+	return &v
+}
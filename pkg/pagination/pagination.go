@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -12,20 +14,80 @@ import (
 // Page-Based Pagination (Offset Pagination)
 // =============================================================================
 
+const (
+	// DefaultMaxPageSize is the page size ceiling used until SetMaxPageSize is called
+	DefaultMaxPageSize = 100
+
+	// HardMaxPageSize is the absolute ceiling; no configuration can raise the limit past it
+	HardMaxPageSize = 1000
+)
+
+var maxPageSize = DefaultMaxPageSize
+
+// SetMaxPageSize sets the page size ceiling applied by Validate.
+// It is meant to be called once at startup. Non-positive values restore the
+// default and values above HardMaxPageSize are clamped to it.
+func SetMaxPageSize(size int) {
+	switch {
+	case size < 1:
+		size = DefaultMaxPageSize
+	case size > HardMaxPageSize:
+		size = HardMaxPageSize
+	}
+	maxPageSize = size
+}
+
+// MaxPageSize returns the page size ceiling currently applied by Validate
+func MaxPageSize() int {
+	return maxPageSize
+}
+
+// CountMode selects how a page-based list computes its total
+type CountMode string
+
+const (
+	CountExact    CountMode = "exact"    // COUNT(*) over the filtered rows (default)
+	CountEstimate CountMode = "estimate" // Planner row estimate; cheap but approximate
+	CountNone     CountMode = "none"     // No total; has_next comes from fetching one extra row
+)
+
+// ParseCountMode parses a ?count= value; anything unrecognised is CountExact
+func ParseCountMode(s string) CountMode {
+	switch mode := CountMode(s); mode {
+	case CountEstimate, CountNone:
+		return mode
+	default:
+		return CountExact
+	}
+}
+
 // Pagination represents pagination parameters
 type Pagination struct {
-	CurrentPage int   `json:"current_page"`
-	PerPage     int   `json:"per_page"`
-	Total       int64 `json:"total"`
-	TotalPages  int   `json:"total_pages"`
-	HasNext     bool  `json:"has_next"`
-	HasPrev     bool  `json:"has_prev"`
+	CurrentPage int       `json:"current_page"`
+	PerPage     int       `json:"per_page"`
+	Total       int64     `json:"total"`
+	TotalPages  int       `json:"total_pages"`
+	HasNext     bool      `json:"has_next"`
+	HasPrev     bool      `json:"has_prev"`
+	Count       CountMode `json:"count,omitempty"` // Set when total is estimated or omitted
+	Links       *Links    `json:"links,omitempty"`
+}
+
+// Links holds URLs of the pages around the current one. prev and next are omitted at
+// either end, and last when the total isn't counted exactly.
+type Links struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
 }
 
 // PaginationParams represents input parameters for pagination
 type PaginationParams struct {
-	Page    int `form:"page" json:"page"`
-	PerPage int `form:"per_page" json:"per_page"`
+	Page    int       `form:"page" json:"page"`
+	PerPage int       `form:"per_page" json:"per_page"`
+	Count   CountMode `form:"count" json:"count"` // Empty means CountExact
 }
 
 // DefaultPagination returns default pagination values
@@ -44,9 +106,10 @@ func (p *PaginationParams) Validate() {
 	if p.PerPage < 1 {
 		p.PerPage = 15
 	}
-	if p.PerPage > 100 {
-		p.PerPage = 100
+	if p.PerPage > maxPageSize {
+		p.PerPage = maxPageSize
 	}
+	p.Count = ParseCountMode(string(p.Count))
 }
 
 // Offset calculates the offset for SQL queries
@@ -54,9 +117,23 @@ func (p *PaginationParams) Offset() int {
 	return (p.Page - 1) * p.PerPage
 }
 
-// NewPagination creates a new Pagination response
+// NewPagination creates a new Pagination response.
+//
+// total_pages is always at least 1: an empty result is reported as a single
+// empty page (current_page 1 of 1) rather than "page 1 of 0". A non-positive
+// perPage is treated as the default page size to avoid dividing by zero.
 func NewPagination(page, perPage int, total int64) *Pagination {
+	if perPage < 1 {
+		perPage = DefaultPagination().PerPage
+	}
+	if page < 1 {
+		page = 1
+	}
+
 	totalPages := int(math.Ceil(float64(total) / float64(perPage)))
+	if totalPages < 1 {
+		totalPages = 1
+	}
 
 	return &Pagination{
 		CurrentPage: page,
@@ -68,6 +145,47 @@ func NewPagination(page, perPage int, total int64) *Pagination {
 	}
 }
 
+// NewPaginationForParams creates a Pagination response that honours params.Count.
+//
+// With CountNone the repository reports total as a lower bound (rows before the page
+// plus the rows fetched, including one look-ahead row), which is enough to derive
+// has_next; total and total_pages are then reported as 0.
+func NewPaginationForParams(params *PaginationParams, total int64) *Pagination {
+	p := NewPagination(params.Page, params.PerPage, total)
+	switch params.Count {
+	case CountEstimate:
+		p.Count = CountEstimate
+	case CountNone:
+		p.Count = CountNone
+		p.Total = 0
+		p.TotalPages = 0
+	}
+	return p
+}
+
+// NewLinks builds the page links of p from the URL of the request that listed it,
+// keeping its other query parameters. The links are relative to the host.
+func NewLinks(u *url.URL, p *Pagination) *Links {
+	page := func(n int) string {
+		q := u.Query()
+		q.Set("page", strconv.Itoa(n))
+		q.Set("per_page", strconv.Itoa(p.PerPage))
+		return (&url.URL{Path: u.Path, RawQuery: q.Encode()}).String()
+	}
+
+	links := &Links{Self: page(p.CurrentPage), First: page(1)}
+	if p.HasPrev {
+		links.Prev = page(p.CurrentPage - 1)
+	}
+	if p.HasNext {
+		links.Next = page(p.CurrentPage + 1)
+	}
+	if p.Count == "" || p.Count == CountExact {
+		links.Last = page(p.TotalPages)
+	}
+	return links
+}
+
 // PaginatedResult represents a paginated result with items and pagination info
 type PaginatedResult[T any] struct {
 	Items      []T         `json:"items"`
@@ -136,8 +254,8 @@ func (c *CursorParams) Validate() {
 	if c.Limit < 1 {
 		c.Limit = 15
 	}
-	if c.Limit > 100 {
-		c.Limit = 100
+	if c.Limit > maxPageSize {
+		c.Limit = maxPageSize
 	}
 	if c.Direction == "" {
 		c.Direction = CursorDirectionNext
@@ -175,20 +293,27 @@ func EncodeCursor(id string, createdAt ...time.Time) string {
 }
 
 // NewCursorPagination creates a new CursorPagination response
-// items should be the fetched items (with limit+1 to detect hasMore)
-// limit is the requested limit
-func NewCursorPagination[T any](items []T, limit int, getID func(T) string, getCreatedAt func(T) time.Time) (*CursorPagination, []T) {
+// items should be the fetched items in ascending order (with limit+1 to detect hasMore).
+// For prev pages the extra item sits at the front, so it is trimmed from there.
+func NewCursorPagination[T any](items []T, params *CursorParams, getID func(T) string, getCreatedAt func(T) time.Time) (*CursorPagination, []T) {
+	limit := params.Limit
 	hasMore := len(items) > limit
-
-	// Trim to the requested limit if we fetched extra
-	if hasMore {
-		items = items[:limit]
-	}
-
-	pagination := &CursorPagination{
-		Limit:   limit,
-		HasNext: hasMore,
-		HasPrev: false, // Will be determined by the caller based on cursor presence
+	hasCursor := params.Cursor != ""
+
+	pagination := &CursorPagination{Limit: limit}
+
+	if params.Direction == CursorDirectionPrev {
+		if hasMore {
+			items = items[len(items)-limit:]
+		}
+		pagination.HasPrev = hasMore
+		pagination.HasNext = hasCursor
+	} else {
+		if hasMore {
+			items = items[:limit]
+		}
+		pagination.HasNext = hasMore
+		pagination.HasPrev = hasCursor
 	}
 
 	if len(items) > 0 {
@@ -0,0 +1,34 @@
+package pagination
+
+import "testing"
+
+func TestNewPagination(t *testing.T) {
+	tests := []struct {
+		name               string
+		page, perPage      int
+		total              int64
+		wantPerPage        int
+		wantTotalPages     int
+		wantNext, wantPrev bool
+	}{
+		{name: "empty result is one empty page", page: 1, perPage: 15, total: 0, wantPerPage: 15, wantTotalPages: 1},
+		{name: "single item", page: 1, perPage: 15, total: 1, wantPerPage: 15, wantTotalPages: 1},
+		{name: "zero per page uses default", page: 1, perPage: 0, total: 20, wantPerPage: 15, wantTotalPages: 2, wantNext: true},
+		{name: "last page", page: 2, perPage: 10, total: 20, wantPerPage: 10, wantTotalPages: 2, wantPrev: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPagination(tt.page, tt.perPage, tt.total)
+			if p.PerPage != tt.wantPerPage {
+				t.Errorf("PerPage = %d, want %d", p.PerPage, tt.wantPerPage)
+			}
+			if p.TotalPages != tt.wantTotalPages {
+				t.Errorf("TotalPages = %d, want %d", p.TotalPages, tt.wantTotalPages)
+			}
+			if p.HasNext != tt.wantNext || p.HasPrev != tt.wantPrev {
+				t.Errorf("HasNext, HasPrev = %v, %v, want %v, %v", p.HasNext, p.HasPrev, tt.wantNext, tt.wantPrev)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Package pdf writes simple text-and-line PDF documents using the built-in
+// Helvetica fonts, so no font files or external libraries are needed.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and default margin in points (1/72 inch)
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+	Margin     = 40.0
+)
+
+// Text alignment relative to the x position
+const (
+	AlignLeft = iota
+	AlignCenter
+	AlignRight
+)
+
+// Document builds a multi-page A4 PDF. Coordinates are in points with the origin
+// at the top-left corner of the page.
+type Document struct {
+	pages []*bytes.Buffer
+	bold  bool
+	size  float64
+}
+
+// NewDocument creates a document with one empty page and 10pt regular text
+func NewDocument() *Document {
+	d := &Document{size: 10}
+	d.AddPage()
+	return d
+}
+
+// AddPage starts a new page; subsequent drawing goes to it
+func (d *Document) AddPage() *Document {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	return d
+}
+
+// PageCount returns the number of pages
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// SetFont sets the font weight and size in points for subsequent text
+func (d *Document) SetFont(bold bool, size float64) *Document {
+	d.bold = bold
+	d.size = size
+	return d
+}
+
+// Text draws s with its baseline at y. The alignment decides whether x is the
+// left edge, the center or the right edge of the text. Characters outside
+// printable ASCII are replaced with '?'.
+func (d *Document) Text(x, y float64, align int, s string) *Document {
+	s = sanitize(s)
+	switch align {
+	case AlignCenter:
+		x -= d.TextWidth(s) / 2
+	case AlignRight:
+		x -= d.TextWidth(s)
+	}
+
+	font := "F1"
+	if d.bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, d.size, x, PageHeight-y, escape(s))
+	return d
+}
+
+// Line draws a 0.5pt line between two points
+func (d *Document) Line(x1, y1, x2, y2 float64) *Document {
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+	return d
+}
+
+// TextWidth returns the width of s in points for the current font
+func (d *Document) TextWidth(s string) float64 {
+	widths := helveticaWidths
+	if d.bold {
+		widths = helveticaBoldWidths
+	}
+
+	total := 0
+	for _, r := range sanitize(s) {
+		total += widths[r-' ']
+	}
+	return float64(total) * d.size / 1000
+}
+
+// Bytes renders the document as a PDF file
+func (d *Document) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+
+	// Objects are numbered: 1 catalog, 2 page tree, 3-4 fonts, then a page and its
+	// content stream for every page
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+func (d *Document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// sanitize replaces characters the standard fonts cannot show with '?'
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+// escape escapes the characters that delimit PDF string literals
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
+
+// Glyph widths for printable ASCII (' ' to '~') in 1/1000 em, from the Adobe AFM files
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
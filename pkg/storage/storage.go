@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Storage keeps uploaded files and serves them at public URLs.
+type Storage interface {
+	// Save stores the content under key (a slash-separated path such as
+	// "profile-photos/<id>/<name>.png") and returns the file's public URL.
+	Save(ctx context.Context, key string, content io.Reader) (string, error)
+	// Delete removes the file at a URL returned by Save. URLs this storage did not
+	// issue (e.g. external photo links) are ignored.
+	Delete(ctx context.Context, url string) error
+}
+
+// --- Local disk storage (files served by the API under the public URL) ---
+
+type localStorage struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStorage creates a storage that writes files under root and serves them at
+// baseURL, e.g. "http://localhost:8080/uploads".
+func NewLocalStorage(root, baseURL string) Storage {
+	return &localStorage{root: root, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (s *localStorage) Save(ctx context.Context, key string, content io.Reader) (string, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return "", fmt.Errorf("storage: failed to create directory: %w", err)
+	}
+
+	// Write to a temporary file first so a failed upload never leaves a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("storage: failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("storage: failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("storage: failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return "", fmt.Errorf("storage: failed to store %s: %w", key, err)
+	}
+
+	return s.baseURL + "/" + key, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, url string) error {
+	key, ok := strings.CutPrefix(url, s.baseURL+"/")
+	if !ok {
+		return nil
+	}
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// path maps a key to a file under the root, rejecting keys that would escape it
+func (s *localStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
@@ -2,19 +2,33 @@ package utils
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// Token types carried in the typ claim, so that one kind of token can't be used as another
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 // JWTClaims represents the claims in a JWT token
 type JWTClaims struct {
+	// TokenType is TokenTypeAccess for access, step-up and impersonation tokens and
+	// TokenTypeRefresh for refresh tokens
+	TokenType   string    `json:"typ"`
 	UserID      uuid.UUID `json:"user_id"`
 	TenantID    uuid.UUID `json:"tenant_id"`
 	Email       string    `json:"email"`
 	Roles       []string  `json:"roles"`
 	Permissions []string  `json:"permissions"`
+	// ImpersonatedBy is the super-admin acting as this user, set only on impersonation tokens
+	ImpersonatedBy *uuid.UUID `json:"impersonated_by,omitempty"`
+	// StepUp marks a short-lived token issued after the user re-entered their password
+	StepUp bool `json:"step_up,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -23,20 +37,34 @@ type JWTManager struct {
 	secretKey          []byte
 	accessTokenExpiry  time.Duration
 	refreshTokenExpiry time.Duration
+	stepUpExpiry       time.Duration
+
+	// Revoked token IDs until they expire. Kept in memory, so revocation only
+	// applies to the instance that revoked the token.
+	revoked   map[string]time.Time
+	revokedMu sync.RWMutex
+
+	// Users whose tokens issued before the recorded time are rejected, e.g. after
+	// the account was deactivated. Also kept in memory only.
+	revokedUsers map[uuid.UUID]time.Time
 }
 
 // NewJWTManager creates a new JWT manager
-func NewJWTManager(secret string, accessExpiry, refreshExpiry time.Duration) *JWTManager {
+func NewJWTManager(secret string, accessExpiry, refreshExpiry, stepUpExpiry time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey:          []byte(secret),
 		accessTokenExpiry:  accessExpiry,
 		refreshTokenExpiry: refreshExpiry,
+		stepUpExpiry:       stepUpExpiry,
+		revoked:            make(map[string]time.Time),
+		revokedUsers:       make(map[uuid.UUID]time.Time),
 	}
 }
 
 // GenerateAccessToken generates a new access token with tenant context
 func (m *JWTManager) GenerateAccessToken(userID, tenantID uuid.UUID, email string, roles, permissions []string) (string, error) {
 	claims := &JWTClaims{
+		TokenType:   TokenTypeAccess,
 		UserID:      userID,
 		TenantID:    tenantID,
 		Email:       email,
@@ -55,44 +83,174 @@ func (m *JWTManager) GenerateAccessToken(userID, tenantID uuid.UUID, email strin
 	return token.SignedString(m.secretKey)
 }
 
-// GenerateRefreshToken generates a new refresh token
+// GenerateStepUpToken generates a short-lived access token marked as step-up, for
+// actions that require the user to have re-authenticated recently
+func (m *JWTManager) GenerateStepUpToken(userID, tenantID uuid.UUID, email string, roles, permissions []string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.stepUpExpiry)
+	claims := &JWTClaims{
+		TokenType:   TokenTypeAccess,
+		UserID:      userID,
+		TenantID:    tenantID,
+		Email:       email,
+		Roles:       roles,
+		Permissions: permissions,
+		StepUp:      true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "investify-api",
+			Subject:   userID.String(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(m.secretKey)
+	return signed, expiresAt, err
+}
+
+// GenerateImpersonationToken generates a short-lived access token that lets a super-admin
+// act as another user. It carries the impersonator and a token ID so it can be revoked.
+func (m *JWTManager) GenerateImpersonationToken(userID, tenantID uuid.UUID, email string, roles, permissions []string, impersonatorID uuid.UUID, expiry time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(expiry)
+	claims := &JWTClaims{
+		TokenType:      TokenTypeAccess,
+		UserID:         userID,
+		TenantID:       tenantID,
+		Email:          email,
+		Roles:          roles,
+		Permissions:    permissions,
+		ImpersonatedBy: &impersonatorID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "investify-api",
+			Subject:   userID.String(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(m.secretKey)
+	return signed, expiresAt, err
+}
+
+// RevokeAccessToken rejects the token from now until it expires. Tokens without an
+// ID (regular access tokens) cannot be revoked.
+func (m *JWTManager) RevokeAccessToken(claims *JWTClaims) {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return
+	}
+
+	m.revokedMu.Lock()
+	defer m.revokedMu.Unlock()
+
+	// Forget revocations of tokens that have expired anyway
+	now := time.Now()
+	for id, expiresAt := range m.revoked {
+		if now.After(expiresAt) {
+			delete(m.revoked, id)
+		}
+	}
+	m.revoked[claims.ID] = claims.ExpiresAt.Time
+}
+
+// RevokeUserTokens rejects every access and refresh token issued to the user up to
+// now, including regular access tokens that carry no ID
+func (m *JWTManager) RevokeUserTokens(userID uuid.UUID) {
+	m.revokedMu.Lock()
+	defer m.revokedMu.Unlock()
+
+	// Tokens are compared at the second precision of their issued-at claim; a token
+	// issued later in this second is still accepted
+	now := time.Now()
+	longest := m.accessTokenExpiry
+	if m.refreshTokenExpiry > longest {
+		longest = m.refreshTokenExpiry
+	}
+	for id, revokedAt := range m.revokedUsers {
+		if now.Sub(revokedAt) > longest {
+			delete(m.revokedUsers, id)
+		}
+	}
+	m.revokedUsers[userID] = now.Truncate(time.Second)
+}
+
+// userTokenRevoked reports whether a token issued to the user at issuedAt was revoked
+// with RevokeUserTokens
+func (m *JWTManager) userTokenRevoked(userID uuid.UUID, issuedAt *jwt.NumericDate) bool {
+	m.revokedMu.RLock()
+	revokedAt, ok := m.revokedUsers[userID]
+	m.revokedMu.RUnlock()
+	return ok && (issuedAt == nil || issuedAt.Time.Before(revokedAt))
+}
+
+// GenerateRefreshToken generates a new refresh token. It carries a token ID so it can
+// be revoked with RevokeAccessToken like any other token.
 func (m *JWTManager) GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	claims := &jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.refreshTokenExpiry)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		NotBefore: jwt.NewNumericDate(time.Now()),
-		Issuer:    "investify-api",
-		Subject:   userID.String(),
+	claims := &JWTClaims{
+		TokenType: TokenTypeRefresh,
+		UserID:    userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.refreshTokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "investify-api",
+			Subject:   userID.String(),
+		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(m.secretKey)
 }
 
-// ValidateAccessToken validates an access token and returns the claims
+// ValidateAccessToken validates an access token and returns the claims. Refresh tokens
+// are rejected.
 func (m *JWTManager) ValidateAccessToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return m.secretKey, nil
-	})
-
+	claims, err := m.parseToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(*JWTClaims)
-	if !ok || !token.Valid {
-		return nil, errors.New("invalid token")
+	// Access tokens issued before the typ claim existed carry none; refresh tokens from
+	// then are told apart by their missing user ID
+	if claims.TokenType != TokenTypeAccess && claims.TokenType != "" || claims.UserID == uuid.Nil {
+		return nil, errors.New("not an access token")
 	}
 
 	return claims, nil
 }
 
-// ValidateRefreshToken validates a refresh token and returns the user ID
+// ValidateRefreshToken validates a refresh token and returns the user ID. Access,
+// step-up and impersonation tokens are rejected, so they can't be exchanged for a
+// regular session.
 func (m *JWTManager) ValidateRefreshToken(tokenString string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+	claims, err := m.parseToken(tokenString)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if claims.TokenType != TokenTypeRefresh || claims.ImpersonatedBy != nil || claims.StepUp {
+		return uuid.Nil, errors.New("not a refresh token")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid user ID in token")
+	}
+
+	return userID, nil
+}
+
+// parseToken verifies a token's signature and expiry and rejects it if it was revoked,
+// either by its ID or with all of its user's tokens
+func (m *JWTManager) parseToken(tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
@@ -100,18 +258,26 @@ func (m *JWTManager) ValidateRefreshToken(tokenString string) (uuid.UUID, error)
 	})
 
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*JWTClaims)
 	if !ok || !token.Valid {
-		return uuid.Nil, errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
-	userID, err := uuid.Parse(claims.Subject)
-	if err != nil {
-		return uuid.Nil, errors.New("invalid user ID in token")
+	if claims.ID != "" {
+		m.revokedMu.RLock()
+		_, revoked := m.revoked[claims.ID]
+		m.revokedMu.RUnlock()
+		if revoked {
+			return nil, errors.New("token has been revoked")
+		}
 	}
 
-	return userID, nil
+	if m.userTokenRevoked(claims.UserID, claims.IssuedAt) {
+		return nil, errors.New("token has been revoked")
+	}
+
+	return claims, nil
 }
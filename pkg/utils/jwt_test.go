@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestJWTManager() *JWTManager {
+	return NewJWTManager("test-secret", time.Hour, 24*time.Hour, 5*time.Minute)
+}
+
+func TestValidateRefreshTokenRejectsOtherTokens(t *testing.T) {
+	m := newTestJWTManager()
+	userID, tenantID := uuid.New(), uuid.New()
+
+	access, err := m.GenerateAccessToken(userID, tenantID, "user@example.com", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stepUp, _, err := m.GenerateStepUpToken(userID, tenantID, "user@example.com", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	impersonation, _, err := m.GenerateImpersonationToken(userID, tenantID, "user@example.com", nil, nil, uuid.New(), 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"access": access, "step-up": stepUp, "impersonation": impersonation} {
+		if _, err := m.ValidateRefreshToken(token); err == nil {
+			t.Errorf("%s token was accepted as a refresh token", name)
+		}
+	}
+}
+
+func TestValidateRefreshToken(t *testing.T) {
+	m := newTestJWTManager()
+	userID := uuid.New()
+
+	refresh, err := m.GenerateRefreshToken(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.ValidateRefreshToken(refresh)
+	if err != nil {
+		t.Fatalf("ValidateRefreshToken: %v", err)
+	}
+	if got != userID {
+		t.Errorf("user ID = %s, want %s", got, userID)
+	}
+	if _, err := m.ValidateAccessToken(refresh); err == nil {
+		t.Error("refresh token was accepted as an access token")
+	}
+}
+
+func TestValidateRefreshTokenRejectsRevoked(t *testing.T) {
+	m := newTestJWTManager()
+	userID := uuid.New()
+
+	refresh, err := m.GenerateRefreshToken(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Revocation works at second precision, so revoke from a later second
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	m.RevokeUserTokens(userID)
+
+	if _, err := m.ValidateRefreshToken(refresh); err == nil {
+		t.Error("refresh token of a user whose tokens were revoked was accepted")
+	}
+}
+
+func TestRevokedImpersonationTokenIsRejected(t *testing.T) {
+	m := newTestJWTManager()
+	token, _, err := m.GenerateImpersonationToken(uuid.New(), uuid.New(), "user@example.com", nil, nil, uuid.New(), 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := m.ValidateAccessToken(token)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+
+	m.RevokeAccessToken(claims)
+
+	if _, err := m.ValidateAccessToken(token); err == nil {
+		t.Error("revoked impersonation token was accepted as an access token")
+	}
+	if _, err := m.ValidateRefreshToken(token); err == nil {
+		t.Error("revoked impersonation token was accepted as a refresh token")
+	}
+}